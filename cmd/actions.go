@@ -0,0 +1,22 @@
+package cmd
+
+import "github.com/spf13/cobra"
+
+// actionFlags holds the post-validation action flags shared by the validation commands
+type actionFlags struct {
+	dryRun   bool
+	disabled bool
+}
+
+var (
+	validateActionFlags actionFlags
+	checkActionFlags    actionFlags
+	watchActionFlags    actionFlags
+)
+
+// register adds the action flags to a command
+func (f *actionFlags) register(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&f.dryRun, "dry-run", false, "Show the post-validation actions of the preset configuration without running them")
+	cmd.Flags().BoolVar(&f.disabled, "no-actions", false, "Do not run the post-validation actions of the preset configuration")
+	cmd.MarkFlagsMutuallyExclusive("dry-run", "no-actions")
+}
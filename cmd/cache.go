@@ -0,0 +1,121 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/autobrr/sfvbrr/internal/cache"
+	"github.com/spf13/cobra"
+)
+
+// cacheFlags holds the verification cache flags shared by the validation commands
+type cacheFlags struct {
+	trust    bool
+	disabled bool
+	maxAge   time.Duration
+}
+
+var (
+	sfvCacheFlags   cacheFlags
+	zipCacheFlags   cacheFlags
+	checkCacheFlags cacheFlags
+
+	cachePruneMaxAge time.Duration
+)
+
+// register adds the cache flags to a command
+func (f *cacheFlags) register(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&f.trust, "trust-cache", false, "Skip files that are unchanged since they were last verified")
+	cmd.Flags().BoolVar(&f.disabled, "no-cache", false, "Do not read or record verification results in the cache (recorded by default)")
+	cmd.Flags().DurationVar(&f.maxAge, "max-age", 0, "Re-verify files whose cached result is older than this (e.g. 720h, 0 = no limit)")
+	cmd.MarkFlagsMutuallyExclusive("trust-cache", "no-cache")
+}
+
+// open opens the verification cache unless it is disabled.
+// A cache that cannot be opened (e.g. locked by another run) only disables caching.
+func (f *cacheFlags) open() *cache.Cache {
+	if f.disabled {
+		return nil
+	}
+
+	path, err := cache.DefaultPath()
+	if err == nil {
+		var c *cache.Cache
+		if c, err = cache.Open(path); err == nil {
+			return c
+		}
+	}
+
+	fmt.Fprintf(os.Stderr, "Warning: verification cache disabled: %v\n", err)
+	return nil
+}
+
+// closeCache writes pending results and closes the verification cache, if open
+func closeCache(c *cache.Cache) {
+	if c == nil {
+		return
+	}
+	if err := c.Close(); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+}
+
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Manage the verification cache",
+	Long: `Manage the verification cache.
+
+The cache is on by default: the sfv, zip, check and watch commands record the CRC-32
+and verdict of every verified file in ~/.config/sfvbrr/cache.db, keyed by device,
+inode, size and modification time. With --trust-cache, files that are unchanged since
+they were last verified are not read again; --max-age forces a full re-verification
+of results older than the given duration, and --no-cache disables the cache entirely,
+so nothing is read from or written to it.`,
+}
+
+var cachePruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Remove stale entries from the verification cache",
+	Long: `Remove stale entries from the verification cache.
+
+Entries of files that no longer exist or have changed since they were verified are
+removed. With --max-age, entries older than the given duration are removed as well.
+
+Examples:
+  # Remove entries of deleted or modified files
+  sfvbrr cache prune
+
+  # Also remove entries verified more than 30 days ago
+  sfvbrr cache prune --max-age 720h`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		path, err := cache.DefaultPath()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		c, err := cache.Open(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		removed, err := c.Prune(cachePruneMaxAge)
+		closeCache(c)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Printf("Removed %d cache entries\n", removed)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(cacheCmd)
+	cacheCmd.AddCommand(cachePruneCmd)
+
+	cachePruneCmd.Flags().DurationVar(&cachePruneMaxAge, "max-age", 0, "Also remove entries verified longer ago than this (e.g. 720h, 0 = no limit)")
+}
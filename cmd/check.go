@@ -0,0 +1,122 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/autobrr/sfvbrr/internal/check"
+	"github.com/spf13/cobra"
+)
+
+var (
+	checkPresetPath        string
+	checkWorkers           int
+	checkBufferSize        int
+	checkVerbose           bool
+	checkQuiet             bool
+	checkRecursive         bool
+	checkOverwriteCategory string
+	checkCPUProfile        string
+	checkOutputJSON        bool
+	checkOutputYAML        bool
+	checkOutputNDJSON      bool
+)
+
+var checkCmd = &cobra.Command{
+	Use:   "check [folder...]",
+	Short: "Run all configured checks on release folders",
+	Long: `Run all configured checks on release folders and produce a single verdict per release.
+
+The command detects the release category from the folder name and runs the checks
+configured for the category in the preset configuration file:
+
+  rules   Validate the folder contents against the category rules (like "sfvbrr validate")
+  sfv     Validate the CRC-32 checksums of every SFV file in the release (like "sfvbrr sfv -r")
+  zip     Test the integrity of every ZIP file in the release (like "sfvbrr zip -r")
+  rar     Validate every RAR volume set in the release (like "sfvbrr rar -r")
+
+Checks are selected per category with a "checks" list, e.g. "checks: [rules, sfv]".
+Categories without a list run rules, sfv and zip. A check that finds nothing to
+verify is skipped; use the category rules to require SFV or ZIP files.
+
+Post-validation actions configured in the preset configuration file ("actions")
+run on every checked release, e.g. to move failed releases to a quarantine folder;
+use --dry-run to show them without changing anything.
+
+When the recursive option (-r) is used, the command will search for valid
+release folders in all subdirectories of the specified folder(s).
+
+Examples:
+  # Check a single release
+  sfvbrr check /path/to/release
+
+  # Check all releases below a folder and write a single JSON report
+  sfvbrr check -r --json /path/to/releases
+
+  # Override category detection
+  sfvbrr check --overwrite app /path/to/release`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		cleanup, err := setupProfiling(checkCPUProfile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		defer cleanup()
+
+		outputFormat := check.OutputFormatText
+		if checkOutputJSON {
+			outputFormat = check.OutputFormatJSON
+		} else if checkOutputYAML {
+			outputFormat = check.OutputFormatYAML
+		} else if checkOutputNDJSON {
+			outputFormat = check.OutputFormatNDJSON
+		}
+
+		cacheDB := checkCacheFlags.open()
+		opts := check.Options{
+			PresetPath:        checkPresetPath,
+			Workers:           checkWorkers,
+			BufferSize:        checkBufferSize,
+			Verbose:           checkVerbose,
+			Quiet:             checkQuiet,
+			Recursive:         checkRecursive,
+			OverwriteCategory: checkOverwriteCategory,
+			OutputFormat:      outputFormat,
+			Cache:             cacheDB,
+			TrustCache:        checkCacheFlags.trust,
+			MaxAge:            checkCacheFlags.maxAge,
+			SkipActions:       checkActionFlags.disabled,
+			DryRun:            checkActionFlags.dryRun,
+		}
+
+		ctx, cancel := checkTimeoutFlag.context()
+		err = check.CheckFolders(ctx, args, opts)
+		cancel()
+		closeCache(cacheDB)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(checkCmd)
+
+	checkCmd.Flags().StringVarP(&checkPresetPath, "preset", "p", "", "Path to preset YAML file (default: auto-detect)")
+	checkCmd.Flags().IntVarP(&checkWorkers, "workers", "w", 0, "Number of parallel workers (0 = auto-detect)")
+	checkCmd.Flags().IntVarP(&checkBufferSize, "buffer-size", "b", 0, "Buffer size for file reading in bytes (0 = auto, default 64KB)")
+	checkCmd.Flags().BoolVarP(&checkVerbose, "verbose", "v", false, "Show the detailed results of every check")
+	checkCmd.Flags().BoolVarP(&checkQuiet, "quiet", "q", false, "Quiet mode - only show errors")
+	checkCmd.Flags().BoolVarP(&checkRecursive, "recursive", "r", false, "Recursively search for release folders in subdirectories")
+	checkCmd.Flags().StringVar(&checkOverwriteCategory, "overwrite", "", "Override category detection with specified category (bypasses automatic detection)")
+	checkCmd.Flags().StringVar(&checkCPUProfile, "cpuprofile", "", "Write CPU profile to file")
+	checkCmd.Flags().BoolVar(&checkOutputJSON, "json", false, "Output a single aggregated JSON report")
+	checkCmd.Flags().BoolVar(&checkOutputYAML, "yaml", false, "Output a single aggregated YAML report")
+	checkCmd.Flags().BoolVar(&checkOutputNDJSON, "ndjson", false, "Stream results as newline-delimited JSON events")
+	checkCmd.MarkFlagsMutuallyExclusive("json", "yaml", "ndjson")
+	checkCacheFlags.register(checkCmd)
+	checkActionFlags.register(checkCmd)
+	checkTimeoutFlag.register(checkCmd)
+}
@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// timeoutFlag holds the --timeout flag shared by the validation commands
+type timeoutFlag struct {
+	timeout time.Duration
+}
+
+var (
+	sfvTimeoutFlag       timeoutFlag
+	sfvCreateTimeoutFlag timeoutFlag
+	zipTimeoutFlag       timeoutFlag
+	rarTimeoutFlag       timeoutFlag
+	validateTimeoutFlag  timeoutFlag
+	checkTimeoutFlag     timeoutFlag
+)
+
+// register adds the timeout flag to a command
+func (f *timeoutFlag) register(cmd *cobra.Command) {
+	cmd.Flags().DurationVar(&f.timeout, "timeout", 0, "Cancel the command after this duration, e.g. 30m (0 = no limit)")
+}
+
+// context returns the context of a command run. It is cancelled on Ctrl-C or SIGTERM
+// and when the timeout expires; a second Ctrl-C exits immediately.
+func (f *timeoutFlag) context() (context.Context, context.CancelFunc) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	cancel := context.CancelFunc(func() {})
+	if f.timeout > 0 {
+		ctx, cancel = context.WithTimeoutCause(ctx, f.timeout, fmt.Errorf("timed out after %s", f.timeout))
+	}
+
+	// Restore the default signal behavior once cancelled, so a stuck run can still be killed
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+
+	return ctx, func() {
+		cancel()
+		stop()
+	}
+}
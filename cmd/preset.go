@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/autobrr/sfvbrr/internal/preset"
+	"github.com/autobrr/sfvbrr/internal/report"
+	"github.com/spf13/cobra"
+)
+
+var (
+	presetLintOutputJSON   bool
+	presetLintOutputYAML   bool
+	presetLintOutputNDJSON bool
+)
+
+var presetCmd = &cobra.Command{
+	Use:   "preset",
+	Short: "Work with preset configuration files",
+	Long: `Work with preset configuration files.
+
+Use "sfvbrr preset lint" to check a preset file and the files it includes for
+mistakes before using it.`,
+}
+
+var presetLintCmd = &cobra.Command{
+	Use:   "lint [file]",
+	Short: "Check a preset file for errors",
+	Long: `Check a preset file, its includes and its presets.local.yaml overlay for errors.
+
+Every problem is reported at once, with the file, line and column it was found at:
+unknown fields (e.g. "typ: dir"), mistyped values, invalid regex and glob patterns,
+unbalanced braces, unknown types, checks and verifications, min greater than max,
+unknown or cyclic extends and includes, unknown release attributes in rule set
+conditions and naming policies, and invalid actions.
+
+Warnings are reported for files with an outdated schema_version, which are migrated
+when they are loaded, and for categories that are never detected from release names.
+The command exits with status 1 if there are errors; warnings alone don't fail it.
+
+Without a file, the preset file in the default location is checked.
+
+Examples:
+  # Check the default preset file
+  sfvbrr preset lint
+
+  # Check a custom preset file
+  sfvbrr preset lint /path/to/presets.yaml`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		presetPath := ""
+		if len(args) > 0 {
+			presetPath = args[0]
+		}
+
+		problems, err := preset.Lint(presetPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		for i := range problems {
+			problems[i].File = displayPath(problems[i].File)
+		}
+		errs := problems.Errors()
+
+		format := report.Format("")
+		if presetLintOutputJSON {
+			format = report.FormatJSON
+		} else if presetLintOutputYAML {
+			format = report.FormatYAML
+		} else if presetLintOutputNDJSON {
+			format = report.FormatNDJSON
+		}
+
+		if format != "" {
+			w := report.NewWriter(format, "preset lint")
+			for _, problem := range problems {
+				w.Add(problem, problem.Severity == preset.SeverityError)
+			}
+			if err := w.Close(); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+		} else {
+			for _, problem := range problems {
+				fmt.Println(problem.String())
+			}
+			if len(problems) == 0 {
+				fmt.Println("No problems found")
+			} else {
+				fmt.Printf("%d error(s), %d warning(s)\n", len(errs), len(problems)-len(errs))
+			}
+		}
+
+		if len(errs) > 0 {
+			os.Exit(1)
+		}
+	},
+}
+
+// displayPath shortens a path to be relative to the working directory, if it is inside it
+func displayPath(path string) string {
+	wd, err := os.Getwd()
+	if err != nil || !filepath.IsAbs(path) {
+		return path
+	}
+	rel, err := filepath.Rel(wd, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return path
+	}
+	return rel
+}
+
+func init() {
+	rootCmd.AddCommand(presetCmd)
+	presetCmd.AddCommand(presetLintCmd)
+
+	presetLintCmd.Flags().BoolVar(&presetLintOutputJSON, "json", false, "Output a single aggregated JSON report")
+	presetLintCmd.Flags().BoolVar(&presetLintOutputYAML, "yaml", false, "Output a single aggregated YAML report")
+	presetLintCmd.Flags().BoolVar(&presetLintOutputNDJSON, "ndjson", false, "Stream results as newline-delimited JSON events")
+	presetLintCmd.MarkFlagsMutuallyExclusive("json", "yaml", "ndjson")
+}
@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/autobrr/sfvbrr/internal/rar"
+	"github.com/spf13/cobra"
+)
+
+var (
+	rarVerbose      bool
+	rarQuiet        bool
+	rarRecursive    bool
+	rarCPUProfile   string
+	rarOutputJSON   bool
+	rarOutputYAML   bool
+	rarOutputNDJSON bool
+)
+
+var rarCmd = &cobra.Command{
+	Use:   "rar [folder...]",
+	Short: "Validate RAR volume sets",
+	Long: `Validate RAR volume sets by reading the archive headers of every volume.
+
+The command will search for RAR volumes (case insensitive) in each specified folder,
+group them into sets and check that each set is complete and continuous (no missing
+.r05 between .r04 and .r06), for both old-style (.rar, .r00, .r01, ...) and new-style
+(.part01.rar, .part02.rar, ...) naming.
+
+Every volume must carry a valid RAR4 or RAR5 signature and main archive header, and
+the volume flags, volume numbers and file split flags must be consistent across the
+set. No data is decompressed.
+
+When the recursive option (-r) is used, the command will search for RAR volumes in all
+subdirectories of the specified folder(s).
+
+Examples:
+  # Validate RAR sets in a single folder
+  sfvbrr rar /path/to/release
+
+  # Validate RAR sets in multiple folders
+  sfvbrr rar /path/to/release1 /path/to/release2
+
+  # Validate RAR sets recursively
+  sfvbrr rar -r /path/to/releases`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		cleanup, err := setupProfiling(rarCPUProfile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		defer cleanup()
+
+		outputFormat := rar.OutputFormatText
+		if rarOutputJSON {
+			outputFormat = rar.OutputFormatJSON
+		} else if rarOutputYAML {
+			outputFormat = rar.OutputFormatYAML
+		} else if rarOutputNDJSON {
+			outputFormat = rar.OutputFormatNDJSON
+		}
+
+		opts := rar.Options{
+			Verbose:      rarVerbose,
+			Quiet:        rarQuiet,
+			Recursive:    rarRecursive,
+			OutputFormat: outputFormat,
+		}
+
+		ctx, cancel := rarTimeoutFlag.context()
+		err = rar.ValidateFolders(ctx, args, opts)
+		cancel()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(rarCmd)
+
+	rarCmd.Flags().BoolVarP(&rarVerbose, "verbose", "v", false, "Show detailed validation results for each volume")
+	rarCmd.Flags().BoolVarP(&rarQuiet, "quiet", "q", false, "Quiet mode - only show errors")
+	rarCmd.Flags().BoolVarP(&rarRecursive, "recursive", "r", false, "Recursively search for RAR files in subdirectories")
+	rarCmd.Flags().StringVar(&rarCPUProfile, "cpuprofile", "", "Write CPU profile to file")
+	rarCmd.Flags().BoolVar(&rarOutputJSON, "json", false, "Output a single aggregated JSON report")
+	rarCmd.Flags().BoolVar(&rarOutputYAML, "yaml", false, "Output a single aggregated YAML report")
+	rarCmd.Flags().BoolVar(&rarOutputNDJSON, "ndjson", false, "Stream results as newline-delimited JSON events")
+	rarCmd.MarkFlagsMutuallyExclusive("json", "yaml", "ndjson")
+	rarTimeoutFlag.register(rarCmd)
+}
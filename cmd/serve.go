@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/autobrr/sfvbrr/internal/server"
+	"github.com/spf13/cobra"
+)
+
+var (
+	serveAddr        string
+	serveToken       string
+	serveRoots       []string
+	servePresetPath  string
+	serveWorkers     int
+	serveBufferSize  int
+	serveQueueSize   int
+	serveConcurrency int
+	serveRetention   time.Duration
+	serveTimeout     time.Duration
+)
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serve validation jobs over an HTTP/JSON API",
+	Long: `Serve the validate, sfv, zip and check commands over a small HTTP/JSON API,
+so hosts without access to the storage can request validations.
+
+Jobs are submitted to a bounded queue and run in the background:
+
+  POST /api/v1/jobs              Submit a job, e.g. {"type": "sfv", "path": "/data/Release-GRP"}
+  GET  /api/v1/jobs              List jobs
+  GET  /api/v1/jobs/{id}         Job status: queued, running, done or cancelled, with the verdict
+  GET  /api/v1/jobs/{id}/result  JSON report of a finished job, like the --json output
+  GET  /healthz                  Health check
+
+Every API request needs the token as "Authorization: Bearer <token>". The token is
+read from --token or the SFVBRR_TOKEN environment variable. Jobs can only validate
+directories below the allowed --root directories; symlinks are resolved first.
+SFV entries, ZIP files and RAR volumes that resolve to a file outside the roots are
+reported as invalid and never read.
+
+Examples:
+  # Serve the releases below /data on port 8080
+  SFVBRR_TOKEN=secret sfvbrr serve --root /data
+
+  # Submit a job and fetch its result
+  curl -H "Authorization: Bearer secret" -d '{"type":"check","path":"/data/Release-GRP"}' localhost:8080/api/v1/jobs
+  curl -H "Authorization: Bearer secret" localhost:8080/api/v1/jobs/<id>/result`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		token := serveToken
+		if token == "" {
+			token = os.Getenv("SFVBRR_TOKEN")
+		}
+
+		opts := server.DefaultOptions()
+		opts.Addr = serveAddr
+		opts.Token = token
+		opts.Roots = serveRoots
+		opts.PresetPath = servePresetPath
+		opts.QueueSize = serveQueueSize
+		opts.Concurrency = serveConcurrency
+		opts.Retention = serveRetention
+		opts.JobTimeout = serveTimeout
+		opts.Checksum.Workers = serveWorkers
+		opts.Checksum.BufferSize = serveBufferSize
+
+		srv, err := server.New(opts)
+		if err == nil {
+			fmt.Fprintf(os.Stderr, "Serving on %s\n", serveAddr)
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			err = srv.Run(ctx)
+			stop()
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(serveCmd)
+
+	serveCmd.Flags().StringVarP(&serveAddr, "listen", "l", ":8080", "Address to listen on")
+	serveCmd.Flags().StringVar(&serveToken, "token", "", "API token required from clients (default: $SFVBRR_TOKEN)")
+	serveCmd.Flags().StringArrayVar(&serveRoots, "root", nil, "Directory jobs may validate, including subdirectories (repeatable, required)")
+	serveCmd.Flags().StringVarP(&servePresetPath, "preset", "p", "", "Path to preset YAML file (default: auto-detect)")
+	serveCmd.Flags().IntVarP(&serveWorkers, "workers", "w", 0, "Number of parallel workers per job (0 = auto-detect)")
+	serveCmd.Flags().IntVarP(&serveBufferSize, "buffer-size", "b", 0, "Buffer size for file reading in bytes (0 = auto, default 64KB)")
+	serveCmd.Flags().IntVar(&serveQueueSize, "queue-size", 64, "Maximum number of queued jobs")
+	serveCmd.Flags().IntVar(&serveConcurrency, "jobs", 1, "Number of jobs run at the same time")
+	serveCmd.Flags().DurationVar(&serveRetention, "retention", time.Hour, "How long results of finished jobs are kept")
+	serveCmd.Flags().DurationVar(&serveTimeout, "timeout", 0, "Cancel jobs running longer than this, e.g. 30m (0 = no limit)")
+	serveCmd.MarkFlagRequired("root")
+}
@@ -10,27 +10,42 @@ import (
 )
 
 var (
-	sfvWorkers     int
-	sfvBufferSize  int
-	sfvVerbose     bool
-	sfvQuiet       bool
-	sfvRecursive   bool
-	sfvCPUProfile  string
-	sfvOutputJSON  bool
-	sfvOutputYAML  bool
+	sfvWorkers      int
+	sfvBufferSize   int
+	sfvVerbose      bool
+	sfvQuiet        bool
+	sfvRecursive    bool
+	sfvCPUProfile   string
+	sfvOutputJSON   bool
+	sfvOutputYAML   bool
+	sfvOutputNDJSON bool
+	sfvOrphans      bool
+	sfvIgnore       []string
 )
 
 var sfvCmd = &cobra.Command{
 	Use:   "sfv [folder...]",
-	Short: "Validate SFV CRC-32 checksums",
+	Short: "Validate SFV CRC-32 checksums and other checksum files",
 	Long: `Validate SFV (Simple File Verification) CRC-32 checksums for files in the specified folder(s).
 
 The command will search for an SFV file (case insensitive) in each specified folder
 and validate all files listed in the SFV file against their CRC-32 checksums.
 
+MD5, SHA-1, SHA-256 and BLAKE3 checksum files are validated the same way. They are
+found by their extension (.md5, .sha1, .sha256, .b3 and the *sum variants) or name
+(MD5SUMS, SHA256SUMS, ...). Both the GNU coreutils format ("digest  filename") and
+the BSD tagged format ("SHA256 (filename) = digest") are detected from the content.
+
 When the recursive option (-r) is used, the command will search for SFV files in all
 subdirectories of the specified folder(s).
 
+With --orphans, files present in the folder but not listed in the SFV file (for example
+a stray extra .r15 volume) are reported and fail the validation. Files matching an
+--ignore pattern are skipped; patterns ending with "/" skip whole directories, and
+subdirectories with their own SFV or checksum file are always skipped.
+
+Use "sfvbrr sfv create" to generate a new SFV file for a folder.
+
 Examples:
   # Validate a single folder
   sfvbrr sfv /path/to/release
@@ -39,7 +54,10 @@ Examples:
   sfvbrr sfv /path/to/release1 /path/to/release2
 
   # Validate recursively
-  sfvbrr sfv -r /path/to/releases`,
+  sfvbrr sfv -r /path/to/releases
+
+  # Also report files that are not listed in the SFV
+  sfvbrr sfv --orphans --ignore '*.nfo' --ignore '*.sfv' --ignore 'Sample/' /path/to/release`,
 	Args: cobra.MinimumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		cleanup, err := setupProfiling(sfvCPUProfile)
@@ -54,8 +72,11 @@ Examples:
 			outputFormat = checksum.OutputFormatJSON
 		} else if sfvOutputYAML {
 			outputFormat = checksum.OutputFormatYAML
+		} else if sfvOutputNDJSON {
+			outputFormat = checksum.OutputFormatNDJSON
 		}
 
+		cacheDB := sfvCacheFlags.open()
 		opts := checksum.Options{
 			Workers:      sfvWorkers,
 			BufferSize:   sfvBufferSize,
@@ -63,9 +84,19 @@ Examples:
 			Quiet:        sfvQuiet,
 			Recursive:    sfvRecursive,
 			OutputFormat: outputFormat,
+
+			CheckOrphans:   sfvOrphans,
+			IgnorePatterns: sfvIgnore,
+			Cache:          cacheDB,
+			TrustCache:     sfvCacheFlags.trust,
+			MaxAge:         sfvCacheFlags.maxAge,
 		}
 
-		if err := checksum.ValidateFolders(args, opts); err != nil {
+		ctx, cancel := sfvTimeoutFlag.context()
+		err = checksum.ValidateFolders(ctx, args, opts)
+		cancel()
+		closeCache(cacheDB)
+		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 			os.Exit(1)
 		}
@@ -81,9 +112,14 @@ func init() {
 	sfvCmd.Flags().BoolVarP(&sfvQuiet, "quiet", "q", false, "Quiet mode - only show errors")
 	sfvCmd.Flags().BoolVarP(&sfvRecursive, "recursive", "r", false, "Recursively search for SFV files in subdirectories")
 	sfvCmd.Flags().StringVar(&sfvCPUProfile, "cpuprofile", "", "Write CPU profile to file")
-	sfvCmd.Flags().BoolVar(&sfvOutputJSON, "json", false, "Output results in JSON format")
-	sfvCmd.Flags().BoolVar(&sfvOutputYAML, "yaml", false, "Output results in YAML format")
-	sfvCmd.MarkFlagsMutuallyExclusive("json", "yaml")
+	sfvCmd.Flags().BoolVar(&sfvOutputJSON, "json", false, "Output a single aggregated JSON report")
+	sfvCmd.Flags().BoolVar(&sfvOutputYAML, "yaml", false, "Output a single aggregated YAML report")
+	sfvCmd.Flags().BoolVar(&sfvOutputNDJSON, "ndjson", false, "Stream results as newline-delimited JSON events")
+	sfvCmd.Flags().BoolVar(&sfvOrphans, "orphans", false, "Report files in the folder that are not listed in the SFV")
+	sfvCmd.Flags().StringArrayVar(&sfvIgnore, "ignore", checksum.DefaultOrphanIgnores, "Pattern of files not reported as orphans (repeatable, trailing / matches directories)")
+	sfvCmd.MarkFlagsMutuallyExclusive("json", "yaml", "ndjson")
+	sfvCacheFlags.register(sfvCmd)
+	sfvTimeoutFlag.register(sfvCmd)
 }
 
 // setupProfiling sets up CPU profiling if the cpuprofile path is provided.
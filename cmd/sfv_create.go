@@ -0,0 +1,137 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/autobrr/sfvbrr/internal/checksum"
+	"github.com/spf13/cobra"
+)
+
+var (
+	sfvCreateWorkers    int
+	sfvCreateBufferSize int
+	sfvCreateVerbose    bool
+	sfvCreateQuiet      bool
+	sfvCreateOutput     string
+	sfvCreateInclude    []string
+	sfvCreateExclude    []string
+	sfvCreateForce      bool
+	sfvCreateAlgorithm  string
+	sfvCreateTag        bool
+)
+
+var sfvCreateCmd = &cobra.Command{
+	Use:   "create [folder...]",
+	Short: "Create SFV or checksum files from folder contents",
+	Long: `Create a scene-style SFV file with CRC-32 checksums for the files in the specified folder(s).
+
+The SFV file is written into the folder as <folder name>.sfv (lowercase) unless
+--output is given. Archive volumes are listed first (.rar, .r00, .r01, ... or
+.part01.rar, .part02.rar, ...), followed by all other files in name order.
+
+NFO, DIZ, SFV and other checksum files are never included. Use --include and
+--exclude to further restrict which files are hashed.
+
+With --algorithm md5, sha1, sha256 or blake3 a checksum file in the GNU coreutils
+format ("digest  filename", as written by sha256sum) is created instead, named
+<folder name>.md5, .sha1, .sha256 or .b3. Add --tag for the BSD format
+("SHA256 (filename) = digest").
+
+Examples:
+  # Create an SFV file for a single folder
+  sfvbrr sfv create /path/to/release
+
+  # Only hash the RAR volumes
+  sfvbrr sfv create --include "*.rar" --include "*.r??" /path/to/release
+
+  # Write the SFV file to a custom location
+  sfvbrr sfv create -o /tmp/release.sfv /path/to/release
+
+  # Create a SHA-256 checksum file in the BSD format
+  sfvbrr sfv create -a sha256 --tag /path/to/release`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if sfvCreateOutput != "" && len(args) > 1 {
+			fmt.Fprintf(os.Stderr, "Error: --output can only be used with a single folder\n")
+			os.Exit(1)
+		}
+
+		hasher, err := checksum.LookupHasher(sfvCreateAlgorithm)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if sfvCreateTag && hasher.Algorithm == checksum.AlgorithmCRC32 {
+			fmt.Fprintf(os.Stderr, "Error: --tag requires --algorithm md5, sha1, sha256 or blake3\n")
+			os.Exit(1)
+		}
+
+		opts := checksum.CreateOptions{
+			Options: checksum.Options{
+				Workers:      sfvCreateWorkers,
+				BufferSize:   sfvCreateBufferSize,
+				Verbose:      sfvCreateVerbose,
+				Quiet:        sfvCreateQuiet,
+				OutputFormat: checksum.OutputFormatText,
+			},
+			Output:    sfvCreateOutput,
+			Include:   sfvCreateInclude,
+			Exclude:   sfvCreateExclude,
+			Force:     sfvCreateForce,
+			Version:   version,
+			Algorithm: hasher.Algorithm,
+			Tagged:    sfvCreateTag,
+		}
+
+		ctx, cancel := sfvCreateTimeoutFlag.context()
+
+		var hasErrors bool
+		for _, folder := range args {
+			if ctx.Err() != nil {
+				// Interrupted; the remaining folders are not hashed
+				hasErrors = true
+				break
+			}
+
+			absPath, err := filepath.Abs(folder)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: failed to resolve path %s: %v\n", folder, err)
+				hasErrors = true
+				continue
+			}
+
+			result, err := checksum.CreateSFV(ctx, absPath, opts)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				hasErrors = true
+				continue
+			}
+
+			checksum.DisplayCreateResult(result, opts)
+		}
+
+		cancel()
+
+		if hasErrors {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	sfvCmd.AddCommand(sfvCreateCmd)
+
+	sfvCreateCmd.Flags().IntVarP(&sfvCreateWorkers, "workers", "w", 0, "Number of parallel workers (0 = auto-detect)")
+	sfvCreateCmd.Flags().IntVarP(&sfvCreateBufferSize, "buffer-size", "b", 0, "Buffer size for file reading in bytes (0 = auto, default 64KB)")
+	sfvCreateCmd.Flags().BoolVarP(&sfvCreateVerbose, "verbose", "v", false, "Show the checksum of each file")
+	sfvCreateCmd.Flags().BoolVarP(&sfvCreateQuiet, "quiet", "q", false, "Quiet mode - only show errors")
+	sfvCreateCmd.Flags().StringVarP(&sfvCreateOutput, "output", "o", "", "Path of the SFV file to write (default: <folder>/<folder name>.sfv)")
+	sfvCreateCmd.Flags().StringArrayVar(&sfvCreateInclude, "include", nil, "Only hash files matching this glob pattern (repeatable)")
+	sfvCreateCmd.Flags().StringArrayVar(&sfvCreateExclude, "exclude", nil, "Skip files matching this glob pattern (repeatable)")
+	sfvCreateCmd.Flags().BoolVarP(&sfvCreateForce, "force", "f", false, "Overwrite an existing SFV file")
+	sfvCreateCmd.Flags().StringVarP(&sfvCreateAlgorithm, "algorithm", "a", "crc32", "Checksum algorithm: crc32 (SFV), md5, sha1, sha256 or blake3")
+	sfvCreateCmd.Flags().BoolVar(&sfvCreateTag, "tag", false, "Write BSD-style \"SHA256 (filename) = digest\" lines")
+	sfvCreateTimeoutFlag.register(sfvCreateCmd)
+}
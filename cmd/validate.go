@@ -17,6 +17,7 @@ var (
 	validateCPUProfile        string
 	validateOutputJSON        bool
 	validateOutputYAML        bool
+	validateOutputNDJSON      bool
 )
 
 var validateCmd = &cobra.Command{
@@ -33,6 +34,9 @@ release folders in all subdirectories of the specified folder(s).
 The --overwrite flag allows you to bypass automatic category detection and
 manually specify a category for validation.
 
+Post-validation actions configured in the preset configuration file ("actions")
+run on every validated folder; use --dry-run to show them without changing anything.
+
 Examples:
   # Validate a single folder
   sfvbrr validate /path/to/release
@@ -44,7 +48,10 @@ Examples:
   sfvbrr validate -r /path/to/releases
 
   # Override category detection
-  sfvbrr validate --overwrite app /path/to/release`,
+  sfvbrr validate --overwrite app /path/to/release
+
+  # Show which actions would run
+  sfvbrr validate --dry-run /path/to/release`,
 	Args: cobra.MinimumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		cleanup, err := setupProfiling(validateCPUProfile)
@@ -59,6 +66,8 @@ Examples:
 			outputFormat = validate.OutputFormatJSON
 		} else if validateOutputYAML {
 			outputFormat = validate.OutputFormatYAML
+		} else if validateOutputNDJSON {
+			outputFormat = validate.OutputFormatNDJSON
 		}
 
 		opts := validate.Options{
@@ -68,9 +77,14 @@ Examples:
 			Recursive:         validateRecursive,
 			OverwriteCategory: validateOverwriteCategory,
 			OutputFormat:      outputFormat,
+			SkipActions:       validateActionFlags.disabled,
+			DryRun:            validateActionFlags.dryRun,
 		}
 
-		if err := validate.ValidateFolders(args, opts); err != nil {
+		ctx, cancel := validateTimeoutFlag.context()
+		err = validate.ValidateFolders(ctx, args, opts)
+		cancel()
+		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 			os.Exit(1)
 		}
@@ -86,7 +100,10 @@ func init() {
 	validateCmd.Flags().BoolVarP(&validateRecursive, "recursive", "r", false, "Recursively search for release folders in subdirectories")
 	validateCmd.Flags().StringVar(&validateOverwriteCategory, "overwrite", "", "Override category detection with specified category (bypasses automatic detection)")
 	validateCmd.Flags().StringVar(&validateCPUProfile, "cpuprofile", "", "Write CPU profile to file")
-	validateCmd.Flags().BoolVar(&validateOutputJSON, "json", false, "Output results in JSON format")
-	validateCmd.Flags().BoolVar(&validateOutputYAML, "yaml", false, "Output results in YAML format")
-	validateCmd.MarkFlagsMutuallyExclusive("json", "yaml")
+	validateCmd.Flags().BoolVar(&validateOutputJSON, "json", false, "Output a single aggregated JSON report")
+	validateCmd.Flags().BoolVar(&validateOutputYAML, "yaml", false, "Output a single aggregated YAML report")
+	validateCmd.Flags().BoolVar(&validateOutputNDJSON, "ndjson", false, "Stream results as newline-delimited JSON events")
+	validateCmd.MarkFlagsMutuallyExclusive("json", "yaml", "ndjson")
+	validateActionFlags.register(validateCmd)
+	validateTimeoutFlag.register(validateCmd)
 }
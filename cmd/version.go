@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"runtime/debug"
 
+	"github.com/autobrr/sfvbrr/internal/report"
 	"github.com/spf13/cobra"
 )
 
@@ -32,6 +33,7 @@ func SetVersion(v, bt string) {
 	}
 	version = v
 	buildTime = bt
+	report.SetVersion(v)
 }
 
 func init() {
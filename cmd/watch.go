@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/autobrr/sfvbrr/internal/check"
+	"github.com/autobrr/sfvbrr/internal/watch"
+	"github.com/spf13/cobra"
+)
+
+var (
+	watchPresetPath        string
+	watchWorkers           int
+	watchBufferSize        int
+	watchVerbose           bool
+	watchOverwriteCategory string
+	watchQuietPeriod       time.Duration
+	watchTimeout           time.Duration
+	watchStatePath         string
+	watchOutputNDJSON      bool
+	watchCacheFlags        cacheFlags
+)
+
+var watchCmd = &cobra.Command{
+	Use:   "watch [dir...]",
+	Short: "Check releases as they land in incoming directories",
+	Long: `Watch incoming directories and check every release folder that lands in them.
+
+Every direct subdirectory of a watched directory is a release. Once no file in a
+release has been written for the quiet period, the release is checked like
+"sfvbrr check" does: the category rules plus the SFV and ZIP checks configured for
+the category in the preset configuration file.
+
+Each verdict is emitted as an event, one line per event; use --ndjson for
+structured JSON events. Checked releases are recorded in a state file
+(default ~/.config/sfvbrr/watch-state.json), so releases that are unchanged since
+their last check are not checked again after a restart. Post-validation actions
+configured in the preset configuration file run after every check.
+
+Examples:
+  # Watch an incoming directory
+  sfvbrr watch /path/to/incoming
+
+  # Wait two minutes after the last write and emit JSON events
+  sfvbrr watch --quiet-period 2m --ndjson /path/to/incoming`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		outputFormat := watch.OutputFormatText
+		if watchOutputNDJSON {
+			outputFormat = watch.OutputFormatNDJSON
+		}
+
+		cacheDB := watchCacheFlags.open()
+		opts := watch.Options{
+			Check: check.Options{
+				PresetPath:        watchPresetPath,
+				Workers:           watchWorkers,
+				BufferSize:        watchBufferSize,
+				Verbose:           watchVerbose,
+				OverwriteCategory: watchOverwriteCategory,
+				Cache:             cacheDB,
+				TrustCache:        watchCacheFlags.trust,
+				MaxAge:            watchCacheFlags.maxAge,
+				SkipActions:       watchActionFlags.disabled,
+				DryRun:            watchActionFlags.dryRun,
+			},
+			QuietPeriod:  watchQuietPeriod,
+			CheckTimeout: watchTimeout,
+			StatePath:    watchStatePath,
+			OutputFormat: outputFormat,
+		}
+
+		watcher, err := watch.New(args, opts)
+		if err == nil {
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			err = watcher.Run(ctx)
+			stop()
+		}
+		closeCache(cacheDB)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(watchCmd)
+
+	watchCmd.Flags().StringVarP(&watchPresetPath, "preset", "p", "", "Path to preset YAML file (default: auto-detect)")
+	watchCmd.Flags().IntVarP(&watchWorkers, "workers", "w", 0, "Number of parallel workers (0 = auto-detect)")
+	watchCmd.Flags().IntVarP(&watchBufferSize, "buffer-size", "b", 0, "Buffer size for file reading in bytes (0 = auto, default 64KB)")
+	watchCmd.Flags().BoolVarP(&watchVerbose, "verbose", "v", false, "Show skipped releases and the detailed results of failed releases")
+	watchCmd.Flags().StringVar(&watchOverwriteCategory, "overwrite", "", "Override category detection with specified category (bypasses automatic detection)")
+	watchCmd.Flags().DurationVar(&watchQuietPeriod, "quiet-period", 30*time.Second, "Time without writes before a release is checked")
+	watchCmd.Flags().DurationVar(&watchTimeout, "timeout", 0, "Cancel the check of a release after this duration, e.g. 30m (0 = no limit)")
+	watchCmd.Flags().StringVar(&watchStatePath, "state", "", "Path to the state file of checked releases (default: ~/.config/sfvbrr/watch-state.json)")
+	watchCmd.Flags().BoolVar(&watchOutputNDJSON, "ndjson", false, "Emit events as newline-delimited JSON")
+	watchCacheFlags.register(watchCmd)
+	watchActionFlags.register(watchCmd)
+}
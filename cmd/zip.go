@@ -9,14 +9,15 @@ import (
 )
 
 var (
-	zipWorkers     int
-	zipBufferSize  int
-	zipVerbose     bool
-	zipQuiet       bool
-	zipRecursive   bool
-	zipCPUProfile  string
-	zipOutputJSON  bool
-	zipOutputYAML  bool
+	zipWorkers      int
+	zipBufferSize   int
+	zipVerbose      bool
+	zipQuiet        bool
+	zipRecursive    bool
+	zipCPUProfile   string
+	zipOutputJSON   bool
+	zipOutputYAML   bool
+	zipOutputNDJSON bool
 )
 
 var zipCmd = &cobra.Command{
@@ -26,6 +27,8 @@ var zipCmd = &cobra.Command{
 
 The command will search for ZIP files (case insensitive) in each specified folder
 and validate all entries in each ZIP file by reading them and verifying their CRC-32 checksums.
+Each archive is opened only once, and the entries of all ZIP files found in a folder
+are validated in parallel by a shared pool of workers.
 
 When the recursive option (-r) is used, the command will search for ZIP files in all
 subdirectories of the specified folder(s).
@@ -53,8 +56,11 @@ Examples:
 			outputFormat = checksum.OutputFormatJSON
 		} else if zipOutputYAML {
 			outputFormat = checksum.OutputFormatYAML
+		} else if zipOutputNDJSON {
+			outputFormat = checksum.OutputFormatNDJSON
 		}
 
+		cacheDB := zipCacheFlags.open()
 		opts := checksum.Options{
 			Workers:      zipWorkers,
 			BufferSize:   zipBufferSize,
@@ -62,9 +68,16 @@ Examples:
 			Quiet:        zipQuiet,
 			Recursive:    zipRecursive,
 			OutputFormat: outputFormat,
+			Cache:        cacheDB,
+			TrustCache:   zipCacheFlags.trust,
+			MaxAge:       zipCacheFlags.maxAge,
 		}
 
-		if err := checksum.ValidateZIPFolders(args, opts); err != nil {
+		ctx, cancel := zipTimeoutFlag.context()
+		err = checksum.ValidateZIPFolders(ctx, args, opts)
+		cancel()
+		closeCache(cacheDB)
+		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 			os.Exit(1)
 		}
@@ -80,7 +93,10 @@ func init() {
 	zipCmd.Flags().BoolVarP(&zipQuiet, "quiet", "q", false, "Quiet mode - only show errors")
 	zipCmd.Flags().BoolVarP(&zipRecursive, "recursive", "r", false, "Recursively search for ZIP files in subdirectories")
 	zipCmd.Flags().StringVar(&zipCPUProfile, "cpuprofile", "", "Write CPU profile to file")
-	zipCmd.Flags().BoolVar(&zipOutputJSON, "json", false, "Output results in JSON format")
-	zipCmd.Flags().BoolVar(&zipOutputYAML, "yaml", false, "Output results in YAML format")
-	zipCmd.MarkFlagsMutuallyExclusive("json", "yaml")
+	zipCmd.Flags().BoolVar(&zipOutputJSON, "json", false, "Output a single aggregated JSON report")
+	zipCmd.Flags().BoolVar(&zipOutputYAML, "yaml", false, "Output a single aggregated YAML report")
+	zipCmd.Flags().BoolVar(&zipOutputNDJSON, "ndjson", false, "Stream results as newline-delimited JSON events")
+	zipCmd.MarkFlagsMutuallyExclusive("json", "yaml", "ndjson")
+	zipCacheFlags.register(zipCmd)
+	zipTimeoutFlag.register(zipCmd)
 }
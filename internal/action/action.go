@@ -0,0 +1,301 @@
+package action
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/autobrr/sfvbrr/internal/preset"
+)
+
+// DefaultSymlinkName is the name of symlink actions without a target, like glftpd zipscripts use
+const DefaultSymlinkName = "(incomplete)-{release}"
+
+// Verdict is the outcome of the checks of a release that actions are run on
+type Verdict struct {
+	Folder   string   // Absolute path of the release folder
+	Category string   // Detected or overwritten category
+	Valid    bool     // Whether the release passed
+	Errors   []string // Error types of a failed release
+	Result   any      // Result passed as JSON on stdin to exec actions
+}
+
+// Outcome is the result of a single action
+type Outcome struct {
+	Type        string `json:"type" yaml:"type"`
+	Description string `json:"description" yaml:"description"`
+	DryRun      bool   `json:"dry_run,omitempty" yaml:"dry_run,omitempty"`
+	Error       string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// Failed returns the outcomes of actions that failed
+func Failed(outcomes []Outcome) []Outcome {
+	var failed []Outcome
+	for _, outcome := range outcomes {
+		if outcome.Error != "" {
+			failed = append(failed, outcome)
+		}
+	}
+	return failed
+}
+
+// Run runs the configured actions on a release in order, except that move actions
+// run after all others. Marker and symlink actions reflect the current verdict: when
+// they don't apply, a marker or symlink left by an earlier run is removed. In dry-run
+// mode nothing is changed on disk.
+func Run(actions []preset.Action, verdict Verdict, dryRun bool) []Outcome {
+	var outcomes []Outcome
+	moved := false
+
+	ordered := slices.Clone(actions)
+	slices.SortStableFunc(ordered, func(a, b preset.Action) int {
+		return moveOrder(a) - moveOrder(b)
+	})
+
+	for _, action := range ordered {
+		if len(action.Categories) > 0 && !slices.Contains(action.Categories, verdict.Category) {
+			continue
+		}
+
+		applies := Applies(action, verdict)
+		var outcome *Outcome
+		switch action.Type {
+		case preset.ActionMove:
+			// A release selected by several move actions is moved by the first only
+			if applies && !moved {
+				outcome = move(action, verdict, dryRun)
+				moved = true
+			}
+		case preset.ActionMarker:
+			outcome = marker(action, verdict, applies, dryRun)
+		case preset.ActionSymlink:
+			outcome = symlink(action, verdict, applies, dryRun)
+		case preset.ActionExec:
+			if applies {
+				outcome = run(action, verdict, dryRun)
+			}
+		}
+
+		if outcome != nil {
+			outcome.Type = action.Type
+			outcome.DryRun = dryRun
+			outcomes = append(outcomes, *outcome)
+		}
+	}
+
+	return outcomes
+}
+
+// moveOrder sorts move actions after all other actions
+func moveOrder(action preset.Action) int {
+	if action.Type == preset.ActionMove {
+		return 1
+	}
+	return 0
+}
+
+// Applies reports whether an action is selected by the verdict of a release
+func Applies(action preset.Action, verdict Verdict) bool {
+	switch action.On {
+	case preset.OnAlways:
+		return true
+	case preset.OnPass:
+		return verdict.Valid
+	default:
+		if verdict.Valid {
+			return false
+		}
+		if len(action.Errors) == 0 {
+			return true
+		}
+		for _, errorType := range verdict.Errors {
+			if slices.Contains(action.Errors, errorType) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// expand replaces the {release} and {category} placeholders of an action setting
+func expand(value string, verdict Verdict) string {
+	return strings.NewReplacer(
+		"{release}", filepath.Base(verdict.Folder),
+		"{category}", verdict.Category,
+	).Replace(value)
+}
+
+// resolveDir resolves an action directory; relative directories are relative to the
+// directory containing the release
+func resolveDir(dir string, verdict Verdict) string {
+	dir = expand(dir, verdict)
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(filepath.Dir(verdict.Folder), dir)
+	}
+	return filepath.Clean(dir)
+}
+
+// move moves the release folder into the target directory
+func move(action preset.Action, verdict Verdict, dryRun bool) *Outcome {
+	targetDir := resolveDir(action.Target, verdict)
+	destination := filepath.Join(targetDir, filepath.Base(verdict.Folder))
+
+	if dryRun {
+		return &Outcome{Description: fmt.Sprintf("would move %s to %s", verdict.Folder, targetDir)}
+	}
+
+	outcome := &Outcome{Description: fmt.Sprintf("moved %s to %s", verdict.Folder, targetDir)}
+	if _, err := os.Lstat(destination); err == nil {
+		outcome.Error = fmt.Sprintf("failed to move release: %s already exists", destination)
+		return outcome
+	}
+	if err := os.MkdirAll(targetDir, 0755); err != nil {
+		outcome.Error = fmt.Sprintf("failed to create directory %s: %v", targetDir, err)
+		return outcome
+	}
+	if err := os.Rename(verdict.Folder, destination); err != nil {
+		outcome.Error = fmt.Sprintf("failed to move release: %v", err)
+		return outcome
+	}
+	return outcome
+}
+
+// marker creates an empty marker file in the release folder when the action applies,
+// and removes a stale empty marker when it doesn't
+func marker(action preset.Action, verdict Verdict, applies bool, dryRun bool) *Outcome {
+	path := filepath.Join(verdict.Folder, expand(action.Target, verdict))
+	info, err := os.Lstat(path)
+	exists := err == nil
+
+	if !applies {
+		// Only empty regular files are markers; anything else belongs to the release
+		if !exists || !info.Mode().IsRegular() || info.Size() != 0 {
+			return nil
+		}
+		if dryRun {
+			return &Outcome{Description: fmt.Sprintf("would remove marker %s", path)}
+		}
+		outcome := &Outcome{Description: fmt.Sprintf("removed marker %s", path)}
+		if err := os.Remove(path); err != nil {
+			outcome.Error = fmt.Sprintf("failed to remove marker: %v", err)
+		}
+		return outcome
+	}
+
+	if exists {
+		return nil
+	}
+	if dryRun {
+		return &Outcome{Description: fmt.Sprintf("would create marker %s", path)}
+	}
+	outcome := &Outcome{Description: fmt.Sprintf("created marker %s", path)}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+	if err != nil {
+		outcome.Error = fmt.Sprintf("failed to create marker: %v", err)
+		return outcome
+	}
+	file.Close()
+	return outcome
+}
+
+// symlink creates a symlink to the release folder when the action applies, and
+// removes a stale symlink to the release when it doesn't
+func symlink(action preset.Action, verdict Verdict, applies bool, dryRun bool) *Outcome {
+	name := action.Target
+	if name == "" {
+		name = DefaultSymlinkName
+	}
+	dir := filepath.Dir(verdict.Folder)
+	if action.Dir != "" {
+		dir = resolveDir(action.Dir, verdict)
+	}
+	path := filepath.Join(dir, expand(name, verdict))
+
+	// Links are relative, so they survive moving the whole tree
+	target, err := filepath.Rel(dir, verdict.Folder)
+	if err != nil {
+		target = verdict.Folder
+	}
+
+	current, err := os.Readlink(path)
+	linked := err == nil && resolveLink(dir, current) == verdict.Folder
+
+	if !applies {
+		if !linked {
+			return nil
+		}
+		if dryRun {
+			return &Outcome{Description: fmt.Sprintf("would remove symlink %s", path)}
+		}
+		outcome := &Outcome{Description: fmt.Sprintf("removed symlink %s", path)}
+		if err := os.Remove(path); err != nil {
+			outcome.Error = fmt.Sprintf("failed to remove symlink: %v", err)
+		}
+		return outcome
+	}
+
+	if linked {
+		return nil
+	}
+	if dryRun {
+		return &Outcome{Description: fmt.Sprintf("would create symlink %s -> %s", path, target)}
+	}
+	outcome := &Outcome{Description: fmt.Sprintf("created symlink %s -> %s", path, target)}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		outcome.Error = fmt.Sprintf("failed to create directory %s: %v", dir, err)
+		return outcome
+	}
+	if err := os.Symlink(target, path); err != nil {
+		outcome.Error = fmt.Sprintf("failed to create symlink: %v", err)
+	}
+	return outcome
+}
+
+// resolveLink returns the absolute path a symlink in dir points to
+func resolveLink(dir string, target string) string {
+	if !filepath.IsAbs(target) {
+		target = filepath.Join(dir, target)
+	}
+	return filepath.Clean(target)
+}
+
+// run runs an external command with the result as JSON on stdin
+func run(action preset.Action, verdict Verdict, dryRun bool) *Outcome {
+	args := make([]string, len(action.Command))
+	for i, arg := range action.Command {
+		args[i] = expand(arg, verdict)
+	}
+
+	if dryRun {
+		return &Outcome{Description: fmt.Sprintf("would run %s", strings.Join(args, " "))}
+	}
+
+	outcome := &Outcome{Description: fmt.Sprintf("ran %s", strings.Join(args, " "))}
+	input, err := json.Marshal(verdict.Result)
+	if err != nil {
+		outcome.Error = fmt.Sprintf("failed to encode result: %v", err)
+		return outcome
+	}
+
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stdin = bytes.NewReader(input)
+	// Command output goes to stderr, so it can't interfere with machine-readable output
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+	cmd.Env = append(os.Environ(),
+		"SFVBRR_RELEASE="+verdict.Folder,
+		"SFVBRR_CATEGORY="+verdict.Category,
+		"SFVBRR_VALID="+strconv.FormatBool(verdict.Valid),
+		"SFVBRR_ERRORS="+strings.Join(verdict.Errors, ","),
+	)
+	if err := cmd.Run(); err != nil {
+		outcome.Error = fmt.Sprintf("command failed: %v", err)
+	}
+	return outcome
+}
@@ -0,0 +1,175 @@
+package action
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/autobrr/sfvbrr/internal/preset"
+)
+
+func createRelease(t *testing.T, root string, name string) string {
+	t.Helper()
+	folder := filepath.Join(root, name)
+	if err := os.MkdirAll(folder, 0755); err != nil {
+		t.Fatalf("Failed to create release: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(folder, "release.nfo"), []byte("nfo"), 0644); err != nil {
+		t.Fatalf("Failed to write release file: %v", err)
+	}
+	return folder
+}
+
+func TestApplies(t *testing.T) {
+	passed := Verdict{Valid: true}
+	failed := Verdict{Valid: false, Errors: []string{preset.ErrorChecksum}}
+
+	tests := []struct {
+		name    string
+		action  preset.Action
+		verdict Verdict
+		want    bool
+	}{
+		{"fail by default on failure", preset.Action{}, failed, true},
+		{"fail by default on pass", preset.Action{}, passed, false},
+		{"pass on pass", preset.Action{On: preset.OnPass}, passed, true},
+		{"pass on failure", preset.Action{On: preset.OnPass}, failed, false},
+		{"always", preset.Action{On: preset.OnAlways}, failed, true},
+		{"matching error type", preset.Action{Errors: []string{preset.ErrorMissing, preset.ErrorChecksum}}, failed, true},
+		{"other error type", preset.Action{Errors: []string{preset.ErrorMissing}}, failed, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Applies(tt.action, tt.verdict); got != tt.want {
+				t.Errorf("Applies() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRun_Move(t *testing.T) {
+	root := t.TempDir()
+	folder := createRelease(t, root, "Release-GRP")
+	actions := []preset.Action{
+		{Type: preset.ActionMove, On: preset.OnFail, Target: "quarantine/{category}"},
+		{Type: preset.ActionMove, On: preset.OnAlways, Target: "complete"},
+		{Type: preset.ActionMarker, On: preset.OnFail, Target: "[sfvbrr] FAILED"},
+	}
+	verdict := Verdict{Folder: folder, Category: "app", Valid: false}
+
+	// A dry run changes nothing
+	outcomes := Run(actions, verdict, true)
+	if len(outcomes) != 2 || !outcomes[0].DryRun {
+		t.Fatalf("Expected 2 dry-run outcomes, got %+v", outcomes)
+	}
+	if _, err := os.Stat(folder); err != nil {
+		t.Fatalf("Expected the release to stay in place in dry-run mode: %v", err)
+	}
+
+	outcomes = Run(actions, verdict, false)
+	if len(Failed(outcomes)) > 0 {
+		t.Fatalf("Expected no failed actions, got %+v", outcomes)
+	}
+	moved := filepath.Join(root, "quarantine", "app", "Release-GRP")
+	if _, err := os.Stat(moved); err != nil {
+		t.Fatalf("Expected the release to be moved to %s: %v", moved, err)
+	}
+	// Move actions run last, so the marker was created before the release was moved
+	if _, err := os.Stat(filepath.Join(moved, "[sfvbrr] FAILED")); err != nil {
+		t.Errorf("Expected the marker in the moved release: %v", err)
+	}
+
+	// Moving onto an existing release fails
+	createRelease(t, root, "Release-GRP")
+	outcomes = Run(actions[:1], Verdict{Folder: folder, Category: "app"}, false)
+	if len(Failed(outcomes)) != 1 {
+		t.Errorf("Expected the move to fail, got %+v", outcomes)
+	}
+}
+
+func TestRun_MarkerAndSymlink(t *testing.T) {
+	root := t.TempDir()
+	folder := createRelease(t, root, "Release-GRP")
+	actions := []preset.Action{
+		{Type: preset.ActionMarker, On: preset.OnPass, Target: "[sfvbrr] OK"},
+		{Type: preset.ActionSymlink, On: preset.OnFail},
+	}
+	marker := filepath.Join(folder, "[sfvbrr] OK")
+	link := filepath.Join(root, "(incomplete)-Release-GRP")
+
+	// A failed release gets an incomplete symlink
+	outcomes := Run(actions, Verdict{Folder: folder, Valid: false}, false)
+	if len(outcomes) != 1 || outcomes[0].Error != "" {
+		t.Fatalf("Expected one successful outcome, got %+v", outcomes)
+	}
+	target, err := os.Readlink(link)
+	if err != nil {
+		t.Fatalf("Expected a symlink to the release: %v", err)
+	}
+	if target != "Release-GRP" {
+		t.Errorf("Expected a relative symlink, got %s", target)
+	}
+
+	// Once the release passes, the symlink is replaced by the marker
+	outcomes = Run(actions, Verdict{Folder: folder, Valid: true}, false)
+	if len(outcomes) != 2 || len(Failed(outcomes)) > 0 {
+		t.Fatalf("Expected two successful outcomes, got %+v", outcomes)
+	}
+	if _, err := os.Lstat(link); !os.IsNotExist(err) {
+		t.Errorf("Expected the symlink to be removed, got %v", err)
+	}
+	if _, err := os.Stat(marker); err != nil {
+		t.Errorf("Expected the marker to be created: %v", err)
+	}
+
+	// Running again is a no-op
+	if outcomes := Run(actions, Verdict{Folder: folder, Valid: true}, false); len(outcomes) != 0 {
+		t.Errorf("Expected no outcomes for an unchanged verdict, got %+v", outcomes)
+	}
+
+	// A marker with contents belongs to the release and is kept
+	if err := os.WriteFile(marker, []byte("data"), 0644); err != nil {
+		t.Fatalf("Failed to write marker: %v", err)
+	}
+	Run(actions, Verdict{Folder: folder, Valid: false}, false)
+	if _, err := os.Stat(marker); err != nil {
+		t.Errorf("Expected a non-empty marker to be kept: %v", err)
+	}
+}
+
+func TestRun_Exec(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("exec test requires a POSIX shell")
+	}
+
+	root := t.TempDir()
+	folder := createRelease(t, root, "Release-GRP")
+	output := filepath.Join(root, "result.json")
+
+	actions := []preset.Action{{
+		Type:    preset.ActionExec,
+		On:      preset.OnAlways,
+		Command: []string{"sh", "-c", `cat > "$1"; test "$SFVBRR_VALID" = true`, "sh", output},
+	}}
+	verdict := Verdict{Folder: folder, Valid: true, Result: map[string]any{"valid": true}}
+
+	outcomes := Run(actions, verdict, false)
+	if len(outcomes) != 1 || outcomes[0].Error != "" {
+		t.Fatalf("Expected one successful outcome, got %+v", outcomes)
+	}
+	data, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatalf("Expected the command to receive the result: %v", err)
+	}
+	if string(data) != `{"valid":true}` {
+		t.Errorf("Unexpected result on stdin: %s", data)
+	}
+
+	// A failing command is reported
+	verdict.Valid = false
+	if outcomes := Run(actions, verdict, false); len(Failed(outcomes)) != 1 {
+		t.Errorf("Expected the command to fail, got %+v", outcomes)
+	}
+}
@@ -0,0 +1,35 @@
+package action
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/fatih/color"
+)
+
+var (
+	magenta    = color.New(color.FgMagenta).SprintFunc()
+	yellow     = color.New(color.FgYellow).SprintFunc()
+	success    = color.New(color.FgGreen).SprintFunc()
+	errorColor = color.New(color.FgRed).SprintFunc()
+)
+
+// DisplayOutcomes displays the outcomes of the actions run on a release
+func DisplayOutcomes(outcomes []Outcome) {
+	if len(outcomes) == 0 {
+		return
+	}
+
+	fmt.Fprintf(os.Stdout, "%s\n", magenta("Actions:"))
+	for _, outcome := range outcomes {
+		switch {
+		case outcome.Error != "":
+			fmt.Fprintf(os.Stdout, "  %s %s: %s\n", errorColor("✗"), outcome.Description, errorColor(outcome.Error))
+		case outcome.DryRun:
+			fmt.Fprintf(os.Stdout, "  %s %s\n", yellow("-"), outcome.Description)
+		default:
+			fmt.Fprintf(os.Stdout, "  %s %s\n", success("✓"), outcome.Description)
+		}
+	}
+	fmt.Fprintln(os.Stdout)
+}
@@ -0,0 +1,281 @@
+package cache
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// Bucket groups the records of one kind of verification
+type Bucket string
+
+const (
+	// BucketSFV holds the computed checksums of files listed in SFV files and other manifests
+	BucketSFV Bucket = "sfv"
+	// BucketZIP holds the verdict of each entry of ZIP files
+	BucketZIP Bucket = "zip"
+)
+
+var buckets = []Bucket{BucketSFV, BucketZIP}
+
+// Key identifies a file by device and inode, together with the size and
+// modification time it had when it was verified
+type Key struct {
+	Dev     uint64
+	Inode   uint64
+	Size    int64
+	ModTime int64 // Modification time in nanoseconds since the Unix epoch
+	Path    string
+}
+
+// Record is the last verification result stored for a file or archive entry
+type Record struct {
+	CRC        string    `json:"crc,omitempty"`   // Computed CRC-32 (SFV files only)
+	Hash       string    `json:"hash,omitempty"`  // Computed digest of the other manifest algorithms
+	Valid      bool      `json:"valid"`           // Verdict of the verification
+	Error      string    `json:"error,omitempty"` // Verification error, if any
+	VerifiedAt time.Time `json:"verified_at"`
+}
+
+// storedRecord is the value stored in the database
+type storedRecord struct {
+	Record
+	Path    string `json:"path"`
+	Size    int64  `json:"size"`
+	ModTime int64  `json:"mtime"`
+}
+
+type pendingRecord struct {
+	bucket Bucket
+	id     []byte
+	value  storedRecord
+}
+
+// Cache is an on-disk store of verification results.
+// Records are buffered in memory and written in a single transaction by Flush or Close.
+type Cache struct {
+	db      *bolt.DB
+	mu      sync.Mutex
+	pending []pendingRecord
+}
+
+// DefaultPath returns the default cache file path (cross-platform)
+func DefaultPath() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(homeDir, ".config", "sfvbrr", "cache.db"), nil
+}
+
+// Open opens the cache file at the given path, creating it if needed
+func Open(path string) (*Cache, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create cache directory: %w", err)
+	}
+
+	db, err := bolt.Open(path, 0644, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open cache %s: %w", path, err)
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, bucket := range buckets {
+			if _, err := tx.CreateBucketIfNotExists([]byte(bucket)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to initialize cache: %w", err)
+	}
+
+	return &Cache{db: db}, nil
+}
+
+// Close writes pending records and closes the cache file
+func (c *Cache) Close() error {
+	flushErr := c.Flush()
+	if err := c.db.Close(); err != nil {
+		return fmt.Errorf("failed to close cache: %w", err)
+	}
+	return flushErr
+}
+
+// StatKey returns the cache key of the file at the given path
+func StatKey(path string) (Key, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return Key{}, err
+	}
+	info, err := os.Stat(absPath)
+	if err != nil {
+		return Key{}, err
+	}
+	dev, inode := fileID(absPath, info)
+	return Key{
+		Dev:     dev,
+		Inode:   inode,
+		Size:    info.Size(),
+		ModTime: info.ModTime().UnixNano(),
+		Path:    absPath,
+	}, nil
+}
+
+// recordID builds the database key of a file or of a member (archive entry) of a file
+func recordID(key Key, member string) []byte {
+	id := make([]byte, 16, 16+len(member))
+	binary.BigEndian.PutUint64(id[0:8], key.Dev)
+	binary.BigEndian.PutUint64(id[8:16], key.Inode)
+	return append(id, member...)
+}
+
+// Get returns the record of a file (or of a member of it) if the file is unchanged
+// since it was verified and, when maxAge is positive, the record is not older than maxAge
+func (c *Cache) Get(bucket Bucket, key Key, member string, maxAge time.Duration) (Record, bool) {
+	var stored storedRecord
+	found := false
+
+	c.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(bucket))
+		if b == nil {
+			return nil
+		}
+		data := b.Get(recordID(key, member))
+		if data == nil {
+			return nil
+		}
+		found = json.Unmarshal(data, &stored) == nil
+		return nil
+	})
+
+	if !found || stored.Size != key.Size || stored.ModTime != key.ModTime {
+		return Record{}, false
+	}
+	if maxAge > 0 && time.Since(stored.VerifiedAt) > maxAge {
+		return Record{}, false
+	}
+	return stored.Record, true
+}
+
+// Put queues the record of a file (or of a member of it) for writing
+func (c *Cache) Put(bucket Bucket, key Key, member string, record Record) {
+	if record.VerifiedAt.IsZero() {
+		record.VerifiedAt = time.Now()
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.pending = append(c.pending, pendingRecord{
+		bucket: bucket,
+		id:     recordID(key, member),
+		value: storedRecord{
+			Record:  record,
+			Path:    key.Path,
+			Size:    key.Size,
+			ModTime: key.ModTime,
+		},
+	})
+}
+
+// Flush writes all pending records to the cache file
+func (c *Cache) Flush() error {
+	c.mu.Lock()
+	pending := c.pending
+	c.pending = nil
+	c.mu.Unlock()
+
+	if len(pending) == 0 {
+		return nil
+	}
+
+	err := c.db.Update(func(tx *bolt.Tx) error {
+		for _, p := range pending {
+			data, err := json.Marshal(p.value)
+			if err != nil {
+				return err
+			}
+			if err := tx.Bucket([]byte(p.bucket)).Put(p.id, data); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write cache: %w", err)
+	}
+	return nil
+}
+
+// Prune removes the records of files that no longer exist or have changed since
+// they were verified and, when maxAge is positive, records older than maxAge.
+// It returns the number of records removed.
+func (c *Cache) Prune(maxAge time.Duration) (int, error) {
+	removed := 0
+
+	err := c.db.Update(func(tx *bolt.Tx) error {
+		for _, bucket := range buckets {
+			b := tx.Bucket([]byte(bucket))
+			if b == nil {
+				continue
+			}
+
+			var stale [][]byte
+			err := b.ForEach(func(id, data []byte) error {
+				var stored storedRecord
+				if err := json.Unmarshal(data, &stored); err != nil || !isCurrent(id, stored, maxAge) {
+					stale = append(stale, append([]byte(nil), id...))
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+
+			for _, id := range stale {
+				if err := b.Delete(id); err != nil {
+					return err
+				}
+			}
+			removed += len(stale)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to prune cache: %w", err)
+	}
+
+	return removed, nil
+}
+
+// isCurrent reports whether a stored record still describes the file on disk
+func isCurrent(id []byte, stored storedRecord, maxAge time.Duration) bool {
+	if maxAge > 0 && time.Since(stored.VerifiedAt) > maxAge {
+		return false
+	}
+	key, err := StatKey(stored.Path)
+	if err != nil {
+		return false
+	}
+	if key.Size != stored.Size || key.ModTime != stored.ModTime {
+		return false
+	}
+	current := recordID(key, "")
+	return len(id) >= len(current) && string(id[:len(current)]) == string(current)
+}
+
+// pathHash hashes a path into an identifier used when no inode number is available
+func pathHash(path string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(path))
+	return h.Sum64()
+}
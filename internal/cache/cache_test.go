@@ -0,0 +1,97 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCache_GetPut(t *testing.T) {
+	tmpDir := t.TempDir()
+	c, err := Open(filepath.Join(tmpDir, "cache.db"))
+	if err != nil {
+		t.Fatalf("Failed to open cache: %v", err)
+	}
+	defer c.Close()
+
+	filePath := filepath.Join(tmpDir, "release.rar")
+	if err := os.WriteFile(filePath, []byte("data"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	key, err := StatKey(filePath)
+	if err != nil {
+		t.Fatalf("Failed to stat file: %v", err)
+	}
+
+	c.Put(BucketSFV, key, "", Record{CRC: "ADF3F363", Valid: true})
+	if _, ok := c.Get(BucketSFV, key, "", 0); ok {
+		t.Error("Expected pending record not to be visible before flush")
+	}
+	if err := c.Flush(); err != nil {
+		t.Fatalf("Failed to flush cache: %v", err)
+	}
+
+	record, ok := c.Get(BucketSFV, key, "", 0)
+	if !ok || record.CRC != "ADF3F363" || !record.Valid {
+		t.Fatalf("Expected cached record, got %+v (found %v)", record, ok)
+	}
+	if _, ok := c.Get(BucketZIP, key, "", 0); ok {
+		t.Error("Expected buckets to be separate")
+	}
+
+	// A modified file no longer matches its record
+	modified := key
+	modified.ModTime++
+	if _, ok := c.Get(BucketSFV, modified, "", 0); ok {
+		t.Error("Expected record of a modified file to be ignored")
+	}
+
+	// Records older than the maximum age are not trusted
+	time.Sleep(10 * time.Millisecond)
+	if _, ok := c.Get(BucketSFV, key, "", time.Millisecond); ok {
+		t.Error("Expected record older than max age to be ignored")
+	}
+}
+
+func TestCache_Prune(t *testing.T) {
+	tmpDir := t.TempDir()
+	c, err := Open(filepath.Join(tmpDir, "cache.db"))
+	if err != nil {
+		t.Fatalf("Failed to open cache: %v", err)
+	}
+	defer c.Close()
+
+	keep := filepath.Join(tmpDir, "keep.zip")
+	gone := filepath.Join(tmpDir, "gone.zip")
+	for _, path := range []string{keep, gone} {
+		if err := os.WriteFile(path, []byte("data"), 0644); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+		key, err := StatKey(path)
+		if err != nil {
+			t.Fatalf("Failed to stat file: %v", err)
+		}
+		c.Put(BucketZIP, key, "setup.exe", Record{Valid: true})
+	}
+	if err := c.Flush(); err != nil {
+		t.Fatalf("Failed to flush cache: %v", err)
+	}
+
+	if err := os.Remove(gone); err != nil {
+		t.Fatalf("Failed to remove file: %v", err)
+	}
+
+	removed, err := c.Prune(0)
+	if err != nil {
+		t.Fatalf("Failed to prune cache: %v", err)
+	}
+	if removed != 1 {
+		t.Errorf("Expected 1 removed entry, got %d", removed)
+	}
+
+	key, _ := StatKey(keep)
+	if _, ok := c.Get(BucketZIP, key, "setup.exe", 0); !ok {
+		t.Error("Expected entry of an unchanged file to be kept")
+	}
+}
@@ -0,0 +1,11 @@
+//go:build !unix
+
+package cache
+
+import "os"
+
+// fileID returns a stable identifier for a file on platforms without inode numbers.
+// The absolute path stands in for the inode, so a renamed file is verified again.
+func fileID(path string, info os.FileInfo) (uint64, uint64) {
+	return 0, pathHash(path)
+}
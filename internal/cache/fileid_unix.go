@@ -0,0 +1,16 @@
+//go:build unix
+
+package cache
+
+import (
+	"os"
+	"syscall"
+)
+
+// fileID returns the device and inode numbers of a file
+func fileID(path string, info os.FileInfo) (uint64, uint64) {
+	if stat, ok := info.Sys().(*syscall.Stat_t); ok {
+		return uint64(stat.Dev), uint64(stat.Ino)
+	}
+	return 0, pathHash(path)
+}
@@ -0,0 +1,78 @@
+package check
+
+import (
+	"slices"
+
+	"github.com/autobrr/sfvbrr/internal/action"
+	"github.com/autobrr/sfvbrr/internal/preset"
+	"github.com/autobrr/sfvbrr/internal/validate"
+)
+
+// RunActions runs the post-validation actions of the preset configuration on a
+// checked release and stores their outcomes in the result. Cancelled checks have no
+// verdict, so no actions are run for them.
+func RunActions(result *Result, presetConfig *preset.PresetConfig, dryRun bool) {
+	if len(presetConfig.Actions) == 0 || result.Cancelled {
+		return
+	}
+
+	result.Actions = action.Run(presetConfig.Actions, action.Verdict{
+		Folder:   result.FolderPath,
+		Category: result.Category,
+		Valid:    result.Valid,
+		Errors:   ErrorTypes(result),
+		Result:   ConvertResult(result),
+	}, dryRun)
+}
+
+// ErrorTypes classifies the problems of a failed release check by error type
+func ErrorTypes(result *Result) []string {
+	var types []string
+	add := func(errorType string) {
+		if !slices.Contains(types, errorType) {
+			types = append(types, errorType)
+		}
+	}
+
+	if result.Rules != nil {
+		for _, errorType := range validate.ErrorTypes(result.Rules) {
+			add(errorType)
+		}
+	}
+	for _, sfv := range result.SFV {
+		if sfv.MissingFiles > 0 {
+			add(preset.ErrorMissing)
+		}
+		if sfv.InvalidFiles > 0 {
+			add(preset.ErrorChecksum)
+		}
+		if sfv.OrphanFiles > 0 {
+			add(preset.ErrorOrphans)
+		}
+	}
+	for _, zip := range result.ZIP {
+		if zip.InvalidEntries > 0 {
+			add(preset.ErrorChecksum)
+		}
+		if len(zip.Errors) > 0 {
+			add(preset.ErrorArchive)
+		}
+	}
+	for _, set := range result.RAR {
+		if len(set.MissingVolumes) > 0 {
+			add(preset.ErrorMissing)
+		}
+		if len(set.Errors) > 0 {
+			add(preset.ErrorArchive)
+		}
+	}
+	if len(result.Errors) > 0 {
+		add(preset.ErrorArchive)
+	}
+
+	// Order the error types consistently
+	slices.SortFunc(types, func(a, b string) int {
+		return slices.Index(preset.ErrorTypes, a) - slices.Index(preset.ErrorTypes, b)
+	})
+	return types
+}
@@ -0,0 +1,167 @@
+package check
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/autobrr/sfvbrr/internal/action"
+	"github.com/autobrr/sfvbrr/internal/preset"
+	"github.com/autobrr/sfvbrr/internal/report"
+	"github.com/autobrr/sfvbrr/internal/validate"
+)
+
+// checkSingleFolder checks a single release folder and displays or reports the results
+// Returns true if the release passed all checks
+func checkSingleFolder(ctx context.Context, folderPath string, presetConfig *preset.PresetConfig, opts Options, rep *report.Writer) (bool, error) {
+	// Detect category (or use overwrite if provided)
+	category, err := validate.DetectCategory(folderPath, opts.OverwriteCategory)
+	if err != nil {
+		return false, fmt.Errorf("failed to detect category for %s: %w", folderPath, err)
+	}
+
+	// If category is unknown, skip or report
+	if category == "" {
+		if !opts.Quiet {
+			fmt.Fprintf(os.Stderr, "Warning: %s - unknown or unsupported release category\n", folderPath)
+		}
+		if rep != nil {
+			rep.AddError(fmt.Errorf("%s: unknown or unsupported release category", folderPath))
+		}
+		return false, nil
+	}
+
+	result, err := CheckFolder(ctx, folderPath, presetConfig, category, opts)
+	if err != nil {
+		return false, fmt.Errorf("failed to check folder: %w", err)
+	}
+
+	// Run the post-validation actions before reporting, so their outcomes are included
+	if !opts.SkipActions {
+		RunActions(result, presetConfig, opts.DryRun)
+	}
+
+	// Machine-readable output is collected into a single report
+	if rep != nil {
+		rep.Add(ConvertResult(result), !result.Valid)
+		for _, sfv := range result.SFV {
+			rep.Count("sfv_files", 1)
+			rep.Count("invalid_files", sfv.InvalidFiles)
+			rep.Count("missing_files", sfv.MissingFiles)
+			rep.AddBytes(sfv.TotalBytes)
+		}
+		for _, zip := range result.ZIP {
+			rep.Count("zip_files", 1)
+			rep.Count("invalid_entries", zip.InvalidEntries)
+			rep.AddBytes(zip.TotalBytes)
+		}
+		for _, set := range result.RAR {
+			rep.Count("rar_sets", 1)
+			rep.AddBytes(set.TotalBytes())
+		}
+	} else {
+		DisplayResult(result, opts)
+	}
+
+	if failed := action.Failed(result.Actions); len(failed) > 0 {
+		return result.Valid, fmt.Errorf("%s: %s action failed: %s", folderPath, failed[0].Type, failed[0].Error)
+	}
+	return result.Valid, nil
+}
+
+// CheckFolders runs the configured checks on multiple release folders. Releases
+// left when the context is done are skipped and a cancellation error is returned.
+func CheckFolders(ctx context.Context, folders []string, opts Options) error {
+	// Load preset configuration
+	presetConfig, err := preset.LoadPresets(opts.PresetPath)
+	if err != nil {
+		return fmt.Errorf("failed to load presets: %w", err)
+	}
+
+	// Validate overwrite category if provided
+	if opts.OverwriteCategory != "" {
+		if _, exists := presetConfig.Rules[opts.OverwriteCategory]; !exists {
+			return fmt.Errorf("invalid category '%s': category not found in preset configuration", opts.OverwriteCategory)
+		}
+	}
+
+	var hasErrors bool
+	rep := report.NewWriterFor(string(opts.OutputFormat), "check")
+
+	for _, folder := range folders {
+		if ctx.Err() != nil {
+			break
+		}
+
+		// Resolve absolute path
+		absPath, err := filepath.Abs(folder)
+		if err != nil {
+			report.Error(rep, fmt.Errorf("failed to resolve path %s: %w", folder, err))
+			hasErrors = true
+			continue
+		}
+
+		// Check if directory exists
+		info, err := os.Stat(absPath)
+		if err != nil {
+			report.Error(rep, fmt.Errorf("%s does not exist: %w", folder, err))
+			hasErrors = true
+			continue
+		}
+
+		if !info.IsDir() {
+			report.Error(rep, fmt.Errorf("%s is not a directory", folder))
+			hasErrors = true
+			continue
+		}
+
+		releases := []string{absPath}
+		if opts.Recursive {
+			// Find all release folders recursively
+			releases, err = validate.FindFoldersRecursive(absPath, opts.OverwriteCategory)
+			if err != nil {
+				report.Error(rep, fmt.Errorf("failed to find folders recursively in %s: %w", folder, err))
+				hasErrors = true
+				continue
+			}
+
+			if len(releases) == 0 {
+				if !opts.Quiet {
+					fmt.Fprintf(os.Stderr, "No valid release folders found in %s\n", folder)
+				}
+				// Finding zero folders is not an error, just continue
+				continue
+			}
+		}
+
+		for _, release := range releases {
+			if ctx.Err() != nil {
+				break
+			}
+			valid, err := checkSingleFolder(ctx, release, presetConfig, opts, rep)
+			if err != nil {
+				report.Error(rep, err)
+				hasErrors = true
+			} else if !valid {
+				hasErrors = true
+			}
+		}
+	}
+
+	if rep != nil {
+		if err := rep.Close(); err != nil {
+			return err
+		}
+	}
+
+	if ctx.Err() != nil {
+		return fmt.Errorf("check cancelled: %w", context.Cause(ctx))
+	}
+
+	if hasErrors {
+		return fmt.Errorf("one or more releases failed")
+	}
+
+	return nil
+}
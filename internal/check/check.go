@@ -0,0 +1,144 @@
+package check
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/autobrr/sfvbrr/internal/checksum"
+	"github.com/autobrr/sfvbrr/internal/preset"
+	"github.com/autobrr/sfvbrr/internal/rar"
+	"github.com/autobrr/sfvbrr/internal/validate"
+)
+
+// CheckFolder runs the checks configured for the category on a release folder.
+// A check that finds nothing to verify is skipped; whether SFV or ZIP files are
+// required at all is up to the category rules. When the context is done, the
+// remaining checks are not run and the result is marked as cancelled.
+func CheckFolder(ctx context.Context, folderPath string, presetConfig *preset.PresetConfig, category string, opts Options) (*Result, error) {
+	result := &Result{
+		FolderPath: folderPath,
+		Category:   category,
+		Checks:     presetConfig.GetChecksForCategory(category),
+		Valid:      true,
+		Errors:     make([]error, 0),
+	}
+
+	fail := func(err error) {
+		result.Valid = false
+		result.Errors = append(result.Errors, err)
+	}
+
+	for _, check := range result.Checks {
+		if ctx.Err() != nil {
+			break
+		}
+
+		switch check {
+		case preset.CheckRules:
+			rules, err := validate.ValidateFolder(ctx, folderPath, presetConfig, category)
+			if err != nil {
+				return nil, fmt.Errorf("failed to validate folder: %w", err)
+			}
+			result.Rules = rules
+			if !rules.Valid {
+				result.Valid = false
+			}
+
+		case preset.CheckSFV:
+			sfvPaths, err := checksum.FindSFVFilesRecursive(folderPath)
+			if err != nil {
+				fail(fmt.Errorf("failed to find SFV files: %w", err))
+				continue
+			}
+			if len(sfvPaths) == 0 {
+				result.Skipped = append(result.Skipped, check)
+				continue
+			}
+
+			for _, sfvPath := range sfvPaths {
+				if ctx.Err() != nil {
+					break
+				}
+				if opts.AllowPath != nil {
+					if err := opts.AllowPath(sfvPath); err != nil {
+						fail(err)
+						continue
+					}
+				}
+				sfv, err := checksum.ParseManifestFile(sfvPath)
+				if err != nil {
+					fail(fmt.Errorf("failed to parse SFV file %s: %w", sfvPath, err))
+					continue
+				}
+				sfvResult, err := checksum.ValidateSFV(ctx, sfv, opts.checksumOptions(preset.CheckSFV))
+				if err != nil {
+					fail(fmt.Errorf("failed to validate SFV %s: %w", sfvPath, err))
+					continue
+				}
+				result.SFV = append(result.SFV, sfvResult)
+				if sfvResult.Failed() {
+					result.Valid = false
+				}
+			}
+
+		case preset.CheckZIP:
+			zipPaths, err := checksum.FindZIPFilesRecursive(folderPath)
+			if err != nil {
+				fail(fmt.Errorf("failed to find ZIP files: %w", err))
+				continue
+			}
+			if len(zipPaths) == 0 {
+				result.Skipped = append(result.Skipped, check)
+				continue
+			}
+
+			zipResults, err := checksum.ValidateZIPPaths(ctx, zipPaths, opts.checksumOptions(preset.CheckZIP))
+			if err != nil {
+				fail(err)
+				continue
+			}
+			result.ZIP = zipResults
+			for _, zipResult := range zipResults {
+				if zipResult.Failed() {
+					result.Valid = false
+				}
+			}
+
+		case preset.CheckRAR:
+			sets, err := rar.FindVolumeSetsRecursive(folderPath)
+			if err != nil {
+				fail(fmt.Errorf("failed to find RAR volumes: %w", err))
+				continue
+			}
+			if len(sets) == 0 {
+				result.Skipped = append(result.Skipped, check)
+				continue
+			}
+
+			for _, set := range sets {
+				// Volumes that must not be read are reported as unreadable
+				if opts.AllowPath != nil {
+					for i := range set.Volumes {
+						set.Volumes[i].Error = opts.AllowPath(set.Volumes[i].Path)
+					}
+				}
+				setResult := rar.ValidateSet(set)
+				result.RAR = append(result.RAR, setResult)
+				if !setResult.Valid {
+					result.Valid = false
+				}
+			}
+
+		default:
+			fail(fmt.Errorf("unknown check %q", check))
+		}
+	}
+
+	// Checks cut short by the context leave the release without a verdict
+	if ctx.Err() != nil {
+		result.Cancelled = true
+		result.Valid = false
+	}
+
+	return result, nil
+}
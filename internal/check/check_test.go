@@ -0,0 +1,97 @@
+package check
+
+import (
+	"archive/zip"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/autobrr/sfvbrr/internal/preset"
+)
+
+func writeFile(t *testing.T, path string, data []byte) {
+	t.Helper()
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatalf("Failed to write %s: %v", path, err)
+	}
+}
+
+func TestCheckFolder(t *testing.T) {
+	releaseDir := filepath.Join(t.TempDir(), "APP.v1.0-GRP")
+	if err := os.Mkdir(releaseDir, 0755); err != nil {
+		t.Fatalf("Failed to create release folder: %v", err)
+	}
+
+	f, err := os.Create(filepath.Join(releaseDir, "app.zip"))
+	if err != nil {
+		t.Fatalf("Failed to create ZIP file: %v", err)
+	}
+	zw := zip.NewWriter(f)
+	w, _ := zw.Create("setup.exe")
+	w.Write([]byte("binary"))
+	zw.Close()
+	f.Close()
+
+	writeFile(t, filepath.Join(releaseDir, "app.nfo"), []byte("nfo"))
+	// Content hashes to a different CRC than listed
+	writeFile(t, filepath.Join(releaseDir, "app.sfv"), []byte("app.nfo 00000000\n"))
+
+	presetConfig := &preset.PresetConfig{
+		Rules: map[string]*preset.CategoryRules{
+			"app": {
+				DenyUnexpected: false,
+				Checks:         []string{preset.CheckRules, preset.CheckZIP, preset.CheckRAR},
+				Rules: []preset.Rule{
+					{Pattern: "*.zip", Min: 1},
+				},
+			},
+		},
+	}
+
+	opts := DefaultOptions()
+	opts.Quiet = true
+
+	result, err := CheckFolder(context.Background(), releaseDir, presetConfig, "app", opts)
+	if err != nil {
+		t.Fatalf("Failed to check folder: %v", err)
+	}
+	if !result.Valid {
+		t.Errorf("Expected release to pass, got errors: %v", result.Errors)
+	}
+	if result.Rules == nil || len(result.ZIP) != 1 || len(result.SFV) != 0 {
+		t.Errorf("Expected rules and one ZIP result only, got rules=%v zip=%d sfv=%d", result.Rules != nil, len(result.ZIP), len(result.SFV))
+	}
+	if len(result.Skipped) != 1 || result.Skipped[0] != preset.CheckRAR {
+		t.Errorf("Expected rar check to be skipped, got %v", result.Skipped)
+	}
+
+	// With the SFV check enabled the bad checksum fails the release
+	presetConfig.Rules["app"].Checks = nil
+	result, err = CheckFolder(context.Background(), releaseDir, presetConfig, "app", opts)
+	if err != nil {
+		t.Fatalf("Failed to check folder: %v", err)
+	}
+	if result.Valid {
+		t.Error("Expected release with a bad SFV checksum to fail")
+	}
+	if len(result.SFV) != 1 || result.SFV[0].InvalidFiles != 1 {
+		t.Errorf("Expected one SFV result with an invalid file")
+	}
+	if types := ErrorTypes(result); len(types) != 1 || types[0] != preset.ErrorChecksum {
+		t.Errorf("Expected a checksum error type, got %v", types)
+	}
+
+	// Actions selected by the error type run on the failed release
+	presetConfig.Actions = []preset.Action{
+		{Type: preset.ActionMarker, Errors: []string{preset.ErrorMissing}, Target: "missing"},
+		{Type: preset.ActionMarker, Errors: []string{preset.ErrorChecksum}, Target: "bad-crc"},
+	}
+	RunActions(result, presetConfig, false)
+	if len(result.Actions) != 1 || result.Actions[0].Error != "" {
+		t.Fatalf("Expected one successful action, got %+v", result.Actions)
+	}
+	if _, err := os.Stat(filepath.Join(releaseDir, "bad-crc")); err != nil {
+		t.Errorf("Expected the marker of the checksum action: %v", err)
+	}
+}
@@ -0,0 +1,140 @@
+package check
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/autobrr/sfvbrr/internal/action"
+	"github.com/autobrr/sfvbrr/internal/checksum"
+	"github.com/autobrr/sfvbrr/internal/rar"
+	"github.com/autobrr/sfvbrr/internal/validate"
+	"github.com/fatih/color"
+)
+
+var (
+	magenta    = color.New(color.FgMagenta).SprintFunc()
+	yellow     = color.New(color.FgYellow).SprintFunc()
+	success    = color.New(color.FgGreen).SprintFunc()
+	label      = color.New(color.FgCyan).SprintFunc()
+	errorColor = color.New(color.FgRed).SprintFunc()
+)
+
+// DisplayResult displays the release check results to the user in text form.
+// Each check is summarized on one line; verbose mode shows the full result of every check.
+// Returns true if the release failed
+func DisplayResult(result *Result, opts Options) bool {
+	if opts.Quiet {
+		// In quiet mode, only show failed releases
+		if result.Cancelled {
+			fmt.Fprintf(os.Stderr, "%s: check cancelled\n", result.FolderPath)
+		} else if !result.Valid {
+			fmt.Fprintf(os.Stderr, "%s: check failed\n", result.FolderPath)
+		}
+		return !result.Valid
+	}
+
+	if opts.Verbose {
+		displayDetails(result, opts)
+	}
+
+	// Show release information
+	fmt.Fprintf(os.Stdout, "\n%s\n", magenta("Checking Release:"))
+	fmt.Fprintf(os.Stdout, "  %-13s %s\n", label("Folder:"), result.FolderPath)
+	fmt.Fprintf(os.Stdout, "  %-13s %s\n", label("Category:"), result.Category)
+	fmt.Fprintf(os.Stdout, "  %-13s %s\n", label("Checks:"), strings.Join(result.Checks, ", "))
+	fmt.Fprintln(os.Stdout)
+
+	fmt.Fprintf(os.Stdout, "%s\n", magenta("Results:"))
+	if rules := result.Rules; rules != nil {
+		invalid := 0
+		for _, ruleResult := range rules.RuleResults {
+			if !ruleResult.Valid {
+				invalid++
+			}
+		}
+		if rules.Valid {
+			fmt.Fprintf(os.Stdout, "  %s rules: %d passed\n", success("✓"), len(rules.RuleResults))
+		} else {
+			fmt.Fprintf(os.Stdout, "  %s rules: %d of %d failed, %d unexpected file(s)\n",
+				errorColor("✗"), invalid, len(rules.RuleResults), len(rules.UnexpectedFiles))
+		}
+		for _, subfolder := range rules.Subfolders {
+			name := filepath.Base(subfolder.FolderPath)
+			if subfolder.Valid {
+				fmt.Fprintf(os.Stdout, "      %s %s (%s)\n", success("✓"), name, subfolder.Category)
+			} else {
+				fmt.Fprintf(os.Stdout, "      %s %s (%s)\n", errorColor("✗"), name, subfolder.Category)
+			}
+		}
+	}
+	for _, sfv := range result.SFV {
+		name := validate.FormatFolderPath(sfv.SFVFile.Path)
+		if sfv.Cancelled {
+			fmt.Fprintf(os.Stdout, "  %s sfv: %s - cancelled\n", yellow("-"), name)
+		} else if sfv.Failed() {
+			fmt.Fprintf(os.Stdout, "  %s sfv: %s - %d invalid, %d missing, %d orphaned\n",
+				errorColor("✗"), name, sfv.InvalidFiles, sfv.MissingFiles, sfv.OrphanFiles)
+		} else {
+			fmt.Fprintf(os.Stdout, "  %s sfv: %s - %d files\n", success("✓"), name, sfv.TotalFiles)
+		}
+	}
+	for _, zip := range result.ZIP {
+		name := validate.FormatFolderPath(zip.ZIPFile.Path)
+		if zip.Cancelled {
+			fmt.Fprintf(os.Stdout, "  %s zip: %s - cancelled\n", yellow("-"), name)
+		} else if zip.InvalidEntries > 0 {
+			fmt.Fprintf(os.Stdout, "  %s zip: %s - %d invalid\n", errorColor("✗"), name, zip.InvalidEntries)
+		} else {
+			fmt.Fprintf(os.Stdout, "  %s zip: %s - %d entries\n", success("✓"), name, zip.TotalEntries)
+		}
+	}
+	for _, set := range result.RAR {
+		if !set.Valid {
+			fmt.Fprintf(os.Stdout, "  %s rar: %s - %d error(s), %d missing volume(s)\n",
+				errorColor("✗"), set.Set.Name, len(set.Errors), len(set.MissingVolumes))
+		} else {
+			fmt.Fprintf(os.Stdout, "  %s rar: %s - %d volumes\n", success("✓"), set.Set.Name, len(set.Set.Volumes))
+		}
+	}
+	for _, check := range result.Skipped {
+		fmt.Fprintf(os.Stdout, "  %s %s: nothing to check\n", yellow("-"), check)
+	}
+	for _, err := range result.Errors {
+		fmt.Fprintf(os.Stdout, "  %s %s\n", errorColor("✗"), errorColor(err.Error()))
+	}
+	fmt.Fprintln(os.Stdout)
+
+	action.DisplayOutcomes(result.Actions)
+
+	// Show verdict
+	if result.Valid {
+		fmt.Fprintf(os.Stdout, "  %-13s %s\n", label("Verdict:"), success("PASS"))
+	} else if result.Cancelled {
+		fmt.Fprintf(os.Stdout, "  %-13s %s\n", label("Verdict:"), yellow("CANCELLED"))
+	} else {
+		fmt.Fprintf(os.Stdout, "  %-13s %s\n", label("Verdict:"), errorColor("FAIL"))
+	}
+	fmt.Fprintln(os.Stdout)
+
+	return !result.Valid
+}
+
+// displayDetails shows the full result of every check the way the individual commands do
+func displayDetails(result *Result, opts Options) {
+	if result.Rules != nil {
+		validate.DisplayResult(result.Rules, validate.Options{Verbose: true})
+	}
+
+	checksumOpts := opts.checksumOptions("")
+	for _, sfv := range result.SFV {
+		checksum.DisplayResult(sfv, checksumOpts)
+	}
+	for _, zip := range result.ZIP {
+		checksum.DisplayZIPResult(zip, checksumOpts)
+	}
+	for _, set := range result.RAR {
+		rar.DisplayResult(set, rar.Options{Verbose: true})
+	}
+}
@@ -0,0 +1,59 @@
+package check
+
+import (
+	"github.com/autobrr/sfvbrr/internal/action"
+	"github.com/autobrr/sfvbrr/internal/checksum"
+	"github.com/autobrr/sfvbrr/internal/rar"
+	"github.com/autobrr/sfvbrr/internal/validate"
+)
+
+// OutputResult represents the JSON/YAML output structure for a release check
+type OutputResult struct {
+	FolderPath string                      `json:"folder_path" yaml:"folder_path"`
+	Category   string                      `json:"category" yaml:"category"`
+	Checks     []string                    `json:"checks" yaml:"checks"`
+	Skipped    []string                    `json:"skipped,omitempty" yaml:"skipped,omitempty"`
+	Valid      bool                        `json:"valid" yaml:"valid"`
+	Cancelled  bool                        `json:"cancelled,omitempty" yaml:"cancelled,omitempty"`
+	Rules      *validate.OutputResult      `json:"rules,omitempty" yaml:"rules,omitempty"`
+	SFV        []*checksum.OutputResult    `json:"sfv,omitempty" yaml:"sfv,omitempty"`
+	ZIP        []*checksum.ZIPOutputResult `json:"zip,omitempty" yaml:"zip,omitempty"`
+	RAR        []*rar.OutputResult         `json:"rar,omitempty" yaml:"rar,omitempty"`
+	Errors     []string                    `json:"errors,omitempty" yaml:"errors,omitempty"`
+	Actions    []action.Outcome            `json:"actions,omitempty" yaml:"actions,omitempty"`
+}
+
+// ConvertResult converts Result to OutputResult
+func ConvertResult(result *Result) *OutputResult {
+	output := &OutputResult{
+		FolderPath: result.FolderPath,
+		Category:   result.Category,
+		Checks:     result.Checks,
+		Skipped:    result.Skipped,
+		Valid:      result.Valid,
+		Cancelled:  result.Cancelled,
+		Actions:    result.Actions,
+	}
+
+	if result.Rules != nil {
+		output.Rules = validate.ConvertValidationResult(result.Rules)
+	}
+	for _, res := range result.SFV {
+		output.SFV = append(output.SFV, checksum.ConvertValidationResult(res))
+	}
+	for _, res := range result.ZIP {
+		output.ZIP = append(output.ZIP, checksum.ConvertZIPValidationResult(res))
+	}
+	for _, res := range result.RAR {
+		output.RAR = append(output.RAR, rar.ConvertSetResult(res))
+	}
+
+	if len(result.Errors) > 0 {
+		output.Errors = make([]string, len(result.Errors))
+		for i, err := range result.Errors {
+			output.Errors[i] = err.Error()
+		}
+	}
+
+	return output
+}
@@ -0,0 +1,100 @@
+package check
+
+import (
+	"time"
+
+	"github.com/autobrr/sfvbrr/internal/action"
+	"github.com/autobrr/sfvbrr/internal/cache"
+	"github.com/autobrr/sfvbrr/internal/checksum"
+	"github.com/autobrr/sfvbrr/internal/rar"
+	"github.com/autobrr/sfvbrr/internal/validate"
+)
+
+// OutputFormat represents the output format type
+type OutputFormat string
+
+const (
+	OutputFormatText   OutputFormat = "text"
+	OutputFormatJSON   OutputFormat = "json"
+	OutputFormatYAML   OutputFormat = "yaml"
+	OutputFormatNDJSON OutputFormat = "ndjson"
+)
+
+// Result represents the combined result of all checks run on a release folder
+type Result struct {
+	FolderPath string
+	Category   string
+	Checks     []string // Checks run on the release, in order
+	Skipped    []string // Checks that found nothing to verify (e.g. no SFV files)
+	Valid      bool     // Verdict for the whole release
+	Cancelled  bool     // The checks were cancelled before they were complete
+	Rules      *validate.ValidationResult
+	SFV        []*checksum.ValidationResult
+	ZIP        []*checksum.ZIPValidationResult
+	RAR        []*rar.SetResult
+	Errors     []error
+	Actions    []action.Outcome // Outcomes of the post-validation actions
+}
+
+// Options contains configuration options for release checks
+type Options struct {
+	PresetPath        string       // Path to preset YAML file (empty = auto-detect)
+	Workers           int          // Number of parallel workers for checksum validation (0 = auto)
+	BufferSize        int          // Buffer size for file reading (0 = auto)
+	Verbose           bool         // Verbose output
+	Quiet             bool         // Quiet mode (minimal output)
+	Recursive         bool         // Recursive mode - search subdirectories for releases
+	OverwriteCategory string       // Override category detection (empty = use auto-detection)
+	OutputFormat      OutputFormat // Output format: text, json, yaml, or ndjson
+
+	Cache      *cache.Cache  // Verification cache for SFV and ZIP checks (nil = disabled)
+	TrustCache bool          // Skip files that are unchanged since they were verified
+	MaxAge     time.Duration // Re-verify files whose cached result is older than this (0 = no limit)
+
+	SkipActions bool // Don't run the post-validation actions of the preset configuration
+	DryRun      bool // Report the post-validation actions without running them
+
+	Progress func(check string, completed, total int) // Called after every validated file or ZIP entry of the sfv and zip checks (nil = none)
+
+	// AllowPath is called with every SFV, ZIP and RAR file before it is read; files it
+	// returns an error for are reported as invalid with that error (nil = all files)
+	AllowPath func(path string) error
+}
+
+// DefaultOptions returns default options for release checks
+func DefaultOptions() Options {
+	return Options{
+		PresetPath:        "",
+		Workers:           0, // Auto-detect
+		BufferSize:        0, // Auto-detect
+		Verbose:           false,
+		Quiet:             false,
+		Recursive:         false,
+		OverwriteCategory: "",
+		OutputFormat:      OutputFormatText,
+	}
+}
+
+// checksumOptions returns the options used for the SFV or ZIP check of a release
+func (o Options) checksumOptions(check string) checksum.Options {
+	var progress checksum.ProgressFunc
+	if o.Progress != nil {
+		progress = func(completed, total int) {
+			o.Progress(check, completed, total)
+		}
+	}
+
+	return checksum.Options{
+		Workers:      o.Workers,
+		BufferSize:   o.BufferSize,
+		Verbose:      o.Verbose,
+		Quiet:        o.Quiet,
+		Recursive:    true, // A release is processed as a batch, without file listings
+		OutputFormat: checksum.OutputFormat(o.OutputFormat),
+		Cache:        o.Cache,
+		TrustCache:   o.TrustCache,
+		MaxAge:       o.MaxAge,
+		Progress:     progress,
+		AllowPath:    o.AllowPath,
+	}
+}
@@ -1,13 +1,15 @@
 package checksum
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
-	"strings"
+
+	"github.com/autobrr/sfvbrr/internal/report"
 )
 
-// FindSFVFiles finds all SFV files in the given directory (case insensitive)
+// FindSFVFiles finds all SFV files and other checksum manifests in the given directory (case insensitive)
 func FindSFVFiles(dir string) ([]string, error) {
 	entries, err := os.ReadDir(dir)
 	if err != nil {
@@ -18,20 +20,20 @@ func FindSFVFiles(dir string) ([]string, error) {
 	for _, entry := range entries {
 		if !entry.IsDir() {
 			filename := entry.Name()
-			if strings.EqualFold(filepath.Ext(filename), ".sfv") {
+			if IsManifestFile(filename) {
 				sfvFiles = append(sfvFiles, filepath.Join(dir, filename))
 			}
 		}
 	}
 
 	if len(sfvFiles) == 0 {
-		return nil, fmt.Errorf("no SFV files found in directory: %s", dir)
+		return nil, fmt.Errorf("no SFV or checksum files found in directory: %s", dir)
 	}
 
 	return sfvFiles, nil
 }
 
-// FindSFVFilesRecursive finds all SFV files recursively in the given directory
+// FindSFVFilesRecursive finds all SFV files and other checksum manifests recursively in the given directory
 func FindSFVFilesRecursive(dir string) ([]string, error) {
 	var sfvFiles []string
 
@@ -42,8 +44,7 @@ func FindSFVFilesRecursive(dir string) ([]string, error) {
 		}
 
 		if !info.IsDir() {
-			// Check if file has .sfv extension (case insensitive)
-			if strings.EqualFold(filepath.Ext(path), ".sfv") {
+			if IsManifestFile(path) {
 				sfvFiles = append(sfvFiles, path)
 			}
 		}
@@ -58,35 +59,56 @@ func FindSFVFilesRecursive(dir string) ([]string, error) {
 	return sfvFiles, nil
 }
 
-// validateSingleSFV validates a single SFV file and displays results
-// Returns true if validation failed (has invalid or missing files)
-func validateSingleSFV(sfvPath string, opts Options) (bool, error) {
+// validateSingleSFV validates a single SFV file and displays or reports the results
+// Returns true if validation failed (has invalid, missing or orphan files or was cancelled)
+func validateSingleSFV(ctx context.Context, sfvPath string, opts Options, rep *report.Writer) (bool, error) {
 	// Parse SFV file
-	sfv, err := ParseSFVFile(sfvPath)
+	sfv, err := ParseManifestFile(sfvPath)
 	if err != nil {
 		return false, fmt.Errorf("failed to parse SFV file %s: %w", sfvPath, err)
 	}
 
 	// Validate SFV
-	result, err := ValidateSFV(sfv, opts)
+	result, err := ValidateSFV(ctx, sfv, opts)
 	if err != nil {
 		return false, fmt.Errorf("failed to validate SFV: %w", err)
 	}
 
+	failed := result.Failed()
+
+	// Machine-readable output is collected into a single report
+	if rep != nil {
+		rep.Add(ConvertValidationResult(result), failed)
+		rep.Count("total_files", result.TotalFiles)
+		rep.Count("valid_files", result.ValidFiles)
+		rep.Count("invalid_files", result.InvalidFiles)
+		rep.Count("missing_files", result.MissingFiles)
+		rep.AddBytes(result.TotalBytes)
+		if opts.CheckOrphans {
+			rep.Count("orphan_files", result.OrphanFiles)
+		}
+		return failed, nil
+	}
+
 	// Display results and return validation status
-	failed := DisplayResult(result, opts)
-	return failed, nil
+	return DisplayResult(result, opts), nil
 }
 
-// ValidateFolders validates SFV files in multiple folders
-func ValidateFolders(folders []string, opts Options) error {
+// ValidateFolders validates SFV files in multiple folders. SFV files left when the
+// context is done are skipped and a cancellation error is returned.
+func ValidateFolders(ctx context.Context, folders []string, opts Options) error {
 	var hasErrors bool
+	rep := report.NewWriterFor(string(opts.OutputFormat), "sfv")
 
 	for _, folder := range folders {
+		if ctx.Err() != nil {
+			break
+		}
+
 		// Resolve absolute path
 		absPath, err := filepath.Abs(folder)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error: failed to resolve path %s: %v\n", folder, err)
+			report.Error(rep, fmt.Errorf("failed to resolve path %s: %w", folder, err))
 			hasErrors = true
 			continue
 		}
@@ -94,70 +116,73 @@ func ValidateFolders(folders []string, opts Options) error {
 		// Check if directory exists
 		info, err := os.Stat(absPath)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error: %s does not exist: %v\n", folder, err)
+			report.Error(rep, fmt.Errorf("%s does not exist: %w", folder, err))
 			hasErrors = true
 			continue
 		}
 
 		if !info.IsDir() {
-			fmt.Fprintf(os.Stderr, "Error: %s is not a directory\n", folder)
+			report.Error(rep, fmt.Errorf("%s is not a directory", folder))
 			hasErrors = true
 			continue
 		}
 
+		var sfvFiles []string
 		if opts.Recursive {
 			// Find all SFV files recursively
-			sfvFiles, err := FindSFVFilesRecursive(absPath)
+			sfvFiles, err = FindSFVFilesRecursive(absPath)
 			if err != nil {
-				fmt.Fprintf(os.Stderr, "Error: failed to find SFV files recursively in %s: %v\n", folder, err)
+				report.Error(rep, fmt.Errorf("failed to find SFV files recursively in %s: %w", folder, err))
 				hasErrors = true
 				continue
 			}
 
 			if len(sfvFiles) == 0 {
 				if !opts.Quiet {
-					fmt.Fprintf(os.Stderr, "No SFV files found in %s\n", folder)
+					fmt.Fprintf(os.Stderr, "No SFV or checksum files found in %s\n", folder)
+				}
+				if rep != nil {
+					rep.AddError(fmt.Errorf("no SFV or checksum files found in %s", folder))
 				}
 				hasErrors = true
 				continue
 			}
-
-			// Validate each SFV file found
-			for _, sfvPath := range sfvFiles {
-				failed, err := validateSingleSFV(sfvPath, opts)
-				if err != nil {
-					fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-					hasErrors = true
-				} else if failed {
-					hasErrors = true
-				}
-			}
 		} else {
 			// Find all SFV files in current directory only
-			sfvFiles, err := FindSFVFiles(absPath)
+			sfvFiles, err = FindSFVFiles(absPath)
 			if err != nil {
-				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				report.Error(rep, err)
 				hasErrors = true
 				continue
 			}
+		}
 
-			// Validate each SFV file found
-			for _, sfvPath := range sfvFiles {
-				failed, err := validateSingleSFV(sfvPath, opts)
-				if err != nil {
-					fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-					hasErrors = true
-				} else if failed {
-					hasErrors = true
-				}
+		// Validate each SFV file found
+		for _, sfvPath := range sfvFiles {
+			if ctx.Err() != nil {
+				break
+			}
+			failed, err := validateSingleSFV(ctx, sfvPath, opts, rep)
+			if err != nil {
+				report.Error(rep, err)
+				hasErrors = true
+			} else if failed {
+				hasErrors = true
 			}
 		}
 	}
 
+	if err := closeReport(rep); err != nil {
+		return err
+	}
+
+	if ctx.Err() != nil {
+		return cancelledError(ctx)
+	}
+
 	if hasErrors {
 		return fmt.Errorf("one or more folders had errors")
 	}
 
 	return nil
 }
-
@@ -1,6 +1,7 @@
 package checksum
 
 import (
+	"context"
 	"os"
 	"path/filepath"
 	"testing"
@@ -124,7 +125,7 @@ func TestValidateFolders_Recursive(t *testing.T) {
 		OutputFormat: OutputFormatText,
 	}
 
-	err = ValidateFolders([]string{tmpDir}, opts)
+	err = ValidateFolders(context.Background(), []string{tmpDir}, opts)
 	if err != nil {
 		t.Errorf("Expected validation to succeed, got error: %v", err)
 	}
@@ -0,0 +1,85 @@
+package checksum
+
+import (
+	"context"
+	"errors"
+
+	"github.com/autobrr/sfvbrr/internal/cache"
+)
+
+// validateFileCached validates a single file against its expected checksum.
+// When the cache is trusted and the file is unchanged since it was last hashed,
+// the cached checksum is compared instead of reading the file.
+func validateFileCached(ctx context.Context, hasher Hasher, entry SFVEntry, buffer []byte, opts Options, tracker *ProgressTracker) SFVResult {
+	if opts.Cache == nil {
+		return validateFile(ctx, hasher, entry, buffer, tracker)
+	}
+
+	// Stat before hashing, so a file modified while it is read is not cached as unchanged
+	key, err := cache.StatKey(entry.Path)
+	if err != nil {
+		return validateFile(ctx, hasher, entry, buffer, tracker)
+	}
+
+	// CRC-32 records keep the member and field they had before other algorithms existed
+	member := ""
+	if hasher.Algorithm != AlgorithmCRC32 {
+		member = string(hasher.Algorithm)
+	}
+
+	if opts.TrustCache {
+		if record, ok := opts.Cache.Get(cache.BucketSFV, key, member, opts.MaxAge); ok {
+			digest := record.CRC
+			if member != "" {
+				digest = record.Hash
+			}
+			if digest != "" {
+				result := compareChecksum(SFVResult{Entry: entry}, digest)
+				result.Cached = true
+				return result
+			}
+		}
+	}
+
+	result := validateFile(ctx, hasher, entry, buffer, tracker)
+	if result.Computed != "" {
+		record := cache.Record{Valid: result.Valid}
+		if member == "" {
+			record.CRC = result.Computed
+		} else {
+			record.Hash = result.Computed
+		}
+		opts.Cache.Put(cache.BucketSFV, key, member, record)
+	}
+	return result
+}
+
+// validateZIPEntryCached validates a single ZIP entry, taking the verdict from the
+// cache when it is trusted and the ZIP file is unchanged since it was last tested
+func validateZIPEntryCached(ctx context.Context, archive *zipArchive, entry ZIPEntry, buffer []byte, opts Options, tracker *ProgressTracker) ZIPResult {
+	if opts.Cache == nil || !archive.cacheable {
+		return validateZIPEntry(ctx, archive, entry, buffer, tracker)
+	}
+
+	if opts.TrustCache {
+		if record, ok := opts.Cache.Get(cache.BucketZIP, archive.key, entry.Name, opts.MaxAge); ok {
+			result := ZIPResult{Entry: entry, Valid: record.Valid, Cached: true}
+			if record.Error != "" {
+				result.Error = errors.New(record.Error)
+			}
+			return result
+		}
+	}
+
+	result := validateZIPEntry(ctx, archive, entry, buffer, tracker)
+	if IsCancelled(result.Error) {
+		// An interrupted test says nothing about the entry
+		return result
+	}
+	record := cache.Record{Valid: result.Valid}
+	if result.Error != nil {
+		record.Error = result.Error.Error()
+	}
+	opts.Cache.Put(cache.BucketZIP, archive.key, entry.Name, record)
+	return result
+}
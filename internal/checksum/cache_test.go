@@ -0,0 +1,89 @@
+package checksum
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/autobrr/sfvbrr/internal/cache"
+)
+
+func TestValidateSFV_TrustCache(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	c, err := cache.Open(filepath.Join(tmpDir, "cache.db"))
+	if err != nil {
+		t.Fatalf("Failed to open cache: %v", err)
+	}
+	defer c.Close()
+
+	releaseDir := filepath.Join(tmpDir, "release")
+	if err := os.Mkdir(releaseDir, 0755); err != nil {
+		t.Fatalf("Failed to create release folder: %v", err)
+	}
+
+	testFile := filepath.Join(releaseDir, "test.txt")
+	content := []byte("test content")
+	if err := os.WriteFile(testFile, content, 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	sfvPath := filepath.Join(releaseDir, "test.sfv")
+	sfvContent := "test.txt " + computeCRC32ForContent(content) + "\n"
+	if err := os.WriteFile(sfvPath, []byte(sfvContent), 0644); err != nil {
+		t.Fatalf("Failed to create SFV file: %v", err)
+	}
+
+	sfv, err := ParseSFVFile(sfvPath)
+	if err != nil {
+		t.Fatalf("Failed to parse SFV file: %v", err)
+	}
+
+	opts := DefaultOptions()
+	opts.Quiet = true
+	opts.Cache = c
+
+	// The first run hashes the file and records the result
+	result, err := ValidateSFV(context.Background(), sfv, opts)
+	if err != nil {
+		t.Fatalf("Failed to validate SFV: %v", err)
+	}
+	if result.ValidFiles != 1 || result.CachedFiles != 0 {
+		t.Fatalf("Expected one hashed file, got valid=%d cached=%d", result.ValidFiles, result.CachedFiles)
+	}
+	if err := c.Flush(); err != nil {
+		t.Fatalf("Failed to flush cache: %v", err)
+	}
+
+	// Corrupt the content without changing size or modification time
+	info, _ := os.Stat(testFile)
+	if err := os.WriteFile(testFile, []byte("TEST CONTENT"), 0644); err != nil {
+		t.Fatalf("Failed to modify test file: %v", err)
+	}
+	if err := os.Chtimes(testFile, info.ModTime(), info.ModTime()); err != nil {
+		t.Fatalf("Failed to restore modification time: %v", err)
+	}
+
+	// A trusted cache skips the unchanged file
+	opts.TrustCache = true
+	result, err = ValidateSFV(context.Background(), sfv, opts)
+	if err != nil {
+		t.Fatalf("Failed to validate SFV: %v", err)
+	}
+	if result.CachedFiles != 1 || result.ValidFiles != 1 {
+		t.Errorf("Expected the cached verdict, got valid=%d cached=%d", result.ValidFiles, result.CachedFiles)
+	}
+
+	// An expired cache entry forces the file to be hashed again
+	time.Sleep(10 * time.Millisecond)
+	opts.MaxAge = time.Millisecond
+	result, err = ValidateSFV(context.Background(), sfv, opts)
+	if err != nil {
+		t.Fatalf("Failed to validate SFV: %v", err)
+	}
+	if result.CachedFiles != 0 || result.InvalidFiles != 1 {
+		t.Errorf("Expected a full re-verification, got invalid=%d cached=%d", result.InvalidFiles, result.CachedFiles)
+	}
+}
@@ -0,0 +1,33 @@
+package checksum
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// contextReader is a reader that stops with the context error once the context is done.
+// The check runs before every read, so a cancelled validation stops within one buffer
+// instead of at the end of the file.
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (r contextReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return r.r.Read(p)
+}
+
+// IsCancelled reports whether an error was caused by a cancelled context or an expired deadline
+func IsCancelled(err error) bool {
+	return errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+}
+
+// cancelledError returns the error of a validation stopped by a done context
+func cancelledError(ctx context.Context) error {
+	return fmt.Errorf("validation cancelled: %w", context.Cause(ctx))
+}
@@ -0,0 +1,134 @@
+package checksum
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// cancellingReader cancels its context after the first read
+type cancellingReader struct {
+	r      io.Reader
+	cancel context.CancelFunc
+	reads  int
+}
+
+func (r *cancellingReader) Read(p []byte) (int, error) {
+	r.reads++
+	r.cancel()
+	return r.r.Read(p)
+}
+
+func TestContextReader_StopsMidFile(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	src := &cancellingReader{r: bytes.NewReader(make([]byte, 4*minBufferSize)), cancel: cancel}
+	n, err := io.CopyBuffer(crc32.NewIEEE(), contextReader{ctx: ctx, r: src}, make([]byte, minBufferSize))
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected context.Canceled, got %v", err)
+	}
+	if src.reads != 1 || n != minBufferSize {
+		t.Errorf("Expected to stop after one chunk, got %d reads and %d bytes", src.reads, n)
+	}
+}
+
+// writeTestSFV creates files with an SFV file listing them and returns the parsed SFV
+func writeTestSFV(t *testing.T, dir string, files int) *SFVFile {
+	t.Helper()
+	var sfv bytes.Buffer
+	for i := 0; i < files; i++ {
+		name := fmt.Sprintf("file%d.bin", i)
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(name), 0644); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+		fmt.Fprintf(&sfv, "%s 00000000\n", name)
+	}
+	sfvPath := filepath.Join(dir, "test.sfv")
+	if err := os.WriteFile(sfvPath, sfv.Bytes(), 0644); err != nil {
+		t.Fatalf("Failed to write SFV file: %v", err)
+	}
+	parsed, err := ParseSFVFile(sfvPath)
+	if err != nil {
+		t.Fatalf("Failed to parse SFV file: %v", err)
+	}
+	return parsed
+}
+
+func TestValidateSFV_Cancelled(t *testing.T) {
+	sfv := writeTestSFV(t, t.TempDir(), 5)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	result, err := ValidateSFV(ctx, sfv, Options{Workers: 2, Quiet: true, CheckOrphans: true})
+	if err != nil {
+		t.Fatalf("Failed to validate SFV: %v", err)
+	}
+	if !result.Cancelled || !result.Failed() {
+		t.Errorf("Expected a cancelled, failed result")
+	}
+	// Cancelled files are neither invalid nor missing
+	if result.ValidFiles != 0 || result.InvalidFiles != 0 || result.MissingFiles != 0 {
+		t.Errorf("Expected no verified files, got %d valid, %d invalid, %d missing",
+			result.ValidFiles, result.InvalidFiles, result.MissingFiles)
+	}
+	for _, res := range result.Results {
+		if res.Entry.Filename == "" || !IsCancelled(res.Error) {
+			t.Errorf("Expected a cancelled result for every entry, got %+v", res)
+		}
+	}
+
+	output := ConvertValidationResult(result)
+	if !output.Cancelled || !output.Results[0].Cancelled {
+		t.Errorf("Expected the output to be marked as cancelled")
+	}
+}
+
+func TestValidateZIPs_Cancelled(t *testing.T) {
+	zipPath := filepath.Join(t.TempDir(), "test.zip")
+	writeTestZIP(t, zipPath, 20, 128)
+	z, err := ParseZIPFile(zipPath)
+	if err != nil {
+		t.Fatalf("Failed to parse ZIP file: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	results, err := ValidateZIPs(ctx, []*ZIPFile{z}, Options{Workers: 2, Quiet: true})
+	if err != nil {
+		t.Fatalf("Failed to validate ZIP files: %v", err)
+	}
+	result := results[0]
+	if !result.Cancelled || !result.Failed() {
+		t.Errorf("Expected a cancelled, failed result")
+	}
+	if result.ValidEntries != 0 || result.InvalidEntries != 0 {
+		t.Errorf("Expected no tested entries, got %d valid and %d invalid", result.ValidEntries, result.InvalidEntries)
+	}
+	for _, res := range result.Results {
+		if res.Entry.Name == "" || !IsCancelled(res.Error) {
+			t.Errorf("Expected a cancelled result for every entry, got %+v", res)
+		}
+	}
+}
+
+func TestValidateFolders_Cancelled(t *testing.T) {
+	dir := t.TempDir()
+	writeTestSFV(t, dir, 2)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := ValidateFolders(ctx, []string{dir}, Options{Quiet: true})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected a cancellation error, got %v", err)
+	}
+}
@@ -0,0 +1,402 @@
+package checksum
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/autobrr/sfvbrr/internal/rar"
+)
+
+// defaultCreateExcludes are the patterns skipped when creating an SFV file.
+// Scene SFV files never list the NFO, the DIZ or other SFV files; checksum
+// manifests of every other format are skipped as well.
+var defaultCreateExcludes = []string{"*.sfv", "*.nfo", "*.diz"}
+
+// CreateOptions contains configuration options for SFV creation
+type CreateOptions struct {
+	Options
+	Output  string   // Path of the SFV file to write (empty = <folder>/<folder name>.sfv)
+	Include []string // Glob patterns of files to include (empty = all files)
+	Exclude []string // Glob patterns of files to exclude (in addition to the defaults)
+	Force   bool     // Overwrite an existing SFV file
+	Version string   // Version written into the comment header
+
+	Algorithm Algorithm // Checksum algorithm (empty = CRC-32, written as an SFV file)
+	Tagged    bool      // Write BSD-style "SHA256 (name) = digest" lines instead of GNU-style ones
+}
+
+// CreateResult represents the result of creating an SFV file
+type CreateResult struct {
+	SFVFile SFVFile
+	Sizes   []int64     // File sizes, in the same order as SFVFile.Entries
+	ModTime []time.Time // File modification times, in the same order as SFVFile.Entries
+	Created time.Time
+}
+
+// CollectSFVEntries returns the files in dir that should be listed in a new SFV file,
+// ordered the way scene SFV files usually are
+func CollectSFVEntries(dir string, include []string, exclude []string) ([]SFVEntry, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read directory: %w", err)
+	}
+
+	excludes := append(append([]string{}, defaultCreateExcludes...), exclude...)
+
+	var sfvEntries []SFVEntry
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		name := entry.Name()
+		if len(include) > 0 && !matchAnyGlob(name, include) {
+			continue
+		}
+		if matchAnyGlob(name, excludes) || IsManifestFile(name) {
+			continue
+		}
+
+		sfvEntry := SFVEntry{Filename: name}
+		sfvEntry.JoinPath(dir)
+		sfvEntries = append(sfvEntries, sfvEntry)
+	}
+
+	SortSFVEntries(sfvEntries)
+	return sfvEntries, nil
+}
+
+// matchAnyGlob reports whether name matches any of the glob patterns (case insensitive)
+func matchAnyGlob(name string, patterns []string) bool {
+	lower := strings.ToLower(name)
+	for _, pattern := range patterns {
+		if matched, err := filepath.Match(strings.ToLower(pattern), lower); err == nil && matched {
+			return true
+		}
+	}
+	return false
+}
+
+// SortSFVEntries sorts entries the way scene SFV files are usually ordered:
+// archive volumes first (grouped by set, in volume order), then everything else by name
+func SortSFVEntries(entries []SFVEntry) {
+	sort.SliceStable(entries, func(i, j int) bool {
+		baseI, idxI, volI := volumeOrder(entries[i].Filename)
+		baseJ, idxJ, volJ := volumeOrder(entries[j].Filename)
+
+		if volI != volJ {
+			return volI
+		}
+		if volI {
+			if baseI != baseJ {
+				return baseI < baseJ
+			}
+			return idxI < idxJ
+		}
+		return strings.ToLower(entries[i].Filename) < strings.ToLower(entries[j].Filename)
+	})
+}
+
+// volumeOrder returns the set name and position of an archive volume.
+// RAR volumes (name.rar + name.r00..name.z99, name.partNN.rar) and split files (name.001) are recognized.
+func volumeOrder(filename string) (string, int, bool) {
+	if volume, ok := rar.ParseVolumeName(filename); ok {
+		return volume.Key(), volume.Index, true
+	}
+
+	lower := strings.ToLower(filename)
+	ext := filepath.Ext(lower)
+	if len(ext) == 4 && isDigits(ext[1:]) {
+		n, _ := strconv.Atoi(ext[1:])
+		return strings.TrimSuffix(lower, ext), n, true
+	}
+
+	return "", 0, false
+}
+
+// isDigits reports whether s is a non-empty string of ASCII digits
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// DefaultSFVPath returns the default path of a new SFV file for a folder
+func DefaultSFVPath(dir string) string {
+	return DefaultManifestPath(dir, hashers[0])
+}
+
+// DefaultManifestPath returns the default path of a new checksum manifest for a folder,
+// e.g. <folder name>.sfv or <folder name>.sha256
+func DefaultManifestPath(dir string, hasher Hasher) string {
+	return filepath.Join(dir, strings.ToLower(filepath.Base(dir))+hasher.Extension)
+}
+
+// CreateSFV hashes the files of a folder and writes a scene-style SFV file, or a
+// GNU- or BSD-style manifest when another algorithm is selected.
+// Nothing is written when the context is done before every file is hashed.
+func CreateSFV(ctx context.Context, dir string, opts CreateOptions) (*CreateResult, error) {
+	hasher, err := LookupHasher(string(opts.Algorithm))
+	if err != nil {
+		return nil, err
+	}
+
+	format := FormatSFV
+	if hasher.Algorithm != AlgorithmCRC32 {
+		format = FormatGNU
+		if opts.Tagged {
+			format = FormatBSD
+		}
+	}
+
+	outputPath := opts.Output
+	if outputPath == "" {
+		outputPath = DefaultManifestPath(dir, hasher)
+	}
+
+	if !opts.Force {
+		if _, err := os.Stat(outputPath); err == nil {
+			return nil, fmt.Errorf("checksum file already exists: %s (use --force to overwrite)", outputPath)
+		}
+	}
+
+	entries, err := CollectSFVEntries(dir, opts.Include, opts.Exclude)
+	if err != nil {
+		return nil, err
+	}
+
+	// Never hash the SFV file we are about to write
+	absOutput, _ := filepath.Abs(outputPath)
+	filtered := entries[:0]
+	for _, entry := range entries {
+		if absEntry, _ := filepath.Abs(entry.Path); absEntry != absOutput {
+			filtered = append(filtered, entry)
+		}
+	}
+	entries = filtered
+
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("no files to hash in directory: %s", dir)
+	}
+
+	result := &CreateResult{
+		SFVFile: SFVFile{
+			Path:      outputPath,
+			Dir:       dir,
+			Entries:   entries,
+			Algorithm: hasher.Algorithm,
+			Format:    format,
+		},
+		Sizes:   make([]int64, len(entries)),
+		ModTime: make([]time.Time, len(entries)),
+		Created: time.Now(),
+	}
+
+	for i, entry := range entries {
+		info, err := os.Stat(entry.Path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to stat file: %w", err)
+		}
+		result.Sizes[i] = info.Size()
+		result.ModTime[i] = info.ModTime()
+	}
+
+	if err := hashSFVEntries(ctx, hasher, result.SFVFile.Entries, result.Sizes, opts.Options); err != nil {
+		return nil, err
+	}
+
+	f, err := os.Create(outputPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create checksum file: %w", err)
+	}
+
+	if err := WriteManifest(f, result, opts.Version); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to write checksum file: %w", err)
+	}
+
+	if err := f.Close(); err != nil {
+		return nil, fmt.Errorf("failed to write checksum file: %w", err)
+	}
+
+	return result, nil
+}
+
+// hashSFVEntries computes the checksum of every entry in parallel and stores it in entry.Checksum.
+// The sizes of the entries are the total of the progress bar.
+func hashSFVEntries(ctx context.Context, hasher Hasher, entries []SFVEntry, sizes []int64, opts Options) error {
+	workers := calculateOptimalWorkers(len(entries), opts.Workers)
+	bufferSize := resolveBufferSize(opts.BufferSize)
+
+	var totalBytes int64
+	for _, size := range sizes {
+		totalBytes += size
+	}
+	tracker := NewProgressTracker(totalBytes)
+
+	var displayer *Display
+	if !opts.Quiet {
+		displayer = newDisplayer(opts)
+		displayer.SetAction("Hashing files...")
+		displayer.ShowProgress(totalBytes)
+	}
+	defer func() {
+		if displayer == nil {
+			return
+		}
+		if ctx.Err() != nil {
+			displayer.StopProgress()
+		} else {
+			displayer.FinishProgress()
+		}
+	}()
+
+	entryChan := make(chan int, workers)
+	resultChan := make(chan struct {
+		index  int
+		digest string
+		bytes  int64
+		err    error
+	}, len(entries))
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			buffer, release := acquireBuffer(bufferSize)
+			defer release()
+
+			for idx := range entryChan {
+				digest, n, err := computeChecksum(ctx, hasher, entries[idx].Path, buffer, tracker)
+				resultChan <- struct {
+					index  int
+					digest string
+					bytes  int64
+					err    error
+				}{idx, digest, n, err}
+			}
+		}()
+	}
+
+	go func() {
+		defer close(entryChan)
+		for i := range entries {
+			select {
+			case entryChan <- i:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	go func() {
+		wg.Wait()
+		close(resultChan)
+	}()
+
+	stopTicker := startProgressTicker(displayer, tracker)
+
+	var firstErr error
+	for res := range resultChan {
+		if IsCancelled(res.err) {
+			continue
+		}
+		tracker.Skip(sizes[res.index] - res.bytes)
+		if res.err != nil {
+			if firstErr == nil {
+				firstErr = fmt.Errorf("%s: %w", entries[res.index].Filename, res.err)
+			}
+		} else {
+			entries[res.index].Checksum = res.digest
+		}
+	}
+	stopTicker()
+
+	if ctx.Err() != nil {
+		return fmt.Errorf("hashing cancelled: %w", context.Cause(ctx))
+	}
+	return firstErr
+}
+
+// WriteSFV writes a scene-style SFV file with a comment header listing size and
+// modification time of every file, followed by the "filename CRC" lines
+func WriteSFV(w io.Writer, result *CreateResult, version string) error {
+	bw := bufio.NewWriter(w)
+
+	generator := "sfvbrr"
+	if version != "" {
+		generator += " " + version
+	}
+	created := result.Created
+	fmt.Fprintf(bw, "; Generated by %s on %s at %s\n", generator, created.Format("2006-01-02"), created.Format("15:04:05"))
+	fmt.Fprintln(bw, ";")
+
+	for i, entry := range result.SFVFile.Entries {
+		var size int64
+		var modTime time.Time
+		if i < len(result.Sizes) {
+			size = result.Sizes[i]
+		}
+		if i < len(result.ModTime) {
+			modTime = result.ModTime[i]
+		}
+		fmt.Fprintf(bw, "; %12d  %s %s %s\n", size, modTime.Format("15:04.05"), modTime.Format("2006-01-02"), entry.Filename)
+	}
+
+	for _, entry := range result.SFVFile.Entries {
+		fmt.Fprintf(bw, "%s %s\n", entry.Filename, strings.ToUpper(entry.Checksum))
+	}
+
+	return bw.Flush()
+}
+
+// DisplayCreateResult displays the result of creating an SFV file or checksum manifest
+func DisplayCreateResult(result *CreateResult, opts CreateOptions) {
+	if opts.Quiet {
+		return
+	}
+
+	formatter := NewFormatter(opts.Verbose)
+	display := NewDisplay(formatter)
+
+	var totalSize int64
+	for _, size := range result.Sizes {
+		totalSize += size
+	}
+
+	if result.SFVFile.Format == "" || result.SFVFile.Format == FormatSFV {
+		fmt.Fprintf(display.output, "\n%s\n", magenta("Created SFV:"))
+		fmt.Fprintf(display.output, "  %-13s %s\n", label("SFV file:"), result.SFVFile.Path)
+	} else {
+		fmt.Fprintf(display.output, "\n%s\n", magenta("Created checksum file:"))
+		fmt.Fprintf(display.output, "  %-13s %s\n", label("File:"), result.SFVFile.Path)
+		fmt.Fprintf(display.output, "  %-13s %s (%s)\n", label("Algorithm:"), result.SFVFile.Hasher().Tag, result.SFVFile.Format)
+	}
+	fmt.Fprintf(display.output, "  %-13s %d\n", label("Total files:"), len(result.SFVFile.Entries))
+	fmt.Fprintf(display.output, "  %-13s %s\n", label("Total size:"), formatter.FormatBytes(totalSize))
+
+	if opts.Verbose {
+		fmt.Fprintln(display.output)
+		for _, entry := range result.SFVFile.Entries {
+			fmt.Fprintf(display.output, "  %s %s %s\n", success("✓"), entry.Filename, entry.Checksum)
+		}
+	}
+	fmt.Fprintln(display.output)
+}
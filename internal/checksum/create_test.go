@@ -0,0 +1,107 @@
+package checksum
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSortSFVEntries(t *testing.T) {
+	names := []string{
+		"release.nfo",
+		"release.r01",
+		"cover.jpg",
+		"release.s00",
+		"release.rar",
+		"release.r00",
+		"other.part10.rar",
+		"other.part02.rar",
+		"other.part01.rar",
+	}
+
+	entries := make([]SFVEntry, len(names))
+	for i, name := range names {
+		entries[i] = SFVEntry{Filename: name}
+	}
+
+	SortSFVEntries(entries)
+
+	expected := []string{
+		"other.part01.rar",
+		"other.part02.rar",
+		"other.part10.rar",
+		"release.rar",
+		"release.r00",
+		"release.r01",
+		"release.s00",
+		"cover.jpg",
+		"release.nfo",
+	}
+
+	for i, entry := range entries {
+		if entry.Filename != expected[i] {
+			t.Errorf("Position %d: expected %s, got %s", i, expected[i], entry.Filename)
+		}
+	}
+}
+
+func TestCreateSFV_RoundTrip(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	files := map[string]string{
+		"release.rar": "first volume",
+		"release.r00": "second volume",
+		"release.nfo": "nfo is never hashed",
+		"notes.txt":   "excluded by pattern",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(tmpDir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create %s: %v", name, err)
+		}
+	}
+
+	opts := CreateOptions{
+		Options: Options{Quiet: true},
+		Exclude: []string{"*.txt"},
+	}
+
+	result, err := CreateSFV(context.Background(), tmpDir, opts)
+	if err != nil {
+		t.Fatalf("Failed to create SFV: %v", err)
+	}
+
+	if len(result.SFVFile.Entries) != 2 {
+		t.Fatalf("Expected 2 entries, got %d", len(result.SFVFile.Entries))
+	}
+
+	data, err := os.ReadFile(result.SFVFile.Path)
+	if err != nil {
+		t.Fatalf("Failed to read created SFV: %v", err)
+	}
+	if !strings.HasPrefix(string(data), "; Generated by sfvbrr") {
+		t.Errorf("Expected comment header, got %q", strings.SplitN(string(data), "\n", 2)[0])
+	}
+
+	sfv, err := ParseSFVFile(result.SFVFile.Path)
+	if err != nil {
+		t.Fatalf("Failed to parse created SFV: %v", err)
+	}
+	if sfv.Entries[0].Filename != "release.rar" {
+		t.Errorf("Expected release.rar first, got %s", sfv.Entries[0].Filename)
+	}
+
+	validation, err := ValidateSFV(context.Background(), sfv, Options{Quiet: true})
+	if err != nil {
+		t.Fatalf("Failed to validate created SFV: %v", err)
+	}
+	if validation.ValidFiles != 2 {
+		t.Errorf("Expected 2 valid files, got %d", validation.ValidFiles)
+	}
+
+	// A second run must not overwrite the file without Force
+	if _, err := CreateSFV(context.Background(), tmpDir, opts); err == nil {
+		t.Error("Expected error when SFV file already exists")
+	}
+}
@@ -20,6 +20,7 @@ type Display struct {
 	bar       *progressbar.ProgressBar
 	isBatch   bool
 	quiet     bool
+	action    string
 }
 
 func NewDisplay(formatter *Formatter) *Display {
@@ -27,9 +28,26 @@ func NewDisplay(formatter *Formatter) *Display {
 		formatter: formatter,
 		quiet:     false,
 		output:    os.Stdout,
+		action:    "Validating files...",
 	}
 }
 
+// newDisplayer creates a displayer for progress output. Machine-readable output formats
+// keep stdout clean for the report, so progress is written to stderr instead.
+func newDisplayer(opts Options) *Display {
+	displayer := NewDisplay(NewFormatter(opts.Verbose))
+	displayer.SetQuiet(opts.Quiet)
+	if !opts.Quiet && opts.OutputFormat != "" && opts.OutputFormat != OutputFormatText {
+		displayer.output = os.Stderr
+	}
+	return displayer
+}
+
+// SetAction sets the description shown next to the progress bar
+func (d *Display) SetAction(action string) {
+	d.action = action
+}
+
 // SetQuiet enables/disables quiet mode (output redirected to io.Discard)
 func (d *Display) SetQuiet(quiet bool) {
 	d.quiet = quiet
@@ -40,16 +58,19 @@ func (d *Display) SetQuiet(quiet bool) {
 	}
 }
 
-func (d *Display) ShowProgress(total int) {
+// ShowProgress creates the progress bar for the given number of bytes
+func (d *Display) ShowProgress(totalBytes int64) {
 	// Progress bar needs explicit quiet check because it writes directly to the terminal,
 	// bypassing our d.output writer
 	if d.quiet {
 		return
 	}
 	fmt.Fprintln(d.output)
-	d.bar = progressbar.NewOptions(total,
+	d.bar = progressbar.NewOptions64(totalBytes,
+		progressbar.OptionSetWriter(d.output),
 		progressbar.OptionEnableColorCodes(true),
-		progressbar.OptionSetDescription("[cyan][bold]Validating files...[reset]"),
+		progressbar.OptionSetPredictTime(false),
+		progressbar.OptionSetDescription(fmt.Sprintf("[cyan][bold]%s[reset]", d.action)),
 		progressbar.OptionSetTheme(progressbar.Theme{
 			Saucer:        "[green]=[reset]",
 			SaucerHead:    "[green]>[reset]",
@@ -60,7 +81,9 @@ func (d *Display) ShowProgress(total int) {
 	)
 }
 
-func (d *Display) UpdateProgress(completed int, rate float64) {
+// UpdateProgress moves the progress bar to the bytes processed so far and shows
+// the read throughput and the estimated time remaining
+func (d *Display) UpdateProgress(tracker *ProgressTracker) {
 	// Progress bar needs explicit quiet check because it writes directly to the terminal,
 	// bypassing our d.output writer
 	if d.quiet {
@@ -68,15 +91,20 @@ func (d *Display) UpdateProgress(completed int, rate float64) {
 	}
 	// Allow progress updates even in batch mode - batch mode only suppresses file listings
 	if d.bar != nil {
-		if err := d.bar.Set(completed); err != nil {
-			// Silently ignore progress bar errors
-			_ = err
+		description := fmt.Sprintf("[cyan][bold]%s[reset] %s/%s", d.action,
+			d.formatter.FormatBytes(tracker.Done()), d.formatter.FormatBytes(tracker.Total))
+		if rate := tracker.GetRate(); rate > 0 {
+			description += fmt.Sprintf(" [%s/s", d.formatter.FormatBytes(int64(rate)))
+			if eta := tracker.GetETA().Round(time.Second); eta > 0 {
+				description += ", ETA " + d.formatter.FormatDuration(eta)
+			}
+			description += "]"
 		}
+		d.bar.Describe(description)
 
-		if rate > 0 {
-			rateStr := d.formatter.FormatBytes(int64(rate))
-			description := fmt.Sprintf("[cyan][bold]Validating files...[reset] [%s/s]", rateStr)
-			d.bar.Describe(description)
+		if err := d.bar.Set64(tracker.Done()); err != nil {
+			// Silently ignore progress bar errors
+			_ = err
 		}
 	}
 }
@@ -215,6 +243,17 @@ func (d *Display) FinishProgress() {
 	}
 }
 
+// StopProgress stops the progress bar where it is, for validations that were cancelled
+func (d *Display) StopProgress() {
+	if d.quiet {
+		return
+	}
+	if d.bar != nil {
+		_ = d.bar.Exit()
+		fmt.Fprintln(d.output)
+	}
+}
+
 func (d *Display) IsBatch() bool {
 	return d.isBatch
 }
@@ -243,18 +282,9 @@ func (d *Display) ShowWarning(msg string) {
 	fmt.Fprintf(d.output, "%s %s\n", yellow("Warning:"), msg)
 }
 
-// DisplayResult displays the validation results to the user
-// Returns true if validation failed (has invalid or missing files)
+// DisplayResult displays the validation results to the user in text form
+// Returns true if validation failed (has invalid, missing or orphan files)
 func DisplayResult(result *ValidationResult, opts Options) bool {
-	// Handle JSON/YAML output
-	if opts.OutputFormat != OutputFormatText {
-		if err := OutputValidationResult(result, opts.OutputFormat); err != nil {
-			fmt.Fprintf(os.Stderr, "Error: failed to output result: %v\n", err)
-			return true
-		}
-		return result.InvalidFiles > 0 || result.MissingFiles > 0
-	}
-
 	formatter := NewFormatter(opts.Verbose)
 	display := NewDisplay(formatter)
 	display.SetQuiet(opts.Quiet)
@@ -262,18 +292,28 @@ func DisplayResult(result *ValidationResult, opts Options) bool {
 
 	if opts.Quiet {
 		// In quiet mode, only show summary if there are errors
-		if result.InvalidFiles > 0 || result.MissingFiles > 0 {
-			fmt.Fprintf(os.Stderr, "%s: %d invalid, %d missing\n",
+		if result.Failed() {
+			fmt.Fprintf(os.Stderr, "%s: %d invalid, %d missing, %d orphaned\n",
 				result.SFVFile.Path,
 				result.InvalidFiles,
-				result.MissingFiles)
+				result.MissingFiles,
+				result.OrphanFiles)
+			if result.Cancelled {
+				fmt.Fprintf(os.Stderr, "%s: cancelled\n", result.SFVFile.Path)
+			}
 		}
-		return result.InvalidFiles > 0 || result.MissingFiles > 0
+		return result.Failed()
 	}
 
-	// Show SFV file path
-	fmt.Fprintf(display.output, "\n%s\n", magenta("Validating SFV:"))
-	fmt.Fprintf(display.output, "  %-13s %s\n", label("SFV file:"), result.SFVFile.Path)
+	// Show SFV file path; other manifests also name their algorithm
+	if result.SFVFile.Format == "" || result.SFVFile.Format == FormatSFV {
+		fmt.Fprintf(display.output, "\n%s\n", magenta("Validating SFV:"))
+		fmt.Fprintf(display.output, "  %-13s %s\n", label("SFV file:"), result.SFVFile.Path)
+	} else {
+		fmt.Fprintf(display.output, "\n%s\n", magenta("Validating checksum file:"))
+		fmt.Fprintf(display.output, "  %-13s %s\n", label("File:"), result.SFVFile.Path)
+		fmt.Fprintf(display.output, "  %-13s %s (%s)\n", label("Algorithm:"), result.SFVFile.Hasher().Tag, result.SFVFile.Format)
+	}
 	fmt.Fprintf(display.output, "  %-13s %d\n", label("Total files:"), result.TotalFiles)
 	fmt.Fprintln(display.output)
 
@@ -296,6 +336,15 @@ func DisplayResult(result *ValidationResult, opts Options) bool {
 		fmt.Fprintln(display.output)
 	}
 
+	// Orphans are always listed, they have no entry in the SFV to show them under
+	if result.OrphanFiles > 0 {
+		fmt.Fprintf(display.output, "%s\n", magenta("Files not in SFV:"))
+		for _, orphan := range result.Orphans {
+			fmt.Fprintf(display.output, "  %s %s %s\n", errorColor("✗"), orphan, errorColor("(ORPHAN)"))
+		}
+		fmt.Fprintln(display.output)
+	}
+
 	// Show summary
 	fmt.Fprintf(display.output, "%s\n", magenta("Summary:"))
 	fmt.Fprintf(display.output, "  %-15s %s\n", label("Valid:"), success(result.ValidFiles))
@@ -305,24 +354,25 @@ func DisplayResult(result *ValidationResult, opts Options) bool {
 	if result.MissingFiles > 0 {
 		fmt.Fprintf(display.output, "  %-15s %s\n", label("Missing:"), errorColor(result.MissingFiles))
 	}
+	if result.OrphanFiles > 0 {
+		fmt.Fprintf(display.output, "  %-15s %s\n", label("Orphaned:"), errorColor(result.OrphanFiles))
+	}
+	if result.CachedFiles > 0 {
+		fmt.Fprintf(display.output, "  %-15s %d\n", label("From cache:"), result.CachedFiles)
+	}
+	fmt.Fprintf(display.output, "  %-15s %s\n", label("Verified:"), formatThroughput(formatter, result.TotalBytes, result.Duration))
+	if result.Cancelled {
+		fmt.Fprintf(display.output, "  %-15s %s\n", label("Cancelled:"), errorColor(result.TotalFiles-result.ValidFiles-result.InvalidFiles-result.MissingFiles))
+	}
 	fmt.Fprintln(display.output)
 
 	// Return true if validation failed
-	return result.InvalidFiles > 0 || result.MissingFiles > 0
+	return result.Failed()
 }
 
-// DisplayZIPResult displays the ZIP validation results to the user
-// Returns true if validation failed (has invalid entries)
+// DisplayZIPResult displays the ZIP validation results to the user in text form
+// Returns true if validation failed (has invalid entries or was cancelled)
 func DisplayZIPResult(result *ZIPValidationResult, opts Options) bool {
-	// Handle JSON/YAML output
-	if opts.OutputFormat != OutputFormatText {
-		if err := OutputZIPValidationResult(result, opts.OutputFormat); err != nil {
-			fmt.Fprintf(os.Stderr, "Error: failed to output result: %v\n", err)
-			return true
-		}
-		return result.InvalidEntries > 0
-	}
-
 	formatter := NewFormatter(opts.Verbose)
 	display := NewDisplay(formatter)
 	display.SetQuiet(opts.Quiet)
@@ -335,7 +385,10 @@ func DisplayZIPResult(result *ZIPValidationResult, opts Options) bool {
 				result.ZIPFile.Path,
 				result.InvalidEntries)
 		}
-		return result.InvalidEntries > 0
+		if result.Cancelled {
+			fmt.Fprintf(os.Stderr, "%s: cancelled\n", result.ZIPFile.Path)
+		}
+		return result.Failed()
 	}
 
 	// Show ZIP file path
@@ -370,10 +423,31 @@ func DisplayZIPResult(result *ZIPValidationResult, opts Options) bool {
 	if result.InvalidEntries > 0 {
 		fmt.Fprintf(display.output, "  %-15s %s\n", label("Invalid:"), errorColor(result.InvalidEntries))
 	}
+	if result.CachedEntries > 0 {
+		fmt.Fprintf(display.output, "  %-15s %d\n", label("From cache:"), result.CachedEntries)
+	}
+	fmt.Fprintf(display.output, "  %-15s %s\n", label("Verified:"), formatThroughput(formatter, result.TotalBytes, result.Duration))
+	if result.Cancelled {
+		fmt.Fprintf(display.output, "  %-15s %s\n", label("Cancelled:"), errorColor(result.TotalEntries-result.ValidEntries-result.InvalidEntries))
+	}
 	fmt.Fprintln(display.output)
 
 	// Return true if validation failed
-	return result.InvalidEntries > 0
+	return result.Failed()
+}
+
+// formatThroughput formats the bytes read by a validation with its duration and average rate,
+// e.g. "1.2 GiB in 3.4s (361 MiB/s)"
+func formatThroughput(formatter *Formatter, bytes int64, duration time.Duration) string {
+	text := formatter.FormatBytes(bytes)
+	if duration <= 0 {
+		return text
+	}
+	text += " in " + formatter.FormatDuration(duration)
+	if bytes > 0 {
+		text += fmt.Sprintf(" (%s/s)", formatter.FormatBytes(int64(float64(bytes)/duration.Seconds())))
+	}
+	return text
 }
 
 type Formatter struct {
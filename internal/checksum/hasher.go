@@ -0,0 +1,104 @@
+package checksum
+
+import (
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"strings"
+
+	"lukechampine.com/blake3"
+)
+
+// Algorithm identifies a checksum algorithm
+type Algorithm string
+
+const (
+	AlgorithmCRC32  Algorithm = "crc32"
+	AlgorithmMD5    Algorithm = "md5"
+	AlgorithmSHA1   Algorithm = "sha1"
+	AlgorithmSHA256 Algorithm = "sha256"
+	AlgorithmBLAKE3 Algorithm = "blake3"
+)
+
+// Hasher is a checksum algorithm that manifests can be verified and created with
+type Hasher struct {
+	Algorithm Algorithm
+	Tag       string // Name of the algorithm in BSD-style manifests, e.g. "SHA256"
+	Extension string // Extension of manifest files written with the algorithm
+	New       func() hash.Hash
+}
+
+// hashers are the supported algorithms; CRC-32 comes first as the default
+var hashers = []Hasher{
+	{Algorithm: AlgorithmCRC32, Tag: "CRC32", Extension: ".sfv", New: func() hash.Hash { return crc32.NewIEEE() }},
+	{Algorithm: AlgorithmMD5, Tag: "MD5", Extension: ".md5", New: md5.New},
+	{Algorithm: AlgorithmSHA1, Tag: "SHA1", Extension: ".sha1", New: sha1.New},
+	{Algorithm: AlgorithmSHA256, Tag: "SHA256", Extension: ".sha256", New: sha256.New},
+	{Algorithm: AlgorithmBLAKE3, Tag: "BLAKE3", Extension: ".b3", New: func() hash.Hash { return blake3.New(32, nil) }},
+}
+
+// Algorithms returns the names of the supported algorithms
+func Algorithms() []string {
+	names := make([]string, len(hashers))
+	for i, h := range hashers {
+		names[i] = string(h.Algorithm)
+	}
+	return names
+}
+
+// LookupHasher returns the hasher of an algorithm name or BSD tag (case insensitive,
+// "SHA-256" is accepted for "sha256"). The empty name is CRC-32, the algorithm of SFV files.
+func LookupHasher(name string) (Hasher, error) {
+	if name == "" {
+		return hashers[0], nil
+	}
+	normalized := strings.ReplaceAll(strings.ToLower(name), "-", "")
+	for _, h := range hashers {
+		if string(h.Algorithm) == normalized {
+			return h, nil
+		}
+	}
+	return Hasher{}, fmt.Errorf("unknown checksum algorithm %q (supported: %s)", name, strings.Join(Algorithms(), ", "))
+}
+
+// HexLen returns the length of a digest in hexadecimal
+func (h Hasher) HexLen() int {
+	return h.New().Size() * 2
+}
+
+// format formats a digest the way manifests of the algorithm list it:
+// upper case for the CRC-32 of SFV files, lower case for everything else
+func (h Hasher) format(sum []byte) string {
+	digest := hex.EncodeToString(sum)
+	if h.Algorithm == AlgorithmCRC32 {
+		return strings.ToUpper(digest)
+	}
+	return digest
+}
+
+// hasherForLength returns the hasher of a GNU-style digest of unknown algorithm.
+// BLAKE3 digests have the length of SHA-256 ones and need a .b3 file name.
+func hasherForLength(length int) (Hasher, bool) {
+	switch length {
+	case 32:
+		return mustLookupHasher(AlgorithmMD5), true
+	case 40:
+		return mustLookupHasher(AlgorithmSHA1), true
+	case 64:
+		return mustLookupHasher(AlgorithmSHA256), true
+	}
+	return Hasher{}, false
+}
+
+// mustLookupHasher returns the hasher of a supported algorithm
+func mustLookupHasher(algorithm Algorithm) Hasher {
+	h, err := LookupHasher(string(algorithm))
+	if err != nil {
+		panic(err)
+	}
+	return h
+}
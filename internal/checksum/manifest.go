@@ -0,0 +1,294 @@
+package checksum
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ManifestFormat is the line syntax of a checksum manifest
+type ManifestFormat string
+
+const (
+	FormatSFV ManifestFormat = "sfv" // "filename CRC32" lines with ";" comments
+	FormatGNU ManifestFormat = "gnu" // "digest  filename" lines as written by md5sum and sha256sum
+	FormatBSD ManifestFormat = "bsd" // "SHA256 (filename) = digest" lines as written by BSD tools and sha256sum --tag
+)
+
+// manifestExtensions maps the extensions of checksum manifests to their algorithm
+var manifestExtensions = map[string]Algorithm{
+	".sfv":       AlgorithmCRC32,
+	".md5":       AlgorithmMD5,
+	".md5sum":    AlgorithmMD5,
+	".sha1":      AlgorithmSHA1,
+	".sha1sum":   AlgorithmSHA1,
+	".sha256":    AlgorithmSHA256,
+	".sha256sum": AlgorithmSHA256,
+	".b3":        AlgorithmBLAKE3,
+	".b3sum":     AlgorithmBLAKE3,
+	".blake3":    AlgorithmBLAKE3,
+}
+
+// manifestNames maps the conventional names of manifests without extension to their algorithm
+var manifestNames = map[string]Algorithm{
+	"md5sums":    AlgorithmMD5,
+	"sha1sums":   AlgorithmSHA1,
+	"sha256sums": AlgorithmSHA256,
+	"b3sums":     AlgorithmBLAKE3,
+}
+
+// manifestAlgorithm returns the algorithm implied by the name of a manifest file
+func manifestAlgorithm(name string) (Algorithm, bool) {
+	lower := strings.ToLower(filepath.Base(name))
+	if algorithm, ok := manifestNames[lower]; ok {
+		return algorithm, true
+	}
+	algorithm, ok := manifestExtensions[filepath.Ext(lower)]
+	return algorithm, ok
+}
+
+// IsManifestFile reports whether a file name is an SFV file or another checksum manifest
+// (.md5, .sha1, .sha256, .b3, MD5SUMS, SHA256SUMS, ...), case insensitive
+func IsManifestFile(name string) bool {
+	_, ok := manifestAlgorithm(name)
+	return ok
+}
+
+// ParseManifestFile parses an SFV file or another checksum manifest and returns all entries.
+// SFV files are recognized by their extension. For other files the line syntax is detected
+// from the content: BSD-style lines name their algorithm, GNU-style lines take it from the
+// file name or, failing that, from the digest length. A file with neither is parsed as SFV.
+func ParseManifestFile(manifestPath string) (*SFVFile, error) {
+	algorithm, known := manifestAlgorithm(manifestPath)
+	if known && algorithm == AlgorithmCRC32 {
+		return ParseSFVFile(manifestPath)
+	}
+
+	file, err := os.Open(manifestPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open checksum file: %w", err)
+	}
+	defer file.Close()
+
+	var hint *Hasher
+	if known {
+		h := mustLookupHasher(algorithm)
+		hint = &h
+	}
+
+	manifest, err := parseManifest(file, manifestPath, hint)
+	if err != nil {
+		return nil, err
+	}
+	if manifest == nil {
+		if known {
+			return nil, fmt.Errorf("no valid entries found in checksum file")
+		}
+		return ParseSFVFile(manifestPath)
+	}
+	return manifest, nil
+}
+
+// parseManifest parses the GNU- or BSD-style lines of a manifest. The hint is the hasher
+// implied by the file name, if any. It returns nil if no line has either syntax.
+func parseManifest(r io.Reader, manifestPath string, hint *Hasher) (*SFVFile, error) {
+	dir := filepath.Dir(manifestPath)
+	manifest := &SFVFile{
+		Path:    manifestPath,
+		Dir:     dir,
+		Entries: make([]SFVEntry, 0),
+	}
+
+	var hasher Hasher
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		format := FormatBSD
+		tag, filename, digest, ok := parseBSDLine(line)
+		if !ok {
+			format = FormatGNU
+			if filename, digest, ok = parseGNULine(line); !ok {
+				// Skip malformed lines
+				continue
+			}
+		}
+
+		// The first entry decides the format and algorithm of the whole manifest
+		if manifest.Format == "" {
+			switch {
+			case tag != "":
+				h, err := LookupHasher(tag)
+				if err != nil {
+					// Not a digest line, e.g. the SIZE lines of BSD distinfo files
+					continue
+				}
+				hasher = h
+			case hint != nil:
+				hasher = *hint
+			default:
+				h, ok := hasherForLength(len(digest))
+				if !ok {
+					continue
+				}
+				hasher = h
+			}
+			manifest.Format = format
+			manifest.Algorithm = hasher.Algorithm
+		}
+
+		if format != manifest.Format {
+			continue
+		}
+		if tag != "" {
+			h, err := LookupHasher(tag)
+			if err != nil {
+				continue
+			}
+			if h.Algorithm != hasher.Algorithm {
+				return nil, fmt.Errorf("checksum file mixes %s and %s digests", hasher.Tag, h.Tag)
+			}
+		}
+		if len(digest) != hasher.HexLen() {
+			continue
+		}
+
+		entry := SFVEntry{
+			Filename: filename,
+			Checksum: strings.ToLower(digest),
+		}
+		entry.JoinPath(dir)
+		manifest.Entries = append(manifest.Entries, entry)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading checksum file: %w", err)
+	}
+
+	if manifest.Format == "" {
+		return nil, nil
+	}
+	if len(manifest.Entries) == 0 {
+		return nil, fmt.Errorf("no valid entries found in checksum file")
+	}
+	return manifest, nil
+}
+
+// parseGNULine parses a "digest  filename" or "digest *filename" line. Lines starting
+// with a backslash have "\\" and "\n" escapes in the file name.
+func parseGNULine(line string) (string, string, bool) {
+	escaped := strings.HasPrefix(line, "\\")
+	if escaped {
+		line = line[1:]
+	}
+
+	digest, rest, found := strings.Cut(line, " ")
+	if !found || !isHex(digest) || len(rest) < 2 || (rest[0] != ' ' && rest[0] != '*') {
+		return "", "", false
+	}
+
+	filename := rest[1:]
+	if escaped {
+		filename = unescapeGNUName(filename)
+	}
+	return filename, digest, true
+}
+
+// parseBSDLine parses a "TAG (filename) = digest" line
+func parseBSDLine(line string) (string, string, string, bool) {
+	tag, rest, found := strings.Cut(line, " (")
+	if !found || tag == "" || strings.ContainsAny(tag, " \t") {
+		return "", "", "", false
+	}
+
+	idx := strings.LastIndex(rest, ") = ")
+	if idx < 0 {
+		return "", "", "", false
+	}
+	digest := strings.TrimSpace(rest[idx+len(") = "):])
+	if !isHex(digest) {
+		return "", "", "", false
+	}
+	return tag, rest[:idx], digest, true
+}
+
+// unescapeGNUName reverses the escaping of file names with backslashes or newlines
+func unescapeGNUName(name string) string {
+	var b strings.Builder
+	for i := 0; i < len(name); i++ {
+		if name[i] == '\\' && i+1 < len(name) {
+			switch name[i+1] {
+			case '\\':
+				b.WriteByte('\\')
+				i++
+				continue
+			case 'n':
+				b.WriteByte('\n')
+				i++
+				continue
+			case 'r':
+				b.WriteByte('\r')
+				i++
+				continue
+			}
+		}
+		b.WriteByte(name[i])
+	}
+	return b.String()
+}
+
+// escapeGNUName escapes a file name for a GNU-style line. It reports whether the
+// line needs the leading backslash that marks escaped names.
+func escapeGNUName(name string) (string, bool) {
+	if !strings.ContainsAny(name, "\\\n\r") {
+		return name, false
+	}
+	replacer := strings.NewReplacer("\\", "\\\\", "\n", "\\n", "\r", "\\r")
+	return replacer.Replace(name), true
+}
+
+// isHex reports whether s is a non-empty string of hexadecimal digits
+func isHex(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if !strings.ContainsRune("0123456789abcdefABCDEF", r) {
+			return false
+		}
+	}
+	return true
+}
+
+// WriteManifest writes a checksum manifest in the format of the result: a scene-style
+// SFV file, or GNU- or BSD-style lines for the other algorithms
+func WriteManifest(w io.Writer, result *CreateResult, version string) error {
+	if result.SFVFile.Format == "" || result.SFVFile.Format == FormatSFV {
+		return WriteSFV(w, result, version)
+	}
+
+	hasher, err := LookupHasher(string(result.SFVFile.Algorithm))
+	if err != nil {
+		return err
+	}
+
+	bw := bufio.NewWriter(w)
+	for _, entry := range result.SFVFile.Entries {
+		name, escaped := escapeGNUName(entry.Filename)
+		if result.SFVFile.Format == FormatBSD {
+			fmt.Fprintf(bw, "%s (%s) = %s\n", hasher.Tag, entry.Filename, entry.Checksum)
+			continue
+		}
+		if escaped {
+			bw.WriteString("\\")
+		}
+		fmt.Fprintf(bw, "%s  %s\n", entry.Checksum, name)
+	}
+	return bw.Flush()
+}
@@ -0,0 +1,216 @@
+package checksum
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseManifestFile_GNU(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	sum := md5.Sum([]byte("Hello, World!"))
+	digest := hex.EncodeToString(sum[:])
+	content := "# comment\n" +
+		digest + "  test.txt\n" +
+		strings.ToUpper(digest) + " *binary.bin\n" +
+		"\\" + digest + "  back\\\\slash.txt\n" +
+		"not a checksum line\n"
+	manifestPath := filepath.Join(tmpDir, "release.md5")
+	if err := os.WriteFile(manifestPath, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write manifest: %v", err)
+	}
+
+	manifest, err := ParseManifestFile(manifestPath)
+	if err != nil {
+		t.Fatalf("Failed to parse manifest: %v", err)
+	}
+	if manifest.Format != FormatGNU || manifest.Algorithm != AlgorithmMD5 {
+		t.Errorf("Expected a GNU MD5 manifest, got %s %s", manifest.Format, manifest.Algorithm)
+	}
+
+	expected := []string{"test.txt", "binary.bin", "back\\slash.txt"}
+	if len(manifest.Entries) != len(expected) {
+		t.Fatalf("Expected %d entries, got %d", len(expected), len(manifest.Entries))
+	}
+	for i, name := range expected {
+		if manifest.Entries[i].Filename != name {
+			t.Errorf("Entry %d: expected %q, got %q", i, name, manifest.Entries[i].Filename)
+		}
+		if manifest.Entries[i].Checksum != digest {
+			t.Errorf("Entry %d: expected lower case digest, got %s", i, manifest.Entries[i].Checksum)
+		}
+	}
+}
+
+func TestParseManifestFile_BSD(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	digest := strings.Repeat("ab", 32)
+	content := "SHA256 (file (1).txt) = " + digest + "\n" +
+		"SIZE (file (1).txt) = 1234\n" +
+		"SHA256 (other.txt) = " + digest + "\n"
+	manifestPath := filepath.Join(tmpDir, "CHECKSUM")
+	if err := os.WriteFile(manifestPath, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write manifest: %v", err)
+	}
+
+	manifest, err := ParseManifestFile(manifestPath)
+	if err != nil {
+		t.Fatalf("Failed to parse manifest: %v", err)
+	}
+	if manifest.Format != FormatBSD || manifest.Algorithm != AlgorithmSHA256 {
+		t.Errorf("Expected a BSD SHA-256 manifest, got %s %s", manifest.Format, manifest.Algorithm)
+	}
+	if len(manifest.Entries) != 2 || manifest.Entries[0].Filename != "file (1).txt" {
+		t.Errorf("Expected 2 entries starting with \"file (1).txt\", got %+v", manifest.Entries)
+	}
+
+	// A manifest must not mix algorithms
+	mixed := content + "MD5 (third.txt) = " + strings.Repeat("ab", 16) + "\n"
+	if err := os.WriteFile(manifestPath, []byte(mixed), 0644); err != nil {
+		t.Fatalf("Failed to write manifest: %v", err)
+	}
+	if _, err := ParseManifestFile(manifestPath); err == nil {
+		t.Error("Expected an error for a manifest mixing SHA256 and MD5")
+	}
+}
+
+func TestParseManifestFile_Detection(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	tests := []struct {
+		name      string
+		content   string
+		format    ManifestFormat
+		algorithm Algorithm
+	}{
+		{"SHA256SUMS", strings.Repeat("a", 64) + "  file.bin\n", FormatGNU, AlgorithmSHA256},
+		{"release.b3", strings.Repeat("a", 64) + "  file.bin\n", FormatGNU, AlgorithmBLAKE3},
+		{"checksums.txt", strings.Repeat("a", 40) + "  file.bin\n", FormatGNU, AlgorithmSHA1},
+		{"checksums.txt", "file.bin DEADBEEF\n", FormatSFV, AlgorithmCRC32},
+		{"release.sfv", "file.bin deadbeef\n", FormatSFV, AlgorithmCRC32},
+	}
+
+	for _, tt := range tests {
+		manifestPath := filepath.Join(tmpDir, tt.name)
+		if err := os.WriteFile(manifestPath, []byte(tt.content), 0644); err != nil {
+			t.Fatalf("Failed to write manifest: %v", err)
+		}
+		manifest, err := ParseManifestFile(manifestPath)
+		if err != nil {
+			t.Errorf("%s: failed to parse manifest: %v", tt.name, err)
+			continue
+		}
+		if manifest.Format != tt.format || manifest.Algorithm != tt.algorithm {
+			t.Errorf("%s: expected %s %s, got %s %s", tt.name, tt.format, tt.algorithm, manifest.Format, manifest.Algorithm)
+		}
+	}
+}
+
+func TestIsManifestFile(t *testing.T) {
+	for _, name := range []string{"release.sfv", "RELEASE.SFV", "release.md5", "release.sha256sum", "release.b3", "MD5SUMS", "sha256sums"} {
+		if !IsManifestFile(name) {
+			t.Errorf("Expected %s to be a manifest", name)
+		}
+	}
+	for _, name := range []string{"release.nfo", "release.rar", "sums.txt"} {
+		if IsManifestFile(name) {
+			t.Errorf("Expected %s not to be a manifest", name)
+		}
+	}
+}
+
+func TestCreateAndValidateManifest(t *testing.T) {
+	for _, algorithm := range Algorithms() {
+		for _, tagged := range []bool{false, true} {
+			if tagged && algorithm == string(AlgorithmCRC32) {
+				continue
+			}
+
+			dir := filepath.Join(t.TempDir(), "Some.Release-GRP")
+			if err := os.MkdirAll(dir, 0755); err != nil {
+				t.Fatalf("Failed to create directory: %v", err)
+			}
+			for _, name := range []string{"a.bin", "b.bin"} {
+				if err := os.WriteFile(filepath.Join(dir, name), []byte(name+" content"), 0644); err != nil {
+					t.Fatalf("Failed to write file: %v", err)
+				}
+			}
+
+			opts := CreateOptions{
+				Options:   Options{Quiet: true},
+				Algorithm: Algorithm(algorithm),
+				Tagged:    tagged,
+			}
+			created, err := CreateSFV(context.Background(), dir, opts)
+			if err != nil {
+				t.Fatalf("%s: failed to create manifest: %v", algorithm, err)
+			}
+			hasher := mustLookupHasher(Algorithm(algorithm))
+			if created.SFVFile.Path != filepath.Join(dir, "some.release-grp"+hasher.Extension) {
+				t.Errorf("%s: unexpected manifest path %s", algorithm, created.SFVFile.Path)
+			}
+
+			manifest, err := ParseManifestFile(created.SFVFile.Path)
+			if err != nil {
+				t.Fatalf("%s: failed to parse created manifest: %v", algorithm, err)
+			}
+			if manifest.Algorithm != Algorithm(algorithm) || manifest.Format != created.SFVFile.Format {
+				t.Errorf("%s: parsed %s %s, created %s %s", algorithm,
+					manifest.Algorithm, manifest.Format, created.SFVFile.Algorithm, created.SFVFile.Format)
+			}
+
+			result, err := ValidateSFV(context.Background(), manifest, Options{Quiet: true})
+			if err != nil {
+				t.Fatalf("%s: failed to validate manifest: %v", algorithm, err)
+			}
+			if result.ValidFiles != 2 || result.Failed() {
+				t.Errorf("%s: expected 2 valid files, got %d valid, %d invalid", algorithm, result.ValidFiles, result.InvalidFiles)
+			}
+		}
+	}
+}
+
+func TestValidateSFV_SHA256Mismatch(t *testing.T) {
+	tmpDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(tmpDir, "good.txt"), []byte("good"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(tmpDir, "bad.txt"), []byte("bad"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	good := sha256.Sum256([]byte("good"))
+	content := hex.EncodeToString(good[:]) + "  good.txt\n" +
+		hex.EncodeToString(good[:]) + "  bad.txt\n" +
+		hex.EncodeToString(good[:]) + "  missing.txt\n"
+	manifestPath := filepath.Join(tmpDir, "release.sha256")
+	if err := os.WriteFile(manifestPath, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write manifest: %v", err)
+	}
+
+	manifest, err := ParseManifestFile(manifestPath)
+	if err != nil {
+		t.Fatalf("Failed to parse manifest: %v", err)
+	}
+	result, err := ValidateSFV(context.Background(), manifest, Options{Quiet: true})
+	if err != nil {
+		t.Fatalf("Failed to validate manifest: %v", err)
+	}
+	if result.ValidFiles != 1 || result.InvalidFiles != 1 || result.MissingFiles != 1 {
+		t.Errorf("Expected 1 valid, 1 invalid and 1 missing file, got %d, %d, %d",
+			result.ValidFiles, result.InvalidFiles, result.MissingFiles)
+	}
+
+	output := ConvertValidationResult(result)
+	if output.SFVFile.Algorithm != AlgorithmSHA256 || output.SFVFile.Format != FormatGNU {
+		t.Errorf("Expected the output to name the algorithm and format, got %s %s",
+			output.SFVFile.Algorithm, output.SFVFile.Format)
+	}
+}
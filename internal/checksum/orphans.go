@@ -0,0 +1,108 @@
+package checksum
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// DefaultOrphanIgnores are the patterns of files that are never reported as orphans.
+// Patterns ending with "/" match directories, which are skipped entirely.
+var DefaultOrphanIgnores = []string{"*.sfv", "*.nfo", "Sample/"}
+
+// FindOrphanFiles returns the files in the SFV directory that are not listed in the SFV.
+// Subdirectories holding their own SFV file or checksum manifest are separate releases and
+// are not descended into. Checksum manifests and files and directories matching one of the
+// ignore patterns are skipped.
+// The returned paths are relative to the SFV directory and use forward slashes.
+func FindOrphanFiles(sfv *SFVFile, ignore []string) ([]string, error) {
+	listed := make(map[string]bool, len(sfv.Entries))
+	for _, entry := range sfv.Entries {
+		listed[normalizeSFVName(entry.Filename)] = true
+	}
+
+	var orphans []string
+	err := filepath.WalkDir(sfv.Dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			// Continue on errors (e.g., permission denied)
+			return nil
+		}
+
+		rel, err := filepath.Rel(sfv.Dir, p)
+		if err != nil || rel == "." {
+			return nil
+		}
+		rel = filepath.ToSlash(rel)
+
+		if d.IsDir() {
+			if matchIgnorePattern(rel, true, ignore) || hasSFVFile(p) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if matchIgnorePattern(rel, false, ignore) || IsManifestFile(rel) {
+			return nil
+		}
+		if !listed[strings.ToLower(rel)] {
+			orphans = append(orphans, rel)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error walking directory: %w", err)
+	}
+
+	sort.Strings(orphans)
+	return orphans, nil
+}
+
+// normalizeSFVName converts an SFV entry name to a lower case, slash separated relative path
+func normalizeSFVName(name string) string {
+	return strings.ToLower(path.Clean(strings.ReplaceAll(name, "\\", "/")))
+}
+
+// matchIgnorePattern reports whether the relative path matches one of the ignore patterns (case insensitive).
+// Patterns ending with "/" only match directories. Patterns containing "/" are matched
+// against the full relative path, all others against the base name.
+func matchIgnorePattern(rel string, isDir bool, patterns []string) bool {
+	lowerRel := strings.ToLower(rel)
+	base := path.Base(lowerRel)
+
+	for _, pattern := range patterns {
+		pattern = strings.ToLower(pattern)
+		if strings.HasSuffix(pattern, "/") {
+			if !isDir {
+				continue
+			}
+			pattern = strings.TrimSuffix(pattern, "/")
+		}
+
+		target := base
+		if strings.Contains(pattern, "/") {
+			target = lowerRel
+		}
+		if matched, err := path.Match(pattern, target); err == nil && matched {
+			return true
+		}
+	}
+	return false
+}
+
+// hasSFVFile reports whether the directory directly contains an SFV file or checksum manifest
+func hasSFVFile(dir string) bool {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return false
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() && IsManifestFile(entry.Name()) {
+			return true
+		}
+	}
+	return false
+}
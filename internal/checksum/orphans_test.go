@@ -0,0 +1,108 @@
+package checksum
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestFindOrphanFiles(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	files := []string{
+		"release.rar",
+		"release.r00",
+		"release.r15",
+		"release.nfo",
+		"release.sfv",
+		"Sample/release-sample.mkv",
+		"Subs/release.subs.rar",
+		"CD1/release-cd1.rar",
+		"CD1/release-cd1.sfv",
+	}
+	for _, name := range files {
+		path := filepath.Join(tmpDir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte("data"), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	sfv := &SFVFile{
+		Path: filepath.Join(tmpDir, "release.sfv"),
+		Dir:  tmpDir,
+		Entries: []SFVEntry{
+			{Filename: "RELEASE.RAR", Checksum: "00000000"},
+			{Filename: "release.r00", Checksum: "00000000"},
+		},
+	}
+
+	orphans, err := FindOrphanFiles(sfv, DefaultOrphanIgnores)
+	if err != nil {
+		t.Fatalf("Failed to find orphan files: %v", err)
+	}
+
+	// CD1 has its own SFV, Sample/ and *.nfo/*.sfv are ignored
+	expected := []string{"Subs/release.subs.rar", "release.r15"}
+	if !reflect.DeepEqual(orphans, expected) {
+		t.Errorf("Expected orphans %v, got %v", expected, orphans)
+	}
+
+	orphans, err = FindOrphanFiles(sfv, []string{"*.nfo", "*.sfv", "Sample/", "Subs/*.rar", "*.r15"})
+	if err != nil {
+		t.Fatalf("Failed to find orphan files: %v", err)
+	}
+	if len(orphans) != 0 {
+		t.Errorf("Expected no orphans, got %v", orphans)
+	}
+}
+
+func TestValidateSFV_Orphans(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	content := []byte("test content")
+	if err := os.WriteFile(filepath.Join(tmpDir, "test.txt"), content, 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(tmpDir, "extra.txt"), content, 0644); err != nil {
+		t.Fatalf("Failed to create extra file: %v", err)
+	}
+
+	sfvPath := filepath.Join(tmpDir, "test.sfv")
+	sfvContent := "test.txt " + computeCRC32ForContent(content) + "\n"
+	if err := os.WriteFile(sfvPath, []byte(sfvContent), 0644); err != nil {
+		t.Fatalf("Failed to create SFV file: %v", err)
+	}
+
+	sfv, err := ParseSFVFile(sfvPath)
+	if err != nil {
+		t.Fatalf("Failed to parse SFV file: %v", err)
+	}
+
+	opts := DefaultOptions()
+	opts.Quiet = true
+
+	result, err := ValidateSFV(context.Background(), sfv, opts)
+	if err != nil {
+		t.Fatalf("Failed to validate SFV: %v", err)
+	}
+	if result.Failed() {
+		t.Error("Expected validation to pass without orphan checking")
+	}
+
+	opts.CheckOrphans = true
+	result, err = ValidateSFV(context.Background(), sfv, opts)
+	if err != nil {
+		t.Fatalf("Failed to validate SFV: %v", err)
+	}
+	if result.OrphanFiles != 1 || result.Orphans[0] != "extra.txt" {
+		t.Errorf("Expected extra.txt as orphan, got %v", result.Orphans)
+	}
+	if !result.Failed() {
+		t.Error("Expected validation to fail with orphan files")
+	}
+}
@@ -1,76 +1,101 @@
 package checksum
 
-import (
-	"encoding/json"
-	"fmt"
-	"os"
-
-	"gopkg.in/yaml.v3"
-)
+import "github.com/autobrr/sfvbrr/internal/report"
 
 // OutputResult represents the JSON/YAML output structure for SFV validation
 type OutputResult struct {
-	SFVFile     SFVFileOutput `json:"sfv_file" yaml:"sfv_file"`
-	TotalFiles  int           `json:"total_files" yaml:"total_files"`
-	ValidFiles  int           `json:"valid_files" yaml:"valid_files"`
-	InvalidFiles int          `json:"invalid_files" yaml:"invalid_files"`
-	MissingFiles int          `json:"missing_files" yaml:"missing_files"`
-	Results     []SFVResultOutput `json:"results,omitempty" yaml:"results,omitempty"`
-	Errors      []string      `json:"errors,omitempty" yaml:"errors,omitempty"`
+	SFVFile      SFVFileOutput     `json:"sfv_file" yaml:"sfv_file"`
+	TotalFiles   int               `json:"total_files" yaml:"total_files"`
+	ValidFiles   int               `json:"valid_files" yaml:"valid_files"`
+	InvalidFiles int               `json:"invalid_files" yaml:"invalid_files"`
+	MissingFiles int               `json:"missing_files" yaml:"missing_files"`
+	CachedFiles  int               `json:"cached_files,omitempty" yaml:"cached_files,omitempty"`
+	OrphanFiles  int               `json:"orphan_files" yaml:"orphan_files"`
+	Orphans      []string          `json:"orphans,omitempty" yaml:"orphans,omitempty"`
+	Cancelled    bool              `json:"cancelled,omitempty" yaml:"cancelled,omitempty"`
+	TotalBytes   int64             `json:"total_bytes" yaml:"total_bytes"`
+	Results      []SFVResultOutput `json:"results,omitempty" yaml:"results,omitempty"`
+	Errors       []string          `json:"errors,omitempty" yaml:"errors,omitempty"`
 }
 
 type SFVFileOutput struct {
-	Path    string        `json:"path" yaml:"path"`
-	Dir     string        `json:"dir" yaml:"dir"`
-	Entries []SFVEntry    `json:"entries" yaml:"entries"`
+	Path      string         `json:"path" yaml:"path"`
+	Dir       string         `json:"dir" yaml:"dir"`
+	Algorithm Algorithm      `json:"algorithm" yaml:"algorithm"`
+	Format    ManifestFormat `json:"format" yaml:"format"`
+	Entries   []SFVEntry     `json:"entries" yaml:"entries"`
 }
 
 type SFVResultOutput struct {
-	Filename string `json:"filename" yaml:"filename"`
-	Path     string `json:"path" yaml:"path"`
-	Valid    bool   `json:"valid" yaml:"valid"`
-	Computed string `json:"computed,omitempty" yaml:"computed,omitempty"`
-	Error    string `json:"error,omitempty" yaml:"error,omitempty"`
+	Filename  string `json:"filename" yaml:"filename"`
+	Path      string `json:"path" yaml:"path"`
+	Valid     bool   `json:"valid" yaml:"valid"`
+	Computed  string `json:"computed,omitempty" yaml:"computed,omitempty"`
+	Cached    bool   `json:"cached,omitempty" yaml:"cached,omitempty"`
+	Cancelled bool   `json:"cancelled,omitempty" yaml:"cancelled,omitempty"`
+	Bytes     int64  `json:"bytes" yaml:"bytes"`
+	Error     string `json:"error,omitempty" yaml:"error,omitempty"`
 }
 
 // ZIPOutputResult represents the JSON/YAML output structure for ZIP validation
 type ZIPOutputResult struct {
-	ZIPFile       string            `json:"zip_file" yaml:"zip_file"`
-	TotalEntries  int               `json:"total_entries" yaml:"total_entries"`
-	ValidEntries  int               `json:"valid_entries" yaml:"valid_entries"`
-	InvalidEntries int              `json:"invalid_entries" yaml:"invalid_entries"`
-	Results       []ZIPResultOutput `json:"results,omitempty" yaml:"results,omitempty"`
-	Errors        []string           `json:"errors,omitempty" yaml:"errors,omitempty"`
+	ZIPFile        string            `json:"zip_file" yaml:"zip_file"`
+	TotalEntries   int               `json:"total_entries" yaml:"total_entries"`
+	ValidEntries   int               `json:"valid_entries" yaml:"valid_entries"`
+	InvalidEntries int               `json:"invalid_entries" yaml:"invalid_entries"`
+	CachedEntries  int               `json:"cached_entries,omitempty" yaml:"cached_entries,omitempty"`
+	Cancelled      bool              `json:"cancelled,omitempty" yaml:"cancelled,omitempty"`
+	TotalBytes     int64             `json:"total_bytes" yaml:"total_bytes"`
+	Results        []ZIPResultOutput `json:"results,omitempty" yaml:"results,omitempty"`
+	Errors         []string          `json:"errors,omitempty" yaml:"errors,omitempty"`
 }
 
 type ZIPResultOutput struct {
-	Name   string `json:"name" yaml:"name"`
-	Valid  bool   `json:"valid" yaml:"valid"`
-	Error  string `json:"error,omitempty" yaml:"error,omitempty"`
+	Name      string `json:"name" yaml:"name"`
+	Valid     bool   `json:"valid" yaml:"valid"`
+	Cached    bool   `json:"cached,omitempty" yaml:"cached,omitempty"`
+	Cancelled bool   `json:"cancelled,omitempty" yaml:"cancelled,omitempty"`
+	Bytes     int64  `json:"bytes" yaml:"bytes"`
+	Error     string `json:"error,omitempty" yaml:"error,omitempty"`
 }
 
-// convertValidationResult converts ValidationResult to OutputResult
-func convertValidationResult(result *ValidationResult) *OutputResult {
+// ConvertValidationResult converts ValidationResult to OutputResult
+func ConvertValidationResult(result *ValidationResult) *OutputResult {
+	format := result.SFVFile.Format
+	if format == "" {
+		format = FormatSFV
+	}
+
 	output := &OutputResult{
 		SFVFile: SFVFileOutput{
-			Path:    result.SFVFile.Path,
-			Dir:     result.SFVFile.Dir,
-			Entries: result.SFVFile.Entries,
+			Path:      result.SFVFile.Path,
+			Dir:       result.SFVFile.Dir,
+			Algorithm: result.SFVFile.Hasher().Algorithm,
+			Format:    format,
+			Entries:   result.SFVFile.Entries,
 		},
 		TotalFiles:   result.TotalFiles,
 		ValidFiles:   result.ValidFiles,
 		InvalidFiles: result.InvalidFiles,
 		MissingFiles: result.MissingFiles,
+		CachedFiles:  result.CachedFiles,
+		OrphanFiles:  result.OrphanFiles,
+		Orphans:      result.Orphans,
+		Cancelled:    result.Cancelled,
+		TotalBytes:   result.TotalBytes,
 	}
 
 	if len(result.Results) > 0 {
 		output.Results = make([]SFVResultOutput, len(result.Results))
 		for i, res := range result.Results {
 			output.Results[i] = SFVResultOutput{
-				Filename: res.Entry.Filename,
-				Path:     res.Entry.Path,
-				Valid:    res.Valid,
-				Computed: res.Computed,
+				Filename:  res.Entry.Filename,
+				Path:      res.Entry.Path,
+				Valid:     res.Valid,
+				Computed:  res.Computed,
+				Cached:    res.Cached,
+				Cancelled: IsCancelled(res.Error),
+				Bytes:     res.Bytes,
 			}
 			if res.Error != nil {
 				output.Results[i].Error = res.Error.Error()
@@ -88,21 +113,27 @@ func convertValidationResult(result *ValidationResult) *OutputResult {
 	return output
 }
 
-// convertZIPValidationResult converts ZIPValidationResult to ZIPOutputResult
-func convertZIPValidationResult(result *ZIPValidationResult) *ZIPOutputResult {
+// ConvertZIPValidationResult converts ZIPValidationResult to ZIPOutputResult
+func ConvertZIPValidationResult(result *ZIPValidationResult) *ZIPOutputResult {
 	output := &ZIPOutputResult{
 		ZIPFile:        result.ZIPFile.Path,
 		TotalEntries:   result.TotalEntries,
 		ValidEntries:   result.ValidEntries,
 		InvalidEntries: result.InvalidEntries,
+		CachedEntries:  result.CachedEntries,
+		Cancelled:      result.Cancelled,
+		TotalBytes:     result.TotalBytes,
 	}
 
 	if len(result.Results) > 0 {
 		output.Results = make([]ZIPResultOutput, len(result.Results))
 		for i, res := range result.Results {
 			output.Results[i] = ZIPResultOutput{
-				Name:  res.Entry.Name,
-				Valid: res.Valid,
+				Name:      res.Entry.Name,
+				Valid:     res.Valid,
+				Cached:    res.Cached,
+				Cancelled: IsCancelled(res.Error),
+				Bytes:     res.Bytes,
 			}
 			if res.Error != nil {
 				output.Results[i].Error = res.Error.Error()
@@ -120,46 +151,10 @@ func convertZIPValidationResult(result *ZIPValidationResult) *ZIPOutputResult {
 	return output
 }
 
-// OutputValidationResult outputs the validation result in the specified format
-func OutputValidationResult(result *ValidationResult, format OutputFormat) error {
-	if format == OutputFormatText {
-		return nil // Use regular display
-	}
-
-	output := convertValidationResult(result)
-
-	switch format {
-	case OutputFormatJSON:
-		encoder := json.NewEncoder(os.Stdout)
-		encoder.SetIndent("", "  ")
-		return encoder.Encode(output)
-	case OutputFormatYAML:
-		encoder := yaml.NewEncoder(os.Stdout)
-		defer encoder.Close()
-		return encoder.Encode(output)
-	default:
-		return fmt.Errorf("unknown output format: %s", format)
-	}
-}
-
-// OutputZIPValidationResult outputs the ZIP validation result in the specified format
-func OutputZIPValidationResult(result *ZIPValidationResult, format OutputFormat) error {
-	if format == OutputFormatText {
-		return nil // Use regular display
-	}
-
-	output := convertZIPValidationResult(result)
-
-	switch format {
-	case OutputFormatJSON:
-		encoder := json.NewEncoder(os.Stdout)
-		encoder.SetIndent("", "  ")
-		return encoder.Encode(output)
-	case OutputFormatYAML:
-		encoder := yaml.NewEncoder(os.Stdout)
-		defer encoder.Close()
-		return encoder.Encode(output)
-	default:
-		return fmt.Errorf("unknown output format: %s", format)
+// closeReport writes the final report document, if any
+func closeReport(rep *report.Writer) error {
+	if rep == nil {
+		return nil
 	}
+	return rep.Close()
 }
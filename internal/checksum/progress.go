@@ -1,117 +1,175 @@
 package checksum
 
 import (
+	"io"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
+const (
+	// progressInterval is how often the progress bar is refreshed while files are read
+	progressInterval = 200 * time.Millisecond
+	// rateInterval is the minimum time between two samples of the throughput
+	rateInterval = time.Second
+	// rateSmoothing is the weight of the latest sample in the smoothed throughput
+	rateSmoothing = 0.3
+)
+
 // Displayer defines the interface for displaying progress during SFV validation
 type Displayer interface {
-	ShowProgress(total int)
-	UpdateProgress(completed int, rate float64)
+	ShowProgress(totalBytes int64)
+	UpdateProgress(tracker *ProgressTracker)
 	ShowFiles(entries []SFVEntry, numWorkers int)
 	FinishProgress()
 	IsBatch() bool
 	SetQuiet(quiet bool)
 }
 
-// ProgressTracker tracks progress of SFV validation with timing information
+// ProgressTracker tracks the progress of a validation in bytes. Workers count the bytes
+// they hash through Reader; files that are not read (missing, cached or unreadable) are
+// accounted for with Skip, so the total is reached once every file is done.
 type ProgressTracker struct {
-	Total      int
-	Current    int
-	StartTime  time.Time
-	mu         sync.Mutex
-	lastUpdate time.Time
-	lastCount  int
+	Total     int64 // Total number of bytes to process
+	StartTime time.Time
+
+	read    atomic.Int64
+	skipped atomic.Int64
+
+	mu          sync.Mutex
+	sampleTime  time.Time
+	sampleBytes int64
+	rate        float64
 }
 
-// NewProgressTracker creates a new progress tracker
-func NewProgressTracker(total int) *ProgressTracker {
+// NewProgressTracker creates a new progress tracker for the given number of bytes
+func NewProgressTracker(totalBytes int64) *ProgressTracker {
 	now := time.Now()
 	return &ProgressTracker{
-		Total:      total,
-		Current:    0,
+		Total:      totalBytes,
 		StartTime:  now,
-		lastUpdate: now,
-		lastCount:  0,
+		sampleTime: now,
 	}
 }
 
-// Update updates the current progress
-func (pt *ProgressTracker) Update(current int) {
-	pt.mu.Lock()
-	defer pt.mu.Unlock()
-	pt.Current = current
-	pt.lastUpdate = time.Now()
+// countingReader counts the bytes read through it into a progress tracker
+type countingReader struct {
+	r       io.Reader
+	tracker *ProgressTracker
+}
+
+func (c countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.tracker.read.Add(int64(n))
+	return n, err
+}
+
+// Reader wraps r so the bytes read from it are counted as processed
+func (pt *ProgressTracker) Reader(r io.Reader) io.Reader {
+	return countingReader{r: r, tracker: pt}
+}
+
+// Skip counts bytes as processed without reading them
+func (pt *ProgressTracker) Skip(n int64) {
+	if n > 0 {
+		pt.skipped.Add(n)
+	}
+}
+
+// BytesRead returns the number of bytes read so far
+func (pt *ProgressTracker) BytesRead() int64 {
+	return pt.read.Load()
+}
+
+// Done returns the number of bytes processed so far, read or skipped
+func (pt *ProgressTracker) Done() int64 {
+	return pt.read.Load() + pt.skipped.Load()
 }
 
 // GetProgress returns the current progress percentage
 func (pt *ProgressTracker) GetProgress() float64 {
-	pt.mu.Lock()
-	defer pt.mu.Unlock()
 	if pt.Total == 0 {
 		return 0
 	}
-	return float64(pt.Current) / float64(pt.Total) * 100
+	return float64(pt.Done()) / float64(pt.Total) * 100
 }
 
 // GetElapsed returns the elapsed time since the tracker was created
 func (pt *ProgressTracker) GetElapsed() time.Duration {
-	pt.mu.Lock()
-	defer pt.mu.Unlock()
 	return time.Since(pt.StartTime)
 }
 
-// GetRate calculates the current processing rate (items per second)
+// GetRate returns the current read throughput in bytes per second. It is sampled at
+// most once per second and smoothed, so the figure doesn't jump with every chunk.
+// Skipped bytes don't count, a run answered from the cache has no throughput.
 func (pt *ProgressTracker) GetRate() float64 {
 	pt.mu.Lock()
 	defer pt.mu.Unlock()
-	elapsed := time.Since(pt.lastUpdate).Seconds()
-	if elapsed < 0.1 {
-		// Use overall rate if update was too recent
-		elapsed = time.Since(pt.StartTime).Seconds()
-		if elapsed < 0.1 {
-			return 0
+
+	now := time.Now()
+	read := pt.read.Load()
+	elapsed := now.Sub(pt.sampleTime)
+	if elapsed < rateInterval {
+		if pt.rate == 0 {
+			// No sample yet, use the overall rate
+			if total := now.Sub(pt.StartTime).Seconds(); total >= 0.1 {
+				return float64(read) / total
+			}
 		}
-		return float64(pt.Current) / elapsed
+		return pt.rate
 	}
-	delta := pt.Current - pt.lastCount
-	if delta <= 0 {
-		return 0
+
+	sample := float64(read-pt.sampleBytes) / elapsed.Seconds()
+	if pt.rate == 0 {
+		pt.rate = sample
+	} else {
+		pt.rate = rateSmoothing*sample + (1-rateSmoothing)*pt.rate
 	}
-	return float64(delta) / elapsed
+	pt.sampleTime = now
+	pt.sampleBytes = read
+	return pt.rate
 }
 
-// GetETA estimates the time remaining based on current rate
+// GetETA estimates the time remaining based on the current rate
 func (pt *ProgressTracker) GetETA() time.Duration {
-	pt.mu.Lock()
-	defer pt.mu.Unlock()
-	remaining := pt.Total - pt.Current
+	remaining := pt.Total - pt.Done()
 	if remaining <= 0 {
 		return 0
 	}
+	rate := pt.GetRate()
+	if rate <= 0 {
+		return 0
+	}
+	return time.Duration(float64(remaining) / rate * float64(time.Second))
+}
 
-	// Calculate rate inline to avoid deadlock (we already hold the lock)
-	elapsed := time.Since(pt.lastUpdate).Seconds()
-	var rate float64
-	if elapsed < 0.1 {
-		// Use overall rate if update was too recent
-		elapsed = time.Since(pt.StartTime).Seconds()
-		if elapsed < 0.1 {
-			return 0
-		}
-		rate = float64(pt.Current) / elapsed
-	} else {
-		delta := pt.Current - pt.lastCount
-		if delta <= 0 {
-			return 0
-		}
-		rate = float64(delta) / elapsed
+// startProgressTicker refreshes the progress display until the returned function is called.
+// Progress is counted while files are read, so the display moves during large files too.
+func startProgressTicker(displayer *Display, tracker *ProgressTracker) func() {
+	if displayer == nil {
+		return func() {}
 	}
 
-	if rate <= 0 {
-		return 0
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(progressInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				displayer.UpdateProgress(tracker)
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+		wg.Wait()
+		displayer.UpdateProgress(tracker)
 	}
-	seconds := float64(remaining) / rate
-	return time.Duration(seconds) * time.Second
 }
@@ -0,0 +1,87 @@
+package checksum
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestProgressTracker_CountsBytes(t *testing.T) {
+	tracker := NewProgressTracker(1000)
+
+	n, err := io.Copy(io.Discard, tracker.Reader(bytes.NewReader(make([]byte, 600))))
+	if err != nil || n != 600 {
+		t.Fatalf("Expected to copy 600 bytes, got %d: %v", n, err)
+	}
+	tracker.Skip(400)
+	tracker.Skip(-10) // Files that grew while they were read never move progress back
+
+	if tracker.BytesRead() != 600 || tracker.Done() != 1000 {
+		t.Errorf("Expected 600 bytes read and 1000 done, got %d and %d", tracker.BytesRead(), tracker.Done())
+	}
+	if tracker.GetProgress() != 100 {
+		t.Errorf("Expected 100%% progress, got %.1f", tracker.GetProgress())
+	}
+	if tracker.GetETA() != 0 {
+		t.Errorf("Expected no ETA once done, got %s", tracker.GetETA())
+	}
+}
+
+func TestProgressTracker_RateAndETA(t *testing.T) {
+	tracker := NewProgressTracker(3000)
+	tracker.StartTime = time.Now().Add(-time.Second)
+	tracker.sampleTime = tracker.StartTime
+
+	if _, err := io.Copy(io.Discard, tracker.Reader(bytes.NewReader(make([]byte, 1000)))); err != nil {
+		t.Fatalf("Failed to copy: %v", err)
+	}
+
+	// 1000 bytes in one second, 2000 bytes to go
+	rate := tracker.GetRate()
+	if rate < 900 || rate > 1100 {
+		t.Errorf("Expected a rate of about 1000 B/s, got %.0f", rate)
+	}
+	eta := tracker.GetETA()
+	if eta < 1800*time.Millisecond || eta > 2200*time.Millisecond {
+		t.Errorf("Expected an ETA of about 2s, got %s", eta)
+	}
+}
+
+func TestValidateSFV_TotalBytes(t *testing.T) {
+	tmpDir := t.TempDir()
+	content := bytes.Repeat([]byte("x"), 10000)
+	if err := os.WriteFile(filepath.Join(tmpDir, "data.bin"), content, 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	sfvContent := "data.bin " + computeCRC32ForContent(content) + "\nmissing.bin 00000000\n"
+	sfvPath := filepath.Join(tmpDir, "test.sfv")
+	if err := os.WriteFile(sfvPath, []byte(sfvContent), 0644); err != nil {
+		t.Fatalf("Failed to write SFV file: %v", err)
+	}
+
+	sfv, err := ParseSFVFile(sfvPath)
+	if err != nil {
+		t.Fatalf("Failed to parse SFV file: %v", err)
+	}
+	result, err := ValidateSFV(context.Background(), sfv, Options{Quiet: true, BufferSize: minBufferSize})
+	if err != nil {
+		t.Fatalf("Failed to validate SFV: %v", err)
+	}
+
+	if result.TotalBytes != int64(len(content)) {
+		t.Errorf("Expected %d bytes verified, got %d", len(content), result.TotalBytes)
+	}
+	if result.Results[0].Bytes != int64(len(content)) || result.Results[1].Bytes != 0 {
+		t.Errorf("Expected per-file bytes of %d and 0, got %d and %d",
+			len(content), result.Results[0].Bytes, result.Results[1].Bytes)
+	}
+
+	output := ConvertValidationResult(result)
+	if output.TotalBytes != int64(len(content)) || output.Results[0].Bytes != int64(len(content)) {
+		t.Errorf("Expected the bytes in the output, got %d", output.TotalBytes)
+	}
+}
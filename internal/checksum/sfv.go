@@ -2,8 +2,9 @@ package checksum
 
 import (
 	"bufio"
+	"context"
+	"errors"
 	"fmt"
-	"hash/crc32"
 	"io"
 	"os"
 	"path/filepath"
@@ -21,6 +22,9 @@ const (
 	maxBufferSize = 1024 * 1024
 )
 
+// ErrFileNotFound is the error of SFV entries whose file is missing
+var ErrFileNotFound = errors.New("file not found")
+
 // bufferPool is a pool of reusable buffers for file reading
 var bufferPool = sync.Pool{
 	New: func() interface{} {
@@ -58,9 +62,11 @@ func ParseSFVFile(sfvPath string) (*SFVFile, error) {
 
 	dir := filepath.Dir(sfvPath)
 	sfv := &SFVFile{
-		Path:    sfvPath,
-		Dir:     dir,
-		Entries: make([]SFVEntry, 0),
+		Path:      sfvPath,
+		Dir:       dir,
+		Entries:   make([]SFVEntry, 0),
+		Algorithm: AlgorithmCRC32,
+		Format:    FormatSFV,
 	}
 
 	scanner := bufio.NewScanner(file)
@@ -111,26 +117,75 @@ func ParseSFVFile(sfvPath string) (*SFVFile, error) {
 	return sfv, nil
 }
 
-// computeCRC32 computes the CRC-32 checksum of a file
-func computeCRC32(filePath string, buffer []byte) (string, error) {
+// allowPath checks a file with the AllowPath option, if set, before it is read
+func allowPath(opts Options, path string) error {
+	if opts.AllowPath == nil {
+		return nil
+	}
+	return opts.AllowPath(path)
+}
+
+// resolveBufferSize clamps the requested buffer size to the supported range
+func resolveBufferSize(requested int) int {
+	bufferSize := requested
+	if bufferSize == 0 {
+		bufferSize = defaultBufferSize
+	}
+	if bufferSize < minBufferSize {
+		bufferSize = minBufferSize
+	}
+	if bufferSize > maxBufferSize {
+		bufferSize = maxBufferSize
+	}
+	return bufferSize
+}
+
+// acquireBuffer gets a buffer of the given size from the pool or allocates a new one.
+// The returned release function puts the buffer back into the pool.
+func acquireBuffer(bufferSize int) ([]byte, func()) {
+	if bufPtr := bufferPool.Get(); bufPtr != nil {
+		buf := bufPtr.(*[]byte)
+		if len(*buf) >= bufferSize {
+			return (*buf)[:bufferSize], func() { bufferPool.Put(buf) }
+		}
+	}
+
+	buffer := make([]byte, bufferSize)
+	return buffer, func() {
+		newBuf := buffer
+		bufferPool.Put(&newBuf)
+	}
+}
+
+// computeChecksum computes the checksum of a file with the given hasher and returns it
+// with the number of bytes read. The file is read in chunks of the buffer size, the
+// context is checked before every chunk and the bytes are counted by the tracker, if any.
+func computeChecksum(ctx context.Context, hasher Hasher, filePath string, buffer []byte, tracker *ProgressTracker) (string, int64, error) {
 	file, err := os.Open(filePath)
 	if err != nil {
-		return "", fmt.Errorf("failed to open file: %w", err)
+		return "", 0, fmt.Errorf("failed to open file: %w", err)
 	}
 	defer file.Close()
 
-	hash := crc32.NewIEEE()
-	_, err = io.CopyBuffer(hash, file, buffer)
+	var r io.Reader = file
+	if tracker != nil {
+		r = tracker.Reader(file)
+	}
+
+	h := hasher.New()
+	n, err := io.CopyBuffer(h, contextReader{ctx: ctx, r: r}, buffer)
 	if err != nil {
-		return "", fmt.Errorf("failed to read file: %w", err)
+		if IsCancelled(err) {
+			return "", n, err
+		}
+		return "", n, fmt.Errorf("failed to read file: %w", err)
 	}
 
-	// Format as 8-character uppercase hexadecimal
-	return strings.ToUpper(fmt.Sprintf("%08x", hash.Sum32())), nil
+	return hasher.format(h.Sum(nil)), n, nil
 }
 
 // validateFile validates a single file against its expected checksum
-func validateFile(entry SFVEntry, buffer []byte) SFVResult {
+func validateFile(ctx context.Context, hasher Hasher, entry SFVEntry, buffer []byte, tracker *ProgressTracker) SFVResult {
 	result := SFVResult{
 		Entry: entry,
 	}
@@ -138,23 +193,28 @@ func validateFile(entry SFVEntry, buffer []byte) SFVResult {
 	// Check if file exists
 	if _, err := os.Stat(entry.Path); os.IsNotExist(err) {
 		result.Valid = false
-		result.Error = fmt.Errorf("file not found: %s", entry.Filename)
+		result.Error = fmt.Errorf("%w: %s", ErrFileNotFound, entry.Filename)
 		return result
 	}
 
-	// Compute CRC-32
-	computed, err := computeCRC32(entry.Path, buffer)
+	computed, n, err := computeChecksum(ctx, hasher, entry.Path, buffer, tracker)
+	result.Bytes = n
 	if err != nil {
 		result.Valid = false
 		result.Error = err
 		return result
 	}
 
+	return compareChecksum(result, computed)
+}
+
+// compareChecksum records the computed checksum in the result and compares it with the expected one
+func compareChecksum(result SFVResult, computed string) SFVResult {
 	result.Computed = computed
-	result.Valid = strings.EqualFold(computed, entry.Checksum)
+	result.Valid = strings.EqualFold(computed, result.Entry.Checksum)
 
 	if !result.Valid {
-		result.Error = fmt.Errorf("checksum mismatch: expected %s, got %s", entry.Checksum, computed)
+		result.Error = fmt.Errorf("checksum mismatch: expected %s, got %s", result.Entry.Checksum, computed)
 	}
 
 	return result
@@ -188,8 +248,10 @@ func calculateOptimalWorkers(fileCount int, requested int) int {
 	return maxWorkers
 }
 
-// ValidateSFV validates all files in an SFV file using parallel processing
-func ValidateSFV(sfv *SFVFile, opts Options) (*ValidationResult, error) {
+// ValidateSFV validates all files in an SFV file using parallel processing.
+// When the context is done, the workers stop and the partial result is returned
+// with Cancelled set; files that were not verified carry the context error.
+func ValidateSFV(ctx context.Context, sfv *SFVFile, opts Options) (*ValidationResult, error) {
 	if len(sfv.Entries) == 0 {
 		return nil, fmt.Errorf("no entries to validate")
 	}
@@ -198,37 +260,42 @@ func ValidateSFV(sfv *SFVFile, opts Options) (*ValidationResult, error) {
 	workers := calculateOptimalWorkers(len(sfv.Entries), opts.Workers)
 
 	// Determine buffer size
-	bufferSize := opts.BufferSize
-	if bufferSize == 0 {
-		bufferSize = defaultBufferSize
-	}
-	if bufferSize < minBufferSize {
-		bufferSize = minBufferSize
-	}
-	if bufferSize > maxBufferSize {
-		bufferSize = maxBufferSize
+	bufferSize := resolveBufferSize(opts.BufferSize)
+
+	hasher := sfv.Hasher()
+
+	// Progress is measured in bytes; missing files count as empty
+	sizes := make([]int64, len(sfv.Entries))
+	var totalBytes int64
+	for i, entry := range sfv.Entries {
+		if info, err := os.Stat(entry.Path); err == nil {
+			sizes[i] = info.Size()
+			totalBytes += sizes[i]
+		}
 	}
+	tracker := NewProgressTracker(totalBytes)
 
-	// Create displayer for progress tracking
-	formatter := NewFormatter(opts.Verbose)
-	displayer := NewDisplay(formatter)
-	displayer.SetQuiet(opts.Quiet)
+	// Create displayer for progress tracking; quiet (library) callers get no display at all
+	var displayer *Display
 	// Don't set batch mode - we want progress even in recursive/multi-folder mode
 	// Batch mode is only for suppressing file listings, not progress bars
 
 	// Show files and initialize progress bar
 	if !opts.Quiet {
+		displayer = newDisplayer(opts)
 		// Only show file tree for single folder, non-recursive mode
 		if !opts.Recursive && len(sfv.Entries) <= 20 {
 			displayer.ShowFiles(sfv.Entries, workers)
 		}
-		displayer.ShowProgress(len(sfv.Entries))
+		displayer.ShowProgress(totalBytes)
+		defer func() {
+			if ctx.Err() != nil {
+				displayer.StopProgress()
+			} else {
+				displayer.FinishProgress()
+			}
+		}()
 	}
-	defer func() {
-		if !opts.Quiet {
-			displayer.FinishProgress()
-		}
-	}()
 
 	result := &ValidationResult{
 		SFVFile:    *sfv,
@@ -251,31 +318,19 @@ func ValidateSFV(sfv *SFVFile, opts Options) (*ValidationResult, error) {
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			// Get buffer from pool or allocate new one
-			var buffer []byte
-			if bufPtr := bufferPool.Get(); bufPtr != nil {
-				buf := bufPtr.(*[]byte)
-				if len(*buf) >= bufferSize {
-					buffer = (*buf)[:bufferSize]
-					defer bufferPool.Put(buf)
-				} else {
-					buffer = make([]byte, bufferSize)
-					defer func() {
-						newBuf := buffer
-						bufferPool.Put(&newBuf)
-					}()
-				}
-			} else {
-				buffer = make([]byte, bufferSize)
-				defer func() {
-					newBuf := buffer
-					bufferPool.Put(&newBuf)
-				}()
-			}
+			buffer, release := acquireBuffer(bufferSize)
+			defer release()
 
 			for idx := range entryChan {
 				entry := sfv.Entries[idx]
-				validationResult := validateFile(entry, buffer)
+				var validationResult SFVResult
+				if err := ctx.Err(); err != nil {
+					validationResult = SFVResult{Entry: entry, Error: err}
+				} else if err := allowPath(opts, entry.Path); err != nil {
+					validationResult = SFVResult{Entry: entry, Error: err}
+				} else {
+					validationResult = validateFileCached(ctx, hasher, entry, buffer, opts, tracker)
+				}
 				resultChan <- struct {
 					index  int
 					result SFVResult
@@ -286,10 +341,14 @@ func ValidateSFV(sfv *SFVFile, opts Options) (*ValidationResult, error) {
 
 	// Send work to workers
 	go func() {
+		defer close(entryChan)
 		for i := range sfv.Entries {
-			entryChan <- i
+			select {
+			case entryChan <- i:
+			case <-ctx.Done():
+				return
+			}
 		}
-		close(entryChan)
 	}()
 
 	// Wait for all workers to complete
@@ -298,18 +357,27 @@ func ValidateSFV(sfv *SFVFile, opts Options) (*ValidationResult, error) {
 		close(resultChan)
 	}()
 
-	// Create progress tracker
-	tracker := NewProgressTracker(len(sfv.Entries))
+	stopTicker := startProgressTicker(displayer, tracker)
 	completed := 0
 
 	// Collect results and update progress
 	for res := range resultChan {
 		result.Results[res.index] = res.result
+		if IsCancelled(res.result.Error) {
+			// Interrupted files are neither valid nor invalid
+			continue
+		}
+		// Files that were not read in full (cached, missing, unreadable) still count as processed
+		tracker.Skip(sizes[res.index] - res.result.Bytes)
+		result.TotalBytes += res.result.Bytes
+		if res.result.Cached {
+			result.CachedFiles++
+		}
 		if res.result.Valid {
 			result.ValidFiles++
 		} else {
 			if res.result.Error != nil {
-				if strings.Contains(res.result.Error.Error(), "file not found") {
+				if errors.Is(res.result.Error, ErrFileNotFound) {
 					result.MissingFiles++
 				} else {
 					result.InvalidFiles++
@@ -320,9 +388,39 @@ func ValidateSFV(sfv *SFVFile, opts Options) (*ValidationResult, error) {
 
 		// Update progress
 		completed++
-		tracker.Update(completed)
-		rate := tracker.GetRate()
-		displayer.UpdateProgress(completed, rate)
+		if opts.Progress != nil {
+			opts.Progress(completed, len(sfv.Entries))
+		}
+	}
+	stopTicker()
+	result.Duration = tracker.GetElapsed()
+
+	// Files never handed to a worker have no result yet
+	if err := ctx.Err(); err != nil {
+		for i := range result.Results {
+			if result.Results[i].Entry.Filename == "" {
+				result.Results[i] = SFVResult{Entry: sfv.Entries[i], Error: err}
+			}
+			if IsCancelled(result.Results[i].Error) {
+				result.Cancelled = true
+			}
+		}
+	}
+	if result.Cancelled {
+		return result, nil
+	}
+
+	if opts.CheckOrphans {
+		ignore := opts.IgnorePatterns
+		if ignore == nil {
+			ignore = DefaultOrphanIgnores
+		}
+		orphans, err := FindOrphanFiles(sfv, ignore)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find orphan files: %w", err)
+		}
+		result.Orphans = orphans
+		result.OrphanFiles = len(orphans)
 	}
 
 	return result, nil
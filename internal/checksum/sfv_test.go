@@ -1,6 +1,7 @@
 package checksum
 
 import (
+	"context"
 	"fmt"
 	"hash/crc32"
 	"os"
@@ -108,7 +109,7 @@ func TestValidateSFV_FileNotFound(t *testing.T) {
 		t.Fatalf("Failed to parse SFV file: %v", err)
 	}
 
-	result, err := ValidateSFV(sfv, DefaultOptions())
+	result, err := ValidateSFV(context.Background(), sfv, DefaultOptions())
 	if err != nil {
 		t.Fatalf("Failed to validate SFV: %v", err)
 	}
@@ -148,7 +149,7 @@ func TestValidateSFV_ValidFile(t *testing.T) {
 		t.Fatalf("Failed to parse SFV file: %v", err)
 	}
 
-	result, err := ValidateSFV(sfv, DefaultOptions())
+	result, err := ValidateSFV(context.Background(), sfv, DefaultOptions())
 	if err != nil {
 		t.Fatalf("Failed to validate SFV: %v", err)
 	}
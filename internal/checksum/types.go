@@ -2,21 +2,25 @@ package checksum
 
 import (
 	"path/filepath"
+	"time"
+
+	"github.com/autobrr/sfvbrr/internal/cache"
 )
 
 // OutputFormat represents the output format type
 type OutputFormat string
 
 const (
-	OutputFormatText OutputFormat = "text"
-	OutputFormatJSON OutputFormat = "json"
-	OutputFormatYAML OutputFormat = "yaml"
+	OutputFormatText   OutputFormat = "text"
+	OutputFormatJSON   OutputFormat = "json"
+	OutputFormatYAML   OutputFormat = "yaml"
+	OutputFormatNDJSON OutputFormat = "ndjson"
 )
 
-// SFVEntry represents a single entry in an SFV file
+// SFVEntry represents a single entry in an SFV file or other checksum manifest
 type SFVEntry struct {
 	Filename string
-	Checksum string // CRC-32 checksum in hexadecimal format
+	Checksum string // Checksum in hexadecimal format (CRC-32 for SFV files)
 	Path     string // Full path to the file
 }
 
@@ -25,14 +29,26 @@ type SFVResult struct {
 	Entry    SFVEntry
 	Valid    bool
 	Error    error
-	Computed string // The computed CRC-32 checksum
+	Computed string // The computed checksum
+	Cached   bool   // Whether the checksum was taken from the verification cache
+	Bytes    int64  // Number of bytes read to compute the checksum
 }
 
-// SFVFile represents a parsed SFV file
+// SFVFile represents a parsed SFV file or other checksum manifest
 type SFVFile struct {
-	Path    string     // Path to the SFV file
-	Entries []SFVEntry // All entries in the SFV file
-	Dir     string     // Directory containing the SFV file
+	Path      string         // Path to the SFV file
+	Entries   []SFVEntry     // All entries in the SFV file
+	Dir       string         // Directory containing the SFV file
+	Algorithm Algorithm      // Checksum algorithm of the entries (empty = CRC-32)
+	Format    ManifestFormat // Line syntax of the file (empty = SFV)
+}
+
+// Hasher returns the hasher of the checksum algorithm of the entries
+func (f *SFVFile) Hasher() Hasher {
+	if h, err := LookupHasher(string(f.Algorithm)); err == nil {
+		return h
+	}
+	return hashers[0]
 }
 
 // ValidationResult represents the overall result of SFV validation
@@ -43,9 +59,20 @@ type ValidationResult struct {
 	ValidFiles   int
 	InvalidFiles int
 	MissingFiles int
+	CachedFiles  int           // Number of files whose checksum was taken from the verification cache
+	OrphanFiles  int           // Number of files on disk that are not listed in the SFV
+	Orphans      []string      // Paths of the orphan files, relative to the SFV directory
+	Cancelled    bool          // Validation was cancelled before every file was verified
+	TotalBytes   int64         // Number of bytes read and verified
+	Duration     time.Duration // Time taken by the validation
 	Errors       []error
 }
 
+// Failed reports whether the validation found invalid, missing or orphan files or was cancelled
+func (r *ValidationResult) Failed() bool {
+	return r.InvalidFiles > 0 || r.MissingFiles > 0 || r.OrphanFiles > 0 || r.Cancelled
+}
+
 // Options contains configuration options for SFV validation
 type Options struct {
 	Workers      int          // Number of parallel workers (0 = auto)
@@ -53,9 +80,25 @@ type Options struct {
 	Verbose      bool         // Verbose output
 	Quiet        bool         // Quiet mode (minimal output)
 	Recursive    bool         // Recursive mode - search subdirectories
-	OutputFormat OutputFormat // Output format: text, json, or yaml
+	OutputFormat OutputFormat // Output format: text, json, yaml, or ndjson
+
+	CheckOrphans   bool     // Report files on disk that are not listed in the SFV
+	IgnorePatterns []string // Patterns of files never reported as orphans (nil = DefaultOrphanIgnores)
+
+	Cache      *cache.Cache  // Verification cache to record results in (nil = disabled)
+	TrustCache bool          // Skip files that are unchanged since they were verified
+	MaxAge     time.Duration // Re-verify files whose cached result is older than this (0 = no limit)
+
+	Progress ProgressFunc // Called after every validated file or ZIP entry (nil = none)
+
+	// AllowPath is called with every file before it is read; files it returns an error
+	// for are reported as invalid with that error instead of being read (nil = all files)
+	AllowPath func(path string) error
 }
 
+// ProgressFunc receives the number of validated files or entries out of the total
+type ProgressFunc func(completed, total int)
+
 // DefaultOptions returns default options for SFV validation
 func DefaultOptions() Options {
 	return Options{
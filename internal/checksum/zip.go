@@ -2,31 +2,42 @@ package checksum
 
 import (
 	"archive/zip"
+	"context"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/autobrr/sfvbrr/internal/cache"
+	"github.com/autobrr/sfvbrr/internal/report"
 )
 
 // ZIPEntry represents a single entry in a ZIP file
 type ZIPEntry struct {
-	Name string // Name of the file inside the ZIP
-	Path string // Full path to the ZIP file
+	Name  string // Name of the file inside the ZIP
+	Path  string // Full path to the ZIP file
+	Size  int64  // Uncompressed size of the entry
+	index int    // Position of the entry in the ZIP central directory
 }
 
 // ZIPResult represents the result of validating a single ZIP entry
 type ZIPResult struct {
-	Entry ZIPEntry
-	Valid bool
-	Error error
+	Entry  ZIPEntry
+	Valid  bool
+	Error  error
+	Cached bool  // Whether the verdict was taken from the verification cache
+	Bytes  int64 // Number of uncompressed bytes read to test the entry
 }
 
 // ZIPFile represents a ZIP file being validated
 type ZIPFile struct {
-	Path    string     // Path to the ZIP file
-	Entries []ZIPEntry // All entries in the ZIP file
+	Path    string      // Path to the ZIP file
+	Entries []ZIPEntry  // All entries in the ZIP file
+	archive *zipArchive // Central directory read by ParseZIPFile, handed over to the validation
 }
 
 // ZIPValidationResult represents the overall result of ZIP validation
@@ -36,9 +47,18 @@ type ZIPValidationResult struct {
 	TotalEntries   int
 	ValidEntries   int
 	InvalidEntries int
+	CachedEntries  int           // Number of entries whose verdict was taken from the verification cache
+	Cancelled      bool          // Validation was cancelled before every entry was tested
+	TotalBytes     int64         // Number of uncompressed bytes read and tested
+	Duration       time.Duration // Time taken by the validation
 	Errors         []error
 }
 
+// Failed reports whether the ZIP file has invalid entries or its validation was cancelled
+func (r *ZIPValidationResult) Failed() bool {
+	return r.InvalidEntries > 0 || r.Cancelled
+}
+
 // FindZIPFiles finds all ZIP files in the given directory (case insensitive)
 func FindZIPFiles(dir string) ([]string, error) {
 	entries, err := os.ReadDir(dir)
@@ -92,31 +112,36 @@ func FindZIPFilesRecursive(dir string) ([]string, error) {
 	return zipFiles, nil
 }
 
-// ParseZIPFile parses a ZIP file and returns all entries
+// ParseZIPFile parses a ZIP file and returns all entries. The file is closed once the
+// central directory is read; ValidateZIP and ValidateZIPs reuse the parsed directory
+// and reopen the file only while its entries are validated.
 func ParseZIPFile(zipPath string) (*ZIPFile, error) {
-	r, err := zip.OpenReader(zipPath)
+	archive, err := openZIPArchive(zipPath)
 	if err != nil {
-		return nil, fmt.Errorf("failed to open ZIP file: %w", err)
+		return nil, err
 	}
-	defer r.Close()
 
 	zipFile := &ZIPFile{
 		Path:    zipPath,
-		Entries: make([]ZIPEntry, 0, len(r.File)),
+		Entries: make([]ZIPEntry, 0, len(archive.reader.File)),
+		archive: archive,
 	}
 
-	for _, f := range r.File {
+	for i, f := range archive.reader.File {
 		// Skip directory entries
 		if f.FileInfo().IsDir() {
 			continue
 		}
 		entry := ZIPEntry{
-			Name: f.Name,
-			Path: zipPath,
+			Name:  f.Name,
+			Path:  zipPath,
+			Size:  int64(f.UncompressedSize64),
+			index: i,
 		}
 		zipFile.Entries = append(zipFile.Entries, entry)
 	}
 
+	archive.close()
 	if len(zipFile.Entries) == 0 {
 		return nil, fmt.Errorf("no entries found in ZIP file")
 	}
@@ -124,39 +149,107 @@ func ParseZIPFile(zipPath string) (*ZIPFile, error) {
 	return zipFile, nil
 }
 
-// validateZIPEntry validates a single entry in a ZIP file by reading it
-// This is equivalent to `zip -T` which tests the integrity of ZIP entries
-func validateZIPEntry(zipPath string, entryName string) ZIPResult {
-	result := ZIPResult{
-		Entry: ZIPEntry{
-			Name: entryName,
-			Path: zipPath,
-		},
+// zipFileReader is the io.ReaderAt a ZIP archive is read through. The file behind it
+// can be closed and reopened without parsing the central directory again.
+type zipFileReader struct {
+	file *os.File
+}
+
+// ReadAt reads from the open file, or fails if the archive is closed
+func (r *zipFileReader) ReadAt(p []byte, off int64) (int, error) {
+	if r.file == nil {
+		return 0, os.ErrClosed
 	}
+	return r.file.ReadAt(p, off)
+}
 
-	// Open the ZIP file
-	r, err := zip.OpenReader(zipPath)
+// zipArchive is a ZIP file parsed once for validation. The central directory is
+// parsed a single time and shared by all workers; every entry is read through
+// zip.File.Open, which returns an io.SectionReader-backed reader over the shared
+// file handle, so concurrent workers never contend for a file offset.
+type zipArchive struct {
+	path      string
+	source    zipFileReader
+	info      os.FileInfo // File the central directory was read from
+	reader    *zip.Reader
+	remaining atomic.Int64 // Entries not yet validated; the file is closed when it reaches zero
+	key       cache.Key    // Verification cache key of the ZIP file
+	cacheable bool         // Whether key is set
+}
+
+// openZIPArchive opens a ZIP file and reads its central directory
+func openZIPArchive(zipPath string) (*zipArchive, error) {
+	f, err := os.Open(zipPath)
 	if err != nil {
-		result.Valid = false
-		result.Error = fmt.Errorf("failed to open ZIP file: %w", err)
-		return result
+		return nil, fmt.Errorf("failed to open ZIP file: %w", err)
 	}
-	defer r.Close()
 
-	// Find the entry
-	var file *zip.File
-	for _, f := range r.File {
-		if f.Name == entryName {
-			file = f
-			break
-		}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to open ZIP file: %w", err)
+	}
+
+	archive := &zipArchive{path: zipPath, source: zipFileReader{file: f}, info: info}
+	archive.reader, err = zip.NewReader(&archive.source, info.Size())
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to open ZIP file: %w", err)
+	}
+
+	return archive, nil
+}
+
+// reopen reopens the file of an archive parsed earlier, so its entries can be read
+func (a *zipArchive) reopen() error {
+	f, err := os.Open(a.path)
+	if err != nil {
+		return fmt.Errorf("failed to open ZIP file: %w", err)
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return fmt.Errorf("failed to open ZIP file: %w", err)
+	}
+	if info.Size() != a.info.Size() || !info.ModTime().Equal(a.info.ModTime()) {
+		f.Close()
+		return fmt.Errorf("ZIP file changed since it was parsed")
+	}
+
+	a.source.file = f
+	return nil
+}
+
+// close closes the file of an archive; the parsed central directory is kept
+func (a *zipArchive) close() {
+	if a.source.file != nil {
+		a.source.file.Close()
+		a.source.file = nil
+	}
+}
+
+// release marks one entry as done and closes the archive after the last one
+func (a *zipArchive) release() {
+	if a.remaining.Add(-1) == 0 {
+		a.close()
+	}
+}
+
+// validateZIPEntry validates a single entry of an opened ZIP archive by reading it.
+// This is equivalent to `zip -T` which tests the integrity of ZIP entries.
+// The context is checked before every chunk read from the entry.
+func validateZIPEntry(ctx context.Context, archive *zipArchive, entry ZIPEntry, buffer []byte, tracker *ProgressTracker) ZIPResult {
+	result := ZIPResult{
+		Entry: entry,
 	}
 
-	if file == nil {
+	if entry.index < 0 || entry.index >= len(archive.reader.File) || archive.reader.File[entry.index].Name != entry.Name {
 		result.Valid = false
-		result.Error = fmt.Errorf("entry not found: %s", entryName)
+		result.Error = fmt.Errorf("entry not found: %s", entry.Name)
 		return result
 	}
+	file := archive.reader.File[entry.index]
 
 	// Open and read the entry to verify its CRC-32
 	// The zip package automatically verifies CRC-32 when reading
@@ -169,9 +262,17 @@ func validateZIPEntry(zipPath string, entryName string) ZIPResult {
 	defer rc.Close()
 
 	// Read the entire entry to trigger CRC-32 verification
-	_, err = io.Copy(io.Discard, rc)
+	var r io.Reader = rc
+	if tracker != nil {
+		r = tracker.Reader(rc)
+	}
+	result.Bytes, err = io.CopyBuffer(io.Discard, contextReader{ctx: ctx, r: r}, buffer)
 	if err != nil {
 		result.Valid = false
+		if IsCancelled(err) {
+			result.Error = err
+			return result
+		}
 		result.Error = fmt.Errorf("failed to read entry (CRC-32 mismatch or corrupted): %w", err)
 		return result
 	}
@@ -181,44 +282,99 @@ func validateZIPEntry(zipPath string, entryName string) ZIPResult {
 }
 
 // ValidateZIP validates all entries in a ZIP file using parallel processing
-func ValidateZIP(zip *ZIPFile, opts Options) (*ZIPValidationResult, error) {
+func ValidateZIP(ctx context.Context, zip *ZIPFile, opts Options) (*ZIPValidationResult, error) {
 	if len(zip.Entries) == 0 {
 		return nil, fmt.Errorf("no entries to validate")
 	}
 
+	results, err := ValidateZIPs(ctx, []*ZIPFile{zip}, opts)
+	if err != nil {
+		return nil, err
+	}
+	return results[0], nil
+}
+
+// zipJob identifies a single entry of one of the archives being validated
+type zipJob struct {
+	archive int
+	entry   int
+}
+
+// ValidateZIPs validates all entries of several ZIP files with a single worker pool.
+// Entries of all archives are spread across the workers, so a folder with many small
+// archives is processed in parallel as well as a single archive with many entries.
+// Each archive is opened shortly before its first entry is scheduled, reusing the
+// central directory read by ParseZIPFile, and closed as soon as its last entry has
+// been validated. When the context is done, the
+// partial results are returned with Cancelled set on the archives that were not
+// fully tested; their untested entries carry the context error.
+func ValidateZIPs(ctx context.Context, zips []*ZIPFile, opts Options) ([]*ZIPValidationResult, error) {
+	// Take over the archives parsed by ParseZIPFile
+	archives := make([]*zipArchive, len(zips))
+	for i, z := range zips {
+		archives[i], z.archive = z.archive, nil
+	}
+
+	totalEntries := 0
+	for _, z := range zips {
+		if len(z.Entries) == 0 {
+			return nil, fmt.Errorf("no entries to validate in %s", z.Path)
+		}
+		totalEntries += len(z.Entries)
+	}
+	if totalEntries == 0 {
+		return nil, fmt.Errorf("no entries to validate")
+	}
+
 	// Calculate optimal worker count
-	workers := calculateOptimalWorkers(len(zip.Entries), opts.Workers)
+	workers := calculateOptimalWorkers(totalEntries, opts.Workers)
+	bufferSize := resolveBufferSize(opts.BufferSize)
+
+	// Progress is measured in uncompressed bytes
+	var totalBytes int64
+	for _, z := range zips {
+		for _, entry := range z.Entries {
+			totalBytes += entry.Size
+		}
+	}
+	tracker := NewProgressTracker(totalBytes)
 
-	// Create displayer for progress tracking
-	formatter := NewFormatter(opts.Verbose)
-	displayer := NewDisplay(formatter)
-	displayer.SetQuiet(opts.Quiet)
+	// Create displayer for progress tracking; quiet (library) callers get no display at all
+	var displayer *Display
 
 	// Show files and initialize progress bar
 	if !opts.Quiet {
+		displayer = newDisplayer(opts)
 		// For ZIP files, we don't show the file tree (entries are inside ZIP files)
 		// Just show the progress bar which is the main reporting mechanism
-		displayer.ShowProgress(len(zip.Entries))
+		displayer.ShowProgress(totalBytes)
+		defer func() {
+			if ctx.Err() != nil {
+				displayer.StopProgress()
+			} else {
+				displayer.FinishProgress()
+			}
+		}()
 	}
-	defer func() {
-		if !opts.Quiet {
-			displayer.FinishProgress()
-		}
-	}()
 
-	result := &ZIPValidationResult{
-		ZIPFile:      *zip,
-		Results:      make([]ZIPResult, len(zip.Entries)),
-		TotalEntries: len(zip.Entries),
-		Errors:       make([]error, 0),
+	results := make([]*ZIPValidationResult, len(zips))
+	for i, z := range zips {
+		results[i] = &ZIPValidationResult{
+			ZIPFile:      *z,
+			Results:      make([]ZIPResult, len(z.Entries)),
+			TotalEntries: len(z.Entries),
+			Errors:       make([]error, 0),
+		}
 	}
 
+	openErrors := make([]error, len(zips))
+
 	// Create channels for work distribution
-	entryChan := make(chan int, workers)
+	jobChan := make(chan zipJob, workers)
 	resultChan := make(chan struct {
-		index  int
+		job    zipJob
 		result ZIPResult
-	}, len(zip.Entries))
+	}, workers)
 
 	var wg sync.WaitGroup
 
@@ -227,23 +383,75 @@ func ValidateZIP(zip *ZIPFile, opts Options) (*ZIPValidationResult, error) {
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			for idx := range entryChan {
-				entry := zip.Entries[idx]
-				validationResult := validateZIPEntry(entry.Path, entry.Name)
+			buffer, release := acquireBuffer(bufferSize)
+			defer release()
+
+			for job := range jobChan {
+				entry := zips[job.archive].Entries[job.entry]
+
+				var validationResult ZIPResult
+				if archive := archives[job.archive]; archive != nil {
+					if err := ctx.Err(); err != nil {
+						validationResult = ZIPResult{Entry: entry, Error: err}
+					} else {
+						validationResult = validateZIPEntryCached(ctx, archive, entry, buffer, opts, tracker)
+					}
+					archive.release()
+				} else {
+					validationResult = ZIPResult{Entry: entry, Error: openErrors[job.archive]}
+				}
+
 				resultChan <- struct {
-					index  int
+					job    zipJob
 					result ZIPResult
-				}{idx, validationResult}
+				}{job, validationResult}
 			}
 		}()
 	}
 
-	// Send work to workers
+	// Send work to workers, opening each archive right before its entries are queued
 	go func() {
-		for i := range zip.Entries {
-			entryChan <- i
+		defer close(jobChan)
+		for a, z := range zips {
+			if ctx.Err() != nil {
+				return
+			}
+			archive := archives[a]
+			var err error
+			if archive == nil {
+				archive, err = openZIPArchive(z.Path)
+			} else {
+				err = archive.reopen()
+			}
+			if err != nil {
+				openErrors[a] = err
+				archive = nil
+			}
+			archives[a] = archive
+			if archive != nil {
+				archive.remaining.Store(int64(len(z.Entries)))
+				if opts.Cache != nil {
+					if key, err := cache.StatKey(z.Path); err == nil {
+						archive.key = key
+						archive.cacheable = true
+					}
+				}
+			}
+
+			for e := range z.Entries {
+				select {
+				case jobChan <- zipJob{archive: a, entry: e}:
+				case <-ctx.Done():
+					// Entries never queued still hold the archive open
+					if archive := archives[a]; archive != nil {
+						for range z.Entries[e:] {
+							archive.release()
+						}
+					}
+					return
+				}
+			}
 		}
-		close(entryChan)
 	}()
 
 	// Wait for all workers to complete
@@ -252,13 +460,23 @@ func ValidateZIP(zip *ZIPFile, opts Options) (*ZIPValidationResult, error) {
 		close(resultChan)
 	}()
 
-	// Create progress tracker
-	tracker := NewProgressTracker(len(zip.Entries))
+	stopTicker := startProgressTicker(displayer, tracker)
 	completed := 0
 
 	// Collect results and update progress
 	for res := range resultChan {
-		result.Results[res.index] = res.result
+		result := results[res.job.archive]
+		result.Results[res.job.entry] = res.result
+		if IsCancelled(res.result.Error) {
+			// Interrupted entries are neither valid nor invalid
+			continue
+		}
+		// Entries that were not read in full still count as processed
+		tracker.Skip(zips[res.job.archive].Entries[res.job.entry].Size - res.result.Bytes)
+		result.TotalBytes += res.result.Bytes
+		if res.result.Cached {
+			result.CachedEntries++
+		}
 		if res.result.Valid {
 			result.ValidEntries++
 		} else {
@@ -270,57 +488,125 @@ func ValidateZIP(zip *ZIPFile, opts Options) (*ZIPValidationResult, error) {
 
 		// Update progress
 		completed++
-		tracker.Update(completed)
-		rate := tracker.GetRate()
-		displayer.UpdateProgress(completed, rate)
+		if opts.Progress != nil {
+			opts.Progress(completed, totalEntries)
+		}
+	}
+	stopTicker()
+
+	duration := tracker.GetElapsed()
+	for _, result := range results {
+		result.Duration = duration
 	}
 
-	return result, nil
+	// Entries never handed to a worker have no result yet
+	if err := ctx.Err(); err != nil {
+		for a, result := range results {
+			for e := range result.Results {
+				if result.Results[e].Entry.Name == "" {
+					result.Results[e] = ZIPResult{Entry: zips[a].Entries[e], Error: err}
+				}
+				if IsCancelled(result.Results[e].Error) {
+					result.Cancelled = true
+				}
+			}
+		}
+	}
+
+	return results, nil
 }
 
-// validateSingleZIP validates a single ZIP file and displays results
-// Returns true if validation failed (has invalid entries)
-func validateSingleZIP(zipPath string, opts Options) (bool, error) {
-	// Parse ZIP file
-	zip, err := ParseZIPFile(zipPath)
-	if err != nil {
-		// Create a result indicating the ZIP file is invalid/corrupted
-		result := &ZIPValidationResult{
-			ZIPFile: ZIPFile{
-				Path:    zipPath,
-				Entries: []ZIPEntry{},
-			},
-			Results:        []ZIPResult{},
-			TotalEntries:   0,
-			ValidEntries:   0,
-			InvalidEntries: 1, // Mark as invalid since we couldn't parse it
-			Errors:         []error{err},
+// ValidateZIPPaths parses and validates several ZIP files with a shared worker pool.
+// ZIP files that cannot be parsed are returned as invalid results carrying the parse error.
+func ValidateZIPPaths(ctx context.Context, zipPaths []string, opts Options) ([]*ZIPValidationResult, error) {
+	results := make([]*ZIPValidationResult, len(zipPaths))
+	var parsed []*ZIPFile
+	var parsedIdx []int
+
+	for i, zipPath := range zipPaths {
+		// Parse ZIP file
+		var zip *ZIPFile
+		err := allowPath(opts, zipPath)
+		if err == nil {
+			zip, err = ParseZIPFile(zipPath)
+		}
+		if err != nil {
+			// Create a result indicating the ZIP file is invalid/corrupted
+			results[i] = &ZIPValidationResult{
+				ZIPFile: ZIPFile{
+					Path:    zipPath,
+					Entries: []ZIPEntry{},
+				},
+				Results:        []ZIPResult{},
+				TotalEntries:   0,
+				ValidEntries:   0,
+				InvalidEntries: 1, // Mark as invalid since we couldn't parse it
+				Errors:         []error{err},
+			}
+			continue
+		}
+		parsed = append(parsed, zip)
+		parsedIdx = append(parsedIdx, i)
+	}
+
+	// Validate all parseable ZIP files at once
+	if len(parsed) > 0 {
+		validated, err := ValidateZIPs(ctx, parsed, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to validate ZIP: %w", err)
+		}
+		for j, result := range validated {
+			results[parsedIdx[j]] = result
 		}
-		// Display results and return validation status
-		failed := DisplayZIPResult(result, opts)
-		return failed, nil
 	}
 
-	// Validate ZIP
-	result, err := ValidateZIP(zip, opts)
+	return results, nil
+}
+
+// validateZIPBatch validates several ZIP files with a shared worker pool and displays
+// or reports the result of each one in order
+// Returns true if validation failed (has invalid entries)
+func validateZIPBatch(ctx context.Context, zipPaths []string, opts Options, rep *report.Writer) (bool, error) {
+	results, err := ValidateZIPPaths(ctx, zipPaths, opts)
 	if err != nil {
-		return false, fmt.Errorf("failed to validate ZIP: %w", err)
+		return false, err
 	}
 
-	// Display results and return validation status
-	failed := DisplayZIPResult(result, opts)
+	// Display or report results and return validation status
+	var failed bool
+	for _, result := range results {
+		resultFailed := result.Failed()
+		if rep != nil {
+			rep.Add(ConvertZIPValidationResult(result), resultFailed)
+			rep.Count("total_entries", result.TotalEntries)
+			rep.Count("valid_entries", result.ValidEntries)
+			rep.Count("invalid_entries", result.InvalidEntries)
+			rep.AddBytes(result.TotalBytes)
+		} else {
+			DisplayZIPResult(result, opts)
+		}
+		if resultFailed {
+			failed = true
+		}
+	}
 	return failed, nil
 }
 
-// ValidateZIPFolders validates ZIP files in multiple folders
-func ValidateZIPFolders(folders []string, opts Options) error {
+// ValidateZIPFolders validates ZIP files in multiple folders. Folders left when the
+// context is done are skipped and a cancellation error is returned.
+func ValidateZIPFolders(ctx context.Context, folders []string, opts Options) error {
 	var hasErrors bool
+	rep := report.NewWriterFor(string(opts.OutputFormat), "zip")
 
 	for _, folder := range folders {
+		if ctx.Err() != nil {
+			break
+		}
+
 		// Resolve absolute path
 		absPath, err := filepath.Abs(folder)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error: failed to resolve path %s: %v\n", folder, err)
+			report.Error(rep, fmt.Errorf("failed to resolve path %s: %w", folder, err))
 			hasErrors = true
 			continue
 		}
@@ -328,22 +614,23 @@ func ValidateZIPFolders(folders []string, opts Options) error {
 		// Check if directory exists
 		info, err := os.Stat(absPath)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error: %s does not exist: %v\n", folder, err)
+			report.Error(rep, fmt.Errorf("%s does not exist: %w", folder, err))
 			hasErrors = true
 			continue
 		}
 
 		if !info.IsDir() {
-			fmt.Fprintf(os.Stderr, "Error: %s is not a directory\n", folder)
+			report.Error(rep, fmt.Errorf("%s is not a directory", folder))
 			hasErrors = true
 			continue
 		}
 
+		var zipFiles []string
 		if opts.Recursive {
 			// Find all ZIP files recursively
-			zipFiles, err := FindZIPFilesRecursive(absPath)
+			zipFiles, err = FindZIPFilesRecursive(absPath)
 			if err != nil {
-				fmt.Fprintf(os.Stderr, "Error: failed to find ZIP files recursively in %s: %v\n", folder, err)
+				report.Error(rep, fmt.Errorf("failed to find ZIP files recursively in %s: %w", folder, err))
 				hasErrors = true
 				continue
 			}
@@ -352,42 +639,40 @@ func ValidateZIPFolders(folders []string, opts Options) error {
 				if !opts.Quiet {
 					fmt.Fprintf(os.Stderr, "No ZIP files found in %s\n", folder)
 				}
+				if rep != nil {
+					rep.AddError(fmt.Errorf("no ZIP files found in %s", folder))
+				}
 				hasErrors = true
 				continue
 			}
-
-			// Validate each ZIP file found
-			for _, zipPath := range zipFiles {
-				failed, err := validateSingleZIP(zipPath, opts)
-				if err != nil {
-					fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-					hasErrors = true
-				} else if failed {
-					hasErrors = true
-				}
-			}
 		} else {
 			// Find all ZIP files in current directory only
-			zipFiles, err := FindZIPFiles(absPath)
+			zipFiles, err = FindZIPFiles(absPath)
 			if err != nil {
-				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				report.Error(rep, err)
 				hasErrors = true
 				continue
 			}
+		}
 
-			// Validate each ZIP file found
-			for _, zipPath := range zipFiles {
-				failed, err := validateSingleZIP(zipPath, opts)
-				if err != nil {
-					fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-					hasErrors = true
-				} else if failed {
-					hasErrors = true
-				}
-			}
+		// Validate all ZIP files found with a shared worker pool
+		failed, err := validateZIPBatch(ctx, zipFiles, opts, rep)
+		if err != nil {
+			report.Error(rep, err)
+			hasErrors = true
+		} else if failed {
+			hasErrors = true
 		}
 	}
 
+	if err := closeReport(rep); err != nil {
+		return err
+	}
+
+	if ctx.Err() != nil {
+		return cancelledError(ctx)
+	}
+
 	if hasErrors {
 		return fmt.Errorf("one or more folders had errors")
 	}
@@ -0,0 +1,132 @@
+package checksum
+
+import (
+	"archive/zip"
+	"context"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+const benchZIPEntries = 10000
+
+// validateZIPEntryReopen is the previous per-entry implementation, kept as a baseline:
+// it reopens the archive and scans the central directory for every single entry
+func validateZIPEntryReopen(zipPath string, entryName string) ZIPResult {
+	result := ZIPResult{Entry: ZIPEntry{Name: entryName, Path: zipPath}}
+
+	r, err := zip.OpenReader(zipPath)
+	if err != nil {
+		result.Error = err
+		return result
+	}
+	defer r.Close()
+
+	for _, f := range r.File {
+		if f.Name != entryName {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			result.Error = err
+			return result
+		}
+		_, err = io.Copy(io.Discard, rc)
+		rc.Close()
+		result.Error = err
+		result.Valid = err == nil
+		return result
+	}
+
+	result.Error = fmt.Errorf("entry not found: %s", entryName)
+	return result
+}
+
+// validateZIPReopen runs validateZIPEntryReopen with the same worker layout as ValidateZIP
+func validateZIPReopen(z *ZIPFile, workers int) int {
+	entryChan := make(chan int, workers)
+	var valid sync.WaitGroup
+	var mu sync.Mutex
+	count := 0
+
+	for i := 0; i < workers; i++ {
+		valid.Add(1)
+		go func() {
+			defer valid.Done()
+			for idx := range entryChan {
+				if validateZIPEntryReopen(z.Path, z.Entries[idx].Name).Valid {
+					mu.Lock()
+					count++
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+
+	for i := range z.Entries {
+		entryChan <- i
+	}
+	close(entryChan)
+	valid.Wait()
+
+	return count
+}
+
+func setupBenchZIPs(b *testing.B, archives int, entries int) []*ZIPFile {
+	b.Helper()
+	tmpDir := b.TempDir()
+
+	zips := make([]*ZIPFile, archives)
+	for i := range zips {
+		path := filepath.Join(tmpDir, fmt.Sprintf("bench%02d.zip", i))
+		writeTestZIP(b, path, entries, 256)
+		z, err := ParseZIPFile(path)
+		if err != nil {
+			b.Fatalf("Failed to parse ZIP file: %v", err)
+		}
+		zips[i] = z
+	}
+	return zips
+}
+
+func BenchmarkValidateZIP_Reopen(b *testing.B) {
+	zips := setupBenchZIPs(b, 1, benchZIPEntries)
+	workers := calculateOptimalWorkers(benchZIPEntries, 0)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if n := validateZIPReopen(zips[0], workers); n != benchZIPEntries {
+			b.Fatalf("Expected %d valid entries, got %d", benchZIPEntries, n)
+		}
+	}
+}
+
+func BenchmarkValidateZIP_Shared(b *testing.B) {
+	zips := setupBenchZIPs(b, 1, benchZIPEntries)
+	opts := Options{Quiet: true}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		result, err := ValidateZIP(context.Background(), zips[0], opts)
+		if err != nil {
+			b.Fatal(err)
+		}
+		if result.ValidEntries != benchZIPEntries {
+			b.Fatalf("Expected %d valid entries, got %d", benchZIPEntries, result.ValidEntries)
+		}
+	}
+}
+
+func BenchmarkValidateZIPs_ManyArchives(b *testing.B) {
+	zips := setupBenchZIPs(b, 20, benchZIPEntries/20)
+	opts := Options{Quiet: true}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := ValidateZIPs(context.Background(), zips, opts); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package checksum
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeTestZIP creates a ZIP file with the given number of stored (uncompressed) entries
+func writeTestZIP(tb testing.TB, path string, entries int, entrySize int) {
+	tb.Helper()
+
+	f, err := os.Create(path)
+	if err != nil {
+		tb.Fatalf("Failed to create ZIP file: %v", err)
+	}
+	defer f.Close()
+
+	w := zip.NewWriter(f)
+	content := bytes.Repeat([]byte("sfvbrr"), entrySize/6+1)[:entrySize]
+	for i := 0; i < entries; i++ {
+		fw, err := w.CreateHeader(&zip.FileHeader{
+			Name:   fmt.Sprintf("dir/file%05d.txt", i),
+			Method: zip.Store,
+		})
+		if err != nil {
+			tb.Fatalf("Failed to create ZIP entry: %v", err)
+		}
+		if _, err := fw.Write(content); err != nil {
+			tb.Fatalf("Failed to write ZIP entry: %v", err)
+		}
+	}
+
+	if err := w.Close(); err != nil {
+		tb.Fatalf("Failed to finalize ZIP file: %v", err)
+	}
+}
+
+func TestValidateZIPs_MultipleArchives(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	goodPath := filepath.Join(tmpDir, "good.zip")
+	badPath := filepath.Join(tmpDir, "bad.zip")
+	writeTestZIP(t, goodPath, 50, 128)
+	writeTestZIP(t, badPath, 50, 128)
+
+	// Corrupt the payload of the first entry of bad.zip without touching any header
+	data, err := os.ReadFile(badPath)
+	if err != nil {
+		t.Fatalf("Failed to read ZIP file: %v", err)
+	}
+	idx := bytes.Index(data, []byte("sfvbrr"))
+	if idx < 0 {
+		t.Fatal("Failed to locate entry payload")
+	}
+	data[idx] ^= 0xff
+	if err := os.WriteFile(badPath, data, 0644); err != nil {
+		t.Fatalf("Failed to write ZIP file: %v", err)
+	}
+
+	var zips []*ZIPFile
+	for _, path := range []string{goodPath, badPath} {
+		z, err := ParseZIPFile(path)
+		if err != nil {
+			t.Fatalf("Failed to parse ZIP file %s: %v", path, err)
+		}
+		zips = append(zips, z)
+	}
+
+	results, err := ValidateZIPs(context.Background(), zips, Options{Workers: 4, Quiet: true})
+	if err != nil {
+		t.Fatalf("Failed to validate ZIP files: %v", err)
+	}
+
+	if results[0].ValidEntries != 50 || results[0].InvalidEntries != 0 {
+		t.Errorf("good.zip: expected 50 valid and 0 invalid, got %d valid and %d invalid",
+			results[0].ValidEntries, results[0].InvalidEntries)
+	}
+	if results[1].ValidEntries != 49 || results[1].InvalidEntries != 1 {
+		t.Errorf("bad.zip: expected 49 valid and 1 invalid, got %d valid and %d invalid",
+			results[1].ValidEntries, results[1].InvalidEntries)
+	}
+	if results[1].Results[0].Valid {
+		t.Error("bad.zip: expected the first entry to be invalid")
+	}
+}
+
+func TestValidateZIPs_ParsedArchivesHoldNoFile(t *testing.T) {
+	tmpDir := t.TempDir()
+	var zips []*ZIPFile
+	for i := 0; i < 3; i++ {
+		path := filepath.Join(tmpDir, fmt.Sprintf("test%d.zip", i))
+		writeTestZIP(t, path, 10, 128)
+		z, err := ParseZIPFile(path)
+		if err != nil {
+			t.Fatalf("Failed to parse ZIP file: %v", err)
+		}
+		if z.archive.source.file != nil {
+			t.Fatalf("Expected %s to be closed after parsing", path)
+		}
+		zips = append(zips, z)
+	}
+	archives := []*zipArchive{zips[0].archive, zips[1].archive, zips[2].archive}
+
+	// A ZIP file changed after parsing is not read through the stale central directory
+	writeTestZIP(t, zips[2].Path, 5, 64)
+
+	results, err := ValidateZIPs(context.Background(), zips, Options{Workers: 2, Quiet: true})
+	if err != nil {
+		t.Fatalf("Failed to validate ZIP files: %v", err)
+	}
+	for i, result := range results[:2] {
+		if result.ValidEntries != 10 || result.InvalidEntries != 0 {
+			t.Errorf("test%d.zip: expected 10 valid and 0 invalid entries, got %d valid and %d invalid: %v",
+				i, result.ValidEntries, result.InvalidEntries, result.Errors)
+		}
+	}
+	if results[2].InvalidEntries != 10 || len(results[2].Errors) == 0 || !strings.Contains(results[2].Errors[0].Error(), "changed since it was parsed") {
+		t.Errorf("test2.zip: expected 10 invalid entries of a changed file, got %d invalid: %v", results[2].InvalidEntries, results[2].Errors)
+	}
+	for i, archive := range archives {
+		if archive.source.file != nil {
+			t.Errorf("Expected test%d.zip to be closed after validation", i)
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package media
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// Audio formats recognized by ProbeAudio
+const (
+	FormatMP3  = "mp3"  // MPEG audio, with or without ID3v2 tag
+	FormatFLAC = "flac" // Native FLAC
+)
+
+// ErrMD5Mismatch is returned when the decoded FLAC audio doesn't match the STREAMINFO MD5
+var ErrMD5Mismatch = errors.New("MD5 of the decoded audio doesn't match STREAMINFO")
+
+var (
+	id3Magic  = []byte("ID3")
+	flacMagic = []byte("fLaC")
+)
+
+// AudioInfo is the result of a successful audio file check
+type AudioInfo struct {
+	Format        string        `json:"format" yaml:"format"`
+	Size          int64         `json:"size" yaml:"size"`
+	Duration      time.Duration `json:"duration" yaml:"duration"`
+	SampleRate    int           `json:"sample_rate" yaml:"sample_rate"` // Hz
+	Channels      int           `json:"channels" yaml:"channels"`
+	BitsPerSample int           `json:"bits_per_sample,omitempty" yaml:"bits_per_sample,omitempty"` // FLAC only
+	Bitrate       int           `json:"bitrate" yaml:"bitrate"`                                     // Average bitrate in kbps
+	VBR           bool          `json:"vbr,omitempty" yaml:"vbr,omitempty"`                         // MP3 with a Xing or VBRI header
+	ID3v2         string        `json:"id3v2,omitempty" yaml:"id3v2,omitempty"`                     // Version of the ID3v2 tag, e.g. "2.4" (empty = none)
+	MD5           string        `json:"md5,omitempty" yaml:"md5,omitempty"`                         // MD5 of the decoded audio from STREAMINFO (empty = unset)
+	MD5Verified   bool          `json:"md5_verified,omitempty" yaml:"md5_verified,omitempty"`       // The audio was decoded and matched the MD5
+}
+
+// String summarizes the audio file, e.g. "mp3, 3m25s, 44100 Hz, 320 kbps"
+func (i *AudioInfo) String() string {
+	s := fmt.Sprintf("%s, %s, %d Hz", i.Format, i.Duration.Round(time.Second), i.SampleRate)
+	if i.BitsPerSample > 0 {
+		s += fmt.Sprintf(", %d bit", i.BitsPerSample)
+	}
+	s += fmt.Sprintf(", %d kbps", i.Bitrate)
+	if i.VBR {
+		s += " VBR"
+	}
+	if i.MD5Verified {
+		s += ", MD5 verified"
+	}
+	return s
+}
+
+// ProbeAudio checks the headers of an MP3 or FLAC file: the ID3v2 tag and the MPEG frame
+// sync of MP3 files, the STREAMINFO and metadata blocks of FLAC files. With decode, the
+// frames of FLAC files are decoded as well, checking the CRC of every frame and comparing
+// the MD5 of the decoded audio with STREAMINFO, which reads the whole file. Decoding stops
+// with the context error once the context is done.
+func ProbeAudio(ctx context.Context, path string, decode bool) (*AudioInfo, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer f.Close()
+
+	stat, err := f.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat file: %w", err)
+	}
+	return ProbeAudioReader(ctx, f, stat.Size(), decode)
+}
+
+// ProbeAudioReader checks the audio file read from r, which holds size bytes
+func ProbeAudioReader(ctx context.Context, r io.ReaderAt, size int64, decode bool) (*AudioInfo, error) {
+	if size == 0 {
+		return nil, ErrEmpty
+	}
+
+	// FLAC files sometimes carry an ID3v2 tag as well, the stream starts after it
+	start, version, err := skipID3v2(r, size)
+	if err != nil {
+		return nil, err
+	}
+	magic := make([]byte, 4)
+	n, err := r.ReadAt(magic, start)
+	if err != nil && err != io.EOF {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	magic = magic[:n]
+
+	var info *AudioInfo
+	if bytes.Equal(magic, flacMagic) {
+		info, err = probeFLAC(ctx, r, start, size, decode)
+	} else {
+		info, err = probeMP3(r, start, size)
+	}
+	if err != nil {
+		return nil, err
+	}
+	info.Size = size
+	info.ID3v2 = version
+	return info, nil
+}
+
+// skipID3v2 returns the offset after the ID3v2 tag at the start of a file and the tag
+// version, or 0 and "" if there is none
+func skipID3v2(r io.ReaderAt, size int64) (int64, string, error) {
+	header := make([]byte, 10)
+	n, err := r.ReadAt(header, 0)
+	if err != nil && err != io.EOF {
+		return 0, "", fmt.Errorf("failed to read file: %w", err)
+	}
+	if n < 3 || !bytes.Equal(header[:3], id3Magic) {
+		return 0, "", nil
+	}
+	if n < 10 {
+		return 0, "", fmt.Errorf("%w: incomplete ID3v2 header", ErrTruncated)
+	}
+
+	major, flags := header[3], header[5]
+	if major < 2 || major > 4 || header[4] == 0xff {
+		return 0, "", fmt.Errorf("%w: unknown ID3v2 version 2.%d", ErrCorrupt, major)
+	}
+	var tagSize int64
+	for _, b := range header[6:10] {
+		if b&0x80 != 0 {
+			return 0, "", fmt.Errorf("%w: invalid ID3v2 tag size", ErrCorrupt)
+		}
+		tagSize = tagSize<<7 | int64(b)
+	}
+	end := 10 + tagSize
+	if major == 4 && flags&0x10 != 0 {
+		end += 10 // Footer
+	}
+	if end > size {
+		return 0, "", truncated("ID3v2 tag", end, size)
+	}
+	return end, fmt.Sprintf("2.%d", major), nil
+}
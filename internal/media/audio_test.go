@@ -0,0 +1,313 @@
+package media
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/binary"
+	"errors"
+	"math"
+	"testing"
+	"time"
+)
+
+// buildMP3 creates an MP3 file of MPEG-1 layer III frames at 128 kbps and 44.1 kHz,
+// with an ID3v2.4 tag and optionally a Xing header in the first frame
+func buildMP3(frames int, xing bool) []byte {
+	var buf bytes.Buffer
+	buf.Write([]byte{'I', 'D', '3', 4, 0, 0, 0, 0, 0, 20})
+	buf.Write(make([]byte, 20))
+
+	const frameLength = 417 // 144 * 128000 / 44100
+	for i := 0; i < frames; i++ {
+		frame := make([]byte, frameLength)
+		copy(frame, []byte{0xff, 0xfb, 0x90, 0x00})
+		if i == 0 && xing {
+			copy(frame[36:], "Xing")
+			binary.BigEndian.PutUint32(frame[40:], 3)
+			binary.BigEndian.PutUint32(frame[44:], uint32(frames))
+			binary.BigEndian.PutUint32(frame[48:], uint32(frames*frameLength))
+		}
+		buf.Write(frame)
+	}
+	return buf.Bytes()
+}
+
+func TestProbeAudio_MP3(t *testing.T) {
+	path := writeFile(t, "track.mp3", buildMP3(100, false))
+	info, err := ProbeAudio(context.Background(), path, false)
+	if err != nil {
+		t.Fatalf("ProbeAudio failed: %v", err)
+	}
+	if info.Format != FormatMP3 || info.SampleRate != 44100 || info.Channels != 2 || info.Bitrate != 128 || info.VBR || info.ID3v2 != "2.4" {
+		t.Errorf("Unexpected info %+v", info)
+	}
+	// 100 frames of 417 bytes at 128 kbps
+	if info.Duration.Round(time.Millisecond) != 2606*time.Millisecond {
+		t.Errorf("Expected a duration of 2.606s, got %s", info.Duration)
+	}
+
+	info, err = ProbeAudio(context.Background(), writeFile(t, "vbr.mp3", buildMP3(100, true)), false)
+	if err != nil {
+		t.Fatalf("ProbeAudio failed: %v", err)
+	}
+	// 100 frames of 1152 samples at 44.1 kHz
+	if !info.VBR || info.Duration.Round(time.Millisecond) != 2612*time.Millisecond {
+		t.Errorf("Unexpected VBR info %+v", info)
+	}
+
+	truncatedMP3 := buildMP3(100, true)[:30+417*50]
+	if _, err := ProbeAudio(context.Background(), writeFile(t, "truncated.mp3", truncatedMP3), false); !errors.Is(err, ErrTruncated) {
+		t.Errorf("Expected ErrTruncated for a truncated VBR file, got %v", err)
+	}
+	if _, err := ProbeAudio(context.Background(), writeFile(t, "noise.mp3", bytes.Repeat([]byte("noise"), 1000)), false); !errors.Is(err, ErrCorrupt) {
+		t.Errorf("Expected ErrCorrupt without frame sync, got %v", err)
+	}
+	badTag := buildMP3(10, false)
+	badTag[6] = 0x80
+	if _, err := ProbeAudio(context.Background(), writeFile(t, "badtag.mp3", badTag), false); !errors.Is(err, ErrCorrupt) {
+		t.Errorf("Expected ErrCorrupt for an invalid ID3v2 size, got %v", err)
+	}
+}
+
+// bitWriter writes the bits of FLAC test streams
+type bitWriter struct {
+	buf []byte
+	acc byte
+	n   uint
+}
+
+func (w *bitWriter) write(v uint64, n uint) {
+	for i := n; i > 0; i-- {
+		w.acc = w.acc<<1 | byte(v>>(i-1)&1)
+		if w.n++; w.n == 8 {
+			w.buf = append(w.buf, w.acc)
+			w.acc, w.n = 0, 0
+		}
+	}
+}
+
+func (w *bitWriter) signed(v int64, n uint) {
+	w.write(uint64(v)&(1<<n-1), n)
+}
+
+func (w *bitWriter) rice(v int64, k uint) {
+	u := uint64(v<<1) ^ uint64(v>>63)
+	for q := u >> k; q > 0; q-- {
+		w.write(0, 1)
+	}
+	w.write(1, 1)
+	w.write(u&(1<<k-1), k)
+}
+
+func (w *bitWriter) align() {
+	if w.n > 0 {
+		w.write(0, 8-w.n)
+	}
+}
+
+// residual writes the residual of samples after order warm-up samples in two Rice
+// partitions, the second one escaped to raw 20-bit values
+func (w *bitWriter) residual(residual []int64, order int) {
+	w.write(0, 2) // Rice coding with 4-bit parameters
+	w.write(1, 4) // Partition order 1
+	half := (len(residual)+order)/2 - order
+	w.write(4, 4)
+	for _, r := range residual[:half] {
+		w.rice(r, 4)
+	}
+	w.write(15, 4)
+	w.write(20, 5)
+	for _, r := range residual[half:] {
+		w.signed(r, 20)
+	}
+}
+
+func (w *bitWriter) verbatim(samples []int64, bps uint) {
+	w.write(0x02, 8)
+	for _, s := range samples {
+		w.signed(s, bps)
+	}
+}
+
+func (w *bitWriter) constant(value int64, bps uint) {
+	w.write(0x00, 8)
+	w.signed(value, bps)
+}
+
+func (w *bitWriter) fixed(samples []int64, order int, bps uint) {
+	w.write(uint64(8+order)<<1, 8)
+	for _, s := range samples[:order] {
+		w.signed(s, bps)
+	}
+	residual := make([]int64, 0, len(samples))
+	for i := order; i < len(samples); i++ {
+		var prediction int64
+		switch order {
+		case 1:
+			prediction = samples[i-1]
+		case 2:
+			prediction = 2*samples[i-1] - samples[i-2]
+		}
+		residual = append(residual, samples[i]-prediction)
+	}
+	w.residual(residual, order)
+}
+
+func (w *bitWriter) lpc(samples []int64, coefficients []int64, shift int64, bps uint) {
+	order := len(coefficients)
+	w.write(uint64(31+order)<<1, 8)
+	for _, s := range samples[:order] {
+		w.signed(s, bps)
+	}
+	w.write(11, 4) // 12-bit coefficients
+	w.signed(shift, 5)
+	for _, c := range coefficients {
+		w.signed(c, 12)
+	}
+	residual := make([]int64, 0, len(samples))
+	for i := order; i < len(samples); i++ {
+		var prediction int64
+		for j, c := range coefficients {
+			prediction += c * samples[i-1-j]
+		}
+		residual = append(residual, samples[i]-prediction>>shift)
+	}
+	w.residual(residual, order)
+}
+
+// flacFrame encodes a frame of blockSize samples with the channel assignment code
+func flacFrame(number int, blockSize int, channelCode uint64, subframes func(w *bitWriter)) []byte {
+	w := &bitWriter{}
+	w.write(0x3ffe, 14)
+	w.write(0, 2)
+	w.write(7, 4) // 16-bit block size follows
+	w.write(0, 4) // Sample rate from STREAMINFO
+	w.write(channelCode, 4)
+	w.write(0, 4) // Sample size from STREAMINFO
+	w.write(uint64(number), 8)
+	w.write(uint64(blockSize-1), 16)
+	var crc8 byte
+	for _, b := range w.buf {
+		crc8 = crc8Table[crc8^b]
+	}
+	w.write(uint64(crc8), 8)
+
+	subframes(w)
+	w.align()
+	var crc16 uint16
+	for _, b := range w.buf {
+		crc16 = crc16<<8 ^ crc16Table[byte(crc16>>8)^b]
+	}
+	w.write(uint64(crc16), 16)
+	return w.buf
+}
+
+// buildFLAC creates a 16-bit stereo FLAC file whose frames use every subframe type
+// and stereo decorrelation
+func buildFLAC() []byte {
+	const blockSize, frames = 64, 4
+	left := make([]int64, blockSize*frames)
+	right := make([]int64, blockSize*frames)
+	for i := range left[:blockSize*3] {
+		left[i] = int64(8000 * math.Sin(float64(i)/5))
+		right[i] = int64(6000*math.Sin(float64(i)/7)) - 3
+	}
+
+	sum := md5.New()
+	for i := range left {
+		binary.Write(sum, binary.LittleEndian, [2]int16{int16(left[i]), int16(right[i])})
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("fLaC")
+	info := make([]byte, 4+flacStreamInfoSize)
+	info[0] = 0x80 // Last block, STREAMINFO
+	info[3] = flacStreamInfoSize
+	binary.BigEndian.PutUint16(info[4:], blockSize)
+	binary.BigEndian.PutUint16(info[6:], blockSize)
+	binary.BigEndian.PutUint64(info[14:], 44100<<44|1<<41|15<<36|uint64(len(left)))
+	copy(info[22:], sum.Sum(nil))
+	buf.Write(info)
+
+	side := make([]int64, len(left))
+	mid := make([]int64, len(left))
+	for i := range left {
+		side[i] = left[i] - right[i]
+		mid[i] = (left[i] + right[i]) >> 1
+	}
+	block := func(s []int64, n int) []int64 { return s[n*blockSize : (n+1)*blockSize] }
+
+	buf.Write(flacFrame(0, blockSize, 1, func(w *bitWriter) {
+		w.verbatim(block(left, 0), 16)
+		w.fixed(block(right, 0), 2, 16)
+	}))
+	buf.Write(flacFrame(1, blockSize, 8, func(w *bitWriter) {
+		w.fixed(block(left, 1), 1, 16)
+		w.verbatim(block(side, 1), 17)
+	}))
+	buf.Write(flacFrame(2, blockSize, 10, func(w *bitWriter) {
+		w.lpc(block(mid, 2), []int64{1800, -900}, 10, 16)
+		w.fixed(block(side, 2), 0, 17)
+	}))
+	buf.Write(flacFrame(3, blockSize, 1, func(w *bitWriter) {
+		w.constant(0, 16)
+		w.constant(0, 16)
+	}))
+	return buf.Bytes()
+}
+
+func TestProbeAudio_FLAC(t *testing.T) {
+	data := buildFLAC()
+	path := writeFile(t, "track.flac", data)
+
+	info, err := ProbeAudio(context.Background(), path, false)
+	if err != nil {
+		t.Fatalf("ProbeAudio failed: %v", err)
+	}
+	if info.Format != FormatFLAC || info.SampleRate != 44100 || info.Channels != 2 || info.BitsPerSample != 16 || info.MD5 == "" || info.MD5Verified {
+		t.Errorf("Unexpected info %+v", info)
+	}
+	if info.Duration != 256*time.Second/44100 {
+		t.Errorf("Expected a duration of 256 samples, got %s", info.Duration)
+	}
+
+	info, err = ProbeAudio(context.Background(), path, true)
+	if err != nil {
+		t.Fatalf("ProbeAudio with decoding failed: %v", err)
+	}
+	if !info.MD5Verified {
+		t.Errorf("Expected the MD5 to be verified, got %+v", info)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := ProbeAudio(ctx, path, true); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected decoding to stop with a cancelled context, got %v", err)
+	}
+
+	// A flipped bit in the audio fails the frame CRC
+	corrupt := bytes.Clone(data)
+	corrupt[len(corrupt)-40] ^= 0x10
+	if _, err := ProbeAudio(context.Background(), writeFile(t, "corrupt.flac", corrupt), true); !errors.Is(err, ErrCorrupt) {
+		t.Errorf("Expected ErrCorrupt for a corrupt frame, got %v", err)
+	}
+	if _, err := ProbeAudio(context.Background(), writeFile(t, "truncated.flac", data[:len(data)-5]), true); !errors.Is(err, ErrTruncated) {
+		t.Errorf("Expected ErrTruncated for a truncated file, got %v", err)
+	}
+
+	wrongMD5 := bytes.Clone(data)
+	wrongMD5[4+4+18] ^= 0xff
+	if _, err := ProbeAudio(context.Background(), writeFile(t, "md5.flac", wrongMD5), true); !errors.Is(err, ErrMD5Mismatch) {
+		t.Errorf("Expected ErrMD5Mismatch, got %v", err)
+	}
+
+	noStreamInfo := bytes.Clone(data)
+	noStreamInfo[4] = 0x81 // Last block, PADDING
+	if _, err := ProbeAudio(context.Background(), writeFile(t, "nostreaminfo.flac", noStreamInfo), false); !errors.Is(err, ErrCorrupt) {
+		t.Errorf("Expected ErrCorrupt without STREAMINFO, got %v", err)
+	}
+	if _, err := ProbeAudio(context.Background(), writeFile(t, "header.flac", data[:20]), false); !errors.Is(err, ErrTruncated) {
+		t.Errorf("Expected ErrTruncated for a cut STREAMINFO, got %v", err)
+	}
+}
@@ -0,0 +1,588 @@
+package media
+
+import (
+	"bufio"
+	"context"
+	"crypto/md5"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"math/bits"
+	"time"
+)
+
+// FLAC metadata block types
+const (
+	flacStreamInfo   = 0
+	flacInvalidBlock = 127
+)
+
+// flacStreamInfoSize is the size of the STREAMINFO block
+const flacStreamInfoSize = 34
+
+// flacStream holds the STREAMINFO fields needed to decode the frames
+type flacStream struct {
+	sampleRate    int
+	channels      int
+	bitsPerSample int
+	totalSamples  int64
+	maxBlockSize  int
+	md5           [16]byte
+}
+
+// probeFLAC checks the metadata blocks of a FLAC stream starting at start: STREAMINFO must
+// come first and be valid, every block must end within the file, and the first frame
+// must follow the last block. With decode, all frames are decoded and checked.
+func probeFLAC(ctx context.Context, r io.ReaderAt, start, size int64, decode bool) (*AudioInfo, error) {
+	off := start + int64(len(flacMagic))
+	var stream *flacStream
+	for last := false; !last; {
+		header, err := readAt(r, off, 4)
+		if err != nil {
+			return nil, err
+		}
+		last = header[0]&0x80 != 0
+		blockType := int(header[0] & 0x7f)
+		length := int64(header[1])<<16 | int64(header[2])<<8 | int64(header[3])
+		if end := off + 4 + length; end > size {
+			return nil, truncated("FLAC metadata block", end, size)
+		}
+
+		switch {
+		case stream == nil && blockType != flacStreamInfo:
+			return nil, fmt.Errorf("%w: FLAC stream doesn't start with STREAMINFO", ErrCorrupt)
+		case blockType == flacStreamInfo && stream != nil:
+			return nil, fmt.Errorf("%w: more than one STREAMINFO block", ErrCorrupt)
+		case blockType == flacInvalidBlock:
+			return nil, fmt.Errorf("%w: invalid FLAC metadata block type", ErrCorrupt)
+		case blockType == flacStreamInfo:
+			if length != flacStreamInfoSize {
+				return nil, fmt.Errorf("%w: STREAMINFO has %d bytes, expected %d", ErrCorrupt, length, flacStreamInfoSize)
+			}
+			data, err := readAt(r, off+4, length)
+			if err != nil {
+				return nil, err
+			}
+			if stream, err = parseStreamInfo(data); err != nil {
+				return nil, err
+			}
+		}
+		off += 4 + length
+	}
+
+	sync, err := readAt(r, off, 2)
+	if err != nil {
+		return nil, fmt.Errorf("%w: no audio frames after the FLAC metadata", ErrTruncated)
+	}
+	if binary.BigEndian.Uint16(sync)>>2 != 0x3ffe {
+		return nil, fmt.Errorf("%w: no FLAC frame sync after the metadata at byte %d", ErrCorrupt, off)
+	}
+
+	info := &AudioInfo{
+		Format:        FormatFLAC,
+		SampleRate:    stream.sampleRate,
+		Channels:      stream.channels,
+		BitsPerSample: stream.bitsPerSample,
+	}
+	if stream.md5 != [16]byte{} {
+		info.MD5 = hex.EncodeToString(stream.md5[:])
+	}
+	if stream.totalSamples > 0 {
+		info.Duration = time.Duration(stream.totalSamples * int64(time.Second) / int64(stream.sampleRate))
+		info.Bitrate = int(float64(size-off) * 8 / info.Duration.Seconds() / 1000)
+	}
+
+	if decode {
+		sum, err := decodeFLAC(ctx, io.NewSectionReader(r, off, size-off), stream)
+		if err != nil {
+			return nil, err
+		}
+		if info.MD5 != "" {
+			if sum != info.MD5 {
+				return nil, fmt.Errorf("%w: %s, decoded %s", ErrMD5Mismatch, info.MD5, sum)
+			}
+			info.MD5Verified = true
+		}
+	}
+	return info, nil
+}
+
+// parseStreamInfo parses and checks the STREAMINFO block
+func parseStreamInfo(data []byte) (*flacStream, error) {
+	minBlock, maxBlock := int(binary.BigEndian.Uint16(data[0:2])), int(binary.BigEndian.Uint16(data[2:4]))
+	packed := binary.BigEndian.Uint64(data[10:18])
+	stream := &flacStream{
+		sampleRate:    int(packed >> 44),
+		channels:      int(packed>>41&0x7) + 1,
+		bitsPerSample: int(packed>>36&0x1f) + 1,
+		totalSamples:  int64(packed & 0xfffffffff),
+		maxBlockSize:  maxBlock,
+	}
+	copy(stream.md5[:], data[18:34])
+
+	switch {
+	case minBlock < 16 || maxBlock < minBlock:
+		return nil, fmt.Errorf("%w: invalid STREAMINFO block sizes %d-%d", ErrCorrupt, minBlock, maxBlock)
+	case stream.sampleRate == 0:
+		return nil, fmt.Errorf("%w: STREAMINFO has no sample rate", ErrCorrupt)
+	case stream.bitsPerSample < 4:
+		return nil, fmt.Errorf("%w: STREAMINFO has %d bits per sample", ErrCorrupt, stream.bitsPerSample)
+	}
+	return stream, nil
+}
+
+// flacReader reads the bits of FLAC frames, updating the CRCs of the frame header and
+// of the whole frame with every byte. Bytes are only read when their bits are needed,
+// so the CRCs end exactly at the byte boundary after the last bits read.
+type flacReader struct {
+	r     *bufio.Reader
+	acc   uint64 // Bits not read yet, in the lowest n bits
+	n     uint
+	crc8  byte
+	crc16 uint16
+	bytes int64 // Number of bytes read
+}
+
+// readByte reads the next byte into the bit accumulator
+func (fr *flacReader) readByte() error {
+	b, err := fr.r.ReadByte()
+	if err != nil {
+		if err == io.EOF {
+			return io.ErrUnexpectedEOF
+		}
+		return err
+	}
+	fr.acc = fr.acc<<8 | uint64(b)
+	fr.n += 8
+	fr.bytes++
+	fr.crc8 = crc8Table[fr.crc8^b]
+	fr.crc16 = fr.crc16<<8 ^ crc16Table[byte(fr.crc16>>8)^b]
+	return nil
+}
+
+// bits reads an unsigned value of n bits, n <= 56
+func (fr *flacReader) bits(n uint) (uint64, error) {
+	for fr.n < n {
+		if err := fr.readByte(); err != nil {
+			return 0, err
+		}
+	}
+	fr.n -= n
+	v := fr.acc >> fr.n
+	fr.acc &= 1<<fr.n - 1
+	return v, nil
+}
+
+// signed reads a two's complement value of n bits
+func (fr *flacReader) signed(n uint) (int64, error) {
+	v, err := fr.bits(n)
+	if err != nil || n == 0 {
+		return 0, err
+	}
+	return int64(v<<(64-n)) >> (64 - n), nil
+}
+
+// unary counts the zero bits before the next one bit
+func (fr *flacReader) unary() (uint64, error) {
+	var count uint64
+	for {
+		if fr.n == 0 {
+			if err := fr.readByte(); err != nil {
+				return 0, err
+			}
+		}
+		if fr.acc == 0 {
+			count += uint64(fr.n)
+			fr.n = 0
+			continue
+		}
+		zeros := fr.n - uint(bits.Len64(fr.acc))
+		count += uint64(zeros)
+		fr.n -= zeros + 1
+		fr.acc &= 1<<fr.n - 1
+		return count, nil
+	}
+}
+
+// align skips the bits up to the next byte boundary
+func (fr *flacReader) align() {
+	fr.n = 0
+	fr.acc = 0
+}
+
+// decodeFLAC decodes the frames of a FLAC stream, checking the CRC of every frame header
+// and frame, and returns the MD5 of the decoded samples. The context is checked before
+// every frame, so a cancelled check stops within one frame.
+func decodeFLAC(ctx context.Context, r io.Reader, stream *flacStream) (string, error) {
+	fr := &flacReader{r: bufio.NewReaderSize(r, 256*1024)}
+	sum := md5.New()
+	var decoded int64
+	for {
+		if err := ctx.Err(); err != nil {
+			return "", err
+		}
+		if _, err := fr.r.Peek(1); err == io.EOF {
+			break
+		}
+		// Some taggers append an ID3v1 tag after the last frame
+		if tag, err := fr.r.Peek(3); err == nil && string(tag) == "TAG" {
+			if rest, _ := fr.r.Peek(129); len(rest) == 128 {
+				break
+			}
+		}
+
+		frameStart := fr.bytes
+		samples, err := decodeFrame(fr, stream, sum)
+		if err != nil {
+			if errors.Is(err, io.ErrUnexpectedEOF) {
+				return "", fmt.Errorf("%w: FLAC frame at byte %d is incomplete", ErrTruncated, frameStart)
+			}
+			return "", fmt.Errorf("FLAC frame at byte %d: %w", frameStart, err)
+		}
+		decoded += int64(samples)
+	}
+
+	if stream.totalSamples > 0 && decoded != stream.totalSamples {
+		return "", fmt.Errorf("%w: decoded %d samples, STREAMINFO says %d", ErrTruncated, decoded, stream.totalSamples)
+	}
+	return hex.EncodeToString(sum.Sum(nil)), nil
+}
+
+// decodeFrame decodes a single frame, adds its samples to the MD5 and returns the number
+// of samples per channel
+func decodeFrame(fr *flacReader, stream *flacStream, sum hash.Hash) (int, error) {
+	fr.crc8, fr.crc16 = 0, 0
+
+	header, err := fr.bits(32)
+	if err != nil {
+		return 0, err
+	}
+	if header>>18 != 0x3ffe {
+		return 0, fmt.Errorf("%w: lost FLAC frame sync", ErrCorrupt)
+	}
+	blockCode, rateCode := header>>12&0xf, header>>8&0xf
+	channelCode, sizeCode := header>>4&0xf, header>>1&0x7
+	if blockCode == 0 || rateCode == 15 || channelCode > 10 || sizeCode == 3 || header&1 != 0 {
+		return 0, fmt.Errorf("%w: invalid FLAC frame header", ErrCorrupt)
+	}
+
+	// Frame or sample number, UTF-8 coded
+	first, err := fr.bits(8)
+	if err != nil {
+		return 0, err
+	}
+	for extra := bits.LeadingZeros8(^uint8(first)) - 1; extra > 0; extra-- {
+		if _, err := fr.bits(8); err != nil {
+			return 0, err
+		}
+	}
+
+	var blockSize int
+	switch {
+	case blockCode == 1:
+		blockSize = 192
+	case blockCode <= 5:
+		blockSize = 576 << (blockCode - 2)
+	case blockCode == 6, blockCode == 7:
+		v, err := fr.bits(uint(8 * (blockCode - 5)))
+		if err != nil {
+			return 0, err
+		}
+		blockSize = int(v) + 1
+	default:
+		blockSize = 256 << (blockCode - 8)
+	}
+	switch rateCode {
+	case 12:
+		_, err = fr.bits(8)
+	case 13, 14:
+		_, err = fr.bits(16)
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	bps := stream.bitsPerSample
+	if sizeCode != 0 {
+		bps = [8]int{0, 8, 12, 0, 16, 20, 24, 32}[sizeCode]
+	}
+	channels := int(channelCode) + 1
+	if channelCode >= 8 {
+		channels = 2
+	}
+
+	crc8 := fr.crc8
+	if v, err := fr.bits(8); err != nil {
+		return 0, err
+	} else if byte(v) != crc8 {
+		return 0, fmt.Errorf("%w: FLAC frame header CRC mismatch", ErrCorrupt)
+	}
+
+	samples := make([][]int64, channels)
+	for ch := range samples {
+		channelBPS := bps
+		if channelCode == 8 && ch == 1 || channelCode == 9 && ch == 0 || channelCode == 10 && ch == 1 {
+			channelBPS++ // Side channel
+		}
+		if samples[ch], err = decodeSubframe(fr, blockSize, uint(channelBPS)); err != nil {
+			return 0, err
+		}
+	}
+
+	fr.align()
+	crc16 := fr.crc16
+	if v, err := fr.bits(16); err != nil {
+		return 0, err
+	} else if uint16(v) != crc16 {
+		return 0, fmt.Errorf("%w: FLAC frame CRC mismatch", ErrCorrupt)
+	}
+
+	// Undo the stereo decorrelation
+	switch channelCode {
+	case 8: // Left and side
+		for i, side := range samples[1] {
+			samples[1][i] = samples[0][i] - side
+		}
+	case 9: // Side and right
+		for i, side := range samples[0] {
+			samples[0][i] = side + samples[1][i]
+		}
+	case 10: // Mid and side
+		for i, side := range samples[1] {
+			mid := samples[0][i]<<1 | side&1
+			samples[0][i], samples[1][i] = (mid+side)>>1, (mid-side)>>1
+		}
+	}
+
+	// The MD5 covers the interleaved samples as little-endian integers
+	width := (bps + 7) / 8
+	buf := make([]byte, 0, blockSize*channels*width)
+	var le [8]byte
+	for i := 0; i < blockSize; i++ {
+		for ch := range samples {
+			binary.LittleEndian.PutUint64(le[:], uint64(samples[ch][i]))
+			buf = append(buf, le[:width]...)
+		}
+	}
+	sum.Write(buf)
+	return blockSize, nil
+}
+
+// decodeSubframe decodes the samples of one channel
+func decodeSubframe(fr *flacReader, blockSize int, bps uint) ([]int64, error) {
+	header, err := fr.bits(8)
+	if err != nil {
+		return nil, err
+	}
+	if header&0x80 != 0 {
+		return nil, fmt.Errorf("%w: invalid FLAC subframe header", ErrCorrupt)
+	}
+	var wasted uint
+	if header&1 != 0 {
+		k, err := fr.unary()
+		if err != nil {
+			return nil, err
+		}
+		wasted = uint(k) + 1
+		if wasted >= bps {
+			return nil, fmt.Errorf("%w: invalid FLAC wasted bits", ErrCorrupt)
+		}
+		bps -= wasted
+	}
+
+	samples := make([]int64, blockSize)
+	kind := int(header >> 1 & 0x3f)
+	switch {
+	case kind == 0: // Constant
+		v, err := fr.signed(bps)
+		if err != nil {
+			return nil, err
+		}
+		for i := range samples {
+			samples[i] = v
+		}
+	case kind == 1: // Verbatim
+		for i := range samples {
+			if samples[i], err = fr.signed(bps); err != nil {
+				return nil, err
+			}
+		}
+	case kind >= 8 && kind <= 12: // Fixed predictor
+		order := kind - 8
+		if err := decodeWarmup(fr, samples, order, bps); err != nil {
+			return nil, err
+		}
+		if err := decodeResidual(fr, samples, order); err != nil {
+			return nil, err
+		}
+		restoreFixed(samples, order)
+	case kind >= 32: // Linear predictor
+		order := kind - 31
+		if err := decodeWarmup(fr, samples, order, bps); err != nil {
+			return nil, err
+		}
+		precision, err := fr.bits(4)
+		if err != nil {
+			return nil, err
+		}
+		if precision == 15 {
+			return nil, fmt.Errorf("%w: invalid FLAC LPC precision", ErrCorrupt)
+		}
+		shift, err := fr.signed(5)
+		if err != nil {
+			return nil, err
+		}
+		if shift < 0 {
+			return nil, fmt.Errorf("%w: negative FLAC LPC shift", ErrCorrupt)
+		}
+		coefficients := make([]int64, order)
+		for i := range coefficients {
+			if coefficients[i], err = fr.signed(uint(precision) + 1); err != nil {
+				return nil, err
+			}
+		}
+		if err := decodeResidual(fr, samples, order); err != nil {
+			return nil, err
+		}
+		for i := order; i < len(samples); i++ {
+			var prediction int64
+			for j, c := range coefficients {
+				prediction += c * samples[i-1-j]
+			}
+			samples[i] += prediction >> shift
+		}
+	default:
+		return nil, fmt.Errorf("%w: reserved FLAC subframe type %d", ErrCorrupt, kind)
+	}
+
+	if wasted > 0 {
+		for i := range samples {
+			samples[i] <<= wasted
+		}
+	}
+	return samples, nil
+}
+
+// decodeWarmup reads the unencoded first samples of a predicted subframe
+func decodeWarmup(fr *flacReader, samples []int64, order int, bps uint) error {
+	if order > len(samples) {
+		return fmt.Errorf("%w: FLAC predictor order %d exceeds the block size", ErrCorrupt, order)
+	}
+	for i := 0; i < order; i++ {
+		v, err := fr.signed(bps)
+		if err != nil {
+			return err
+		}
+		samples[i] = v
+	}
+	return nil
+}
+
+// decodeResidual reads the Rice coded residual of a predicted subframe into the samples
+// after the warm-up samples
+func decodeResidual(fr *flacReader, samples []int64, order int) error {
+	method, err := fr.bits(2)
+	if err != nil {
+		return err
+	}
+	if method > 1 {
+		return fmt.Errorf("%w: reserved FLAC residual coding method", ErrCorrupt)
+	}
+	paramBits, escape := uint(4), uint64(15)
+	if method == 1 {
+		paramBits, escape = 5, 31
+	}
+	partitionOrder, err := fr.bits(4)
+	if err != nil {
+		return err
+	}
+	partitions := 1 << partitionOrder
+	partitionSize := len(samples) >> partitionOrder
+	if partitionSize<<partitionOrder != len(samples) || partitionSize < order {
+		return fmt.Errorf("%w: invalid FLAC residual partition order", ErrCorrupt)
+	}
+
+	i := order
+	for p := 0; p < partitions; p++ {
+		n := partitionSize
+		if p == 0 {
+			n -= order
+		}
+		param, err := fr.bits(paramBits)
+		if err != nil {
+			return err
+		}
+		if param == escape {
+			raw, err := fr.bits(5)
+			if err != nil {
+				return err
+			}
+			for ; n > 0; n-- {
+				if samples[i], err = fr.signed(uint(raw)); err != nil {
+					return err
+				}
+				i++
+			}
+			continue
+		}
+		for ; n > 0; n-- {
+			q, err := fr.unary()
+			if err != nil {
+				return err
+			}
+			low, err := fr.bits(uint(param))
+			if err != nil {
+				return err
+			}
+			u := q<<param | low
+			samples[i] = int64(u>>1) ^ -int64(u&1)
+			i++
+		}
+	}
+	return nil
+}
+
+// restoreFixed adds the predictions of a fixed predictor to the residual
+func restoreFixed(s []int64, order int) {
+	for i := order; i < len(s); i++ {
+		switch order {
+		case 1:
+			s[i] += s[i-1]
+		case 2:
+			s[i] += 2*s[i-1] - s[i-2]
+		case 3:
+			s[i] += 3*s[i-1] - 3*s[i-2] + s[i-3]
+		case 4:
+			s[i] += 4*s[i-1] - 6*s[i-2] + 4*s[i-3] - s[i-4]
+		}
+	}
+}
+
+// crc8Table and crc16Table are the tables of the CRC-8 (polynomial 0x07) of FLAC frame
+// headers and the CRC-16 (polynomial 0x8005) of FLAC frames
+var (
+	crc8Table  [256]byte
+	crc16Table [256]uint16
+)
+
+func init() {
+	for i := range 256 {
+		c8, c16 := byte(i), uint16(i)<<8
+		for range 8 {
+			if c8&0x80 != 0 {
+				c8 = c8<<1 ^ 0x07
+			} else {
+				c8 <<= 1
+			}
+			if c16&0x8000 != 0 {
+				c16 = c16<<1 ^ 0x8005
+			} else {
+				c16 <<= 1
+			}
+		}
+		crc8Table[i], crc16Table[i] = c8, c16
+	}
+}
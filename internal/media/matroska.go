@@ -0,0 +1,363 @@
+package media
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"time"
+)
+
+// ebmlMagic is the ID of the EBML header that starts every Matroska file
+var ebmlMagic = []byte{0x1a, 0x45, 0xdf, 0xa3}
+
+// Matroska element IDs, with their marker bits
+const (
+	mkvEBML          = 0x1a45dfa3
+	mkvDocType       = 0x4282
+	mkvSegment       = 0x18538067
+	mkvSeekHead      = 0x114d9b74
+	mkvInfo          = 0x1549a966
+	mkvTimecodeScale = 0x2ad7b1
+	mkvDuration      = 0x4489
+	mkvTracks        = 0x1654ae6b
+	mkvTrackEntry    = 0xae
+	mkvTrackNumber   = 0xd7
+	mkvTrackType     = 0x83
+	mkvCodecID       = 0x86
+	mkvCluster       = 0x1f43b675
+	mkvCues          = 0x1c53bb6b
+	mkvChapters      = 0x1043a770
+	mkvTags          = 0x1254c367
+	mkvAttachments   = 0x1941a469
+	mkvVoid          = 0xec
+	mkvCRC32         = 0xbf
+)
+
+// mkvTopLevel are the elements allowed directly inside the Segment
+var mkvTopLevel = map[uint64]bool{
+	mkvSeekHead: true, mkvInfo: true, mkvTracks: true, mkvCluster: true, mkvCues: true,
+	mkvChapters: true, mkvTags: true, mkvAttachments: true, mkvVoid: true, mkvCRC32: true,
+}
+
+// defaultTimecodeScale is the Matroska default of one millisecond per tick
+const defaultTimecodeScale = 1000000
+
+// ebmlElement is the header of an EBML element
+type ebmlElement struct {
+	id         uint64
+	offset     int64 // Offset of the element header
+	dataOffset int64 // Offset of the element data
+	size       int64 // Size of the data, -1 if unknown
+}
+
+// end returns the offset right after the element data
+func (e ebmlElement) end() int64 {
+	return e.dataOffset + e.size
+}
+
+// readVint decodes an EBML variable size integer from buf. IDs keep their marker bit,
+// sizes don't. It returns the value, its length and whether all value bits are set
+// (the "unknown size" marker).
+func readVint(buf []byte, keepMarker bool) (uint64, int, bool, bool) {
+	if len(buf) == 0 || buf[0] == 0 {
+		return 0, 0, false, false
+	}
+	length := 1
+	for mask := byte(0x80); buf[0]&mask == 0; mask >>= 1 {
+		length++
+	}
+	if len(buf) < length {
+		return 0, 0, false, false
+	}
+
+	value := uint64(buf[0])
+	if !keepMarker {
+		value &= uint64(0xff >> length)
+	}
+	for _, b := range buf[1:length] {
+		value = value<<8 | uint64(b)
+	}
+
+	allOnes := value == (uint64(1)<<(7*length))-1
+	return value, length, allOnes && !keepMarker, true
+}
+
+// readElement reads the element header at off
+func readElement(r io.ReaderAt, off, size int64) (ebmlElement, error) {
+	buf := make([]byte, 16)
+	n, err := r.ReadAt(buf, off)
+	if err != nil && err != io.EOF {
+		return ebmlElement{}, fmt.Errorf("failed to read file: %w", err)
+	}
+	buf = buf[:n]
+
+	id, idLen, _, ok := readVint(buf, true)
+	if !ok || idLen > 4 {
+		if int64(n) < 16 && off+int64(n) >= size {
+			return ebmlElement{}, fmt.Errorf("%w: incomplete element header at byte %d", ErrTruncated, off)
+		}
+		return ebmlElement{}, fmt.Errorf("%w: invalid element ID at byte %d", ErrCorrupt, off)
+	}
+	dataSize, sizeLen, unknown, ok := readVint(buf[idLen:], false)
+	if !ok {
+		if off+int64(n) >= size {
+			return ebmlElement{}, fmt.Errorf("%w: incomplete element header at byte %d", ErrTruncated, off)
+		}
+		return ebmlElement{}, fmt.Errorf("%w: invalid element size at byte %d", ErrCorrupt, off)
+	}
+
+	element := ebmlElement{
+		id:         id,
+		offset:     off,
+		dataOffset: off + int64(idLen+sizeLen),
+		size:       int64(dataSize),
+	}
+	if unknown {
+		element.size = -1
+	} else if dataSize > math.MaxInt64/2 {
+		return ebmlElement{}, fmt.Errorf("%w: element size overflow at byte %d", ErrCorrupt, off)
+	}
+	return element, nil
+}
+
+// children parses the child elements held in data
+func children(data []byte) ([]ebmlElement, error) {
+	var elements []ebmlElement
+	for off := 0; off < len(data); {
+		id, idLen, _, ok := readVint(data[off:], true)
+		if !ok {
+			return nil, fmt.Errorf("%w: invalid element ID", ErrCorrupt)
+		}
+		size, sizeLen, unknown, ok := readVint(data[off+idLen:], false)
+		if !ok || unknown || int64(size) > int64(len(data)-off-idLen-sizeLen) {
+			return nil, fmt.Errorf("%w: invalid element size", ErrCorrupt)
+		}
+		dataOffset := off + idLen + sizeLen
+		elements = append(elements, ebmlElement{id: id, dataOffset: int64(dataOffset), size: int64(size)})
+		off = dataOffset + int(size)
+	}
+	return elements, nil
+}
+
+// elementData returns the data of a child element parsed by children
+func elementData(data []byte, e ebmlElement) []byte {
+	return data[e.dataOffset:e.end()]
+}
+
+// readUint decodes an EBML unsigned integer
+func readUint(data []byte) uint64 {
+	var v uint64
+	for _, b := range data {
+		v = v<<8 | uint64(b)
+	}
+	return v
+}
+
+// readFloat decodes an EBML float of 4 or 8 bytes
+func readFloat(data []byte) (float64, bool) {
+	switch len(data) {
+	case 4:
+		return float64(math.Float32frombits(binary.BigEndian.Uint32(data))), true
+	case 8:
+		return math.Float64frombits(binary.BigEndian.Uint64(data)), true
+	}
+	return 0, false
+}
+
+// probeMatroska checks the EBML header, the Segment and its top-level elements
+func probeMatroska(r io.ReaderAt, size int64) (*Info, error) {
+	header, err := readElement(r, 0, size)
+	if err != nil {
+		return nil, err
+	}
+	if header.id != mkvEBML {
+		return nil, fmt.Errorf("%w: expected EBML header", ErrUnknownFormat)
+	}
+	if header.size < 0 || header.end() > size {
+		return nil, truncated("EBML header", header.end(), size)
+	}
+	headerData, err := readAt(r, header.dataOffset, header.size)
+	if err != nil {
+		return nil, err
+	}
+	headerChildren, err := children(headerData)
+	if err != nil {
+		return nil, fmt.Errorf("EBML header: %w", err)
+	}
+
+	info := &Info{}
+	for _, child := range headerChildren {
+		if child.id == mkvDocType {
+			info.Format = string(elementData(headerData, child))
+		}
+	}
+	switch info.Format {
+	case FormatMatroska, FormatWebM:
+	default:
+		return nil, fmt.Errorf("%w: EBML document type %q", ErrUnknownFormat, info.Format)
+	}
+
+	segment, err := readElement(r, header.end(), size)
+	if err != nil {
+		return nil, err
+	}
+	if segment.id != mkvSegment {
+		return nil, fmt.Errorf("%w: expected Segment at byte %d", ErrCorrupt, segment.offset)
+	}
+	segmentEnd := size
+	if segment.size >= 0 {
+		if segment.end() > size {
+			return nil, truncated("Segment", segment.end(), size)
+		}
+		segmentEnd = segment.end()
+	}
+
+	var seenInfo, seenTracks, seenCluster bool
+	timecodeScale := uint64(defaultTimecodeScale)
+	var duration float64
+
+	for off := segment.dataOffset; off < segmentEnd; {
+		element, err := readElement(r, off, size)
+		if err != nil {
+			return nil, err
+		}
+		if !mkvTopLevel[element.id] {
+			return nil, fmt.Errorf("%w: unexpected element 0x%X at byte %d", ErrCorrupt, element.id, off)
+		}
+
+		if element.size < 0 {
+			// Clusters of live recordings have an unknown size and end at the next top-level element
+			if element.id != mkvCluster {
+				return nil, fmt.Errorf("%w: element 0x%X at byte %d has an unknown size", ErrCorrupt, element.id, off)
+			}
+			seenCluster = true
+			next, err := skipUnknownSizeCluster(r, element.dataOffset, segmentEnd, size)
+			if err != nil {
+				return nil, err
+			}
+			off = next
+			continue
+		}
+		if element.end() > segmentEnd {
+			return nil, truncated(fmt.Sprintf("element 0x%X", element.id), element.end(), size)
+		}
+
+		switch element.id {
+		case mkvInfo:
+			seenInfo = true
+			data, err := readAt(r, element.dataOffset, element.size)
+			if err != nil {
+				return nil, err
+			}
+			elements, err := children(data)
+			if err != nil {
+				return nil, fmt.Errorf("Info: %w", err)
+			}
+			for _, child := range elements {
+				switch child.id {
+				case mkvTimecodeScale:
+					if scale := readUint(elementData(data, child)); scale > 0 {
+						timecodeScale = scale
+					}
+				case mkvDuration:
+					if d, ok := readFloat(elementData(data, child)); ok {
+						duration = d
+					}
+				}
+			}
+		case mkvTracks:
+			seenTracks = true
+			data, err := readAt(r, element.dataOffset, element.size)
+			if err != nil {
+				return nil, err
+			}
+			tracks, err := parseMatroskaTracks(data)
+			if err != nil {
+				return nil, fmt.Errorf("Tracks: %w", err)
+			}
+			info.Tracks = tracks
+		case mkvCluster:
+			seenCluster = true
+		}
+		off = element.end()
+	}
+
+	switch {
+	case !seenInfo:
+		return nil, fmt.Errorf("%w: no Info element", ErrCorrupt)
+	case !seenTracks:
+		return nil, fmt.Errorf("%w: no Tracks element", ErrCorrupt)
+	case !seenCluster:
+		return nil, fmt.Errorf("%w: no Cluster elements", ErrTruncated)
+	}
+
+	if duration > 0 && !math.IsInf(duration, 0) {
+		info.Duration = time.Duration(duration * float64(timecodeScale))
+	}
+	return info, nil
+}
+
+// skipUnknownSizeCluster walks the children of a cluster of unknown size and returns
+// the offset of the next top-level element
+func skipUnknownSizeCluster(r io.ReaderAt, off, end, size int64) (int64, error) {
+	for off < end {
+		element, err := readElement(r, off, size)
+		if err != nil {
+			return 0, err
+		}
+		if mkvTopLevel[element.id] {
+			return off, nil
+		}
+		if element.size < 0 {
+			return 0, fmt.Errorf("%w: element 0x%X at byte %d has an unknown size", ErrCorrupt, element.id, off)
+		}
+		if element.end() > end {
+			return 0, truncated(fmt.Sprintf("element 0x%X", element.id), element.end(), size)
+		}
+		off = element.end()
+	}
+	return off, nil
+}
+
+// parseMatroskaTracks parses the TrackEntry elements of the Tracks element
+func parseMatroskaTracks(data []byte) ([]Track, error) {
+	entries, err := children(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var tracks []Track
+	for _, entry := range entries {
+		if entry.id != mkvTrackEntry {
+			continue
+		}
+		entryData := elementData(data, entry)
+		fields, err := children(entryData)
+		if err != nil {
+			return nil, err
+		}
+
+		track := Track{Type: TrackOther}
+		for _, field := range fields {
+			value := elementData(entryData, field)
+			switch field.id {
+			case mkvTrackNumber:
+				track.Number = int(readUint(value))
+			case mkvTrackType:
+				switch readUint(value) {
+				case 1:
+					track.Type = TrackVideo
+				case 2:
+					track.Type = TrackAudio
+				case 17:
+					track.Type = TrackSubtitle
+				}
+			case mkvCodecID:
+				track.Codec = string(value)
+			}
+		}
+		tracks = append(tracks, track)
+	}
+	return tracks, nil
+}
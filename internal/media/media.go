@@ -0,0 +1,145 @@
+package media
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// Container formats recognized by Probe
+const (
+	FormatMatroska = "matroska" // Matroska (.mkv) and WebM, built on EBML
+	FormatWebM     = "webm"
+	FormatMP4      = "mp4" // ISO base media file format (.mp4, .m4v, .mov)
+)
+
+// Track types
+const (
+	TrackVideo    = "video"
+	TrackAudio    = "audio"
+	TrackSubtitle = "subtitle"
+	TrackOther    = "other"
+)
+
+var (
+	// ErrEmpty is returned for zero-byte files
+	ErrEmpty = errors.New("file is empty")
+	// ErrUnknownFormat is returned for files that are neither Matroska nor MP4
+	ErrUnknownFormat = errors.New("unknown container format")
+	// ErrTruncated is returned when an element, box or audio frame extends past the end of the file
+	ErrTruncated = errors.New("file is truncated")
+	// ErrCorrupt is returned when the element structure or the audio headers can't be parsed
+	ErrCorrupt = errors.New("file structure is corrupt")
+)
+
+// maxMetadataSize limits the size of the metadata elements read into memory
+// (Matroska Info and Tracks, MP4 moov). Real files have a few kilobytes.
+const maxMetadataSize = 64 * 1024 * 1024
+
+// Track describes a single track of a container
+type Track struct {
+	Number int    `json:"number" yaml:"number"`
+	Type   string `json:"type" yaml:"type"`   // "video", "audio", "subtitle" or "other"
+	Codec  string `json:"codec" yaml:"codec"` // Matroska codec ID (e.g. "V_MPEG4/ISO/AVC") or MP4 sample entry (e.g. "avc1")
+}
+
+// Info is the result of a successful container check
+type Info struct {
+	Format   string        `json:"format" yaml:"format"`
+	Size     int64         `json:"size" yaml:"size"`
+	Duration time.Duration `json:"duration" yaml:"duration"`
+	Tracks   []Track       `json:"tracks" yaml:"tracks"`
+}
+
+// CountTracks returns the number of tracks of a type
+func (i *Info) CountTracks(trackType string) int {
+	n := 0
+	for _, track := range i.Tracks {
+		if track.Type == trackType {
+			n++
+		}
+	}
+	return n
+}
+
+// String summarizes the container, e.g. "matroska, 1m0s, 1 video + 2 audio tracks"
+func (i *Info) String() string {
+	return fmt.Sprintf("%s, %s, %d video + %d audio tracks", i.Format, i.Duration.Round(time.Second),
+		i.CountTracks(TrackVideo), i.CountTracks(TrackAudio))
+}
+
+// Probe checks the container structure of a Matroska or MP4 file: the top-level elements
+// must be intact and end within the file, and the duration and track list must be readable.
+// Only the element headers and the metadata are read, not the media data.
+func Probe(path string) (*Info, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer f.Close()
+
+	stat, err := f.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat file: %w", err)
+	}
+	return ProbeReader(f, stat.Size())
+}
+
+// ProbeReader checks the container read from r, which holds size bytes
+func ProbeReader(r io.ReaderAt, size int64) (*Info, error) {
+	if size == 0 {
+		return nil, ErrEmpty
+	}
+
+	magic := make([]byte, 12)
+	n, err := r.ReadAt(magic, 0)
+	if err != nil && err != io.EOF {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	magic = magic[:n]
+
+	var info *Info
+	switch {
+	case bytes.HasPrefix(magic, ebmlMagic):
+		info, err = probeMatroska(r, size)
+	case len(magic) >= 8 && isMP4BoxType(string(magic[4:8])):
+		info, err = probeMP4(r, size)
+	default:
+		return nil, ErrUnknownFormat
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	info.Size = size
+	if len(info.Tracks) == 0 {
+		return nil, fmt.Errorf("%w: no tracks", ErrCorrupt)
+	}
+	if info.Duration <= 0 {
+		return nil, fmt.Errorf("%w: no duration", ErrCorrupt)
+	}
+	return info, nil
+}
+
+// truncated returns the error of an element that ends past the end of the file
+func truncated(element string, end, size int64) error {
+	return fmt.Errorf("%w: %s ends at byte %d, file has %d bytes", ErrTruncated, element, end, size)
+}
+
+// readAt reads n bytes at off, which must lie within the file
+func readAt(r io.ReaderAt, off int64, n int64) ([]byte, error) {
+	if n > maxMetadataSize {
+		return nil, fmt.Errorf("%w: element of %d bytes at byte %d is too large", ErrCorrupt, n, off)
+	}
+	buf := make([]byte, n)
+	if _, err := r.ReadAt(buf, off); err != nil {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return nil, fmt.Errorf("%w: unexpected end of file at byte %d", ErrTruncated, off)
+		}
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	return buf, nil
+}
@@ -0,0 +1,159 @@
+package media
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// ebml encodes an EBML element with an 8-byte size
+func ebml(id uint32, data ...[]byte) []byte {
+	var buf bytes.Buffer
+	idBytes := make([]byte, 4)
+	binary.BigEndian.PutUint32(idBytes, id)
+	buf.Write(bytes.TrimLeft(idBytes, "\x00"))
+
+	body := bytes.Join(data, nil)
+	size := make([]byte, 8)
+	binary.BigEndian.PutUint64(size, uint64(len(body)))
+	size[0] = 0x01
+	buf.Write(size)
+	buf.Write(body)
+	return buf.Bytes()
+}
+
+// buildMKV creates a minimal Matroska file of a given duration in milliseconds
+func buildMKV(durationMS float64) []byte {
+	duration := make([]byte, 8)
+	binary.BigEndian.PutUint64(duration, math.Float64bits(durationMS))
+
+	header := ebml(mkvEBML, ebml(mkvDocType, []byte("matroska")))
+	info := ebml(mkvInfo, ebml(mkvTimecodeScale, []byte{0x0f, 0x42, 0x40}), ebml(mkvDuration, duration))
+	tracks := ebml(mkvTracks,
+		ebml(mkvTrackEntry, ebml(mkvTrackNumber, []byte{1}), ebml(mkvTrackType, []byte{1}), ebml(mkvCodecID, []byte("V_MPEG4/ISO/AVC"))),
+		ebml(mkvTrackEntry, ebml(mkvTrackNumber, []byte{2}), ebml(mkvTrackType, []byte{2}), ebml(mkvCodecID, []byte("A_AC3"))),
+	)
+	cluster := ebml(mkvCluster, ebml(0xe7, []byte{0}), ebml(0xa3, bytes.Repeat([]byte{0xaa}, 1000)))
+	return append(header, ebml(mkvSegment, info, tracks, cluster)...)
+}
+
+// box encodes an MP4 box
+func box(boxType string, data ...[]byte) []byte {
+	body := bytes.Join(data, nil)
+	buf := make([]byte, 8, 8+len(body))
+	binary.BigEndian.PutUint32(buf[0:4], uint32(8+len(body)))
+	copy(buf[4:8], boxType)
+	return append(buf, body...)
+}
+
+// buildMP4 creates a minimal MP4 file of a given duration in seconds
+func buildMP4(seconds uint32) []byte {
+	mvhd := make([]byte, 100)
+	binary.BigEndian.PutUint32(mvhd[12:16], 1000)
+	binary.BigEndian.PutUint32(mvhd[16:20], seconds*1000)
+
+	trak := func(id uint32, handler, codec string) []byte {
+		tkhd := make([]byte, 84)
+		binary.BigEndian.PutUint32(tkhd[12:16], id)
+		hdlr := make([]byte, 25)
+		copy(hdlr[8:12], handler)
+		stsd := make([]byte, 8)
+		binary.BigEndian.PutUint32(stsd[4:8], 1)
+		entry := box(codec, make([]byte, 8))
+		return box("trak", box("tkhd", tkhd), box("mdia", box("hdlr", hdlr),
+			box("minf", box("stbl", box("stsd", stsd, entry)))))
+	}
+
+	return bytes.Join([][]byte{
+		box("ftyp", []byte("isom\x00\x00\x02\x00isomiso2avc1mp41")),
+		box("moov", box("mvhd", mvhd), trak(1, "vide", "avc1"), trak(2, "soun", "mp4a")),
+		box("mdat", bytes.Repeat([]byte{0xbb}, 1000)),
+	}, nil)
+}
+
+func writeFile(t *testing.T, name string, data []byte) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	return path
+}
+
+func TestProbe_Matroska(t *testing.T) {
+	info, err := Probe(writeFile(t, "sample.mkv", buildMKV(61500)))
+	if err != nil {
+		t.Fatalf("Expected a valid container, got %v", err)
+	}
+
+	if info.Format != FormatMatroska {
+		t.Errorf("Expected format %q, got %q", FormatMatroska, info.Format)
+	}
+	if info.Duration != 61500*time.Millisecond {
+		t.Errorf("Expected a duration of 1m1.5s, got %s", info.Duration)
+	}
+	expected := []Track{{1, TrackVideo, "V_MPEG4/ISO/AVC"}, {2, TrackAudio, "A_AC3"}}
+	if len(info.Tracks) != len(expected) {
+		t.Fatalf("Expected %d tracks, got %+v", len(expected), info.Tracks)
+	}
+	for i, track := range expected {
+		if info.Tracks[i] != track {
+			t.Errorf("Expected track %+v, got %+v", track, info.Tracks[i])
+		}
+	}
+}
+
+func TestProbe_MP4(t *testing.T) {
+	info, err := Probe(writeFile(t, "sample.mp4", buildMP4(90)))
+	if err != nil {
+		t.Fatalf("Expected a valid container, got %v", err)
+	}
+
+	if info.Format != FormatMP4 {
+		t.Errorf("Expected format %q, got %q", FormatMP4, info.Format)
+	}
+	if info.Duration != 90*time.Second {
+		t.Errorf("Expected a duration of 1m30s, got %s", info.Duration)
+	}
+	if info.CountTracks(TrackVideo) != 1 || info.CountTracks(TrackAudio) != 1 {
+		t.Errorf("Expected one video and one audio track, got %+v", info.Tracks)
+	}
+	if info.Tracks[0].Codec != "avc1" || info.Tracks[1].Codec != "mp4a" {
+		t.Errorf("Expected codecs avc1 and mp4a, got %+v", info.Tracks)
+	}
+}
+
+func TestProbe_Broken(t *testing.T) {
+	mkv := buildMKV(1000)
+	mp4 := buildMP4(1)
+	noMoov := bytes.Join([][]byte{box("ftyp", []byte("isom")), box("mdat", make([]byte, 100))}, nil)
+
+	tests := []struct {
+		name     string
+		data     []byte
+		expected error
+	}{
+		{"zero-byte.mkv", nil, ErrEmpty},
+		{"text.mkv", []byte("this is not a video"), ErrUnknownFormat},
+		{"truncated.mkv", mkv[:len(mkv)-500], ErrTruncated},
+		{"header-only.mkv", mkv[:40], ErrTruncated},
+		{"no-duration.mkv", buildMKV(0), ErrCorrupt},
+		{"truncated.mp4", mp4[:len(mp4)-500], ErrTruncated},
+		{"no-moov.mp4", noMoov, ErrCorrupt},
+		{"garbage-tail.mp4", append(bytes.Clone(mp4), 0, 0, 0, 1), ErrTruncated},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Probe(writeFile(t, tt.name, tt.data))
+			if !errors.Is(err, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, err)
+			}
+		})
+	}
+}
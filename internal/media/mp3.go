@@ -0,0 +1,187 @@
+package media
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"time"
+)
+
+// mp3SyncWindow limits how far after the ID3v2 tag the first MPEG frame is searched
+const mp3SyncWindow = 64 * 1024
+
+// mpegBitrates are the bitrates in kbps by bitrate index, for MPEG-1 layers I to III
+// and MPEG-2/2.5 layer I and layers II and III
+var mpegBitrates = [5][16]int{
+	{0, 32, 64, 96, 128, 160, 192, 224, 256, 288, 320, 352, 384, 416, 448, 0},
+	{0, 32, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320, 384, 0},
+	{0, 32, 40, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320, 0},
+	{0, 32, 48, 56, 64, 80, 96, 112, 128, 144, 160, 176, 192, 224, 256, 0},
+	{0, 8, 16, 24, 32, 40, 48, 56, 64, 80, 96, 112, 128, 144, 160, 0},
+}
+
+// mpegSampleRates are the sample rates by sample rate index, for MPEG-1, 2 and 2.5
+var mpegSampleRates = [3][3]int{
+	{44100, 48000, 32000},
+	{22050, 24000, 16000},
+	{11025, 12000, 8000},
+}
+
+// mpegFrame is a parsed MPEG audio frame header
+type mpegFrame struct {
+	mpeg1      bool
+	layer      int // 1, 2 or 3
+	bitrate    int // kbps
+	sampleRate int // Hz
+	channels   int
+	length     int // Frame length in bytes, including the header
+	samples    int // Samples per channel in the frame
+}
+
+// parseMPEGHeader parses the 4-byte header of an MPEG audio frame
+func parseMPEGHeader(h uint32) (mpegFrame, bool) {
+	if h>>21 != 0x7ff {
+		return mpegFrame{}, false
+	}
+	versionBits, layerBits := (h>>19)&3, (h>>17)&3
+	bitrateIndex, rateIndex, padding := (h>>12)&15, (h>>10)&3, int((h>>9)&1)
+	if versionBits == 1 || layerBits == 0 || bitrateIndex == 0 || bitrateIndex == 15 || rateIndex == 3 {
+		return mpegFrame{}, false
+	}
+
+	frame := mpegFrame{mpeg1: versionBits == 3, layer: int(4 - layerBits), channels: 2}
+	if (h>>6)&3 == 3 {
+		frame.channels = 1
+	}
+	switch versionBits {
+	case 3:
+		frame.sampleRate = mpegSampleRates[0][rateIndex]
+		frame.bitrate = mpegBitrates[frame.layer-1][bitrateIndex]
+	case 2, 0:
+		frame.sampleRate = mpegSampleRates[1][rateIndex]
+		if versionBits == 0 {
+			frame.sampleRate = mpegSampleRates[2][rateIndex]
+		}
+		frame.bitrate = mpegBitrates[4][bitrateIndex]
+		if frame.layer == 1 {
+			frame.bitrate = mpegBitrates[3][bitrateIndex]
+		}
+	}
+
+	switch {
+	case frame.layer == 1:
+		frame.samples = 384
+		frame.length = (12*frame.bitrate*1000/frame.sampleRate + padding) * 4
+	case frame.layer == 3 && !frame.mpeg1:
+		frame.samples = 576
+		frame.length = 72*frame.bitrate*1000/frame.sampleRate + padding
+	default:
+		frame.samples = 1152
+		frame.length = 144*frame.bitrate*1000/frame.sampleRate + padding
+	}
+	return frame, true
+}
+
+// sideInfoSize returns the size of the layer III side information after the frame header
+func (f mpegFrame) sideInfoSize() int {
+	switch {
+	case f.mpeg1 && f.channels == 1:
+		return 17
+	case f.mpeg1:
+		return 32
+	case f.channels == 1:
+		return 9
+	default:
+		return 17
+	}
+}
+
+// probeMP3 checks the MPEG audio frames starting at start. The first frame must be found
+// within mp3SyncWindow bytes and be followed by another valid frame. The duration comes
+// from the Xing/Info or VBRI header of the first frame, or from the size of the audio
+// for constant bitrate files without one.
+func probeMP3(r io.ReaderAt, start, size int64) (*AudioInfo, error) {
+	end := size
+	tag := make([]byte, 3)
+	if size-start >= 128 {
+		if _, err := r.ReadAt(tag, size-128); err == nil && bytes.Equal(tag, []byte("TAG")) {
+			end -= 128 // ID3v1 tag
+		}
+	}
+
+	window := make([]byte, min(mp3SyncWindow, end-start))
+	n, err := r.ReadAt(window, start)
+	if err != nil && err != io.EOF {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	window = window[:n]
+
+	offset, frame, found := -1, mpegFrame{}, false
+	for i := 0; i+4 <= len(window) && !found; i++ {
+		if window[i] != 0xff {
+			continue
+		}
+		f, ok := parseMPEGHeader(binary.BigEndian.Uint32(window[i:]))
+		if !ok {
+			continue
+		}
+		// A second frame right after the first one rules out random 0xFFE bytes
+		next := int64(i) + int64(f.length)
+		if start+next == end {
+			offset, frame, found = i, f, true
+			break
+		}
+		nextHeader := make([]byte, 4)
+		if _, err := r.ReadAt(nextHeader, start+next); err != nil {
+			continue
+		}
+		if g, ok := parseMPEGHeader(binary.BigEndian.Uint32(nextHeader)); ok && g.sampleRate == f.sampleRate && g.layer == f.layer {
+			offset, frame, found = i, f, true
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("%w: no MPEG audio frame sync found", ErrCorrupt)
+	}
+
+	info := &AudioInfo{Format: FormatMP3, SampleRate: frame.sampleRate, Channels: frame.channels, Bitrate: frame.bitrate}
+	audioStart := start + int64(offset)
+	audioBytes := end - audioStart
+
+	// Xing/Info and VBRI headers hold the number of frames and bytes of the stream
+	var frames, streamBytes int64
+	first := window[offset:min(offset+frame.length, len(window))]
+	if xing := 4 + frame.sideInfoSize(); frame.layer == 3 && len(first) >= xing+16 {
+		switch string(first[xing : xing+4]) {
+		case "Xing", "Info":
+			info.VBR = string(first[xing:xing+4]) == "Xing"
+			flags := binary.BigEndian.Uint32(first[xing+4:])
+			pos := xing + 8
+			if flags&1 != 0 {
+				frames = int64(binary.BigEndian.Uint32(first[pos:]))
+				pos += 4
+			}
+			if flags&2 != 0 && len(first) >= pos+4 {
+				streamBytes = int64(binary.BigEndian.Uint32(first[pos:]))
+			}
+		}
+	}
+	if len(first) >= 36+18 && string(first[36:40]) == "VBRI" {
+		info.VBR = true
+		streamBytes = int64(binary.BigEndian.Uint32(first[46:]))
+		frames = int64(binary.BigEndian.Uint32(first[50:]))
+	}
+
+	if streamBytes > 0 && streamBytes > audioBytes+int64(frame.length) {
+		return nil, fmt.Errorf("%w: the MP3 header says %d bytes of audio, file has %d", ErrTruncated, streamBytes, audioBytes)
+	}
+	if frames > 0 {
+		info.Duration = time.Duration(frames * int64(frame.samples) * int64(time.Second) / int64(frame.sampleRate))
+		if seconds := info.Duration.Seconds(); seconds > 0 && info.VBR {
+			info.Bitrate = int(float64(audioBytes) * 8 / seconds / 1000)
+		}
+	} else {
+		info.Duration = time.Duration(audioBytes * 8 * int64(time.Second) / int64(frame.bitrate*1000))
+	}
+	return info, nil
+}
@@ -0,0 +1,271 @@
+package media
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"time"
+)
+
+// mp4TopLevel are the box types allowed at the top level of an MP4 file
+var mp4TopLevel = map[string]bool{
+	"ftyp": true, "moov": true, "mdat": true, "moof": true, "mfra": true, "free": true,
+	"skip": true, "wide": true, "uuid": true, "meta": true, "styp": true, "sidx": true,
+	"pdin": true, "pnot": true,
+}
+
+// isMP4BoxType reports whether the first box type of a file marks an MP4 file
+func isMP4BoxType(boxType string) bool {
+	switch boxType {
+	case "ftyp", "moov", "mdat", "free", "skip", "wide", "pnot", "styp":
+		return true
+	}
+	return false
+}
+
+// mp4Box is the header of an ISOBMFF box
+type mp4Box struct {
+	boxType    string
+	offset     int64 // Offset of the box header
+	dataOffset int64 // Offset of the box data
+	size       int64 // Size of the data
+}
+
+// end returns the offset right after the box data
+func (b mp4Box) end() int64 {
+	return b.dataOffset + b.size
+}
+
+// readBox reads the box header at off
+func readBox(r io.ReaderAt, off, size int64) (mp4Box, error) {
+	if size-off < 8 {
+		return mp4Box{}, fmt.Errorf("%w: incomplete box header at byte %d", ErrTruncated, off)
+	}
+	buf := make([]byte, 16)
+	n, err := r.ReadAt(buf, off)
+	if err != nil && err != io.EOF {
+		return mp4Box{}, fmt.Errorf("failed to read file: %w", err)
+	}
+	buf = buf[:n]
+
+	box := mp4Box{boxType: string(buf[4:8]), offset: off}
+	boxSize := int64(binary.BigEndian.Uint32(buf[0:4]))
+	headerSize := int64(8)
+	switch boxSize {
+	case 0:
+		// The box extends to the end of the file
+		boxSize = size - off
+	case 1:
+		if n < 16 {
+			return mp4Box{}, fmt.Errorf("%w: incomplete box header at byte %d", ErrTruncated, off)
+		}
+		large := binary.BigEndian.Uint64(buf[8:16])
+		if large > uint64(1)<<62 {
+			return mp4Box{}, fmt.Errorf("%w: box size overflow at byte %d", ErrCorrupt, off)
+		}
+		boxSize = int64(large)
+		headerSize = 16
+	}
+	if boxSize < headerSize {
+		return mp4Box{}, fmt.Errorf("%w: invalid size of %q box at byte %d", ErrCorrupt, box.boxType, off)
+	}
+
+	box.dataOffset = off + headerSize
+	box.size = boxSize - headerSize
+	return box, nil
+}
+
+// boxes parses the child boxes held in data
+func boxes(data []byte) ([]mp4Box, error) {
+	var children []mp4Box
+	for off := int64(0); off < int64(len(data)); {
+		if int64(len(data))-off < 8 {
+			return nil, fmt.Errorf("%w: incomplete box header", ErrCorrupt)
+		}
+		box := mp4Box{boxType: string(data[off+4 : off+8]), offset: off}
+		boxSize := int64(binary.BigEndian.Uint32(data[off : off+4]))
+		headerSize := int64(8)
+		switch boxSize {
+		case 0:
+			boxSize = int64(len(data)) - off
+		case 1:
+			if int64(len(data))-off < 16 {
+				return nil, fmt.Errorf("%w: incomplete box header", ErrCorrupt)
+			}
+			large := binary.BigEndian.Uint64(data[off+8 : off+16])
+			if large > uint64(len(data)) {
+				return nil, fmt.Errorf("%w: invalid size of %q box", ErrCorrupt, box.boxType)
+			}
+			boxSize = int64(large)
+			headerSize = 16
+		}
+		if boxSize < headerSize || boxSize > int64(len(data))-off {
+			return nil, fmt.Errorf("%w: invalid size of %q box", ErrCorrupt, box.boxType)
+		}
+		box.dataOffset = off + headerSize
+		box.size = boxSize - headerSize
+		children = append(children, box)
+		off += boxSize
+	}
+	return children, nil
+}
+
+// boxData returns the data of a child box parsed by boxes
+func boxData(data []byte, b mp4Box) []byte {
+	return data[b.dataOffset:b.end()]
+}
+
+// findBox returns the data of the first child box of a type
+func findBox(data []byte, boxType string) ([]byte, bool) {
+	children, err := boxes(data)
+	if err != nil {
+		return nil, false
+	}
+	for _, child := range children {
+		if child.boxType == boxType {
+			return boxData(data, child), true
+		}
+	}
+	return nil, false
+}
+
+// probeMP4 checks the top-level boxes and reads the movie header and tracks from moov
+func probeMP4(r io.ReaderAt, size int64) (*Info, error) {
+	info := &Info{Format: FormatMP4}
+	var moov []byte
+	var seenMedia bool
+
+	for off := int64(0); off < size; {
+		box, err := readBox(r, off, size)
+		if err != nil {
+			return nil, err
+		}
+		if !mp4TopLevel[box.boxType] {
+			return nil, fmt.Errorf("%w: unexpected %q box at byte %d", ErrCorrupt, box.boxType, off)
+		}
+		if box.end() > size {
+			return nil, truncated(fmt.Sprintf("%q box", box.boxType), box.end(), size)
+		}
+
+		switch box.boxType {
+		case "moov":
+			if moov != nil {
+				return nil, fmt.Errorf("%w: more than one moov box", ErrCorrupt)
+			}
+			moov, err = readAt(r, box.dataOffset, box.size)
+			if err != nil {
+				return nil, err
+			}
+		case "mdat", "moof":
+			seenMedia = true
+		}
+		off = box.end()
+	}
+
+	if moov == nil {
+		return nil, fmt.Errorf("%w: no moov box", ErrCorrupt)
+	}
+	if !seenMedia {
+		return nil, fmt.Errorf("%w: no mdat box", ErrTruncated)
+	}
+
+	children, err := boxes(moov)
+	if err != nil {
+		return nil, fmt.Errorf("moov: %w", err)
+	}
+	var timescale uint32
+	for _, child := range children {
+		data := boxData(moov, child)
+		switch child.boxType {
+		case "mvhd":
+			var duration uint64
+			timescale, duration, err = parseMovieHeader(data)
+			if err != nil {
+				return nil, err
+			}
+			info.Duration = scaleDuration(duration, timescale)
+		case "trak":
+			info.Tracks = append(info.Tracks, parseMP4Track(data))
+		}
+	}
+
+	// Fragmented files may only have the duration in the movie extends header
+	if info.Duration == 0 && timescale > 0 {
+		if mvex, ok := findBox(moov, "mvex"); ok {
+			if mehd, ok := findBox(mvex, "mehd"); ok && len(mehd) >= 8 {
+				var duration uint64
+				if mehd[0] == 1 && len(mehd) >= 12 {
+					duration = binary.BigEndian.Uint64(mehd[4:12])
+				} else {
+					duration = uint64(binary.BigEndian.Uint32(mehd[4:8]))
+				}
+				info.Duration = scaleDuration(duration, timescale)
+			}
+		}
+	}
+	return info, nil
+}
+
+// parseMovieHeader returns the timescale and duration of an mvhd box
+func parseMovieHeader(data []byte) (uint32, uint64, error) {
+	if len(data) < 1 {
+		return 0, 0, fmt.Errorf("%w: empty mvhd box", ErrCorrupt)
+	}
+	if data[0] == 1 {
+		if len(data) < 32 {
+			return 0, 0, fmt.Errorf("%w: short mvhd box", ErrCorrupt)
+		}
+		return binary.BigEndian.Uint32(data[20:24]), binary.BigEndian.Uint64(data[24:32]), nil
+	}
+	if len(data) < 20 {
+		return 0, 0, fmt.Errorf("%w: short mvhd box", ErrCorrupt)
+	}
+	return binary.BigEndian.Uint32(data[12:16]), uint64(binary.BigEndian.Uint32(data[16:20])), nil
+}
+
+// scaleDuration converts a duration in timescale units
+func scaleDuration(duration uint64, timescale uint32) time.Duration {
+	// All ones marks an unknown duration
+	if timescale == 0 || duration == 0 || duration == 0xffffffff || duration == 0xffffffffffffffff {
+		return 0
+	}
+	seconds := float64(duration) / float64(timescale)
+	return time.Duration(seconds * float64(time.Second))
+}
+
+// parseMP4Track reads the track ID, handler type and codec of a trak box
+func parseMP4Track(trak []byte) Track {
+	track := Track{Type: TrackOther}
+
+	if tkhd, ok := findBox(trak, "tkhd"); ok && len(tkhd) >= 1 {
+		if tkhd[0] == 1 && len(tkhd) >= 24 {
+			track.Number = int(binary.BigEndian.Uint32(tkhd[20:24]))
+		} else if len(tkhd) >= 16 {
+			track.Number = int(binary.BigEndian.Uint32(tkhd[12:16]))
+		}
+	}
+
+	mdia, ok := findBox(trak, "mdia")
+	if !ok {
+		return track
+	}
+	if hdlr, ok := findBox(mdia, "hdlr"); ok && len(hdlr) >= 12 {
+		switch string(hdlr[8:12]) {
+		case "vide":
+			track.Type = TrackVideo
+		case "soun":
+			track.Type = TrackAudio
+		case "subt", "text", "sbtl":
+			track.Type = TrackSubtitle
+		}
+	}
+	if minf, ok := findBox(mdia, "minf"); ok {
+		if stbl, ok := findBox(minf, "stbl"); ok {
+			// The sample description has a full box header and entry count before the entries
+			if stsd, ok := findBox(stbl, "stsd"); ok && len(stsd) >= 16 {
+				track.Codec = string(stsd[12:16])
+			}
+		}
+	}
+	return track
+}
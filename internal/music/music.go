@@ -0,0 +1,208 @@
+package music
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/autobrr/sfvbrr/internal/checksum"
+)
+
+// Result is the result of cross-checking the playlists, SFV files and audio tracks of a
+// music release
+type Result struct {
+	Dir        string
+	Playlists  []Playlist
+	Tracks     []Track  // Audio files in the release folder, in track order
+	Discs      int      // Number of discs the tracks are numbered on
+	Missing    []string // Files listed in a playlist that are not on disk
+	Unlisted   []string // Audio files not listed in any playlist
+	Unverified []string // Audio files not listed in any SFV file
+	Valid      bool
+	Errors     []error
+}
+
+// String summarizes the result, e.g. "2 playlist(s), 21 tracks on 2 discs"
+func (r *Result) String() string {
+	s := fmt.Sprintf("%d playlist(s), %d tracks", len(r.Playlists), len(r.Tracks))
+	if r.Discs > 1 {
+		s += fmt.Sprintf(" on %d discs", r.Discs)
+	}
+	return s
+}
+
+// Validate cross-checks the playlists of a music release with names relative to dir
+// against the audio files in the folder and its SFV files. Every playlist must list
+// existing files, each once and in track order, every audio file must be listed in a
+// playlist and in an SFV file, and the track numbers must be continuous on every disc,
+// with "101-", "201-" prefixes numbering the discs. Each problem is reported as its
+// own error.
+func Validate(dir string, names []string) *Result {
+	result := &Result{Dir: dir, Valid: true}
+	fail := func(format string, args ...any) {
+		result.Valid = false
+		result.Errors = append(result.Errors, fmt.Errorf(format, args...))
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		fail("failed to read directory: %v", err)
+		return result
+	}
+	onDisk := make(map[string]string) // Lower case name to name of the files in the folder
+	var sfvNames []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		name := entry.Name()
+		onDisk[strings.ToLower(name)] = name
+		if IsAudioFile(name) {
+			result.Tracks = append(result.Tracks, ParseTrack(name))
+		}
+		if strings.EqualFold(path.Ext(name), ".sfv") {
+			sfvNames = append(sfvNames, name)
+		}
+	}
+	sort.SliceStable(result.Tracks, func(i, j int) bool {
+		a, b := result.Tracks[i], result.Tracks[j]
+		switch {
+		case a.Numbered() != b.Numbered():
+			return a.Numbered()
+		case a.before(b) || b.before(a):
+			return a.before(b)
+		default:
+			return a.Name < b.Name
+		}
+	})
+	multiDisc := false
+	for _, track := range result.Tracks {
+		multiDisc = multiDisc || track.discPrefix
+	}
+
+	// Every playlist lists existing files, each once and in track order
+	listed := make(map[string]bool)
+	for _, name := range names {
+		playlist := ReadPlaylist(dir, name)
+		result.Playlists = append(result.Playlists, playlist)
+		if playlist.Error != nil {
+			fail("%s: %v", name, playlist.Error)
+			continue
+		}
+		if len(playlist.Entries) == 0 {
+			fail("%s: lists no tracks", name)
+			continue
+		}
+
+		seen := make(map[string]bool)
+		var last Track
+		for _, entry := range playlist.Entries {
+			key := strings.ToLower(entry)
+			if seen[key] {
+				fail("%s: lists %s more than once", name, entry)
+				continue
+			}
+			seen[key] = true
+			listed[key] = true
+
+			if _, exists := onDisk[key]; !exists && !fileExists(dir, entry) {
+				result.Missing = append(result.Missing, entry)
+				fail("%s: lists missing file %s", name, entry)
+			}
+
+			track := ParseTrack(entry)
+			if !track.Numbered() {
+				continue
+			}
+			if last.Numbered() && track.before(last) {
+				fail("%s: %s is listed after %s", name, entry, last.Name)
+			} else {
+				last = track
+			}
+		}
+	}
+
+	// Every audio file is listed in a playlist
+	for _, track := range result.Tracks {
+		if !listed[strings.ToLower(track.Name)] {
+			result.Unlisted = append(result.Unlisted, track.Name)
+			fail("%s is not listed in any playlist", track.Name)
+		}
+	}
+
+	// Every audio file is listed in an SFV file; whether there is one at all is up to the rules
+	if len(sfvNames) > 0 {
+		verified := make(map[string]bool)
+		for _, name := range sfvNames {
+			sfv, err := checksum.ParseManifestFile(filepath.Join(dir, name))
+			if err != nil {
+				fail("%s: %v", name, err)
+				continue
+			}
+			for _, entry := range sfv.Entries {
+				verified[strings.ToLower(strings.ReplaceAll(entry.Filename, `\`, "/"))] = true
+			}
+		}
+		for _, track := range result.Tracks {
+			if !verified[strings.ToLower(track.Name)] {
+				result.Unverified = append(result.Unverified, track.Name)
+				fail("%s is not listed in any SFV file", track.Name)
+			}
+		}
+	}
+
+	checkNumbering(result, multiDisc, fail)
+	return result
+}
+
+// checkNumbering checks that the discs and the tracks on every disc are numbered
+// continuously. Tracks start at 01, or at 00 for releases with a hidden track.
+func checkNumbering(result *Result, multiDisc bool, fail func(format string, args ...any)) {
+	discs := make(map[int][]Track)
+	numbered := 0
+	for _, track := range result.Tracks {
+		if track.Numbered() {
+			discs[track.Disc] = append(discs[track.Disc], track)
+			numbered++
+			result.Discs = max(result.Discs, track.Disc)
+		}
+	}
+	if numbered == 0 {
+		return
+	}
+	for _, track := range result.Tracks {
+		if !track.Numbered() {
+			fail("%s has no track number", track.Name)
+		}
+	}
+
+	for disc := 1; disc <= result.Discs; disc++ {
+		tracks := discs[disc]
+		if len(tracks) == 0 {
+			fail("missing disc %d", disc)
+			continue
+		}
+
+		// Tracks are sorted by number, so duplicates are next to each other
+		next := min(tracks[0].Number, 1)
+		for i, track := range tracks {
+			if i > 0 && track.Number == tracks[i-1].Number {
+				fail("duplicate track %s: %s and %s", track.Label(multiDisc), tracks[i-1].Name, track.Name)
+				continue
+			}
+			for ; next < track.Number; next++ {
+				fail("missing track %s", Track{Disc: disc, Number: next}.Label(multiDisc))
+			}
+			next = track.Number + 1
+		}
+	}
+}
+
+// fileExists reports whether a slash-separated path relative to dir is a file
+func fileExists(dir, name string) bool {
+	info, err := os.Stat(filepath.Join(dir, filepath.FromSlash(name)))
+	return err == nil && !info.IsDir()
+}
@@ -0,0 +1,130 @@
+package music
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// createRelease creates a music release folder with the given files and contents
+func createRelease(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+	return dir
+}
+
+func TestParsePlaylist(t *testing.T) {
+	data := []byte("\xef\xbb\xbf#EXTM3U\r\n#EXTINF:215,Artist - Intro\r\n01-artist-intro.mp3\r\n\r\nCD2\\201-artist-caf\xe9.mp3\r\n")
+	entries := ParsePlaylist(data, ".")
+	expected := []string{"01-artist-intro.mp3", "CD2/201-artist-café.mp3"}
+	if !reflect.DeepEqual(entries, expected) {
+		t.Errorf("Expected %q, got %q", expected, entries)
+	}
+}
+
+func TestParseTrack(t *testing.T) {
+	tests := []struct {
+		name   string
+		disc   int
+		number int
+	}{
+		{"05-artist-title.mp3", 1, 5},
+		{"105-artist-title.flac", 1, 5},
+		{"212_artist_title.mp3", 2, 12},
+		{"00-artist-hidden.mp3", 1, 0},
+		{"artist-title.mp3", 0, 0},
+	}
+	for _, tt := range tests {
+		track := ParseTrack(tt.name)
+		if track.Disc != tt.disc || track.Number != tt.number {
+			t.Errorf("%s: expected disc %d track %d, got %+v", tt.name, tt.disc, tt.number, track)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	dir := createRelease(t, map[string]string{
+		"101-artist-one.mp3":   "",
+		"102-artist-two.mp3":   "",
+		"201-artist-three.mp3": "",
+		"000-artist.m3u":       "101-artist-one.mp3\n102-artist-two.mp3\n201-artist-three.mp3\n",
+		"100-artist.m3u":       "101-artist-one.mp3\n102-artist-two.mp3\n",
+		"000-artist.sfv":       "101-artist-one.mp3 00000000\n102-artist-two.mp3 00000000\n201-artist-three.mp3 00000000\n",
+	})
+
+	result := Validate(dir, []string{"000-artist.m3u", "100-artist.m3u"})
+	if !result.Valid {
+		t.Fatalf("Expected a valid release, got %v", result.Errors)
+	}
+	if result.Discs != 2 || len(result.Tracks) != 3 || result.String() != "2 playlist(s), 3 tracks on 2 discs" {
+		t.Errorf("Unexpected result %s: %+v", result, result.Tracks)
+	}
+}
+
+func TestValidate_Problems(t *testing.T) {
+	dir := createRelease(t, map[string]string{
+		"01-artist-one.mp3":    "",
+		"02-artist-two.mp3":    "",
+		"04-artist-four.mp3":   "",
+		"05-artist-five.mp3":   "",
+		"00-artist.m3u":        "02-artist-two.mp3\n01-artist-one.mp3\n01-artist-one.mp3\n03-artist-three.mp3\n04-artist-four.mp3\n",
+		"00-artist.sfv":        "01-artist-one.mp3 00000000\n02-artist-two.mp3 00000000\n04-artist-four.mp3 00000000\n",
+		"unnumbered-bonus.mp3": "",
+	})
+
+	result := Validate(dir, []string{"00-artist.m3u"})
+	if result.Valid {
+		t.Fatal("Expected the release to fail")
+	}
+	if !reflect.DeepEqual(result.Missing, []string{"03-artist-three.mp3"}) {
+		t.Errorf("Unexpected missing files %q", result.Missing)
+	}
+	if !reflect.DeepEqual(result.Unlisted, []string{"05-artist-five.mp3", "unnumbered-bonus.mp3"}) {
+		t.Errorf("Unexpected unlisted files %q", result.Unlisted)
+	}
+	if !reflect.DeepEqual(result.Unverified, []string{"05-artist-five.mp3", "unnumbered-bonus.mp3"}) {
+		t.Errorf("Unexpected unverified files %q", result.Unverified)
+	}
+
+	var errors []string
+	for _, err := range result.Errors {
+		errors = append(errors, err.Error())
+	}
+	for _, expected := range []string{
+		"00-artist.m3u: 01-artist-one.mp3 is listed after 02-artist-two.mp3",
+		"00-artist.m3u: lists 01-artist-one.mp3 more than once",
+		"unnumbered-bonus.mp3 has no track number",
+		"missing track 03",
+	} {
+		if !strings.Contains(strings.Join(errors, "\n"), expected) {
+			t.Errorf("Expected error %q, got %q", expected, errors)
+		}
+	}
+}
+
+func TestValidate_Discs(t *testing.T) {
+	dir := createRelease(t, map[string]string{
+		"101-artist-one.mp3":   "",
+		"301-artist-three.mp3": "",
+		"302-artist-four.mp3":  "",
+		"304-artist-six.mp3":   "",
+		"000-artist.m3u":       "101-artist-one.mp3\n301-artist-three.mp3\n302-artist-four.mp3\n304-artist-six.mp3\n",
+	})
+
+	result := Validate(dir, []string{"000-artist.m3u"})
+	var errors []string
+	for _, err := range result.Errors {
+		errors = append(errors, err.Error())
+	}
+	expected := []string{"missing disc 2", "missing track 303"}
+	if !reflect.DeepEqual(errors, expected) {
+		t.Errorf("Expected %q, got %q", expected, errors)
+	}
+}
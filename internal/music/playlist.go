@@ -0,0 +1,143 @@
+package music
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxPlaylistSize limits how much of a playlist is read
+const maxPlaylistSize = 1024 * 1024
+
+// utf8BOM is the byte order mark some tools write at the start of M3U8 files
+var utf8BOM = []byte{0xef, 0xbb, 0xbf}
+
+// trackNumberRegex matches the track number at the start of a file name, e.g. "05-" or "105-"
+var trackNumberRegex = regexp.MustCompile(`^(\d{2,3})[-_. ]`)
+
+// audioExtensions are the file extensions of audio tracks
+var audioExtensions = []string{".mp3", ".flac", ".m4a", ".aac", ".ogg", ".opus", ".wav", ".ape", ".wv"}
+
+// IsAudioFile reports whether a file name has the extension of an audio track
+func IsAudioFile(name string) bool {
+	ext := strings.ToLower(path.Ext(name))
+	for _, audioExt := range audioExtensions {
+		if ext == audioExt {
+			return true
+		}
+	}
+	return false
+}
+
+// Playlist is a parsed M3U or M3U8 playlist
+type Playlist struct {
+	Name    string   // Path of the playlist, relative to the release folder
+	Entries []string // Slash-separated paths of the listed files, relative to the release folder
+	Error   error    // The playlist could not be read
+}
+
+// ReadPlaylist reads the playlist name, relative to dir
+func ReadPlaylist(dir, name string) Playlist {
+	playlist := Playlist{Name: name}
+	f, err := os.Open(filepath.Join(dir, filepath.FromSlash(name)))
+	if err != nil {
+		playlist.Error = fmt.Errorf("failed to open playlist: %w", err)
+		return playlist
+	}
+	defer f.Close()
+
+	data, err := io.ReadAll(io.LimitReader(f, maxPlaylistSize))
+	if err != nil {
+		playlist.Error = fmt.Errorf("failed to read playlist: %w", err)
+		return playlist
+	}
+	playlist.Entries = ParsePlaylist(data, path.Dir(filepath.ToSlash(name)))
+	return playlist
+}
+
+// ParsePlaylist returns the files listed in M3U or M3U8 text, in order. Comments and
+// "#EXTINF" lines are skipped, backslashes are turned into slashes and the paths are
+// resolved against base, the directory of the playlist. Text that isn't valid UTF-8 is
+// decoded as Latin-1, which is what most M3U files written on Windows use.
+func ParsePlaylist(data []byte, base string) []string {
+	data = bytes.TrimPrefix(data, utf8BOM)
+	text := string(data)
+	if !utf8.Valid(data) {
+		runes := make([]rune, len(data))
+		for i, b := range data {
+			runes[i] = rune(b)
+		}
+		text = string(runes)
+	}
+
+	var entries []string
+	scanner := bufio.NewScanner(strings.NewReader(text))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		entry := strings.ReplaceAll(line, `\`, "/")
+		if !path.IsAbs(entry) {
+			entry = path.Join(base, entry)
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// Track is an audio file of a release
+type Track struct {
+	Name   string // Path of the file, relative to the release folder
+	Disc   int    // Disc number, from the first digit of "105-" prefixes (1 for "05-", 0 = unnumbered)
+	Number int    // Track number on the disc
+
+	discPrefix bool // The number is a three digit disc and track prefix
+}
+
+// ParseTrack parses the disc and track number from the name of an audio file.
+// Three digit prefixes such as "105-" and "201-" number the tracks of multi-disc
+// releases, two digit prefixes the tracks of the only disc.
+func ParseTrack(name string) Track {
+	track := Track{Name: name}
+	m := trackNumberRegex.FindStringSubmatch(path.Base(name))
+	if m == nil {
+		return track
+	}
+	n, _ := strconv.Atoi(m[1])
+	if len(m[1]) == 3 && n >= 100 {
+		track.Disc, track.Number, track.discPrefix = n/100, n%100, true
+	} else {
+		track.Disc, track.Number = 1, n
+	}
+	return track
+}
+
+// Numbered reports whether the file name holds a track number
+func (t Track) Numbered() bool {
+	return t.Disc > 0
+}
+
+// Label returns the track number as written in file names, e.g. "105" or "05"
+func (t Track) Label(multiDisc bool) string {
+	if multiDisc {
+		return fmt.Sprintf("%d%02d", t.Disc, t.Number)
+	}
+	return fmt.Sprintf("%02d", t.Number)
+}
+
+// before reports whether the track is numbered before another one
+func (t Track) before(other Track) bool {
+	if t.Disc != other.Disc {
+		return t.Disc < other.Disc
+	}
+	return t.Number < other.Number
+}
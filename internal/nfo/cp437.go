@@ -0,0 +1,44 @@
+package nfo
+
+import (
+	"bytes"
+	"strings"
+	"unicode/utf8"
+)
+
+// cp437 maps the upper half of code page 437, the DOS character set NFO art is drawn in
+var cp437 = [128]rune{
+	'Ç', 'ü', 'é', 'â', 'ä', 'à', 'å', 'ç', 'ê', 'ë', 'è', 'ï', 'î', 'ì', 'Ä', 'Å',
+	'É', 'æ', 'Æ', 'ô', 'ö', 'ò', 'û', 'ù', 'ÿ', 'Ö', 'Ü', '¢', '£', '¥', '₧', 'ƒ',
+	'á', 'í', 'ó', 'ú', 'ñ', 'Ñ', 'ª', 'º', '¿', '⌐', '¬', '½', '¼', '¡', '«', '»',
+	'░', '▒', '▓', '│', '┤', '╡', '╢', '╖', '╕', '╣', '║', '╗', '╝', '╜', '╛', '┐',
+	'└', '┴', '┬', '├', '─', '┼', '╞', '╟', '╚', '╔', '╩', '╦', '╠', '═', '╬', '╧',
+	'╨', '╤', '╥', '╙', '╘', '╒', '╓', '╫', '╪', '┘', '┌', '█', '▄', '▌', '▐', '▀',
+	'α', 'ß', 'Γ', 'π', 'Σ', 'σ', 'µ', 'τ', 'Φ', 'Θ', 'Ω', 'δ', '∞', 'φ', 'ε', '∩',
+	'≡', '±', '≥', '≤', '⌠', '⌡', '÷', '≈', '°', '∙', '·', '√', 'ⁿ', '²', '■', '\u00a0',
+}
+
+// utf8BOM is the byte order mark some editors write at the start of UTF-8 files
+var utf8BOM = []byte{0xef, 0xbb, 0xbf}
+
+// Decode converts NFO or DIZ text to UTF-8. Files that are already valid UTF-8 with
+// non-ASCII characters are kept, everything else is decoded as CP437.
+func Decode(data []byte) string {
+	if bytes.HasPrefix(data, utf8BOM) {
+		return string(data[len(utf8BOM):])
+	}
+	if utf8.Valid(data) {
+		return string(data)
+	}
+
+	var sb strings.Builder
+	sb.Grow(len(data) * 2)
+	for _, b := range data {
+		if b < 0x80 {
+			sb.WriteByte(b)
+		} else {
+			sb.WriteRune(cp437[b-0x80])
+		}
+	}
+	return sb.String()
+}
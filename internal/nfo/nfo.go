@@ -0,0 +1,219 @@
+package nfo
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Sites of the links found in NFO files
+const (
+	SiteIMDb    = "imdb"
+	SiteTVDB    = "tvdb"
+	SiteDiscogs = "discogs"
+)
+
+// Sites lists the link sites in the order they are reported
+var Sites = []string{SiteIMDb, SiteTVDB, SiteDiscogs}
+
+// maxFileSize limits how much of an NFO or DIZ file is read; real ones have a few kilobytes
+const maxFileSize = 1024 * 1024
+
+// Link is a link to a database entry found in the text
+type Link struct {
+	Site string `json:"site" yaml:"site"`
+	URL  string `json:"url" yaml:"url"`
+	ID   string `json:"id,omitempty" yaml:"id,omitempty"` // e.g. "tt0111161" for IMDb
+}
+
+// Info holds the fields extracted from an NFO or file_id.diz file
+type Info struct {
+	Release  string   `json:"release,omitempty" yaml:"release,omitempty"`   // Release name, from a "Release:" line or the first scene name in the text
+	Releases []string `json:"releases,omitempty" yaml:"releases,omitempty"` // All scene names found in the text
+	Group    string   `json:"group,omitempty" yaml:"group,omitempty"`
+	Links    []Link   `json:"links,omitempty" yaml:"links,omitempty"`
+	Disk     int      `json:"disk,omitempty" yaml:"disk,omitempty"`   // Disk number of a "[01/15]" marker (0 for "[xx/15]")
+	Disks    int      `json:"disks,omitempty" yaml:"disks,omitempty"` // Total number of disks of a "[01/15]" marker
+	Text     string   `json:"-" yaml:"-"`                             // The decoded text
+}
+
+// HasLink reports whether a link to a site was found
+func (i *Info) HasLink(site string) bool {
+	for _, link := range i.Links {
+		if link.Site == site {
+			return true
+		}
+	}
+	return false
+}
+
+// MentionsRelease reports whether the release name appears in the text, ignoring case
+func (i *Info) MentionsRelease(name string) bool {
+	for _, release := range i.Releases {
+		if strings.EqualFold(release, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// String summarizes the fields found, e.g. "release Some.Release-GRP, group GRP, 15 disks, imdb"
+func (i *Info) String() string {
+	var parts []string
+	if i.Release != "" {
+		parts = append(parts, "release "+i.Release)
+	}
+	if i.Group != "" {
+		parts = append(parts, "group "+i.Group)
+	}
+	if i.Disks > 0 {
+		parts = append(parts, fmt.Sprintf("%d disks", i.Disks))
+	}
+	for _, site := range Sites {
+		if i.HasLink(site) {
+			parts = append(parts, site)
+		}
+	}
+	if len(parts) == 0 {
+		return "no fields found"
+	}
+	return strings.Join(parts, ", ")
+}
+
+var (
+	// releaseLabelRegex matches lines like "Release.....: Some.Release-GRP"
+	releaseLabelRegex = regexp.MustCompile(`(?im)^[^a-z0-9\n]*(?:release(?: ?name)?|rls(?: ?name)?)\s*[:.]+\s*([a-z0-9][\w.()&+-]*-[a-z0-9_]+)`)
+	// groupLabelRegex matches lines like "Group: GRP"
+	groupLabelRegex = regexp.MustCompile(`(?im)^[^a-z0-9\n]*(?:group|grp)\s*[:.]+\s*([a-z0-9_]+)\s*$`)
+	// sceneNameRegex matches scene release names: at least three dot or underscore
+	// separated parts followed by "-GROUP"
+	sceneNameRegex = regexp.MustCompile(`(?i)(?:^|[^\w./-])((?:[a-z0-9][\w()&+'-]*[._]){2,}[\w()&+'-]*-[a-z0-9_]+)\b`)
+
+	imdbRegex      = regexp.MustCompile(`(?i)(?:https?://)?(?:www\.|m\.)?imdb\.com/title/(tt\d{7,8})`)
+	tvdbRegex      = regexp.MustCompile(`(?i)(?:https?://)?(?:www\.)?thetvdb\.com/[^\s"'<>]*`)
+	discogsRegex   = regexp.MustCompile(`(?i)(?:https?://)?(?:www\.)?discogs\.com/[^\s"'<>]*`)
+	tvdbIDRegex    = regexp.MustCompile(`(?i)(?:series/([^/?#\s]+)|[?&]id=(\d+))`)
+	discogsIDRegex = regexp.MustCompile(`(?i)(?:release|master)/(\d+)`)
+
+	// diskRegex matches disk markers like "[01/15]", "(xx/15)" or "Disk: 01/15"
+	diskRegex = regexp.MustCompile(`(?i)(?:[\[(<{]\s*(\d{1,3}|x{1,3})\s*/\s*(\d{1,3})\s*[\])>}]|disks?\s*[:#]?\s*(\d{1,3}|x{1,3})\s*/\s*(\d{1,3}))`)
+)
+
+// ParseFile reads and parses an NFO or DIZ file
+func ParseFile(path string) (*Info, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer f.Close()
+
+	data, err := io.ReadAll(io.LimitReader(f, maxFileSize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	return Parse(data), nil
+}
+
+// Parse decodes NFO or DIZ text and extracts its fields
+func Parse(data []byte) *Info {
+	text := Decode(data)
+	info := &Info{Text: text}
+
+	seen := make(map[string]bool)
+	addRelease := func(name string) {
+		if key := strings.ToLower(name); !seen[key] {
+			seen[key] = true
+			info.Releases = append(info.Releases, name)
+		}
+	}
+	if m := releaseLabelRegex.FindStringSubmatch(text); m != nil {
+		info.Release = m[1]
+		addRelease(m[1])
+	}
+	for _, m := range sceneNameRegex.FindAllStringSubmatch(text, -1) {
+		if isURL(m[1]) {
+			continue
+		}
+		addRelease(m[1])
+	}
+	if info.Release == "" && len(info.Releases) > 0 {
+		info.Release = info.Releases[0]
+	}
+
+	if m := groupLabelRegex.FindStringSubmatch(text); m != nil {
+		info.Group = m[1]
+	} else if info.Release != "" {
+		info.Group = GroupOf(info.Release)
+	}
+
+	info.Links = findLinks(text)
+
+	if m := diskRegex.FindStringSubmatch(text); m != nil {
+		disk, total := m[1], m[2]
+		if total == "" {
+			disk, total = m[3], m[4]
+		}
+		info.Disk, _ = strconv.Atoi(disk) // "xx" stays 0
+		info.Disks, _ = strconv.Atoi(total)
+	}
+
+	return info
+}
+
+// GroupOf returns the group of a release name, the part after the last dash
+func GroupOf(release string) string {
+	idx := strings.LastIndex(release, "-")
+	if idx < 0 {
+		return ""
+	}
+	return release[idx+1:]
+}
+
+// isURL reports whether a scene name candidate is part of a web address
+func isURL(s string) bool {
+	lower := strings.ToLower(s)
+	return strings.HasPrefix(lower, "www.") || strings.HasPrefix(lower, "http")
+}
+
+// findLinks extracts the IMDb, TVDB and Discogs links of the text
+func findLinks(text string) []Link {
+	var links []Link
+	seen := make(map[string]bool)
+	add := func(link Link) {
+		if !seen[link.URL] {
+			seen[link.URL] = true
+			links = append(links, link)
+		}
+	}
+
+	for _, m := range imdbRegex.FindAllStringSubmatch(text, -1) {
+		add(Link{Site: SiteIMDb, URL: "https://www.imdb.com/title/" + m[1] + "/", ID: m[1]})
+	}
+	for _, m := range tvdbRegex.FindAllString(text, -1) {
+		link := Link{Site: SiteTVDB, URL: normalizeURL(m)}
+		if id := tvdbIDRegex.FindStringSubmatch(link.URL); id != nil {
+			link.ID = id[1] + id[2]
+		}
+		add(link)
+	}
+	for _, m := range discogsRegex.FindAllString(text, -1) {
+		link := Link{Site: SiteDiscogs, URL: normalizeURL(m)}
+		if id := discogsIDRegex.FindStringSubmatch(link.URL); id != nil {
+			link.ID = id[1]
+		}
+		add(link)
+	}
+	return links
+}
+
+// normalizeURL strips trailing punctuation and adds a missing scheme
+func normalizeURL(url string) string {
+	url = strings.TrimRight(url, ".,;:!?)]}")
+	if !strings.Contains(url, "://") {
+		url = "https://" + url
+	}
+	return url
+}
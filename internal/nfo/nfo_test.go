@@ -0,0 +1,114 @@
+package nfo
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDecode(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     []byte
+		expected string
+	}{
+		{"ascii", []byte("plain text"), "plain text"},
+		{"cp437", []byte{0xdb, 0xdb, 0xb2, ' ', 0x82, 't', 0x82}, "██▓ été"},
+		{"utf-8", []byte("██▓ été"), "██▓ été"},
+		{"utf-8 bom", append([]byte{0xef, 0xbb, 0xbf}, "été"...), "été"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Decode(tt.data); got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestParse_NFO(t *testing.T) {
+	text := "\xdb\xdb\xdb\xdb GROUP PRESENTS \xdb\xdb\xdb\xdb\r\n" +
+		"\xba Release....: The.Movie.2025.1080p.BluRay.x264-GRP \xba\r\n" +
+		"\xba Date.......: 2025-01-01                            \xba\r\n" +
+		"\xba IMDb.......: https://www.imdb.com/title/tt0111161/  \xba\r\n" +
+		"\xba Greets to Other.Release.2024.720p.WEB.h264-OTHER    \xba\r\n" +
+		"\xba Visit www.some-site.com or imdb.com/title/tt0111161 \xba\r\n"
+
+	info := Parse([]byte(text))
+
+	if info.Release != "The.Movie.2025.1080p.BluRay.x264-GRP" {
+		t.Errorf("Expected the labelled release name, got %q", info.Release)
+	}
+	if info.Group != "GRP" {
+		t.Errorf("Expected group GRP, got %q", info.Group)
+	}
+	if len(info.Releases) != 2 || !info.MentionsRelease("other.release.2024.720p.web.h264-other") {
+		t.Errorf("Expected two release names, got %v", info.Releases)
+	}
+	if len(info.Links) != 1 || info.Links[0].ID != "tt0111161" || !info.HasLink(SiteIMDb) {
+		t.Errorf("Expected one IMDb link, got %+v", info.Links)
+	}
+	if info.Disks != 0 {
+		t.Errorf("Expected no disk count, got %d", info.Disks)
+	}
+}
+
+func TestParse_Links(t *testing.T) {
+	text := "TVDB: https://thetvdb.com/series/some-show.\n" +
+		"Discogs: www.discogs.com/release/12345-Artist-Title\n"
+
+	info := Parse([]byte(text))
+
+	expected := []Link{
+		{Site: SiteTVDB, URL: "https://thetvdb.com/series/some-show", ID: "some-show"},
+		{Site: SiteDiscogs, URL: "https://www.discogs.com/release/12345-Artist-Title", ID: "12345"},
+	}
+	if len(info.Links) != len(expected) {
+		t.Fatalf("Expected %d links, got %+v", len(expected), info.Links)
+	}
+	for i, link := range expected {
+		if info.Links[i] != link {
+			t.Errorf("Expected %+v, got %+v", link, info.Links[i])
+		}
+	}
+}
+
+func TestParse_DIZ(t *testing.T) {
+	tests := []struct {
+		text  string
+		disk  int
+		disks int
+	}{
+		{"Some Tool v1.0 [01/15]", 1, 15},
+		{"Some Tool v1.0 (xx/07)", 0, 7},
+		{"Some Tool v1.0\nDISKS: 03/12", 3, 12},
+		{"Some Tool v1.0", 0, 0},
+	}
+
+	for _, tt := range tests {
+		info := Parse([]byte(tt.text))
+		if info.Disk != tt.disk || info.Disks != tt.disks {
+			t.Errorf("%q: expected disk %d of %d, got %d of %d", tt.text, tt.disk, tt.disks, info.Disk, info.Disks)
+		}
+	}
+}
+
+func TestParseFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "file_id.diz")
+	if err := os.WriteFile(path, []byte("\xc9\xcd\xcd\xbb\r\nTool.v1.0-GRP [xx/03]\r\n"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	info, err := ParseFile(path)
+	if err != nil {
+		t.Fatalf("Failed to parse file: %v", err)
+	}
+	if info.Release != "Tool.v1.0-GRP" || info.Disks != 3 {
+		t.Errorf("Expected Tool.v1.0-GRP with 3 disks, got %q with %d", info.Release, info.Disks)
+	}
+	if !strings.HasPrefix(info.Text, "╔══╗\r\n") {
+		t.Errorf("Expected CP437 box drawing characters, got %q", info.Text)
+	}
+}
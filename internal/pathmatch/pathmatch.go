@@ -0,0 +1,277 @@
+// Package pathmatch matches rule patterns against paths inside a release folder.
+//
+// Patterns are slash-separated and relative to the release folder. Every segment is
+// matched against one path element: "*", "?" and character classes ("[abc]", "[a-z]",
+// "[!abc]") work like in shell globs, and a "**" segment matches any number of path
+// elements, including none. Braces expand into alternatives before matching, so
+// "CD{1,2}/*.{mkv,mp4}" matches four kinds of paths; braces can be nested and a pattern
+// can hold any number of them. In regex patterns every segment is a regular expression
+// instead (unanchored, like regexp.MatchString), and "**" keeps its meaning.
+package pathmatch
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// maxAlternatives limits the number of patterns a brace expression expands into
+const maxAlternatives = 1024
+
+// Pattern is a compiled rule pattern
+type Pattern struct {
+	source       string
+	alternatives [][]segment // Brace expanded alternatives, each a list of path segments
+}
+
+// segment matches a single path element, or any number of them ("**")
+type segment struct {
+	glob  string
+	regex *regexp.Regexp
+	any   bool
+}
+
+// match reports whether the segment matches a path element
+func (s segment) match(name string) bool {
+	if s.regex != nil {
+		return s.regex.MatchString(name)
+	}
+	matched, _ := path.Match(s.glob, name)
+	return matched
+}
+
+// Compile compiles a glob pattern, or a regex pattern if regex is true
+func Compile(pattern string, regex bool) (*Pattern, error) {
+	if pattern == "" {
+		return nil, errors.New("empty pattern")
+	}
+	if strings.HasPrefix(pattern, "/") {
+		return nil, fmt.Errorf("invalid pattern %q: patterns are relative to the release folder", pattern)
+	}
+	pattern = strings.TrimPrefix(pattern, "./")
+
+	p := &Pattern{source: pattern}
+	if regex {
+		segments, err := compileSegments(pattern, true)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regex %q: %w", pattern, err)
+		}
+		p.alternatives = [][]segment{segments}
+		return p, nil
+	}
+
+	expanded, err := ExpandBraces(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid glob %q: %w", pattern, err)
+	}
+	for _, alternative := range expanded {
+		segments, err := compileSegments(alternative, false)
+		if err != nil {
+			return nil, fmt.Errorf("invalid glob %q: %w", pattern, err)
+		}
+		p.alternatives = append(p.alternatives, segments)
+	}
+	return p, nil
+}
+
+// MustCompile is like Compile but panics if the pattern can't be compiled
+func MustCompile(pattern string, regex bool) *Pattern {
+	p, err := Compile(pattern, regex)
+	if err != nil {
+		panic(err)
+	}
+	return p
+}
+
+// String returns the pattern as written
+func (p *Pattern) String() string {
+	return p.source
+}
+
+// compileSegments splits a pattern without braces into its path segments
+func compileSegments(pattern string, regex bool) ([]segment, error) {
+	parts := strings.Split(pattern, "/")
+	segments := make([]segment, 0, len(parts))
+	for _, part := range parts {
+		switch {
+		case part == "**":
+			// Consecutive "**" segments match the same paths as one
+			if len(segments) == 0 || !segments[len(segments)-1].any {
+				segments = append(segments, segment{any: true})
+			}
+		case part == "" || part == "." || part == "..":
+			return nil, fmt.Errorf("invalid path segment %q", part)
+		case regex:
+			re, err := regexp.Compile(part)
+			if err != nil {
+				return nil, err
+			}
+			segments = append(segments, segment{regex: re})
+		default:
+			glob := translateClasses(part)
+			if _, err := path.Match(glob, ""); err != nil {
+				return nil, fmt.Errorf("segment %q: %w", part, err)
+			}
+			segments = append(segments, segment{glob: glob})
+		}
+	}
+	return segments, nil
+}
+
+// translateClasses rewrites negated character classes from the shell form "[!abc]" to
+// the "[^abc]" form of path.Match
+func translateClasses(glob string) string {
+	var b strings.Builder
+	inClass := false
+	for i := 0; i < len(glob); i++ {
+		c := glob[i]
+		switch {
+		case c == '\\' && i+1 < len(glob):
+			b.WriteByte(c)
+			i++
+			b.WriteByte(glob[i])
+			continue
+		case c == '[' && !inClass:
+			inClass = true
+			b.WriteByte(c)
+			if i+1 < len(glob) && glob[i+1] == '!' {
+				b.WriteByte('^')
+				i++
+			}
+			continue
+		case c == ']' && inClass:
+			inClass = false
+		}
+		b.WriteByte(c)
+	}
+	return b.String()
+}
+
+// ExpandBraces expands the brace expressions of a glob, e.g. "*.{mkv,mp4}" into
+// "*.mkv" and "*.mp4". Braces may be nested and repeated; braces inside character
+// classes or escaped with a backslash are taken literally. Spaces around the options
+// are ignored, so "*.{mkv, mp4}" expands the same way.
+func ExpandBraces(glob string) ([]string, error) {
+	start, end, options, err := findBraces(glob)
+	if err != nil {
+		return nil, err
+	}
+	if start < 0 {
+		return []string{glob}, nil
+	}
+
+	var expanded []string
+	for _, option := range options {
+		alternatives, err := ExpandBraces(glob[:start] + strings.TrimSpace(option) + glob[end+1:])
+		if err != nil {
+			return nil, err
+		}
+		expanded = append(expanded, alternatives...)
+		if len(expanded) > maxAlternatives {
+			return nil, fmt.Errorf("braces expand into more than %d patterns", maxAlternatives)
+		}
+	}
+	return expanded, nil
+}
+
+// findBraces finds the first top-level brace expression of a glob and splits its
+// content on the top-level commas. start is -1 if the glob has no braces.
+func findBraces(glob string) (start, end int, options []string, err error) {
+	start, depth, inClass := -1, 0, false
+	last := 0
+	for i := 0; i < len(glob); i++ {
+		c := glob[i]
+		switch {
+		case c == '\\':
+			i++
+		case inClass:
+			if c == ']' {
+				inClass = false
+			}
+		case c == '[':
+			inClass = true
+		case c == '{':
+			if depth == 0 {
+				start, last = i, i+1
+			}
+			depth++
+		case c == ',' && depth == 1:
+			options = append(options, glob[last:i])
+			last = i + 1
+		case c == '}':
+			if depth == 0 {
+				return 0, 0, nil, errors.New("unbalanced braces, } without {")
+			}
+			depth--
+			if depth == 0 {
+				return start, i, append(options, glob[last:i]), nil
+			}
+		}
+	}
+	if depth > 0 {
+		return 0, 0, nil, errors.New("unbalanced braces, { without }")
+	}
+	return -1, -1, nil, nil
+}
+
+// Match reports whether a slash-separated path relative to the release folder matches
+// the pattern
+func (p *Pattern) Match(name string) bool {
+	parts := strings.Split(name, "/")
+	for _, segments := range p.alternatives {
+		if matchSegments(segments, parts) {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchBelow reports whether the pattern can match a path inside the directory, i.e.
+// whether the directory has to be searched for matches
+func (p *Pattern) MatchBelow(dir string) bool {
+	parts := strings.Split(dir, "/")
+	for _, segments := range p.alternatives {
+		if matchPrefix(segments, parts) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchSegments matches path elements against segments, trying every split of "**"
+func matchSegments(segments []segment, parts []string) bool {
+	for len(segments) > 0 {
+		if segments[0].any {
+			for i := 0; i <= len(parts); i++ {
+				if matchSegments(segments[1:], parts[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(parts) == 0 || !segments[0].match(parts[0]) {
+			return false
+		}
+		segments, parts = segments[1:], parts[1:]
+	}
+	return len(parts) == 0
+}
+
+// matchPrefix reports whether the path elements can be the leading directories of a match
+func matchPrefix(segments []segment, parts []string) bool {
+	for len(parts) > 0 {
+		if len(segments) == 0 {
+			return false
+		}
+		if segments[0].any {
+			return true
+		}
+		if !segments[0].match(parts[0]) {
+			return false
+		}
+		segments, parts = segments[1:], parts[1:]
+	}
+	return len(segments) > 0
+}
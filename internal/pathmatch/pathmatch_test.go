@@ -0,0 +1,132 @@
+package pathmatch
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		pattern string
+		regex   bool
+		name    string
+		want    bool
+	}{
+		{"*.nfo", false, "release.nfo", true},
+		{"*.nfo", false, "Sample/release.nfo", false},
+		{"*.r??", false, "release.r01", true},
+		{"*.{mkv,mp4}", false, "release.mp4", true},
+		{"*.{mkv,mp4}", false, "release.avi", false},
+		{"*.{mkv, mp4}", false, "release.mp4", true},
+		{"Sample/*.mkv", false, "Sample/sample.mkv", true},
+		{"Sample/*.mkv", false, "sample.mkv", false},
+		{"CD{1,2}/*.{mkv,mp4}", false, "CD2/part.mp4", true},
+		{"CD{1,2}/*.{mkv,mp4}", false, "CD3/part.mp4", false},
+		{"*.{r{0,1}?,rar}", false, "release.r14", true},
+		{"*.{r{0,1}?,rar}", false, "release.rar", true},
+		{"*.{r{0,1}?,rar}", false, "release.r24", false},
+		{"Season */Disc */*.iso", false, "Season 1/Disc 2/show.iso", true},
+		{"Season */Disc */*.iso", false, "Season 1/show.iso", false},
+		{"**/*.nfo", false, "release.nfo", true},
+		{"**/*.nfo", false, "Season 1/Disc 2/show.nfo", true},
+		{"Extras/**", false, "Extras/a/b.mkv", true},
+		{"Extras/**", false, "Extras", true},
+		{"a/**/b/*.txt", false, "a/x/y/b/c.txt", true},
+		{"a/**/b/*.txt", false, "a/b/c.txt", true},
+		{"a/**/b/*.txt", false, "a/x/c.txt", false},
+		{"*.[!s]fv", false, "release.xfv", true},
+		{"*.[!s]fv", false, "release.sfv", false},
+		{"[0-9][0-9]-*.flac", false, "01-track.flac", true},
+		{`\{literal\}.txt`, false, "{literal}.txt", true},
+		{`^[Ss]ample$/\.mkv$`, true, "Sample/sample.mkv", true},
+		{`^[Ss]ample$/\.mkv$`, true, "Proof/sample.mkv", false},
+		{`**/\.nfo$`, true, "CD1/release.nfo", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern+" "+tt.name, func(t *testing.T) {
+			if got := MustCompile(tt.pattern, tt.regex).Match(tt.name); got != tt.want {
+				t.Errorf("Match(%q) = %v, want %v", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatchBelow(t *testing.T) {
+	tests := []struct {
+		pattern string
+		dir     string
+		want    bool
+	}{
+		{"*.nfo", "Sample", false},
+		{"Sample", "Sample", false},
+		{"Sample/*.mkv", "Sample", true},
+		{"Sample/*.mkv", "Proof", false},
+		{"Sample/*.mkv", "Sample/Extra", false},
+		{"Season */Disc */*.iso", "Season 1", true},
+		{"Season */Disc */*.iso", "Season 1/Disc 1", true},
+		{"**/*.nfo", "anything/at/all", true},
+		{"{CD1,Sample}/*.mkv", "Sample", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern+" "+tt.dir, func(t *testing.T) {
+			if got := MustCompile(tt.pattern, false).MatchBelow(tt.dir); got != tt.want {
+				t.Errorf("MatchBelow(%q) = %v, want %v", tt.dir, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExpandBraces(t *testing.T) {
+	tests := []struct {
+		glob string
+		want string
+	}{
+		{"*.nfo", "*.nfo"},
+		{"*.{mkv,mp4}", "*.mkv,*.mp4"},
+		{"*.{mkv, mp4}", "*.mkv,*.mp4"},
+		{"*.{ r{0, 1}? , rar }", "*.r0?,*.r1?,*.rar"},
+		{"{a,b}{1,2}", "a1,a2,b1,b2"},
+		{"*.{r{0,1}?,rar}", "*.r0?,*.r1?,*.rar"},
+		{"x{,y}", "x,xy"},
+		{"[{]*", "[{]*"},
+		{`\{a,b\}`, `\{a,b\}`},
+	}
+
+	for _, tt := range tests {
+		expanded, err := ExpandBraces(tt.glob)
+		if err != nil {
+			t.Errorf("ExpandBraces(%q) failed: %v", tt.glob, err)
+			continue
+		}
+		if got := strings.Join(expanded, ","); got != tt.want {
+			t.Errorf("ExpandBraces(%q) = %q, want %q", tt.glob, got, tt.want)
+		}
+	}
+}
+
+func TestCompile_Errors(t *testing.T) {
+	tests := []struct {
+		pattern  string
+		regex    bool
+		expected string
+	}{
+		{"", false, "empty pattern"},
+		{"/etc/*.nfo", false, "relative to the release folder"},
+		{"../*.nfo", false, `invalid path segment ".."`},
+		{"Sample//*.mkv", false, `invalid path segment ""`},
+		{"*.{mkv,mp4", false, "unbalanced braces, { without }"},
+		{"*.mkv}", false, "unbalanced braces, } without {"},
+		{"*.[mkv", false, "syntax error in pattern"},
+		{"{a,b}{c,d}{e,f}{g,h}{i,j}{k,l}{m,n}{o,p}{q,r}{s,t}{u,v}", false, "more than 1024 patterns"},
+		{"(unclosed", true, `invalid regex "(unclosed"`},
+	}
+
+	for _, tt := range tests {
+		_, err := Compile(tt.pattern, tt.regex)
+		if err == nil || !strings.Contains(err.Error(), tt.expected) {
+			t.Errorf("Compile(%q) error = %v, want one containing %q", tt.pattern, err, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,309 @@
+package preset
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/autobrr/sfvbrr/internal/pathmatch"
+	"gopkg.in/yaml.v3"
+)
+
+// Severities of the problems found in preset files
+const (
+	SeverityError   = "error"   // The preset file can't be loaded
+	SeverityWarning = "warning" // The preset file loads, but probably doesn't do what was intended
+)
+
+// Problem is a single problem found in a preset file
+type Problem struct {
+	File     string `json:"file" yaml:"file"`
+	Line     int    `json:"line,omitempty" yaml:"line,omitempty"` // 0 if the problem concerns the whole file
+	Column   int    `json:"column,omitempty" yaml:"column,omitempty"`
+	Severity string `json:"severity" yaml:"severity"`
+	Message  string `json:"message" yaml:"message"`
+}
+
+// String formats the problem like a compiler message, e.g. "presets.yaml:12:9: min 3 is greater than max 1"
+func (p Problem) String() string {
+	location := p.File
+	if p.Line > 0 {
+		location += ":" + strconv.Itoa(p.Line)
+		if p.Column > 0 {
+			location += ":" + strconv.Itoa(p.Column)
+		}
+	}
+	if p.Severity == SeverityWarning {
+		return location + ": warning: " + p.Message
+	}
+	return location + ": " + p.Message
+}
+
+// Problems is a list of problems, returned as the error of preset files that can't be loaded
+type Problems []Problem
+
+// Errors returns the problems with error severity
+func (p Problems) Errors() Problems {
+	var errs Problems
+	for _, problem := range p {
+		if problem.Severity == SeverityError {
+			errs = append(errs, problem)
+		}
+	}
+	return errs
+}
+
+// Error lists the problems, one per line
+func (p Problems) Error() string {
+	if len(p) == 1 {
+		return p[0].String()
+	}
+	lines := make([]string, len(p))
+	for i, problem := range p {
+		lines[i] = "  " + problem.String()
+	}
+	return fmt.Sprintf("%d problems in preset file:\n%s", len(p), strings.Join(lines, "\n"))
+}
+
+// Lint loads a preset file like LoadPresets and returns every problem found in it, its
+// includes and its overlay, including warnings that don't prevent it from loading
+func Lint(presetPath string) (Problems, error) {
+	absPath, err := findPresetFile(presetPath)
+	if err != nil {
+		return nil, err
+	}
+	_, problems := loadConfig(absPath)
+	slices.SortStableFunc(problems, func(a, b Problem) int {
+		return cmp.Or(cmp.Compare(a.File, b.File), cmp.Compare(a.Line, b.Line), cmp.Compare(a.Column, b.Column))
+	})
+	return problems, nil
+}
+
+// source is the YAML node a value was decoded from
+type source struct {
+	file string
+	node *yaml.Node // nil if the position is unknown
+}
+
+// field returns the source of the value of a key of a mapping node
+func (s source) field(key string) source {
+	if s.node == nil || s.node.Kind != yaml.MappingNode {
+		return source{file: s.file}
+	}
+	for i := 0; i+1 < len(s.node.Content); i += 2 {
+		if s.node.Content[i].Value == key {
+			return source{file: s.file, node: s.node.Content[i+1]}
+		}
+	}
+	return source{file: s.file}
+}
+
+// key returns the source of a key of a mapping node
+func (s source) key(key string) source {
+	if s.node != nil && s.node.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(s.node.Content); i += 2 {
+			if s.node.Content[i].Value == key {
+				return source{file: s.file, node: s.node.Content[i]}
+			}
+		}
+	}
+	return source{file: s.file}
+}
+
+// index returns the source of an element of a sequence node
+func (s source) index(i int) source {
+	if s.node == nil || s.node.Kind != yaml.SequenceNode || i >= len(s.node.Content) {
+		return source{file: s.file}
+	}
+	return source{file: s.file, node: s.node.Content[i]}
+}
+
+// problem returns a problem positioned at the value of key, falling back to the node
+// itself if the key is not set
+func (s source) problem(key string) Problem {
+	node := s.node
+	if key != "" {
+		if value := s.field(key).node; value != nil {
+			node = value
+		}
+	}
+	problem := Problem{File: s.file}
+	if node != nil {
+		problem.Line, problem.Column = node.Line, node.Column
+	}
+	return problem
+}
+
+var (
+	// yamlLineRegex matches the line number of YAML syntax and type errors
+	yamlLineRegex = regexp.MustCompile(`^(?:yaml: )?line (\d+): (.*)$`)
+	// unknownFieldRegex matches the unknown field errors of strict decoding
+	unknownFieldRegex = regexp.MustCompile(`^field (\S+) not found in type`)
+	// yamlTypeNames replaces the Go type names in YAML errors by what they are in the file
+	yamlTypeNames = strings.NewReplacer(
+		"type preset.rawConfig", "the preset file",
+		"type preset.rawCategory", "category",
+		"type preset.rawRule", "rule",
+		"type preset.rawAction", "action",
+		"type *preset.ContentRule", "content",
+		"type preset.ContentRule", "content",
+		"type *preset.AudioRule", "audio",
+		"type *preset.Naming", "naming",
+		"type preset.Naming", "naming",
+		"type preset.AudioRule", "audio",
+	)
+)
+
+// yamlProblems converts YAML syntax and type errors into problems. The column is looked
+// up in the parsed document where possible, as the YAML errors only carry the line.
+func yamlProblems(path string, root *yaml.Node, err error) Problems {
+	messages := []string{err.Error()}
+	var typeErr *yaml.TypeError
+	if errors.As(err, &typeErr) {
+		messages = typeErr.Errors
+	}
+
+	problems := make(Problems, 0, len(messages))
+	for _, message := range messages {
+		problem := Problem{File: path, Severity: SeverityError, Message: strings.TrimPrefix(message, "yaml: ")}
+		if m := yamlLineRegex.FindStringSubmatch(message); m != nil {
+			problem.Line, _ = strconv.Atoi(m[1])
+			problem.Message = yamlTypeNames.Replace(m[2])
+			key := ""
+			if f := unknownFieldRegex.FindStringSubmatch(m[2]); f != nil {
+				key = f[1]
+				problem.Message = fmt.Sprintf("unknown field %q in %s", key, strings.TrimPrefix(problem.Message, "field "+key+" not found in "))
+			}
+			problem.Column = columnOf(root, problem.Line, key)
+		}
+		problems = append(problems, problem)
+	}
+	return problems
+}
+
+// columnOf returns the column of the first node on a line, or of the mapping key with the
+// given name on that line
+func columnOf(node *yaml.Node, line int, key string) int {
+	if node == nil {
+		return 0
+	}
+	if node.Line == line && (key == "" || node.Value == key) && node.Kind == yaml.ScalarNode {
+		return node.Column
+	}
+	for _, child := range node.Content {
+		if column := columnOf(child, line, key); column > 0 {
+			return column
+		}
+	}
+	return 0
+}
+
+// validateCategory checks the settings and rules of a category as written in a file
+func (l *loader) validateCategory(name string, catRules *rawCategory) {
+	for i, check := range catRules.Checks {
+		switch check {
+		case CheckRules, CheckSFV, CheckZIP, CheckRAR:
+		default:
+			l.errorf(catRules.src.field("checks").index(i), "", "category %q has unknown check %q (expected one of rules, sfv, zip, rar)", name, check)
+		}
+	}
+	for _, rule := range catRules.Rules {
+		l.validateRule(rule)
+	}
+	for _, set := range catRules.RuleSets {
+		l.validateRuleSet(name, set)
+	}
+	for _, subfolder := range catRules.Subfolders {
+		if subfolder.Pattern == "" {
+			l.errorf(subfolder.src, "", "subfolder of category %q has no pattern", name)
+		} else if _, err := pathmatch.Compile(subfolder.Pattern, subfolder.Regex); err != nil {
+			l.errorf(subfolder.src, "pattern", "%v", err)
+		}
+	}
+	if catRules.Naming != nil {
+		if err := validateNaming(catRules.Naming); err != nil {
+			l.errorf(catRules.src, "naming", "%v", err)
+		}
+	}
+}
+
+// validateRuleSet checks the conditions and rules of a rule set as written in a file
+func (l *loader) validateRuleSet(category string, set rawRuleSet) {
+	if len(set.When) == 0 {
+		l.errorf(set.src, "when", "rule set of category %q has no when conditions", category)
+	}
+	for _, attribute := range slices.Sorted(maps.Keys(set.When)) {
+		if _, known := releaseAttributes[attribute]; !known {
+			l.errorf(set.src.field("when").key(attribute), "", "unknown release attribute %q (expected one of %s)", attribute, strings.Join(ReleaseAttributes, ", "))
+			continue
+		}
+		if len(set.When[attribute]) == 0 {
+			l.errorf(set.src.field("when"), attribute, "condition %q has no values", attribute)
+		}
+	}
+	for _, rule := range set.Rules {
+		l.validateRule(rule)
+	}
+}
+
+// validateRule checks a rule as written in a file
+func (l *loader) validateRule(rule rawRule) {
+	if rule.Remove {
+		if rule.ID == "" {
+			l.errorf(rule.src, "remove", "remove requires the id of the rule to remove")
+		}
+		return
+	}
+
+	if rule.Pattern == "" {
+		l.errorf(rule.src, "", "rule has no pattern")
+	} else if _, err := pathmatch.Compile(rule.Pattern, rule.Regex); err != nil {
+		l.errorf(rule.src, "pattern", "%v", err)
+	}
+
+	switch rule.Type {
+	case "", "file", "dir":
+	default:
+		l.errorf(rule.src, "type", "unknown type %q (expected file or dir)", rule.Type)
+	}
+
+	if rule.Min < 0 {
+		l.errorf(rule.src, "min", "min %d is negative", rule.Min)
+	}
+	if rule.Max < 0 {
+		l.errorf(rule.src, "max", "max %d is negative", rule.Max)
+	}
+	if rule.Max > 0 && rule.Min > rule.Max {
+		l.errorf(rule.src, "max", "min %d is greater than max %d", rule.Min, rule.Max)
+	}
+
+	switch rule.Verify {
+	case "":
+	case VerifyAudio, VerifyContainer, VerifyDisks, VerifyPlaylist, VerifyRAR:
+		if rule.Type == "dir" {
+			l.errorf(rule.src, "verify", "verify: %s can't be used with dir rules", rule.Verify)
+		}
+	default:
+		l.errorf(rule.src, "verify", "unknown verify %q (expected audio, container, disks, playlist or rar)", rule.Verify)
+	}
+
+	if rule.Content != nil {
+		if err := validateContentRule(rule.Rule); err != nil {
+			l.errorf(rule.src, "content", "%v", err)
+		}
+	}
+
+	if rule.Audio != nil {
+		if rule.Verify != VerifyAudio {
+			l.errorf(rule.src, "audio", "audio assertions require verify: audio")
+		}
+		if rule.Audio.MinBitrate < 0 {
+			l.errorf(rule.src.field("audio"), "min_bitrate", "min_bitrate %d is negative", rule.Audio.MinBitrate)
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package preset
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+func TestLint(t *testing.T) {
+	path := writePreset(t, t.TempDir(), "presets.yaml", `schema_version: 2
+rules:
+  movie:
+    deny_unexpected: true
+    checks: [rules, sfvs]
+    rules:
+      - pattern: "*.nfo"
+        typ: dir
+      - pattern: "Sample/*.{mkv,mp4"
+      - pattern: "(unclosed"
+        regex: true
+      - pattern: "*.rar"
+        min: 3
+        max: 1
+      - pattern: "*.r??"
+        type: rar
+`)
+
+	problems, err := Lint(path)
+	if err != nil {
+		t.Fatalf("Failed to lint presets: %v", err)
+	}
+
+	expected := []struct {
+		line, column int
+		message      string
+	}{
+		{5, 21, `category "movie" has unknown check "sfvs" (expected one of rules, sfv, zip, rar)`},
+		{8, 9, `unknown field "typ" in rule`},
+		{9, 18, `invalid glob "Sample/*.{mkv,mp4": unbalanced braces, { without }`},
+		{10, 18, "invalid regex \"(unclosed\": error parsing regexp: missing closing ): `(unclosed`"},
+		{14, 14, "min 3 is greater than max 1"},
+		{16, 15, `unknown type "rar" (expected file or dir)`},
+	}
+	if len(problems) != len(expected) {
+		t.Fatalf("Expected %d problems, got %d: %v", len(expected), len(problems), problems)
+	}
+	for i, want := range expected {
+		got := problems[i]
+		if got.File != path || got.Line != want.line || got.Column != want.column || got.Message != want.message || got.Severity != SeverityError {
+			t.Errorf("Expected %d:%d: %s, got %s", want.line, want.column, want.message, got)
+		}
+	}
+
+	_, err = LoadPresets(path)
+	var errs Problems
+	if !errors.As(err, &errs) || len(errs) != len(expected) {
+		t.Errorf("Expected LoadPresets to return all %d problems, got %v", len(expected), err)
+	}
+}
+
+func TestLint_Default(t *testing.T) {
+	path := writePreset(t, t.TempDir(), "presets.yaml", string(defaultPresetsYAML))
+
+	problems, err := Lint(path)
+	if err != nil {
+		t.Fatalf("Failed to lint presets: %v", err)
+	}
+	if len(problems) > 0 {
+		t.Errorf("Expected no problems in the default presets, got %v", problems)
+	}
+}
+
+func TestMigrate(t *testing.T) {
+	for version := 1; version < CurrentSchemaVersion; version++ {
+		if _, ok := migrations[version]; !ok {
+			t.Errorf("No migration from schema_version %d", version)
+		}
+	}
+
+	dir := t.TempDir()
+	path := writePreset(t, dir, "presets.yaml", `schema_version: 1
+rules:
+  game:
+    deny_unexpected: true
+    rules:
+      - pattern: "*.rar"
+`)
+
+	problems, err := Lint(path)
+	if err != nil {
+		t.Fatalf("Failed to lint presets: %v", err)
+	}
+	if len(problems) != 1 || problems[0].Severity != SeverityWarning || problems[0].Line != 1 {
+		t.Errorf("Expected one outdated schema_version warning, got %v", problems)
+	}
+
+	config, err := LoadPresets(path)
+	if err != nil {
+		t.Fatalf("Failed to load presets: %v", err)
+	}
+	if config.SchemaVersion != CurrentSchemaVersion || len(config.Rules["game"].Rules) != 1 {
+		t.Errorf("Expected the file to be migrated to schema_version %d, got %+v", CurrentSchemaVersion, config)
+	}
+
+	newer := writePreset(t, dir, "newer.yaml", "schema_version: 99\nrules: {}\n")
+	if _, err := LoadPresets(newer); err == nil {
+		t.Error("Expected an error for a newer schema_version")
+	}
+
+	overlay := writePreset(t, dir, "overlay.yaml", "rules: {}\n")
+	config, err = LoadPresets(overlay)
+	if err != nil {
+		t.Fatalf("Failed to load presets: %v", err)
+	}
+	if config.SchemaVersion != CurrentSchemaVersion {
+		t.Errorf("Expected schema_version %d for a file without one, got %d", CurrentSchemaVersion, config.SchemaVersion)
+	}
+
+	if _, err := LoadPresets(filepath.Join(dir, "missing.yaml")); err == nil {
+		t.Error("Expected an error for a missing file")
+	}
+}
@@ -0,0 +1,46 @@
+package preset
+
+import "strings"
+
+// migration upgrades a preset file from one schema version to the next
+type migration struct {
+	description string
+	migrate     func(raw *rawConfig) // nil if files of the older version are read unchanged
+}
+
+// migrations upgrade preset files, indexed by the version they upgrade from. Every
+// version below CurrentSchemaVersion needs an entry.
+var migrations = map[int]migration{
+	1: {"added include, extends, overlays, checks, rule sets, subfolders, verifications, content and audio assertions, naming policies and actions", nil},
+}
+
+// migrate upgrades a file to the current schema version. Files without a schema_version,
+// such as overlays, are taken to be current. It returns false if the file can't be used.
+func (l *loader) migrate(raw *rawConfig) bool {
+	version := raw.SchemaVersion
+	switch {
+	case version == 0:
+		raw.SchemaVersion = CurrentSchemaVersion
+		return true
+	case version < 0:
+		l.errorf(raw.src, "schema_version", "invalid schema_version %d", version)
+		return false
+	case version > CurrentSchemaVersion:
+		l.errorf(raw.src, "schema_version", "schema_version %d is newer than the supported version %d, update sfvbrr", version, CurrentSchemaVersion)
+		return false
+	case version == CurrentSchemaVersion:
+		return true
+	}
+
+	var changes []string
+	for from := version; from < CurrentSchemaVersion; from++ {
+		m := migrations[from]
+		if m.migrate != nil {
+			m.migrate(raw)
+		}
+		changes = append(changes, m.description)
+	}
+	l.report(SeverityWarning, raw.src, "schema_version", "schema_version %d is outdated and was migrated to %d (%s), update the file", version, CurrentSchemaVersion, strings.Join(changes, "; "))
+	raw.SchemaVersion = CurrentSchemaVersion
+	return true
+}
@@ -0,0 +1,96 @@
+package preset
+
+import (
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/moistari/rls"
+)
+
+// dupeSuffixRegex matches the suffixes of dupes and copies of a release folder, such as
+// "_1", " (1)" and ".DUPE"
+var dupeSuffixRegex = regexp.MustCompile(`(?i)(?:_\d+|\s*\(\d+\)|[._-]dupe)$`)
+
+// Naming is the policy the release names of a category must follow
+type Naming struct {
+	Charset      string            `yaml:"charset,omitempty"`       // Allowed characters as the body of a regex character class, e.g. "A-Za-z0-9._-" (empty = any)
+	NoSpaces     bool              `yaml:"no_spaces,omitempty"`     // The name must not contain spaces
+	RequireGroup bool              `yaml:"require_group,omitempty"` // The name must end with a -GROUP suffix
+	Require      []string          `yaml:"require,omitempty"`       // Release attributes that must be present, e.g. resolution
+	Ban          map[string]Values `yaml:"ban,omitempty"`           // Release attribute values that are not allowed, e.g. "other: READNFO"
+	DenyDupes    bool              `yaml:"deny_dupes,omitempty"`    // The name must not end with a dupe suffix such as _1, (1) or .DUPE
+}
+
+// Check returns the violations of the naming policy by a release name. A dupe suffix is
+// stripped before the other checks, so that "-GRP_1" is only reported as a dupe.
+func (n *Naming) Check(name string) []string {
+	var issues []string
+	if suffix := dupeSuffixRegex.FindString(name); suffix != "" {
+		if n.DenyDupes {
+			issues = append(issues, fmt.Sprintf("dupe suffix %q", strings.TrimSpace(suffix)))
+		}
+		name = strings.TrimSuffix(name, suffix)
+	}
+
+	if n.NoSpaces && strings.Contains(name, " ") {
+		issues = append(issues, "contains spaces")
+	}
+	if n.Charset != "" {
+		if disallowed, err := regexp.Compile("[^" + n.Charset + "]"); err == nil {
+			var chars []string
+			for _, char := range disallowed.FindAllString(name, -1) {
+				if !slices.Contains(chars, char) && !(n.NoSpaces && char == " ") {
+					chars = append(chars, char)
+				}
+			}
+			if len(chars) > 0 {
+				issues = append(issues, fmt.Sprintf("contains characters outside [%s]: %q", n.Charset, strings.Join(chars, "")))
+			}
+		}
+	}
+
+	release := rls.ParseString(name)
+	if n.RequireGroup && release.Group == "" {
+		issues = append(issues, "has no -GROUP suffix")
+	}
+	for _, attribute := range n.Require {
+		if value, known := releaseAttributes[attribute]; known && len(value(release)) == 0 {
+			issues = append(issues, fmt.Sprintf("has no %s tag", attribute))
+		}
+	}
+	for _, attribute := range slices.Sorted(maps.Keys(n.Ban)) {
+		value, known := releaseAttributes[attribute]
+		if !known {
+			continue
+		}
+		for _, have := range value(release) {
+			if slices.ContainsFunc(n.Ban[attribute], func(banned string) bool { return strings.EqualFold(have, banned) }) {
+				issues = append(issues, fmt.Sprintf("has banned %s tag %q", attribute, have))
+			}
+		}
+	}
+	return issues
+}
+
+// validateNaming checks the settings of a naming policy
+func validateNaming(naming *Naming) error {
+	if naming.Charset != "" {
+		if _, err := regexp.Compile("[^" + naming.Charset + "]"); err != nil {
+			return fmt.Errorf("invalid charset %q: %w", naming.Charset, err)
+		}
+	}
+	for _, attribute := range naming.Require {
+		if _, known := releaseAttributes[attribute]; !known {
+			return fmt.Errorf("unknown release attribute %q in require (expected one of %s)", attribute, strings.Join(ReleaseAttributes, ", "))
+		}
+	}
+	for _, attribute := range slices.Sorted(maps.Keys(naming.Ban)) {
+		if _, known := releaseAttributes[attribute]; !known {
+			return fmt.Errorf("unknown release attribute %q in ban (expected one of %s)", attribute, strings.Join(ReleaseAttributes, ", "))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+package preset
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNamingCheck(t *testing.T) {
+	naming := &Naming{
+		Charset:      "A-Za-z0-9._-",
+		NoSpaces:     true,
+		RequireGroup: true,
+		Require:      []string{"resolution"},
+		Ban:          map[string]Values{"other": {"readnfo"}, "source": {"CAM"}},
+		DenyDupes:    true,
+	}
+
+	tests := []struct {
+		name     string
+		expected []string
+	}{
+		{"Movie.2020.1080p.BluRay.x264-GRP", nil},
+		{"Movie.2020.1080p.BluRay.x264-GRP_1", []string{`dupe suffix "_1"`}},
+		{"Movie.2020.1080p.BluRay.x264-GRP (1)", []string{`dupe suffix "(1)"`}},
+		{"Movie.2020.1080p.BluRay.x264-GRP.DUPE", []string{`dupe suffix ".DUPE"`}},
+		{"Movie 2020 1080p BluRay x264-GRP", []string{"contains spaces"}},
+		{"Movie.2020.1080p.BluRay.x264-GRP!", []string{`contains characters outside [A-Za-z0-9._-]: "!"`}},
+		{"Movie.2020.1080p.BluRay.x264", []string{"has no -GROUP suffix"}},
+		{"Movie.2020.BluRay.x264-GRP", []string{"has no resolution tag"}},
+		{"Movie.2020.READNFO.1080p.CAM.x264-GRP", []string{`has banned other tag "READNFO"`, `has banned source tag "CAM"`}},
+	}
+	for _, tt := range tests {
+		if issues := naming.Check(tt.name); !reflect.DeepEqual(issues, tt.expected) {
+			t.Errorf("%s: expected %q, got %q", tt.name, tt.expected, issues)
+		}
+	}
+
+	// Dupe suffixes are only stripped without deny_dupes
+	if issues := (&Naming{RequireGroup: true}).Check("Movie.2020.1080p.BluRay.x264-GRP_1"); len(issues) != 0 {
+		t.Errorf("Expected no issues without deny_dupes, got %q", issues)
+	}
+}
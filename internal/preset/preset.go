@@ -4,30 +4,146 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 
-	"gopkg.in/yaml.v3"
+	"github.com/autobrr/sfvbrr/internal/nfo"
+	"github.com/autobrr/sfvbrr/internal/pathmatch"
+)
+
+// Checks that can be run on a release by the check command
+const (
+	CheckRules = "rules" // Validate the folder contents against the category rules
+	CheckSFV   = "sfv"   // Validate the CRC-32 checksums of every SFV file
+	CheckZIP   = "zip"   // Test the integrity of every ZIP file
+	CheckRAR   = "rar"   // Validate every RAR volume set
+)
+
+// DefaultChecks are the checks run for categories that don't configure any
+var DefaultChecks = []string{CheckRules, CheckSFV, CheckZIP}
+
+// Action types run on a release once its verdict is known
+const (
+	ActionMove    = "move"    // Move the release folder into another directory
+	ActionMarker  = "marker"  // Create an empty marker file inside the release folder
+	ActionSymlink = "symlink" // Create a symlink to the release folder, e.g. "(incomplete)-{release}"
+	ActionExec    = "exec"    // Run a command with the result as JSON on stdin
+)
+
+// Verdicts an action can be run on
+const (
+	OnPass   = "pass"
+	OnFail   = "fail"
+	OnAlways = "always"
+)
+
+// Error types a failed release is classified by, used to select actions
+const (
+	ErrorRules      = "rules"      // A category rule failed (missing or too many files)
+	ErrorUnexpected = "unexpected" // Files not matching any rule of a deny_unexpected category
+	ErrorMissing    = "missing"    // Files listed in an SFV or RAR volumes are missing
+	ErrorChecksum   = "checksum"   // CRC-32 mismatches of SFV files or ZIP entries
+	ErrorOrphans    = "orphans"    // Files not listed in the SFV
+	ErrorArchive    = "archive"    // Broken or unreadable ZIP or RAR archives
+	ErrorMedia      = "media"      // Broken or truncated video containers or audio files of verify: container and audio rules
+	ErrorContent    = "content"    // NFO or DIZ content that fails the content assertions of a rule
+	ErrorPlaylist   = "playlist"   // Playlists, SFV files and track numbers of verify: playlist rules that don't match
+	ErrorNaming     = "naming"     // Release names that violate the naming policy of the category
+)
+
+// ErrorTypes lists all error types in the order they are reported
+var ErrorTypes = []string{ErrorRules, ErrorUnexpected, ErrorMissing, ErrorChecksum, ErrorOrphans, ErrorArchive, ErrorMedia, ErrorContent, ErrorPlaylist, ErrorNaming}
+
+// Verifications a rule can run on its matching files
+const (
+	VerifyAudio     = "audio"     // Check the MP3 frame sync and ID3v2 tag or the FLAC STREAMINFO of audio files
+	VerifyContainer = "container" // Check the Matroska or MP4 container structure
+	VerifyDisks     = "disks"     // Cross-check the file_id.diz disk numbers and inner RAR volumes of 0-day ZIP files
+	VerifyPlaylist  = "playlist"  // Cross-check the M3U playlists of music releases with the audio files and SFV files
+	VerifyRAR       = "rar"       // Validate the RAR volume sets of the matching volumes
 )
 
 // Rule represents a single validation rule
 type Rule struct {
-	Pattern     string `yaml:"pattern"`
-	Type        string `yaml:"type,omitempty"` // "file" (default) or "dir"
-	Min         int    `yaml:"min,omitempty"`
-	Max         int    `yaml:"max,omitempty"`
-	Description string `yaml:"description,omitempty"`
-	Regex       bool   `yaml:"regex,omitempty"` // If true, pattern is treated as regex instead of glob
+	ID          string       `yaml:"id,omitempty"` // Identifies the rule for categories that extend or override it
+	Pattern     string       `yaml:"pattern"`
+	Type        string       `yaml:"type,omitempty"` // "file" (default) or "dir"
+	Min         int          `yaml:"min,omitempty"`
+	Max         int          `yaml:"max,omitempty"`
+	Description string       `yaml:"description,omitempty"`
+	Regex       bool         `yaml:"regex,omitempty"`   // If true, pattern is treated as regex instead of glob
+	Verify      string       `yaml:"verify,omitempty"`  // "audio" (MP3/FLAC headers), "container" (video file structure), "disks" (0-day ZIP disk set), "playlist" (music track list) or "rar" (RAR volume sets)
+	Content     *ContentRule `yaml:"content,omitempty"` // Assertions on the text of every matching NFO or DIZ file
+	Audio       *AudioRule   `yaml:"audio,omitempty"`   // Assertions on the audio files of verify: audio rules
+}
+
+// AudioRule holds the assertions on the audio files matched by a verify: audio rule
+type AudioRule struct {
+	VerifyMD5      bool `yaml:"verify_md5,omitempty"`       // Decode FLAC files and compare the audio with the STREAMINFO MD5
+	SameBitrate    bool `yaml:"same_bitrate,omitempty"`     // All MP3 files must share one bitrate (or all be VBR)
+	SameSampleRate bool `yaml:"same_sample_rate,omitempty"` // All files must share one sample rate
+	MinBitrate     int  `yaml:"min_bitrate,omitempty"`      // Minimum average bitrate in kbps (0 = any)
+}
+
+// ContentRule holds the assertions on the parsed content of NFO and file_id.diz files
+type ContentRule struct {
+	ReleaseMatchesFolder bool     `yaml:"release_matches_folder,omitempty"` // The release name of the folder must appear in the text
+	GroupMatchesFolder   bool     `yaml:"group_matches_folder,omitempty"`   // The group must be the group of the folder name
+	RequireLinks         []string `yaml:"require_links,omitempty"`          // Link sites that must be present: "imdb", "tvdb" or "discogs"
+	DisksMatch           string   `yaml:"disks_match,omitempty"`            // Glob whose number of matching files must equal the disk count, e.g. "*.zip"
 }
 
 // CategoryRules represents rules and settings for a category
 type CategoryRules struct {
-	DenyUnexpected bool   `yaml:"deny_unexpected"`
-	Rules          []Rule `yaml:"rules"`
+	Extends        string      `yaml:"extends,omitempty"` // Category the rules and settings were inherited from
+	DenyUnexpected bool        `yaml:"deny_unexpected"`
+	Checks         []string    `yaml:"checks,omitempty"` // Checks run by the check command (empty = DefaultChecks)
+	Rules          []Rule      `yaml:"rules"`
+	RuleSets       []RuleSet   `yaml:"rule_sets,omitempty"`  // Rules that only apply to releases matching conditions
+	Subfolders     []Subfolder `yaml:"subfolders,omitempty"` // Subfolders validated as releases of their own
+	Naming         *Naming     `yaml:"naming,omitempty"`     // Policy the release names must follow (nil = any name)
 }
 
+// Subfolder selects the subfolders of a release that are validated as releases of their
+// own, such as the CD1 and CD2 folders of a multi-disc release or the episodes of a season pack
+type Subfolder struct {
+	ID          string `yaml:"id,omitempty"`
+	Pattern     string `yaml:"pattern"`
+	Regex       bool   `yaml:"regex,omitempty"`    // If true, pattern is treated as regex instead of glob
+	Category    string `yaml:"category,omitempty"` // Category whose rules apply (empty = detected from the subfolder name)
+	Description string `yaml:"description,omitempty"`
+}
+
+// RuleSet holds changes to the rules of a category that apply to the releases whose
+// name matches all of its conditions, e.g. "source: BluRay"
+type RuleSet struct {
+	ID             string            `yaml:"id,omitempty"`
+	When           map[string]Values `yaml:"when"`                      // Conditions on the release attributes parsed from the folder name
+	DenyUnexpected *bool             `yaml:"deny_unexpected,omitempty"` // Overrides deny_unexpected of the category (nil = keep)
+	Rules          []Rule            `yaml:"rules,omitempty"`           // Rules that replace the category rule with the same id, or are added
+	Remove         []string          `yaml:"remove,omitempty"`          // Ids of category rules that don't apply
+}
+
+// Action represents a follow-up action run on a release once its verdict is known
+type Action struct {
+	Type       string   `yaml:"type"`                 // "move", "marker", "symlink" or "exec"
+	On         string   `yaml:"on,omitempty"`         // "pass", "fail" (default) or "always"
+	Errors     []string `yaml:"errors,omitempty"`     // Only run on failures with one of these error types
+	Categories []string `yaml:"categories,omitempty"` // Only run for these categories (empty = all)
+	Target     string   `yaml:"target,omitempty"`     // Destination directory (move), file name (marker) or link name (symlink)
+	Dir        string   `yaml:"dir,omitempty"`        // Directory of the symlink (default: next to the release)
+	Command    []string `yaml:"command,omitempty"`    // Command and arguments (exec)
+}
+
+// CurrentSchemaVersion is the schema_version of the preset files of this release. Files
+// with an older version are migrated when they are loaded.
+const CurrentSchemaVersion = 2
+
 // PresetConfig represents the entire preset configuration
 type PresetConfig struct {
 	SchemaVersion int                       `yaml:"schema_version"`
 	Rules         map[string]*CategoryRules `yaml:"rules"`
+	Actions       []Action                  `yaml:"actions,omitempty"`
 }
 
 // getDefaultConfigPath returns the default configuration file path (cross-platform)
@@ -92,19 +208,38 @@ func expandPath(path string) (string, error) {
 	return path, nil
 }
 
-// LoadPresets loads the preset configuration from a YAML file
+// LoadPresets loads the preset configuration from a YAML file, with its includes and
+// overlay merged and the categories that extend other categories flattened. Files are
+// decoded strictly and validated; if any of them has errors, all errors are returned as
+// Problems.
 func LoadPresets(presetPath string) (*PresetConfig, error) {
+	absPath, err := findPresetFile(presetPath)
+	if err != nil {
+		return nil, err
+	}
+
+	// Read the file with its includes and overlay, and resolve the extended categories
+	config, problems := loadConfig(absPath)
+	if errs := problems.Errors(); len(errs) > 0 {
+		return nil, errs
+	}
+	return config, nil
+}
+
+// findPresetFile returns the absolute path of the preset file, looking in the default
+// locations if no path is given
+func findPresetFile(presetPath string) (string, error) {
 	// If no path provided, try default location
 	if presetPath == "" {
 		// Initialize default config on first run
 		if err := initializeDefaultConfig(); err != nil {
-			return nil, fmt.Errorf("failed to initialize default config: %w", err)
+			return "", fmt.Errorf("failed to initialize default config: %w", err)
 		}
 
 		// Get the default config path
 		defaultPath, err := getDefaultConfigPath()
 		if err != nil {
-			return nil, fmt.Errorf("failed to get default config path: %w", err)
+			return "", fmt.Errorf("failed to get default config path: %w", err)
 		}
 
 		// Try to find presets.yaml in common locations
@@ -122,61 +257,80 @@ func LoadPresets(presetPath string) (*PresetConfig, error) {
 		}
 
 		if presetPath == "" {
-			return nil, fmt.Errorf("preset file not found in default locations")
+			return "", fmt.Errorf("preset file not found in default locations")
 		}
 	} else {
 		// Expand ~ in provided path
 		var err error
 		presetPath, err = expandPath(presetPath)
 		if err != nil {
-			return nil, err
+			return "", err
 		}
 	}
 
 	// Resolve absolute path
 	absPath, err := filepath.Abs(presetPath)
 	if err != nil {
-		return nil, fmt.Errorf("failed to resolve preset path: %w", err)
+		return "", fmt.Errorf("failed to resolve preset path: %w", err)
 	}
+	return absPath, nil
+}
 
-	// Read file
-	data, err := os.ReadFile(absPath)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read preset file: %w", err)
+// validateContentRule checks the content assertions of a rule
+func validateContentRule(rule Rule) error {
+	if rule.Type == "dir" {
+		return fmt.Errorf("content can't be used with dir rules")
 	}
-
-	// First, parse as raw structure to validate required fields
-	var rawConfig struct {
-		Rules map[string]interface{} `yaml:"rules"`
+	for _, site := range rule.Content.RequireLinks {
+		if !slices.Contains(nfo.Sites, site) {
+			return fmt.Errorf("unknown link site %q (expected one of %s)", site, strings.Join(nfo.Sites, ", "))
+		}
 	}
-	if err := yaml.Unmarshal(data, &rawConfig); err != nil {
-		return nil, fmt.Errorf("failed to parse preset file: %w", err)
+	if rule.Content.DisksMatch != "" {
+		if _, err := pathmatch.Compile(rule.Content.DisksMatch, false); err != nil {
+			return fmt.Errorf("disks_match: %w", err)
+		}
 	}
+	return nil
+}
 
-	// Validate that deny_unexpected is present in all categories
-	for category, rawValue := range rawConfig.Rules {
-		if rawValue == nil {
-			return nil, fmt.Errorf("category %q has no configuration", category)
+// validateAction checks an action configuration for unknown or missing settings
+func validateAction(action Action, rules map[string]*CategoryRules) error {
+	switch action.Type {
+	case ActionMove, ActionMarker:
+		if action.Target == "" {
+			return fmt.Errorf("%s action requires a target", action.Type)
 		}
-
-		// Check if it's a map (new format)
-		if categoryMap, ok := rawValue.(map[string]interface{}); ok {
-			if _, exists := categoryMap["deny_unexpected"]; !exists {
-				return nil, fmt.Errorf("category %q is missing required field 'deny_unexpected'", category)
-			}
-		} else {
-			// If it's not a map, it's invalid format
-			return nil, fmt.Errorf("category %q has invalid format: expected map with 'deny_unexpected' and 'rules'", category)
+	case ActionSymlink:
+	case ActionExec:
+		if len(action.Command) == 0 {
+			return fmt.Errorf("exec action requires a command")
 		}
+	default:
+		return fmt.Errorf("unknown action type %q (expected one of move, marker, symlink, exec)", action.Type)
 	}
 
-	// Now parse into the actual config structure
-	var config PresetConfig
-	if err := yaml.Unmarshal(data, &config); err != nil {
-		return nil, fmt.Errorf("failed to parse preset file: %w", err)
+	switch action.On {
+	case "", OnPass, OnFail, OnAlways:
+	default:
+		return fmt.Errorf("unknown verdict %q (expected one of pass, fail, always)", action.On)
 	}
 
-	return &config, nil
+	if len(action.Errors) > 0 && action.On != "" && action.On != OnFail {
+		return fmt.Errorf("errors can only be used with on: fail")
+	}
+	for _, errorType := range action.Errors {
+		if !slices.Contains(ErrorTypes, errorType) {
+			return fmt.Errorf("unknown error type %q (expected one of %s)", errorType, strings.Join(ErrorTypes, ", "))
+		}
+	}
+
+	for _, category := range action.Categories {
+		if _, exists := rules[category]; !exists {
+			return fmt.Errorf("unknown category %q", category)
+		}
+	}
+	return nil
 }
 
 // GetRulesForCategory returns the rules for a specific category
@@ -196,3 +350,12 @@ func (c *PresetConfig) GetDenyUnexpected(category string) bool {
 	}
 	return catRules.DenyUnexpected
 }
+
+// GetChecksForCategory returns the checks to run for a category
+func (c *PresetConfig) GetChecksForCategory(category string) []string {
+	catRules, exists := c.Rules[category]
+	if !exists || len(catRules.Checks) == 0 {
+		return DefaultChecks
+	}
+	return catRules.Checks
+}
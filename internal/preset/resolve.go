@@ -0,0 +1,470 @@
+package preset
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/moistari/rls"
+	"gopkg.in/yaml.v3"
+)
+
+// IncludeDefault is the include name of the embedded default preset configuration
+const IncludeDefault = "default"
+
+// rawConfig is a preset file as written, before includes, overlays and extends are resolved
+type rawConfig struct {
+	SchemaVersion int                     `yaml:"schema_version"`
+	Include       []string                `yaml:"include,omitempty"`
+	Rules         map[string]*rawCategory `yaml:"rules"`
+	Actions       []rawAction             `yaml:"actions,omitempty"`
+	src           source
+}
+
+// rawCategory is a category as written. Unset fields are inherited from the category it
+// extends or the file it overrides.
+type rawCategory struct {
+	Extends        string         `yaml:"extends,omitempty"`
+	DenyUnexpected *bool          `yaml:"deny_unexpected"`
+	Checks         []string       `yaml:"checks,omitempty"`
+	Rules          []rawRule      `yaml:"rules"`
+	RuleSets       []rawRuleSet   `yaml:"rule_sets,omitempty"`
+	Subfolders     []rawSubfolder `yaml:"subfolders,omitempty"`
+	Naming         *Naming        `yaml:"naming,omitempty"`
+	src            source
+}
+
+// rawSubfolder is a subfolder selection as written
+type rawSubfolder struct {
+	Subfolder `yaml:",inline"`
+	src       source
+}
+
+// rawRuleSet is a rule set as written. A rule set with the id of an inherited rule set
+// replaces it.
+type rawRuleSet struct {
+	ID             string            `yaml:"id,omitempty"`
+	When           map[string]Values `yaml:"when"`
+	DenyUnexpected *bool             `yaml:"deny_unexpected"`
+	Rules          []rawRule         `yaml:"rules"`
+	src            source
+}
+
+// rawRule is a rule as written. A rule with the id of an inherited rule replaces it,
+// or removes it with remove: true.
+type rawRule struct {
+	Rule   `yaml:",inline"`
+	Remove bool `yaml:"remove,omitempty"`
+	src    source
+}
+
+// rawAction is an action as written
+type rawAction struct {
+	Action `yaml:",inline"`
+	src    source
+}
+
+// loader loads preset files and collects the problems found in them
+type loader struct {
+	problems Problems
+}
+
+// report records a problem at the value of key in src, or at src itself if key is empty
+func (l *loader) report(severity string, src source, key string, format string, args ...any) {
+	problem := src.problem(key)
+	problem.Severity = severity
+	problem.Message = fmt.Sprintf(format, args...)
+	l.problems = append(l.problems, problem)
+}
+
+// errorf records an error at the value of key in src
+func (l *loader) errorf(src source, key string, format string, args ...any) {
+	l.report(SeverityError, src, key, format, args...)
+}
+
+// OverlayPath returns the path of the user overlay of a preset file, e.g.
+// "presets.local.yaml" for "presets.yaml". The overlay is applied when it exists.
+func OverlayPath(presetPath string) string {
+	ext := filepath.Ext(presetPath)
+	return strings.TrimSuffix(presetPath, ext) + ".local" + ext
+}
+
+// loadConfig reads a preset file, its includes and its overlay and flattens them into
+// the final configuration. All problems found are returned; the configuration is only
+// usable if none of them is an error.
+func loadConfig(path string) (*PresetConfig, Problems) {
+	l := &loader{}
+	raw := l.loadFile(path, nil, source{file: path})
+	if raw == nil {
+		return nil, l.problems
+	}
+
+	overlayPath := OverlayPath(path)
+	if _, err := os.Stat(overlayPath); err == nil {
+		if overlay := l.loadFile(overlayPath, nil, source{file: overlayPath}); overlay != nil {
+			raw = mergeConfigs(raw, overlay)
+		}
+	}
+
+	return l.resolve(raw), l.problems
+}
+
+// loadFile reads a preset file and merges it on top of the files it includes. The stack
+// holds the files being loaded to detect include cycles; from is where the file was
+// included. Nil is returned if the file could not be read at all.
+func (l *loader) loadFile(path string, stack []string, from source) *rawConfig {
+	var data []byte
+	if path == IncludeDefault {
+		data = defaultPresetsYAML
+	} else {
+		if slices.Contains(stack, path) {
+			l.errorf(from, "", "include cycle: %s -> %s", strings.Join(stack, " -> "), path)
+			return nil
+		}
+		var err error
+		if data, err = os.ReadFile(path); err != nil {
+			l.errorf(from, "", "failed to read preset file: %v", err)
+			return nil
+		}
+	}
+	stack = append(stack, path)
+
+	raw := l.parseFile(path, data)
+	if raw == nil {
+		return nil
+	}
+
+	base := &rawConfig{}
+	for i, include := range raw.Include {
+		includeSrc := raw.src.field("include").index(i)
+		includePath, err := resolveInclude(path, include)
+		if err != nil {
+			l.errorf(includeSrc, "", "%v", err)
+			continue
+		}
+		if included := l.loadFile(includePath, stack, includeSrc); included != nil {
+			base = mergeConfigs(base, included)
+		}
+	}
+
+	return mergeConfigs(base, raw)
+}
+
+// parseFile strictly decodes a preset file, migrates it to the current schema version
+// and validates its categories and rules
+func (l *loader) parseFile(path string, data []byte) *rawConfig {
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		l.problems = append(l.problems, yamlProblems(path, &root, err)...)
+		return nil
+	}
+
+	raw := &rawConfig{}
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(raw); err != nil && !errors.Is(err, io.EOF) {
+		l.problems = append(l.problems, yamlProblems(path, &root, err)...)
+		// After unknown fields and mistyped values the rest of the file is still checked
+		var typeErr *yaml.TypeError
+		if !errors.As(err, &typeErr) {
+			return nil
+		}
+	}
+	annotate(raw, path, &root)
+
+	if !l.migrate(raw) {
+		return nil
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(raw.Rules)) {
+		catRules := raw.Rules[name]
+		if catRules == nil {
+			l.errorf(raw.src.field("rules"), name, "category %q has no configuration", name)
+			delete(raw.Rules, name)
+			continue
+		}
+		l.validateCategory(name, catRules)
+	}
+	return raw
+}
+
+// annotate records where the categories, rules, includes and actions of a file were decoded from
+func annotate(raw *rawConfig, path string, root *yaml.Node) {
+	doc := root
+	if doc.Kind == yaml.DocumentNode && len(doc.Content) > 0 {
+		doc = doc.Content[0]
+	}
+	raw.src = source{file: path, node: doc}
+
+	if categories := raw.src.field("rules").node; categories != nil && categories.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(categories.Content); i += 2 {
+			catRules := raw.Rules[categories.Content[i].Value]
+			if catRules == nil {
+				continue
+			}
+			catRules.src = source{file: path, node: categories.Content[i+1]}
+			for j := range catRules.Rules {
+				catRules.Rules[j].src = catRules.src.field("rules").index(j)
+			}
+			for j := range catRules.RuleSets {
+				set := &catRules.RuleSets[j]
+				set.src = catRules.src.field("rule_sets").index(j)
+				for k := range set.Rules {
+					set.Rules[k].src = set.src.field("rules").index(k)
+				}
+			}
+			for j := range catRules.Subfolders {
+				catRules.Subfolders[j].src = catRules.src.field("subfolders").index(j)
+			}
+		}
+	}
+	for i := range raw.Actions {
+		raw.Actions[i].src = raw.src.field("actions").index(i)
+	}
+}
+
+// resolveInclude returns the path of an include, relative to the including file
+func resolveInclude(from, include string) (string, error) {
+	if include == IncludeDefault {
+		return IncludeDefault, nil
+	}
+	path, err := expandPath(include)
+	if err != nil {
+		return "", err
+	}
+	if !filepath.IsAbs(path) && from != IncludeDefault {
+		path = filepath.Join(filepath.Dir(from), path)
+	}
+	return filepath.Clean(path), nil
+}
+
+// mergeConfigs merges top onto base: new categories are added, categories of both are
+// merged field by field and the actions of top run after those of base
+func mergeConfigs(base, top *rawConfig) *rawConfig {
+	merged := &rawConfig{
+		SchemaVersion: max(base.SchemaVersion, top.SchemaVersion),
+		Rules:         make(map[string]*rawCategory, len(base.Rules)+len(top.Rules)),
+		Actions:       append(slices.Clone(base.Actions), top.Actions...),
+		src:           top.src,
+	}
+	for name, catRules := range base.Rules {
+		merged.Rules[name] = catRules
+	}
+	for name, catRules := range top.Rules {
+		if existing, exists := merged.Rules[name]; exists {
+			merged.Rules[name] = mergeCategories(existing, catRules)
+		} else {
+			merged.Rules[name] = catRules
+		}
+	}
+	return merged
+}
+
+// mergeCategories merges the fields set in top onto base
+func mergeCategories(base, top *rawCategory) *rawCategory {
+	merged := *base
+	if top.Extends != "" {
+		merged.Extends = top.Extends
+	}
+	if top.DenyUnexpected != nil {
+		merged.DenyUnexpected = top.DenyUnexpected
+	}
+	if len(top.Checks) > 0 {
+		merged.Checks = top.Checks
+	}
+	if len(top.Subfolders) > 0 {
+		merged.Subfolders = top.Subfolders
+	}
+	if top.Naming != nil {
+		merged.Naming = top.Naming
+	}
+	merged.Rules = mergeRules(base.Rules, top.Rules)
+	merged.RuleSets = slices.Clone(base.RuleSets)
+	for _, set := range top.RuleSets {
+		idx := -1
+		if set.ID != "" {
+			idx = slices.IndexFunc(merged.RuleSets, func(s rawRuleSet) bool { return s.ID == set.ID })
+		}
+		if idx >= 0 {
+			merged.RuleSets[idx] = set
+		} else {
+			merged.RuleSets = append(merged.RuleSets, set)
+		}
+	}
+	return &merged
+}
+
+// mergeRules applies the rules of top to base: rules with the id of a base rule replace
+// it in place or remove it, other rules are appended. Removals of rules that are not in
+// base are kept, as they may target a rule of a category that is extended later.
+func mergeRules(base, top []rawRule) []rawRule {
+	merged := slices.Clone(base)
+	for _, rule := range top {
+		idx := -1
+		if rule.ID != "" {
+			idx = slices.IndexFunc(merged, func(r rawRule) bool { return r.ID == rule.ID && !r.Remove })
+		}
+		switch {
+		case idx >= 0 && rule.Remove:
+			merged = slices.Delete(merged, idx, idx+1)
+		case idx >= 0:
+			merged[idx] = rule
+		default:
+			merged = append(merged, rule)
+		}
+	}
+	return merged
+}
+
+// resolve flattens the categories that extend other categories and converts the
+// configuration into its final form
+func (l *loader) resolve(raw *rawConfig) *PresetConfig {
+	resolved := make(map[string]*rawCategory, len(raw.Rules))
+	failed := make(map[string]bool)
+
+	var resolveCategory func(name string, stack []string) *rawCategory
+	resolveCategory = func(name string, stack []string) *rawCategory {
+		if catRules, done := resolved[name]; done || failed[name] {
+			return catRules
+		}
+		catRules := raw.Rules[name]
+		if slices.Contains(stack, name) {
+			l.errorf(catRules.src, "extends", "extends cycle: %s -> %s", strings.Join(stack, " -> "), name)
+			failed[name] = true
+			return nil
+		}
+		if catRules.Extends != "" {
+			if _, exists := raw.Rules[catRules.Extends]; !exists {
+				l.errorf(catRules.src, "extends", "category %q extends unknown category %q", name, catRules.Extends)
+				failed[name] = true
+				return nil
+			}
+			parent := resolveCategory(catRules.Extends, append(stack, name))
+			if parent == nil {
+				failed[name] = true
+				return nil
+			}
+			catRules = mergeCategories(parent, catRules)
+		}
+		resolved[name] = catRules
+		return catRules
+	}
+
+	config := &PresetConfig{
+		SchemaVersion: raw.SchemaVersion,
+		Rules:         make(map[string]*CategoryRules, len(raw.Rules)),
+		Actions:       make([]Action, 0, len(raw.Actions)),
+	}
+	extended := make(map[string]bool)
+	for _, catRules := range raw.Rules {
+		extended[catRules.Extends] = true
+		for _, subfolder := range catRules.Subfolders {
+			extended[subfolder.Category] = true
+		}
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(raw.Rules)) {
+		rawCat := raw.Rules[name]
+		catRules := resolveCategory(name, nil)
+		if catRules == nil {
+			continue
+		}
+		if catRules.DenyUnexpected == nil {
+			l.errorf(rawCat.src, "", "category %q is missing required field 'deny_unexpected'", name)
+			continue
+		}
+		// Base categories that only exist to be extended or to validate subfolders are not
+		// expected to be detected
+		if !extended[name] && rls.ParseType(name).String() != name {
+			l.report(SeverityWarning, rawCat.src, "", "category %q is never detected from release names, it is only used with --overwrite", name)
+		}
+
+		final := &CategoryRules{
+			Extends:        rawCat.Extends,
+			DenyUnexpected: *catRules.DenyUnexpected,
+			Checks:         catRules.Checks,
+			Rules:          make([]Rule, 0, len(catRules.Rules)),
+			Naming:         catRules.Naming,
+		}
+		ids := make(map[string]bool)
+		for _, rule := range catRules.Rules {
+			if rule.Remove {
+				l.errorf(rule.src, "id", "category %q removes unknown rule id %q", name, rule.ID)
+				continue
+			}
+			if rule.ID != "" {
+				if ids[rule.ID] {
+					l.errorf(rule.src, "id", "category %q has more than one rule with id %q", name, rule.ID)
+					continue
+				}
+				ids[rule.ID] = true
+			}
+			final.Rules = append(final.Rules, rule.Rule)
+		}
+		for _, set := range catRules.RuleSets {
+			final.RuleSets = append(final.RuleSets, l.resolveRuleSet(name, set, ids))
+		}
+		for _, subfolder := range catRules.Subfolders {
+			final.Subfolders = append(final.Subfolders, subfolder.Subfolder)
+		}
+		config.Rules[name] = final
+	}
+
+	// Subfolder categories can only be checked once every category is resolved
+	for _, name := range slices.Sorted(maps.Keys(raw.Rules)) {
+		catRules := resolved[name]
+		if catRules == nil || config.Rules[name] == nil {
+			continue
+		}
+		for _, subfolder := range catRules.Subfolders {
+			if subfolder.Category != "" && raw.Rules[subfolder.Category] == nil {
+				l.errorf(subfolder.src, "category", "subfolders of category %q use unknown category %q", name, subfolder.Category)
+			}
+		}
+	}
+
+	for i, action := range raw.Actions {
+		if err := validateAction(action.Action, config.Rules); err != nil {
+			l.errorf(action.src, "", "action %d: %v", i+1, err)
+			continue
+		}
+		config.Actions = append(config.Actions, action.Action)
+	}
+	return config
+}
+
+// resolveRuleSet converts a rule set into its final form. Removals must name a rule of
+// the category.
+func (l *loader) resolveRuleSet(category string, set rawRuleSet, categoryIDs map[string]bool) RuleSet {
+	final := RuleSet{
+		ID:             set.ID,
+		When:           set.When,
+		DenyUnexpected: set.DenyUnexpected,
+	}
+	ids := make(map[string]bool)
+	for _, rule := range set.Rules {
+		if rule.ID != "" {
+			if ids[rule.ID] {
+				l.errorf(rule.src, "id", "rule set of category %q has more than one rule with id %q", category, rule.ID)
+				continue
+			}
+			ids[rule.ID] = true
+		}
+		if rule.Remove {
+			if !categoryIDs[rule.ID] {
+				l.errorf(rule.src, "id", "rule set of category %q removes unknown rule id %q", category, rule.ID)
+				continue
+			}
+			final.Remove = append(final.Remove, rule.ID)
+			continue
+		}
+		final.Rules = append(final.Rules, rule.Rule)
+	}
+	return final
+}
@@ -0,0 +1,211 @@
+package preset
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writePreset(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write preset file: %v", err)
+	}
+	return path
+}
+
+func ruleIDs(rules []Rule) []string {
+	ids := make([]string, 0, len(rules))
+	for _, rule := range rules {
+		ids = append(ids, rule.ID)
+	}
+	return ids
+}
+
+func TestLoadPresets_Extends(t *testing.T) {
+	path := writePreset(t, t.TempDir(), "presets.yaml", `
+rules:
+  base:
+    deny_unexpected: true
+    checks: [rules, sfv]
+    rules:
+      - {id: nfo, pattern: "*.nfo", min: 1, max: 1}
+      - {id: sfv, pattern: "*.sfv", min: 1}
+      - {id: jpg, pattern: "*.jpg"}
+  middle:
+    extends: base
+    rules:
+      - {id: sfv, pattern: "*.sfv", min: 1, max: 1}
+      - {id: zip, pattern: "*.zip", min: 1}
+  leaf:
+    extends: middle
+    deny_unexpected: false
+    rules:
+      - {id: jpg, remove: true}
+`)
+
+	config, err := LoadPresets(path)
+	if err != nil {
+		t.Fatalf("Failed to load presets: %v", err)
+	}
+
+	leaf := config.Rules["leaf"]
+	if got := strings.Join(ruleIDs(leaf.Rules), ","); got != "nfo,sfv,zip" {
+		t.Errorf("Expected rules nfo,sfv,zip, got %s", got)
+	}
+	if leaf.Rules[1].Max != 1 {
+		t.Errorf("Expected the sfv rule of middle to replace the one of base, got %+v", leaf.Rules[1])
+	}
+	if leaf.DenyUnexpected {
+		t.Error("Expected deny_unexpected of leaf to override the inherited value")
+	}
+	if strings.Join(leaf.Checks, ",") != "rules,sfv" {
+		t.Errorf("Expected inherited checks, got %v", leaf.Checks)
+	}
+	if got := strings.Join(ruleIDs(config.Rules["base"].Rules), ","); got != "nfo,sfv,jpg" {
+		t.Errorf("Expected base to be unchanged, got %s", got)
+	}
+}
+
+func TestLoadPresets_IncludeAndOverlay(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, "shared"), 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	writePreset(t, filepath.Join(dir, "shared"), "extra.yaml", `
+rules:
+  custom:
+    extends: movie
+    rules:
+      - {id: proof, remove: true}
+      - {id: proof-dir, remove: true}
+`)
+	path := writePreset(t, dir, "presets.yaml", `
+include: [default, shared/extra.yaml]
+rules:
+  music:
+    rules:
+      - {id: jpg, remove: true}
+`)
+	writePreset(t, dir, "presets.local.yaml", `
+rules:
+  movie:
+    rules:
+      - {id: sample, pattern: "Sample/*.mkv", min: 1, max: 1}
+`)
+
+	config, err := LoadPresets(path)
+	if err != nil {
+		t.Fatalf("Failed to load presets: %v", err)
+	}
+
+	if _, exists := config.Rules["app"]; !exists {
+		t.Error("Expected the default categories to be included")
+	}
+	if got := strings.Join(ruleIDs(config.Rules["music"].Rules), ","); got != "m3u,sfv,nfo,audio" {
+		t.Errorf("Expected the jpg rule of music to be removed, got %s", got)
+	}
+	custom := config.Rules["custom"]
+	if got := strings.Join(ruleIDs(custom.Rules), ","); got != "rar,sfv,nfo,sample-dir,sample,rxx" {
+		t.Errorf("Expected the proof rules of custom to be removed, got %s", got)
+	}
+	if custom.Rules[4].Pattern != "Sample/*.mkv" {
+		t.Errorf("Expected the overlay to apply to extending categories, got %q", custom.Rules[4].Pattern)
+	}
+}
+
+func TestLoadPresets_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		files    map[string]string
+		expected string
+	}{
+		{
+			name: "include cycle",
+			files: map[string]string{
+				"presets.yaml": "include: [a.yaml]\nrules: {}\n",
+				"a.yaml":       "include: [presets.yaml]\nrules: {}\n",
+			},
+			expected: "include cycle",
+		},
+		{
+			name: "extends cycle",
+			files: map[string]string{
+				"presets.yaml": "rules:\n  a: {extends: b}\n  b: {extends: a}\n",
+			},
+			expected: "extends cycle",
+		},
+		{
+			name: "unknown parent",
+			files: map[string]string{
+				"presets.yaml": "rules:\n  a: {extends: b}\n",
+			},
+			expected: `extends unknown category "b"`,
+		},
+		{
+			name: "unknown removal",
+			files: map[string]string{
+				"presets.yaml": "rules:\n  a:\n    deny_unexpected: true\n    rules:\n      - {id: nfo, remove: true}\n",
+			},
+			expected: `removes unknown rule id "nfo"`,
+		},
+		{
+			name: "duplicate id",
+			files: map[string]string{
+				"presets.yaml": "rules:\n  a:\n    deny_unexpected: true\n    rules:\n      - {id: nfo, pattern: a}\n      - {id: nfo, pattern: b}\n",
+			},
+			expected: `more than one rule with id "nfo"`,
+		},
+		{
+			name: "unknown subfolder category",
+			files: map[string]string{
+				"presets.yaml": "rules:\n  a:\n    deny_unexpected: true\n    subfolders:\n      - {pattern: \"CD[0-9]\", category: b}\n",
+			},
+			expected: `subfolders of category "a" use unknown category "b"`,
+		},
+		{
+			name: "subfolder without pattern",
+			files: map[string]string{
+				"presets.yaml": "rules:\n  a:\n    deny_unexpected: true\n    subfolders:\n      - {category: a}\n",
+			},
+			expected: `subfolder of category "a" has no pattern`,
+		},
+		{
+			name: "audio assertions without verify",
+			files: map[string]string{
+				"presets.yaml": "rules:\n  a:\n    deny_unexpected: true\n    rules:\n      - {pattern: \"*.mp3\", audio: {same_bitrate: true}}\n",
+			},
+			expected: "audio assertions require verify: audio",
+		},
+		{
+			name: "naming with unknown attribute",
+			files: map[string]string{
+				"presets.yaml": "rules:\n  a:\n    deny_unexpected: true\n    naming: {require: [resolutoin]}\n",
+			},
+			expected: `unknown release attribute "resolutoin" in require`,
+		},
+		{
+			name: "broken overlay",
+			files: map[string]string{
+				"presets.yaml":       "rules: {}\n",
+				"presets.local.yaml": "rules: [",
+			},
+			expected: "presets.local.yaml:1:",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range tt.files {
+				writePreset(t, dir, name, content)
+			}
+			_, err := LoadPresets(filepath.Join(dir, "presets.yaml"))
+			if err == nil || !strings.Contains(err.Error(), tt.expected) {
+				t.Errorf("Expected error containing %q, got %v", tt.expected, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,175 @@
+package preset
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/moistari/rls"
+	"gopkg.in/yaml.v3"
+)
+
+// Values is a condition on a release attribute: one value or a list of values, of which
+// any must match. Values starting with "!" exclude releases with that value.
+type Values []string
+
+// UnmarshalYAML accepts a single value as well as a list
+func (v *Values) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		*v = Values{node.Value}
+		return nil
+	}
+	var values []string
+	if err := node.Decode(&values); err != nil {
+		return err
+	}
+	*v = values
+	return nil
+}
+
+// releaseAttributes are the release name fields rule set conditions can test, as parsed by rls
+var releaseAttributes = map[string]func(release rls.Release) []string{
+	"title":      func(r rls.Release) []string { return nonEmpty(r.Title) },
+	"year":       func(r rls.Release) []string { return nonZero(r.Year) },
+	"series":     func(r rls.Release) []string { return nonZero(r.Series) },
+	"episode":    func(r rls.Release) []string { return nonZero(r.Episode) },
+	"resolution": func(r rls.Release) []string { return nonEmpty(r.Resolution) },
+	"source":     func(r rls.Release) []string { return nonEmpty(r.Source) },
+	"collection": func(r rls.Release) []string { return nonEmpty(r.Collection) },
+	"codec":      func(r rls.Release) []string { return r.Codec },
+	"hdr":        func(r rls.Release) []string { return r.HDR },
+	"audio":      func(r rls.Release) []string { return r.Audio },
+	"channels":   func(r rls.Release) []string { return nonEmpty(r.Channels) },
+	"other":      func(r rls.Release) []string { return r.Other },
+	"cut":        func(r rls.Release) []string { return r.Cut },
+	"edition":    func(r rls.Release) []string { return r.Edition },
+	"language":   func(r rls.Release) []string { return r.Language },
+	"platform":   func(r rls.Release) []string { return nonEmpty(r.Platform) },
+	"arch":       func(r rls.Release) []string { return nonEmpty(r.Arch) },
+	"region":     func(r rls.Release) []string { return nonEmpty(r.Region) },
+	"container":  func(r rls.Release) []string { return nonEmpty(r.Container) },
+	"genre":      func(r rls.Release) []string { return nonEmpty(r.Genre) },
+	"disc":       func(r rls.Release) []string { return nonEmpty(r.Disc) },
+	"version":    func(r rls.Release) []string { return nonEmpty(r.Version) },
+	"group":      func(r rls.Release) []string { return nonEmpty(r.Group) },
+}
+
+// ReleaseAttributes lists the release attributes rule set conditions can test
+var ReleaseAttributes = slices.Sorted(maps.Keys(releaseAttributes))
+
+func nonEmpty(value string) []string {
+	if value == "" {
+		return nil
+	}
+	return []string{value}
+}
+
+func nonZero(value int) []string {
+	if value == 0 {
+		return nil
+	}
+	return []string{strconv.Itoa(value)}
+}
+
+// MatchedRuleSet is a rule set that was applied to a release
+type MatchedRuleSet struct {
+	ID   string            `json:"id,omitempty" yaml:"id,omitempty"`
+	When map[string]Values `json:"when" yaml:"when"` // The conditions of the rule set, all of which the release matched
+}
+
+// String formats the rule set and its conditions, e.g. "uhd (resolution: 2160p, source: BluRay)"
+func (m MatchedRuleSet) String() string {
+	conditions := make([]string, 0, len(m.When))
+	for _, attribute := range slices.Sorted(maps.Keys(m.When)) {
+		conditions = append(conditions, attribute+": "+strings.Join(m.When[attribute], ", "))
+	}
+	if m.ID == "" {
+		return strings.Join(conditions, "; ")
+	}
+	return fmt.Sprintf("%s (%s)", m.ID, strings.Join(conditions, "; "))
+}
+
+// Matches reports whether a release matches all conditions of the rule set. Values are
+// compared case insensitively.
+func (s RuleSet) Matches(release rls.Release) bool {
+	for attribute, values := range s.When {
+		if !matchValues(releaseAttributes[attribute](release), values) {
+			return false
+		}
+	}
+	return true
+}
+
+// matchValues reports whether the release values contain one of the wanted values, and
+// none of the excluded "!" values
+func matchValues(have []string, values Values) bool {
+	contains := func(value string) bool {
+		return slices.ContainsFunc(have, func(h string) bool { return strings.EqualFold(h, value) })
+	}
+	wanted := false
+	for _, value := range values {
+		if excluded, ok := strings.CutPrefix(value, "!"); ok {
+			if contains(excluded) {
+				return false
+			}
+			continue
+		}
+		wanted = true
+		if contains(value) {
+			return true
+		}
+	}
+	return !wanted
+}
+
+// Selection is the rules of a category that apply to a particular release
+type Selection struct {
+	Rules          []Rule
+	DenyUnexpected bool
+	RuleSets       []MatchedRuleSet // Rule sets whose conditions the release matched, in the order they were applied
+	Subfolders     []Subfolder      // Subfolders validated as releases of their own
+	Naming         *Naming          // Policy the release name must follow (nil = any name)
+}
+
+// SelectRules returns the rules of a category for a release: the category rules, with
+// the rule sets whose conditions the release matches applied in order. A rule of a set
+// replaces the category rule with the same id or is added, and removals drop the rule.
+func (c *PresetConfig) SelectRules(category string, release rls.Release) (*Selection, error) {
+	catRules, exists := c.Rules[category]
+	if !exists {
+		return nil, fmt.Errorf("no rules found for category: %s", category)
+	}
+
+	selection := &Selection{
+		Rules:          slices.Clone(catRules.Rules),
+		DenyUnexpected: catRules.DenyUnexpected,
+		Subfolders:     catRules.Subfolders,
+		Naming:         catRules.Naming,
+	}
+	for _, set := range catRules.RuleSets {
+		if !set.Matches(release) {
+			continue
+		}
+		for _, id := range set.Remove {
+			selection.Rules = slices.DeleteFunc(selection.Rules, func(r Rule) bool { return r.ID == id })
+		}
+		for _, rule := range set.Rules {
+			idx := -1
+			if rule.ID != "" {
+				idx = slices.IndexFunc(selection.Rules, func(r Rule) bool { return r.ID == rule.ID })
+			}
+			if idx >= 0 {
+				selection.Rules[idx] = rule
+			} else {
+				selection.Rules = append(selection.Rules, rule)
+			}
+		}
+		if set.DenyUnexpected != nil {
+			selection.DenyUnexpected = *set.DenyUnexpected
+		}
+		selection.RuleSets = append(selection.RuleSets, MatchedRuleSet{ID: set.ID, When: set.When})
+	}
+	return selection, nil
+}
@@ -0,0 +1,130 @@
+package preset
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/moistari/rls"
+)
+
+func TestSelectRules(t *testing.T) {
+	path := writePreset(t, t.TempDir(), "presets.yaml", `
+rules:
+  movie:
+    deny_unexpected: true
+    rules:
+      - {id: nfo, pattern: "*.nfo", min: 1, max: 1}
+      - {id: sample, pattern: "Sample/*.{mkv,mp4}", min: 1, max: 1}
+      - {id: proof, pattern: "Proof", type: dir, max: 1}
+    rule_sets:
+      - id: bluray
+        when: {source: bluray}
+        rules:
+          - {id: proof, pattern: "Proof", type: dir, min: 1, max: 1}
+      - id: uhd
+        when: {resolution: 2160p, source: [BluRay, UHD.BluRay]}
+        rules:
+          - {id: sample, pattern: "Sample/*.mkv", min: 1, max: 1}
+          - {id: m2ts, pattern: "*.m2ts"}
+      - id: web
+        when: {source: WEB, group: "!GRP"}
+        deny_unexpected: false
+        rules:
+          - {id: sample, remove: true}
+  episode:
+    extends: movie
+`)
+
+	config, err := LoadPresets(path)
+	if err != nil {
+		t.Fatalf("Failed to load presets: %v", err)
+	}
+
+	tests := []struct {
+		release  string
+		category string
+		sets     string
+		rules    string
+		deny     bool
+	}{
+		{"Movie.2025.1080p.BluRay.x264-GRP", "movie", "bluray", "nfo:*.nfo,sample:Sample/*.{mkv,mp4},proof:Proof", true},
+		{"Movie.2025.2160p.BluRay.x265-GRP", "movie", "bluray,uhd", "nfo:*.nfo,sample:Sample/*.mkv,proof:Proof,m2ts:*.m2ts", true},
+		{"Movie.2025.1080p.WEB.h264-OTHER", "movie", "web", "nfo:*.nfo,proof:Proof", false},
+		{"Movie.2025.1080p.WEB.h264-GRP", "movie", "", "nfo:*.nfo,sample:Sample/*.{mkv,mp4},proof:Proof", true},
+		{"Show.S01E01.1080p.BluRay.x264-GRP", "episode", "bluray", "nfo:*.nfo,sample:Sample/*.{mkv,mp4},proof:Proof", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.release, func(t *testing.T) {
+			selection, err := config.SelectRules(tt.category, rls.ParseString(tt.release))
+			if err != nil {
+				t.Fatalf("Failed to select rules: %v", err)
+			}
+
+			sets := make([]string, 0, len(selection.RuleSets))
+			for _, set := range selection.RuleSets {
+				sets = append(sets, set.ID)
+			}
+			rules := make([]string, 0, len(selection.Rules))
+			for _, rule := range selection.Rules {
+				rules = append(rules, rule.ID+":"+rule.Pattern)
+			}
+
+			if got := strings.Join(sets, ","); got != tt.sets {
+				t.Errorf("Expected rule sets %q, got %q", tt.sets, got)
+			}
+			if got := strings.Join(rules, ","); got != tt.rules {
+				t.Errorf("Expected rules %q, got %q", tt.rules, got)
+			}
+			if selection.DenyUnexpected != tt.deny {
+				t.Errorf("Expected deny_unexpected %v, got %v", tt.deny, selection.DenyUnexpected)
+			}
+		})
+	}
+
+	if _, err := config.SelectRules("music", rls.Release{}); err == nil {
+		t.Error("Expected an error for an unknown category")
+	}
+}
+
+func TestMatchedRuleSet_String(t *testing.T) {
+	set := MatchedRuleSet{ID: "uhd", When: map[string]Values{"source": {"BluRay"}, "resolution": {"2160p", "4320p"}}}
+	if got := set.String(); got != "uhd (resolution: 2160p, 4320p; source: BluRay)" {
+		t.Errorf("Unexpected string %q", got)
+	}
+}
+
+func TestLint_RuleSets(t *testing.T) {
+	path := writePreset(t, t.TempDir(), "presets.yaml", `
+rules:
+  movie:
+    deny_unexpected: true
+    rules:
+      - {id: nfo, pattern: "*.nfo"}
+    rule_sets:
+      - id: a
+        when: {colour: red}
+      - id: b
+        rules:
+          - {id: sample, remove: true}
+`)
+
+	problems, err := Lint(path)
+	if err != nil {
+		t.Fatalf("Failed to lint presets: %v", err)
+	}
+
+	expected := []string{
+		`9:16: unknown release attribute "colour"`,
+		`10:9: rule set of category "movie" has no when conditions`,
+		`12:18: rule set of category "movie" removes unknown rule id "sample"`,
+	}
+	if len(problems) != len(expected) {
+		t.Fatalf("Expected %d problems, got %v", len(expected), problems)
+	}
+	for i, want := range expected {
+		if got := problems[i].String(); !strings.Contains(got, want) {
+			t.Errorf("Expected a problem containing %q, got %q", want, got)
+		}
+	}
+}
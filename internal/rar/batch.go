@@ -0,0 +1,103 @@
+package rar
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/autobrr/sfvbrr/internal/report"
+)
+
+// ValidateFolders validates RAR volume sets in multiple folders. Sets left when the
+// context is done are skipped and a cancellation error is returned.
+func ValidateFolders(ctx context.Context, folders []string, opts Options) error {
+	var hasErrors bool
+	rep := report.NewWriterFor(string(opts.OutputFormat), "rar")
+
+	for _, folder := range folders {
+		if ctx.Err() != nil {
+			break
+		}
+
+		// Resolve absolute path
+		absPath, err := filepath.Abs(folder)
+		if err != nil {
+			report.Error(rep, fmt.Errorf("failed to resolve path %s: %w", folder, err))
+			hasErrors = true
+			continue
+		}
+
+		// Check if directory exists
+		info, err := os.Stat(absPath)
+		if err != nil {
+			report.Error(rep, fmt.Errorf("%s does not exist: %w", folder, err))
+			hasErrors = true
+			continue
+		}
+
+		if !info.IsDir() {
+			report.Error(rep, fmt.Errorf("%s is not a directory", folder))
+			hasErrors = true
+			continue
+		}
+
+		var sets []*VolumeSet
+		if opts.Recursive {
+			sets, err = FindVolumeSetsRecursive(absPath)
+		} else {
+			sets, err = FindVolumeSets(absPath)
+		}
+		if err != nil {
+			report.Error(rep, fmt.Errorf("failed to find RAR volumes in %s: %w", folder, err))
+			hasErrors = true
+			continue
+		}
+
+		if len(sets) == 0 {
+			if !opts.Quiet {
+				fmt.Fprintf(os.Stderr, "No RAR files found in %s\n", folder)
+			}
+			if rep != nil {
+				rep.AddError(fmt.Errorf("no RAR files found in %s", folder))
+			}
+			hasErrors = true
+			continue
+		}
+
+		// Validate each volume set found
+		for _, set := range sets {
+			if ctx.Err() != nil {
+				break
+			}
+			result := ValidateSet(set)
+			if rep != nil {
+				rep.Add(ConvertSetResult(result), !result.Valid)
+				rep.Count("total_volumes", len(result.Set.Volumes))
+				rep.Count("missing_volumes", len(result.MissingVolumes))
+				rep.AddBytes(result.TotalBytes())
+			} else {
+				DisplayResult(result, opts)
+			}
+			if !result.Valid {
+				hasErrors = true
+			}
+		}
+	}
+
+	if rep != nil {
+		if err := rep.Close(); err != nil {
+			return err
+		}
+	}
+
+	if ctx.Err() != nil {
+		return fmt.Errorf("validation cancelled: %w", context.Cause(ctx))
+	}
+
+	if hasErrors {
+		return fmt.Errorf("one or more folders had errors")
+	}
+
+	return nil
+}
@@ -0,0 +1,81 @@
+package rar
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/fatih/color"
+)
+
+var (
+	magenta    = color.New(color.FgMagenta).SprintFunc()
+	success    = color.New(color.FgGreen).SprintFunc()
+	label      = color.New(color.FgCyan).SprintFunc()
+	errorColor = color.New(color.FgRed).SprintFunc()
+)
+
+// DisplayResult displays the RAR set validation results to the user in text form
+// Returns true if validation failed
+func DisplayResult(result *SetResult, opts Options) bool {
+	if opts.Quiet {
+		// In quiet mode, only show summary if there are errors
+		if !result.Valid {
+			fmt.Fprintf(os.Stderr, "%s: %d error(s), %d missing volume(s)\n",
+				result.Set.Name,
+				len(result.Errors),
+				len(result.MissingVolumes))
+		}
+		return !result.Valid
+	}
+
+	// Show set information
+	fmt.Fprintf(os.Stdout, "\n%s\n", magenta("Validating RAR set:"))
+	fmt.Fprintf(os.Stdout, "  %-13s %s\n", label("Folder:"), result.Set.Dir)
+	fmt.Fprintf(os.Stdout, "  %-13s %s\n", label("First volume:"), result.Set.Name)
+	fmt.Fprintf(os.Stdout, "  %-13s %s\n", label("Naming:"), result.Set.Scheme)
+	if result.Format != "" {
+		fmt.Fprintf(os.Stdout, "  %-13s %s\n", label("Format:"), result.Format)
+	}
+	fmt.Fprintf(os.Stdout, "  %-13s %d\n", label("Volumes:"), len(result.Set.Volumes))
+	fmt.Fprintln(os.Stdout)
+
+	// Show individual volumes if verbose
+	if opts.Verbose {
+		fmt.Fprintf(os.Stdout, "%s\n", magenta("Volumes:"))
+		for _, volume := range result.Set.Volumes {
+			if volume.Error != nil {
+				fmt.Fprintf(os.Stdout, "  %s %s %s\n", errorColor("✗"), volume.Name, errorColor(fmt.Sprintf("(%s)", volume.Error.Error())))
+			} else {
+				fmt.Fprintf(os.Stdout, "  %s %s\n", success("✓"), volume.Name)
+			}
+		}
+		for _, name := range result.MissingVolumes {
+			fmt.Fprintf(os.Stdout, "  %s %s %s\n", errorColor("✗"), name, errorColor("(MISSING)"))
+		}
+		fmt.Fprintln(os.Stdout)
+	}
+
+	// Show errors if any
+	if len(result.Errors) > 0 {
+		fmt.Fprintf(os.Stdout, "%s\n", errorColor("Errors:"))
+		for _, err := range result.Errors {
+			fmt.Fprintf(os.Stdout, "  %s\n", errorColor(err.Error()))
+		}
+		fmt.Fprintln(os.Stdout)
+	}
+
+	// Show summary
+	fmt.Fprintf(os.Stdout, "%s\n", magenta("Summary:"))
+	if result.Valid {
+		fmt.Fprintf(os.Stdout, "  %-15s %s\n", label("Status:"), success("OK"))
+	} else {
+		fmt.Fprintf(os.Stdout, "  %-15s %s\n", label("Status:"), errorColor("FAILED"))
+	}
+	if len(result.MissingVolumes) > 0 {
+		fmt.Fprintf(os.Stdout, "  %-15s %s\n", label("Missing:"), errorColor(len(result.MissingVolumes)))
+	}
+	fmt.Fprintln(os.Stdout)
+
+	// Return true if validation failed
+	return !result.Valid
+}
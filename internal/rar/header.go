@@ -0,0 +1,405 @@
+package rar
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+)
+
+// Format represents the RAR archive format version
+type Format string
+
+const (
+	FormatRAR4 Format = "rar4" // RAR 1.5 - 4.x archive format
+	FormatRAR5 Format = "rar5" // RAR 5.0+ archive format
+)
+
+var (
+	signatureRAR4 = []byte{0x52, 0x61, 0x72, 0x21, 0x1a, 0x07, 0x00}
+	signatureRAR5 = []byte{0x52, 0x61, 0x72, 0x21, 0x1a, 0x07, 0x01, 0x00}
+)
+
+// maxHeaderBlocks limits how many headers are walked per volume.
+// Scene volumes hold a single file, so a handful of blocks is the norm.
+const maxHeaderBlocks = 4096
+
+// RAR4 block types and flags
+const (
+	rar4BlockMain    = 0x73
+	rar4BlockFile    = 0x74
+	rar4BlockEnd     = 0x7b
+	rar4LongBlock    = 0x8000
+	rar4MainVolume   = 0x0001
+	rar4MainSolid    = 0x0008
+	rar4MainNewNum   = 0x0010
+	rar4MainPassword = 0x0080
+	rar4MainFirstVol = 0x0100
+	rar4FileSplitBef = 0x0001
+	rar4FileSplitAft = 0x0002
+	rar4FileLarge    = 0x0100
+	rar4EndNextVol   = 0x0001
+	rar4EndDataCRC   = 0x0002
+	rar4EndVolNumber = 0x0008
+)
+
+// RAR5 header types and flags
+const (
+	rar5HeaderMain       = 1
+	rar5HeaderFile       = 2
+	rar5HeaderEncryption = 4
+	rar5HeaderEnd        = 5
+	rar5FlagExtra        = 0x0001
+	rar5FlagData         = 0x0002
+	rar5FlagSplitBefore  = 0x0008
+	rar5FlagSplitAfter   = 0x0010
+	rar5MainVolume       = 0x0001
+	rar5MainVolumeNumber = 0x0002
+	rar5MainSolid        = 0x0004
+	rar5EndNotLast       = 0x0001
+	rar5FileHasMTime     = 0x0002
+	rar5FileHasCRC       = 0x0004
+)
+
+// FileHeader describes a file header found in a RAR volume
+type FileHeader struct {
+	Name        string
+	SplitBefore bool // File data continues from the previous volume
+	SplitAfter  bool // File data continues in the next volume
+}
+
+// Header contains the archive level information of a single RAR volume.
+// Only headers are read; no data is decompressed.
+type Header struct {
+	Format           Format
+	Volume           bool // Archive is part of a multi-volume set
+	Solid            bool
+	NewNumbering     bool // RAR4: volumes use name.partNN.rar naming (always true for RAR5)
+	FirstVolume      bool // Archive declares itself as the first volume
+	FirstVolumeKnown bool // Whether FirstVolume can be trusted (RAR4 archives created before RAR 3.0 lack the flag)
+	VolumeNumber     int  // Zero-based volume number, -1 if the archive does not record it
+	EncryptedHeaders bool // Headers are encrypted, file headers could not be read
+	HasEnd           bool // End of archive header was found
+	NextVolume       bool // End of archive header says another volume follows
+	Files            []FileHeader
+}
+
+// ReadHeaderFile reads the archive headers of the RAR volume at path
+func ReadHeaderFile(path string) (*Header, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open RAR volume: %w", err)
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open RAR volume: %w", err)
+	}
+
+	return ReadHeader(f, info.Size())
+}
+
+// ReadHeader reads the archive headers from a RAR volume of the given size.
+// It verifies the signature, the header CRCs, and that no header or data area
+// reaches beyond the end of the volume.
+func ReadHeader(r io.ReaderAt, size int64) (*Header, error) {
+	sig := make([]byte, len(signatureRAR5))
+	n, err := r.ReadAt(sig, 0)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to read signature: %w", err)
+	}
+	sig = sig[:n]
+
+	switch {
+	case bytes.Equal(sig, signatureRAR5):
+		return readRAR5(r, size)
+	case len(sig) >= len(signatureRAR4) && bytes.Equal(sig[:len(signatureRAR4)], signatureRAR4):
+		return readRAR4(r, size)
+	default:
+		return nil, fmt.Errorf("invalid RAR signature")
+	}
+}
+
+// readRAR4 walks the headers of a RAR 1.5 - 4.x volume
+func readRAR4(r io.ReaderAt, size int64) (*Header, error) {
+	h := &Header{Format: FormatRAR4, VolumeNumber: -1}
+	offset := int64(len(signatureRAR4))
+	mainSeen := false
+
+	for block := 0; block < maxHeaderBlocks && offset < size; block++ {
+		base := make([]byte, 7)
+		if _, err := r.ReadAt(base, offset); err != nil {
+			return nil, fmt.Errorf("truncated header at offset %d", offset)
+		}
+
+		headCRC := binary.LittleEndian.Uint16(base[0:2])
+		headType := base[2]
+		headFlags := binary.LittleEndian.Uint16(base[3:5])
+		headSize := int64(binary.LittleEndian.Uint16(base[5:7]))
+		if headSize < 7 {
+			return nil, fmt.Errorf("invalid header size %d at offset %d", headSize, offset)
+		}
+		if offset+headSize > size {
+			return nil, fmt.Errorf("truncated header at offset %d", offset)
+		}
+
+		data := make([]byte, headSize)
+		if _, err := r.ReadAt(data, offset); err != nil {
+			return nil, fmt.Errorf("truncated header at offset %d", offset)
+		}
+		if uint16(crc32.ChecksumIEEE(data[2:])) != headCRC {
+			return nil, fmt.Errorf("header CRC mismatch at offset %d", offset)
+		}
+
+		var addSize int64
+		if headFlags&rar4LongBlock != 0 || headType == rar4BlockFile {
+			if headSize < 11 {
+				return nil, fmt.Errorf("invalid header size %d at offset %d", headSize, offset)
+			}
+			addSize = int64(binary.LittleEndian.Uint32(data[7:11]))
+		}
+
+		switch headType {
+		case rar4BlockMain:
+			mainSeen = true
+			h.Volume = headFlags&rar4MainVolume != 0
+			h.Solid = headFlags&rar4MainSolid != 0
+			h.NewNumbering = headFlags&rar4MainNewNum != 0
+			h.FirstVolume = headFlags&rar4MainFirstVol != 0
+			// RAR 3.0+ sets the first volume flag together with the new numbering flag
+			h.FirstVolumeKnown = h.FirstVolume || h.NewNumbering
+			if headFlags&rar4MainPassword != 0 {
+				h.EncryptedHeaders = true
+				return h, nil
+			}
+		case rar4BlockFile:
+			if !mainSeen {
+				return nil, fmt.Errorf("file header before main archive header")
+			}
+			file, err := parseRAR4File(data, headFlags)
+			if err != nil {
+				return nil, fmt.Errorf("invalid file header at offset %d: %w", offset, err)
+			}
+			if headFlags&rar4FileLarge != 0 && len(data) >= 36 {
+				addSize |= int64(binary.LittleEndian.Uint32(data[32:36])) << 32
+			}
+			h.Files = append(h.Files, file)
+		case rar4BlockEnd:
+			h.HasEnd = true
+			h.NextVolume = headFlags&rar4EndNextVol != 0
+			if headFlags&rar4EndVolNumber != 0 {
+				pos := 7
+				if headFlags&rar4EndDataCRC != 0 {
+					pos += 4
+				}
+				if len(data) >= pos+2 {
+					h.VolumeNumber = int(binary.LittleEndian.Uint16(data[pos : pos+2]))
+				}
+			}
+			return h, nil
+		}
+
+		offset += headSize + addSize
+		if offset > size {
+			return nil, fmt.Errorf("truncated data area: volume is %d bytes, expected at least %d", size, offset)
+		}
+	}
+
+	if !mainSeen {
+		return nil, fmt.Errorf("main archive header not found")
+	}
+	return h, nil
+}
+
+// parseRAR4File parses the fixed part of a RAR4 file header
+func parseRAR4File(data []byte, flags uint16) (FileHeader, error) {
+	// base(7) + PACK_SIZE(4) UNP_SIZE(4) HOST_OS(1) FILE_CRC(4) FTIME(4) UNP_VER(1) METHOD(1) NAME_SIZE(2) ATTR(4)
+	const fixed = 32
+	if len(data) < fixed {
+		return FileHeader{}, fmt.Errorf("header too short")
+	}
+
+	nameSize := int(binary.LittleEndian.Uint16(data[26:28]))
+	pos := fixed
+	if flags&rar4FileLarge != 0 {
+		pos += 8
+	}
+	if len(data) < pos+nameSize {
+		return FileHeader{}, fmt.Errorf("file name exceeds header")
+	}
+
+	name := data[pos : pos+nameSize]
+	// Unicode names are stored as "ascii\x00encoded"; the ASCII part is enough for comparison
+	if i := bytes.IndexByte(name, 0); i >= 0 {
+		name = name[:i]
+	}
+
+	return FileHeader{
+		Name:        string(name),
+		SplitBefore: flags&rar4FileSplitBef != 0,
+		SplitAfter:  flags&rar4FileSplitAft != 0,
+	}, nil
+}
+
+// readRAR5 walks the headers of a RAR 5.0+ volume
+func readRAR5(r io.ReaderAt, size int64) (*Header, error) {
+	h := &Header{Format: FormatRAR5, NewNumbering: true, FirstVolumeKnown: true, VolumeNumber: -1}
+	offset := int64(len(signatureRAR5))
+	mainSeen := false
+
+	for block := 0; block < maxHeaderBlocks && offset < size; block++ {
+		// CRC32 (4 bytes) followed by the header size as vint (at most 3 bytes for 2 MB headers)
+		prefix := make([]byte, 7)
+		n, err := r.ReadAt(prefix, offset)
+		if n < 5 {
+			return nil, fmt.Errorf("truncated header at offset %d", offset)
+		}
+		if err != nil && !errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("failed to read header at offset %d: %w", offset, err)
+		}
+		prefix = prefix[:n]
+
+		headCRC := binary.LittleEndian.Uint32(prefix[0:4])
+		headSize, sizeLen, ok := readVint(prefix[4:])
+		if !ok || headSize == 0 || headSize > 2*1024*1024 {
+			return nil, fmt.Errorf("invalid header size at offset %d", offset)
+		}
+
+		total := int64(4+sizeLen) + int64(headSize)
+		if offset+total > size {
+			return nil, fmt.Errorf("truncated header at offset %d", offset)
+		}
+
+		data := make([]byte, total)
+		if _, err := r.ReadAt(data, offset); err != nil {
+			return nil, fmt.Errorf("truncated header at offset %d", offset)
+		}
+		if crc32.ChecksumIEEE(data[4:]) != headCRC {
+			return nil, fmt.Errorf("header CRC mismatch at offset %d", offset)
+		}
+
+		fields := &vintReader{buf: data[4+sizeLen:]}
+		headType := fields.next()
+		headFlags := fields.next()
+		if headFlags&rar5FlagExtra != 0 {
+			fields.next()
+		}
+		var dataSize uint64
+		if headFlags&rar5FlagData != 0 {
+			dataSize = fields.next()
+		}
+		if fields.err {
+			return nil, fmt.Errorf("malformed header at offset %d", offset)
+		}
+
+		switch headType {
+		case rar5HeaderMain:
+			mainSeen = true
+			archiveFlags := fields.next()
+			h.Volume = archiveFlags&rar5MainVolume != 0
+			h.Solid = archiveFlags&rar5MainSolid != 0
+			if archiveFlags&rar5MainVolumeNumber != 0 {
+				h.VolumeNumber = int(fields.next())
+			} else {
+				// The volume number field is omitted only in the first volume
+				h.FirstVolume = true
+				h.VolumeNumber = 0
+			}
+			if fields.err {
+				return nil, fmt.Errorf("malformed main archive header")
+			}
+		case rar5HeaderEncryption:
+			h.EncryptedHeaders = true
+			return h, nil
+		case rar5HeaderFile:
+			if !mainSeen {
+				return nil, fmt.Errorf("file header before main archive header")
+			}
+			fileFlags := fields.next()
+			fields.next() // unpacked size
+			fields.next() // attributes
+			if fileFlags&rar5FileHasMTime != 0 {
+				fields.skip(4)
+			}
+			if fileFlags&rar5FileHasCRC != 0 {
+				fields.skip(4)
+			}
+			fields.next() // compression information
+			fields.next() // host OS
+			nameLen := fields.next()
+			name := fields.bytes(int(nameLen))
+			if fields.err {
+				return nil, fmt.Errorf("invalid file header at offset %d", offset)
+			}
+			h.Files = append(h.Files, FileHeader{
+				Name:        string(name),
+				SplitBefore: headFlags&rar5FlagSplitBefore != 0,
+				SplitAfter:  headFlags&rar5FlagSplitAfter != 0,
+			})
+		case rar5HeaderEnd:
+			h.HasEnd = true
+			endFlags := fields.next()
+			h.NextVolume = endFlags&rar5EndNotLast != 0
+			return h, nil
+		}
+
+		offset += total + int64(dataSize)
+		if offset > size {
+			return nil, fmt.Errorf("truncated data area: volume is %d bytes, expected at least %d", size, offset)
+		}
+	}
+
+	if !mainSeen {
+		return nil, fmt.Errorf("main archive header not found")
+	}
+	return h, nil
+}
+
+// readVint decodes a RAR5 variable length integer
+func readVint(buf []byte) (uint64, int, bool) {
+	var value uint64
+	for i := 0; i < len(buf) && i < 10; i++ {
+		value |= uint64(buf[i]&0x7f) << (7 * i)
+		if buf[i]&0x80 == 0 {
+			return value, i + 1, true
+		}
+	}
+	return 0, 0, false
+}
+
+// vintReader reads consecutive RAR5 header fields, remembering the first error
+type vintReader struct {
+	buf []byte
+	err bool
+}
+
+func (v *vintReader) next() uint64 {
+	if v.err {
+		return 0
+	}
+	value, n, ok := readVint(v.buf)
+	if !ok {
+		v.err = true
+		return 0
+	}
+	v.buf = v.buf[n:]
+	return value
+}
+
+func (v *vintReader) skip(n int) {
+	v.bytes(n)
+}
+
+func (v *vintReader) bytes(n int) []byte {
+	if v.err || n < 0 || n > len(v.buf) {
+		v.err = true
+		return nil
+	}
+	b := v.buf[:n]
+	v.buf = v.buf[n:]
+	return b
+}
@@ -0,0 +1,68 @@
+package rar
+
+// OutputResult represents the JSON/YAML output structure for RAR set validation
+type OutputResult struct {
+	Set            string         `json:"set" yaml:"set"`
+	Dir            string         `json:"dir" yaml:"dir"`
+	Scheme         string         `json:"scheme" yaml:"scheme"`
+	Format         string         `json:"format,omitempty" yaml:"format,omitempty"`
+	Valid          bool           `json:"valid" yaml:"valid"`
+	TotalVolumes   int            `json:"total_volumes" yaml:"total_volumes"`
+	MissingVolumes []string       `json:"missing_volumes,omitempty" yaml:"missing_volumes,omitempty"`
+	Volumes        []VolumeOutput `json:"volumes,omitempty" yaml:"volumes,omitempty"`
+	Errors         []string       `json:"errors,omitempty" yaml:"errors,omitempty"`
+}
+
+type VolumeOutput struct {
+	Name         string `json:"name" yaml:"name"`
+	Index        int    `json:"index" yaml:"index"`
+	Format       string `json:"format,omitempty" yaml:"format,omitempty"`
+	Volume       bool   `json:"volume" yaml:"volume"`
+	FirstVolume  bool   `json:"first_volume" yaml:"first_volume"`
+	VolumeNumber int    `json:"volume_number" yaml:"volume_number"`
+	Solid        bool   `json:"solid" yaml:"solid"`
+	Error        string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// ConvertSetResult converts SetResult to OutputResult
+func ConvertSetResult(result *SetResult) *OutputResult {
+	output := &OutputResult{
+		Set:            result.Set.Name,
+		Dir:            result.Set.Dir,
+		Scheme:         string(result.Set.Scheme),
+		Format:         string(result.Format),
+		Valid:          result.Valid,
+		TotalVolumes:   len(result.Set.Volumes),
+		MissingVolumes: result.MissingVolumes,
+	}
+
+	if len(result.Set.Volumes) > 0 {
+		output.Volumes = make([]VolumeOutput, len(result.Set.Volumes))
+		for i, volume := range result.Set.Volumes {
+			output.Volumes[i] = VolumeOutput{
+				Name:         volume.Name,
+				Index:        volume.Index,
+				VolumeNumber: -1,
+			}
+			if h := volume.Header; h != nil {
+				output.Volumes[i].Format = string(h.Format)
+				output.Volumes[i].Volume = h.Volume
+				output.Volumes[i].FirstVolume = h.FirstVolume
+				output.Volumes[i].VolumeNumber = h.VolumeNumber
+				output.Volumes[i].Solid = h.Solid
+			}
+			if volume.Error != nil {
+				output.Volumes[i].Error = volume.Error.Error()
+			}
+		}
+	}
+
+	if len(result.Errors) > 0 {
+		output.Errors = make([]string, len(result.Errors))
+		for i, err := range result.Errors {
+			output.Errors[i] = err.Error()
+		}
+	}
+
+	return output
+}
@@ -0,0 +1,273 @@
+package rar
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// buildRAR4Volume creates a minimal RAR4 volume holding one split file
+func buildRAR4Volume(index, total int, newNumbering bool) []byte {
+	var buf bytes.Buffer
+	buf.Write(signatureRAR4)
+
+	block := func(headType byte, flags uint16, body []byte) {
+		data := make([]byte, 7+len(body))
+		data[2] = headType
+		binary.LittleEndian.PutUint16(data[3:5], flags)
+		binary.LittleEndian.PutUint16(data[5:7], uint16(len(data)))
+		copy(data[7:], body)
+		binary.LittleEndian.PutUint16(data[0:2], uint16(crc32.ChecksumIEEE(data[2:])))
+		buf.Write(data)
+	}
+
+	mainFlags := uint16(rar4MainVolume)
+	if newNumbering {
+		mainFlags |= rar4MainNewNum
+	}
+	if index == 0 {
+		mainFlags |= rar4MainFirstVol
+	}
+	block(rar4BlockMain, mainFlags, make([]byte, 6))
+
+	payload := []byte("payload")
+	name := []byte("movie.mkv")
+	fileBody := make([]byte, 25+len(name))
+	binary.LittleEndian.PutUint32(fileBody[0:4], uint32(len(payload)))
+	binary.LittleEndian.PutUint16(fileBody[19:21], uint16(len(name)))
+	copy(fileBody[25:], name)
+	fileFlags := uint16(rar4LongBlock)
+	if index > 0 {
+		fileFlags |= rar4FileSplitBef
+	}
+	if index < total-1 {
+		fileFlags |= rar4FileSplitAft
+	}
+	block(rar4BlockFile, fileFlags, fileBody)
+	buf.Write(payload)
+
+	endFlags := uint16(rar4EndVolNumber)
+	if index < total-1 {
+		endFlags |= rar4EndNextVol
+	}
+	endBody := make([]byte, 2)
+	binary.LittleEndian.PutUint16(endBody, uint16(index))
+	block(rar4BlockEnd, endFlags, endBody)
+
+	return buf.Bytes()
+}
+
+// buildRAR5Volume creates a minimal RAR5 volume holding one split file
+func buildRAR5Volume(index, total int) []byte {
+	var buf bytes.Buffer
+	buf.Write(signatureRAR5)
+
+	vint := func(v uint64) []byte {
+		var out []byte
+		for {
+			b := byte(v & 0x7f)
+			v >>= 7
+			if v != 0 {
+				out = append(out, b|0x80)
+				continue
+			}
+			return append(out, b)
+		}
+	}
+	header := func(fields ...[]byte) {
+		data := bytes.Join(fields, nil)
+		sized := append(vint(uint64(len(data))), data...)
+		crc := make([]byte, 4)
+		binary.LittleEndian.PutUint32(crc, crc32.ChecksumIEEE(sized))
+		buf.Write(crc)
+		buf.Write(sized)
+	}
+
+	archiveFlags := uint64(rar5MainVolume)
+	if index == 0 {
+		header(vint(rar5HeaderMain), vint(0), vint(archiveFlags))
+	} else {
+		archiveFlags |= rar5MainVolumeNumber
+		header(vint(rar5HeaderMain), vint(0), vint(archiveFlags), vint(uint64(index)))
+	}
+
+	payload := []byte("payload")
+	name := []byte("movie.mkv")
+	flags := uint64(rar5FlagData)
+	if index > 0 {
+		flags |= rar5FlagSplitBefore
+	}
+	if index < total-1 {
+		flags |= rar5FlagSplitAfter
+	}
+	header(vint(rar5HeaderFile), vint(flags), vint(uint64(len(payload))),
+		vint(0), vint(uint64(len(payload))), vint(0), vint(0), vint(0), vint(uint64(len(name))), name)
+	buf.Write(payload)
+
+	var endFlags uint64
+	if index < total-1 {
+		endFlags = rar5EndNotLast
+	}
+	header(vint(rar5HeaderEnd), vint(0), vint(endFlags))
+
+	return buf.Bytes()
+}
+
+func writeVolumes(t *testing.T, dir string, volumes map[string][]byte) {
+	t.Helper()
+	for name, data := range volumes {
+		if err := os.WriteFile(filepath.Join(dir, name), data, 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+}
+
+func TestParseVolumeName(t *testing.T) {
+	tests := []struct {
+		name   string
+		base   string
+		index  int
+		scheme Scheme
+		ok     bool
+	}{
+		{"movie.rar", "movie", 0, SchemeOld, true},
+		{"movie.r00", "movie", 1, SchemeOld, true},
+		{"movie.r99", "movie", 100, SchemeOld, true},
+		{"movie.s00", "movie", 101, SchemeOld, true},
+		{"MOVIE.R05", "MOVIE", 6, SchemeOld, true},
+		{"movie.part01.rar", "movie", 0, SchemeNew, true},
+		{"movie.part10.rar", "movie", 9, SchemeNew, true},
+		{"movie.nfo", "", 0, "", false},
+		{"movie.srt", "", 0, "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v, ok := ParseVolumeName(tt.name)
+			if ok != tt.ok {
+				t.Fatalf("ParseVolumeName(%q) ok = %v, want %v", tt.name, ok, tt.ok)
+			}
+			if !ok {
+				return
+			}
+			if v.Base != tt.base || v.Index != tt.index || v.Scheme != tt.scheme {
+				t.Errorf("ParseVolumeName(%q) = %+v", tt.name, v)
+			}
+			if got := v.FileName(v.Index); got != tt.name {
+				t.Errorf("FileName(%d) = %q, want %q", v.Index, got, tt.name)
+			}
+		})
+	}
+}
+
+func TestValidateSet_RAR4Complete(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeVolumes(t, tmpDir, map[string][]byte{
+		"movie.rar": buildRAR4Volume(0, 3, false),
+		"movie.r00": buildRAR4Volume(1, 3, false),
+		"movie.r01": buildRAR4Volume(2, 3, false),
+	})
+
+	sets, err := FindVolumeSets(tmpDir)
+	if err != nil {
+		t.Fatalf("Failed to find volume sets: %v", err)
+	}
+	if len(sets) != 1 {
+		t.Fatalf("Expected 1 set, got %d", len(sets))
+	}
+
+	result := ValidateSet(sets[0])
+	if !result.Valid {
+		t.Errorf("Expected valid set, got errors: %v", result.Errors)
+	}
+	if result.Format != FormatRAR4 {
+		t.Errorf("Expected format rar4, got %s", result.Format)
+	}
+}
+
+func TestValidateSet_MissingVolume(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeVolumes(t, tmpDir, map[string][]byte{
+		"movie.rar": buildRAR4Volume(0, 4, false),
+		"movie.r00": buildRAR4Volume(1, 4, false),
+		"movie.r02": buildRAR4Volume(3, 4, false),
+	})
+
+	sets, err := FindVolumeSets(tmpDir)
+	if err != nil {
+		t.Fatalf("Failed to find volume sets: %v", err)
+	}
+
+	result := ValidateSet(sets[0])
+	if result.Valid {
+		t.Fatal("Expected invalid set")
+	}
+	if len(result.MissingVolumes) != 1 || result.MissingVolumes[0] != "movie.r01" {
+		t.Errorf("Expected missing movie.r01, got %v", result.MissingVolumes)
+	}
+}
+
+func TestValidateSet_RAR5NewNaming(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeVolumes(t, tmpDir, map[string][]byte{
+		"movie.part1.rar": buildRAR5Volume(0, 3),
+		"movie.part2.rar": buildRAR5Volume(1, 3),
+		"movie.part3.rar": buildRAR5Volume(2, 3),
+	})
+
+	sets, err := FindVolumeSets(tmpDir)
+	if err != nil {
+		t.Fatalf("Failed to find volume sets: %v", err)
+	}
+
+	result := ValidateSet(sets[0])
+	if !result.Valid {
+		t.Errorf("Expected valid set, got errors: %v", result.Errors)
+	}
+
+	// Dropping the last volume leaves a set whose last file continues in a next volume
+	if err := os.Remove(filepath.Join(tmpDir, "movie.part3.rar")); err != nil {
+		t.Fatalf("Failed to remove volume: %v", err)
+	}
+	sets, _ = FindVolumeSets(tmpDir)
+	result = ValidateSet(sets[0])
+	if result.Valid {
+		t.Error("Expected set without its last volume to be invalid")
+	}
+}
+
+func TestValidateSet_Corrupted(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	truncated := buildRAR5Volume(1, 2)
+	writeVolumes(t, tmpDir, map[string][]byte{
+		"movie.part1.rar": []byte("not a rar archive"),
+		"movie.part2.rar": truncated[:len(truncated)-12],
+	})
+
+	sets, err := FindVolumeSets(tmpDir)
+	if err != nil {
+		t.Fatalf("Failed to find volume sets: %v", err)
+	}
+
+	result := ValidateSet(sets[0])
+	if result.Valid {
+		t.Fatal("Expected invalid set")
+	}
+
+	var messages []string
+	for _, err := range result.Errors {
+		messages = append(messages, err.Error())
+	}
+	joined := strings.Join(messages, "\n")
+	if !strings.Contains(joined, "invalid RAR signature") {
+		t.Errorf("Expected signature error, got:\n%s", joined)
+	}
+	if !strings.Contains(joined, "truncated") {
+		t.Errorf("Expected truncation error, got:\n%s", joined)
+	}
+}
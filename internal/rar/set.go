@@ -0,0 +1,272 @@
+package rar
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Volume represents a single volume of a RAR set
+type Volume struct {
+	Name   string // File name of the volume
+	Path   string // Full path to the volume
+	Index  int    // Zero-based position in the set, derived from the file name
+	Size   int64  // Size of the volume file in bytes (0 if its headers were read elsewhere)
+	Header *Header
+	Error  error // Error reading the volume headers
+}
+
+// VolumeSet represents all volumes sharing the same base name and naming scheme
+type VolumeSet struct {
+	Name    string // File name of the first volume (present or not)
+	Dir     string // Directory containing the volumes
+	Scheme  Scheme
+	Volumes []Volume // Volumes found on disk, ordered by index
+	naming  VolumeName
+}
+
+// SetResult represents the result of validating a RAR volume set
+type SetResult struct {
+	Set            VolumeSet
+	Format         Format // Format of the first readable volume
+	Valid          bool
+	MissingVolumes []string // Expected volume file names that are not on disk
+	Errors         []error
+}
+
+// FindVolumeSets finds all RAR volume sets in the given directory
+func FindVolumeSets(dir string) ([]*VolumeSet, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read directory: %w", err)
+	}
+
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			names = append(names, entry.Name())
+		}
+	}
+
+	return GroupVolumes(dir, names), nil
+}
+
+// FindVolumeSetsRecursive finds all RAR volume sets recursively in the given directory
+func FindVolumeSetsRecursive(dir string) ([]*VolumeSet, error) {
+	var sets []*VolumeSet
+
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			// Continue on errors (e.g., permission denied)
+			return nil
+		}
+
+		if info.IsDir() {
+			dirSets, err := FindVolumeSets(path)
+			if err == nil {
+				sets = append(sets, dirSets...)
+			}
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("error walking directory: %w", err)
+	}
+
+	return sets, nil
+}
+
+// GroupVolumes groups the RAR volumes among the given file names into sets.
+// Names that are not RAR volumes are ignored.
+func GroupVolumes(dir string, names []string) []*VolumeSet {
+	setsByKey := make(map[string]*VolumeSet)
+	var keys []string
+
+	for _, name := range names {
+		parsed, ok := ParseVolumeName(name)
+		if !ok {
+			continue
+		}
+
+		key := parsed.Key()
+		set, exists := setsByKey[key]
+		if !exists {
+			set = &VolumeSet{Dir: dir, Scheme: parsed.Scheme, naming: parsed}
+			setsByKey[key] = set
+			keys = append(keys, key)
+		}
+
+		// Prefer the naming (case, width) of the first volume
+		if parsed.Index == 0 {
+			set.naming = parsed
+		}
+
+		set.Volumes = append(set.Volumes, Volume{
+			Name:  name,
+			Path:  filepath.Join(dir, name),
+			Index: parsed.Index,
+		})
+	}
+
+	sets := make([]*VolumeSet, 0, len(keys))
+	sort.Strings(keys)
+	for _, key := range keys {
+		set := setsByKey[key]
+		sort.SliceStable(set.Volumes, func(i, j int) bool {
+			return set.Volumes[i].Index < set.Volumes[j].Index
+		})
+		set.Name = set.naming.FileName(0)
+		sets = append(sets, set)
+	}
+
+	return sets
+}
+
+// Contains reports whether the set has a volume with the given file name (case insensitive)
+func (s *VolumeSet) Contains(name string) bool {
+	for _, volume := range s.Volumes {
+		if strings.EqualFold(volume.Name, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// TotalBytes returns the size of the volumes whose headers were read from disk
+func (r *SetResult) TotalBytes() int64 {
+	var total int64
+	for _, volume := range r.Set.Volumes {
+		total += volume.Size
+	}
+	return total
+}
+
+// ValidateSet reads the headers of every volume in the set and checks that
+// the set is complete, continuous and internally consistent
+func ValidateSet(set *VolumeSet) *SetResult {
+	result := &SetResult{
+		Set:   *set,
+		Valid: true,
+	}
+	result.Set.Volumes = make([]Volume, len(set.Volumes))
+	copy(result.Set.Volumes, set.Volumes)
+
+	fail := func(format string, args ...any) {
+		result.Valid = false
+		result.Errors = append(result.Errors, fmt.Errorf(format, args...))
+	}
+
+	// Continuity: every index from 0 up to the highest volume must be present
+	present := make(map[int]string)
+	for _, volume := range result.Set.Volumes {
+		if other, exists := present[volume.Index]; exists {
+			fail("duplicate volume %d: %s and %s", volume.Index+1, other, volume.Name)
+			continue
+		}
+		present[volume.Index] = volume.Name
+	}
+
+	last := -1
+	if n := len(result.Set.Volumes); n > 0 {
+		last = result.Set.Volumes[n-1].Index
+	}
+	for i := 0; i <= last; i++ {
+		if _, exists := present[i]; !exists {
+			name := set.naming.FileName(i)
+			result.MissingVolumes = append(result.MissingVolumes, name)
+			fail("missing volume %s", name)
+		}
+	}
+
+	// Read the headers of every volume, unless the caller already did (e.g. from inside a ZIP file)
+	for i := range result.Set.Volumes {
+		volume := &result.Set.Volumes[i]
+		if volume.Header == nil && volume.Error == nil {
+			if info, err := os.Stat(volume.Path); err == nil {
+				volume.Size = info.Size()
+			}
+			volume.Header, volume.Error = ReadHeaderFile(volume.Path)
+		}
+		if volume.Error != nil {
+			fail("%s: %v", volume.Name, volume.Error)
+		}
+	}
+
+	multiVolume := len(result.Set.Volumes) > 1 || last > 0
+	anyFirstFlag := false
+	for _, volume := range result.Set.Volumes {
+		if volume.Header != nil && volume.Header.FirstVolume && volume.Header.Format == FormatRAR4 {
+			anyFirstFlag = true
+		}
+	}
+
+	var first *Header
+	for i, volume := range result.Set.Volumes {
+		h := volume.Header
+		if h == nil {
+			continue
+		}
+
+		if first == nil {
+			first = h
+			result.Format = h.Format
+		} else {
+			if h.Format != first.Format {
+				fail("%s: archive format %s differs from %s", volume.Name, h.Format, first.Format)
+			}
+			if h.Solid != first.Solid {
+				fail("%s: solid flag differs from the rest of the set", volume.Name)
+			}
+		}
+
+		if multiVolume && !h.Volume {
+			fail("%s: volume flag not set in a multi-volume set", volume.Name)
+		}
+
+		if h.Format == FormatRAR4 && h.NewNumbering != (set.Scheme == SchemeNew) {
+			fail("%s: archive naming flag does not match %s-style volume names", volume.Name, set.Scheme)
+		}
+
+		// Volume position recorded in the archive must match the file name
+		if h.VolumeNumber >= 0 && h.VolumeNumber != volume.Index {
+			fail("%s: archive declares volume %d, but file name indicates volume %d", volume.Name, h.VolumeNumber+1, volume.Index+1)
+		}
+		if volume.Index == 0 && !h.FirstVolume && (h.FirstVolumeKnown || anyFirstFlag) {
+			fail("%s: first volume flag not set", volume.Name)
+		}
+		if volume.Index > 0 && h.FirstVolume {
+			fail("%s: first volume flag set on volume %d", volume.Name, volume.Index+1)
+		}
+
+		if h.EncryptedHeaders {
+			continue
+		}
+
+		// Split flags must chain the volumes together
+		isLast := i == len(result.Set.Volumes)-1
+		if len(h.Files) > 0 {
+			if volume.Index == 0 && h.Files[0].SplitBefore {
+				fail("%s: first volume continues a file from a previous volume", volume.Name)
+			}
+			if volume.Index > 0 && !h.Files[0].SplitBefore {
+				fail("%s: file does not continue from the previous volume", volume.Name)
+			}
+			lastFile := h.Files[len(h.Files)-1]
+			if !isLast && !lastFile.SplitAfter {
+				fail("%s: file does not continue in the next volume", volume.Name)
+			}
+			if isLast && lastFile.SplitAfter {
+				fail("%s: last volume found, but its file continues in a next volume (set is incomplete)", volume.Name)
+			}
+		}
+		if isLast && h.HasEnd && h.NextVolume {
+			fail("%s: last volume found, but the archive expects a next volume (set is incomplete)", volume.Name)
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,29 @@
+package rar
+
+// OutputFormat represents the output format type
+type OutputFormat string
+
+const (
+	OutputFormatText   OutputFormat = "text"
+	OutputFormatJSON   OutputFormat = "json"
+	OutputFormatYAML   OutputFormat = "yaml"
+	OutputFormatNDJSON OutputFormat = "ndjson"
+)
+
+// Options contains configuration options for RAR validation
+type Options struct {
+	Verbose      bool         // Verbose output
+	Quiet        bool         // Quiet mode (minimal output)
+	Recursive    bool         // Recursive mode - search subdirectories
+	OutputFormat OutputFormat // Output format: text, json, yaml, or ndjson
+}
+
+// DefaultOptions returns default options for RAR validation
+func DefaultOptions() Options {
+	return Options{
+		Verbose:      false,
+		Quiet:        false,
+		Recursive:    false,
+		OutputFormat: OutputFormatText,
+	}
+}
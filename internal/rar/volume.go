@@ -0,0 +1,119 @@
+package rar
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Scheme represents the naming scheme of a RAR volume set
+type Scheme string
+
+const (
+	// SchemeOld is the old-style naming: name.rar, name.r00 ... name.r99, name.s00 ...
+	SchemeOld Scheme = "old"
+	// SchemeNew is the new-style naming: name.part01.rar, name.part02.rar ...
+	SchemeNew Scheme = "new"
+)
+
+// VolumeName is a parsed RAR volume file name
+type VolumeName struct {
+	Base   string // Name of the set without the volume suffix (original case)
+	Index  int    // Zero-based position of the volume in the set
+	Scheme Scheme
+	Width  int  // Number of digits of the part number (new-style naming only)
+	Upper  bool // Whether the volume extension is upper case
+}
+
+// ParseVolumeName parses a file name into its RAR volume components.
+// It returns false if the name is not a RAR volume.
+func ParseVolumeName(filename string) (VolumeName, bool) {
+	ext := filepath.Ext(filename)
+	lowerExt := strings.ToLower(ext)
+	base := strings.TrimSuffix(filename, ext)
+	upper := ext != lowerExt
+
+	if lowerExt == ".rar" {
+		partExt := filepath.Ext(base)
+		lowerPart := strings.ToLower(partExt)
+		if strings.HasPrefix(lowerPart, ".part") && isDigits(lowerPart[len(".part"):]) {
+			digits := lowerPart[len(".part"):]
+			n, _ := strconv.Atoi(digits)
+			if n > 0 {
+				return VolumeName{
+					Base:   strings.TrimSuffix(base, partExt),
+					Index:  n - 1,
+					Scheme: SchemeNew,
+					Width:  len(digits),
+					Upper:  upper,
+				}, true
+			}
+		}
+		// Old-style naming: the .rar file is the first volume
+		return VolumeName{Base: base, Index: 0, Scheme: SchemeOld, Upper: upper}, true
+	}
+
+	// Old-style continuation volumes: .r00 ... .r99, .s00 ... .z99
+	if len(lowerExt) == 4 && lowerExt[1] >= 'r' && lowerExt[1] <= 'z' && isDigits(lowerExt[2:]) {
+		n, _ := strconv.Atoi(lowerExt[2:])
+		return VolumeName{
+			Base:   base,
+			Index:  1 + int(lowerExt[1]-'r')*100 + n,
+			Scheme: SchemeOld,
+			Upper:  upper,
+		}, true
+	}
+
+	return VolumeName{}, false
+}
+
+// Key returns the case-insensitive identifier of the set the volume belongs to
+func (v VolumeName) Key() string {
+	return string(v.Scheme) + ":" + strings.ToLower(v.Base)
+}
+
+// FileName returns the file name of the volume at the given index of the same set
+func (v VolumeName) FileName(index int) string {
+	var name string
+	switch v.Scheme {
+	case SchemeNew:
+		width := v.Width
+		if width == 0 {
+			width = 2
+		}
+		name = fmt.Sprintf("%s.part%0*d.rar", v.Base, width, index+1)
+	default:
+		if index == 0 {
+			name = v.Base + ".rar"
+		} else {
+			n := index - 1
+			name = fmt.Sprintf("%s.%c%02d", v.Base, 'r'+rune(n/100), n%100)
+		}
+	}
+
+	if v.Upper {
+		ext := filepath.Ext(name)
+		name = strings.TrimSuffix(name, ext) + strings.ToUpper(ext)
+		if v.Scheme == SchemeNew {
+			base := strings.TrimSuffix(name, filepath.Ext(name))
+			partExt := filepath.Ext(base)
+			name = strings.TrimSuffix(base, partExt) + strings.ToUpper(partExt) + filepath.Ext(name)
+		}
+	}
+
+	return name
+}
+
+// isDigits reports whether s is a non-empty string of ASCII digits
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
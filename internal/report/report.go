@@ -0,0 +1,237 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Format represents a machine-readable report format
+type Format string
+
+const (
+	FormatJSON   Format = "json"   // A single JSON document written when the run finishes
+	FormatYAML   Format = "yaml"   // A single YAML document written when the run finishes
+	FormatNDJSON Format = "ndjson" // One JSON event per line, streamed while the run progresses
+)
+
+// toolName is the name reported in every report
+const toolName = "sfvbrr"
+
+// version is the tool version reported in every report
+var version = "dev"
+
+// SetVersion sets the tool version reported in every report
+func SetVersion(v string) {
+	if v != "" {
+		version = v
+	}
+}
+
+// Totals contains the aggregated counters of a run
+type Totals struct {
+	Items  int            `json:"items" yaml:"items"`
+	Passed int            `json:"passed" yaml:"passed"`
+	Failed int            `json:"failed" yaml:"failed"`
+	Errors int            `json:"errors" yaml:"errors"`
+	Bytes  int64          `json:"bytes,omitempty" yaml:"bytes,omitempty"`   // Bytes verified: read by the sfv and zip checks, volume sizes of the rar check
+	Counts map[string]int `json:"counts,omitempty" yaml:"counts,omitempty"` // Command specific counters (e.g. total_files)
+}
+
+// Report is the top-level envelope of a JSON/YAML report
+type Report struct {
+	Tool       string    `json:"tool" yaml:"tool"`
+	Version    string    `json:"version" yaml:"version"`
+	Command    string    `json:"command" yaml:"command"`
+	StartedAt  time.Time `json:"started_at" yaml:"started_at"`
+	FinishedAt time.Time `json:"finished_at" yaml:"finished_at"`
+	Totals     Totals    `json:"totals" yaml:"totals"`
+	Results    []any     `json:"results" yaml:"results"`
+	Errors     []string  `json:"errors,omitempty" yaml:"errors,omitempty"`
+}
+
+// Event is a single line of an NDJSON report
+type Event struct {
+	Type       string     `json:"type"` // "start", "result", "error" or "summary"
+	Tool       string     `json:"tool,omitempty"`
+	Version    string     `json:"version,omitempty"`
+	Command    string     `json:"command,omitempty"`
+	StartedAt  *time.Time `json:"started_at,omitempty"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+	Failed     *bool      `json:"failed,omitempty"`
+	Result     any        `json:"result,omitempty"`
+	Error      string     `json:"error,omitempty"`
+	Totals     *Totals    `json:"totals,omitempty"`
+}
+
+// Writer collects per-item results into a single report, or streams them as NDJSON
+type Writer struct {
+	mu     sync.Mutex
+	out    io.Writer
+	format Format
+	report Report
+	err    error
+}
+
+// NewWriter creates a report writer for the given command that writes to stdout
+func NewWriter(format Format, command string) *Writer {
+	return NewWriterTo(os.Stdout, format, command)
+}
+
+// NewWriterTo creates a report writer for the given command that writes to out
+func NewWriterTo(out io.Writer, format Format, command string) *Writer {
+	w := &Writer{
+		out:    out,
+		format: format,
+		report: Report{
+			Tool:      toolName,
+			Version:   version,
+			Command:   command,
+			StartedAt: time.Now(),
+			Results:   make([]any, 0),
+		},
+	}
+
+	if format == FormatNDJSON {
+		w.emit(Event{
+			Type:      "start",
+			Tool:      toolName,
+			Version:   version,
+			Command:   command,
+			StartedAt: &w.report.StartedAt,
+		})
+	}
+
+	return w
+}
+
+// NewWriterFor creates a report writer for the given command that writes to stdout if
+// format is one of the machine-readable formats, or returns nil for any other output
+// format (e.g. text)
+func NewWriterFor(format string, command string) *Writer {
+	switch f := Format(format); f {
+	case FormatJSON, FormatYAML, FormatNDJSON:
+		return NewWriter(f, command)
+	default:
+		return nil
+	}
+}
+
+// Error prints an error to stderr and records it in the report w, if any
+func Error(w *Writer, err error) {
+	fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+	if w != nil {
+		w.AddError(err)
+	}
+}
+
+// Add records the result of a single item (an SFV file, a ZIP file, a folder, ...)
+func (w *Writer) Add(result any, failed bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.report.Totals.Items++
+	if failed {
+		w.report.Totals.Failed++
+	} else {
+		w.report.Totals.Passed++
+	}
+
+	if w.format == FormatNDJSON {
+		w.emit(Event{Type: "result", Failed: &failed, Result: result})
+		return
+	}
+	w.report.Results = append(w.report.Results, result)
+}
+
+// AddError records an error that is not tied to a single item result
+func (w *Writer) AddError(err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.report.Totals.Errors++
+
+	if w.format == FormatNDJSON {
+		w.emit(Event{Type: "error", Error: err.Error()})
+		return
+	}
+	w.report.Errors = append(w.report.Errors, err.Error())
+}
+
+// Count adds n to a command specific counter in the totals
+func (w *Writer) Count(key string, n int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.report.Totals.Counts == nil {
+		w.report.Totals.Counts = make(map[string]int)
+	}
+	w.report.Totals.Counts[key] += n
+}
+
+// AddBytes adds n to the number of bytes verified in the totals
+func (w *Writer) AddBytes(n int64) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.report.Totals.Bytes += n
+}
+
+// Close finishes the report. JSON and YAML documents are written here;
+// NDJSON streams end with a summary event.
+func (w *Writer) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.report.FinishedAt = time.Now()
+
+	switch w.format {
+	case FormatNDJSON:
+		w.emit(Event{
+			Type:       "summary",
+			FinishedAt: &w.report.FinishedAt,
+			Totals:     &w.report.Totals,
+		})
+	case FormatJSON:
+		encoder := json.NewEncoder(w.out)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(w.report); err != nil && w.err == nil {
+			w.err = err
+		}
+	case FormatYAML:
+		encoder := yaml.NewEncoder(w.out)
+		if err := encoder.Encode(w.report); err != nil && w.err == nil {
+			w.err = err
+		}
+		if err := encoder.Close(); err != nil && w.err == nil {
+			w.err = err
+		}
+	default:
+		return fmt.Errorf("unknown output format: %s", w.format)
+	}
+
+	if w.err != nil {
+		return fmt.Errorf("failed to write report: %w", w.err)
+	}
+	return nil
+}
+
+// emit writes a single NDJSON line; the caller must hold w.mu
+func (w *Writer) emit(event Event) {
+	data, err := json.Marshal(event)
+	if err != nil {
+		if w.err == nil {
+			w.err = err
+		}
+		return
+	}
+	data = append(data, '\n')
+	if _, err := w.out.Write(data); err != nil && w.err == nil {
+		w.err = err
+	}
+}
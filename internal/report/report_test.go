@@ -0,0 +1,91 @@
+package report
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestWriter_JSON(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewWriterTo(&buf, FormatJSON, "sfv")
+
+	w.Add(map[string]string{"path": "a.sfv"}, false)
+	w.Add(map[string]string{"path": "b.sfv"}, true)
+	w.AddError(errors.New("folder does not exist"))
+	w.Count("total_files", 3)
+	w.AddBytes(5 << 30)
+
+	if err := w.Close(); err != nil {
+		t.Fatalf("Failed to close report: %v", err)
+	}
+
+	var report Report
+	if err := json.Unmarshal(buf.Bytes(), &report); err != nil {
+		t.Fatalf("Report is not a single JSON document: %v", err)
+	}
+
+	if report.Tool != "sfvbrr" || report.Command != "sfv" {
+		t.Errorf("Unexpected tool/command: %s/%s", report.Tool, report.Command)
+	}
+	if report.Totals.Items != 2 || report.Totals.Passed != 1 || report.Totals.Failed != 1 || report.Totals.Errors != 1 {
+		t.Errorf("Unexpected totals: %+v", report.Totals)
+	}
+	if report.Totals.Counts["total_files"] != 3 {
+		t.Errorf("Expected total_files 3, got %d", report.Totals.Counts["total_files"])
+	}
+	if report.Totals.Bytes != 5<<30 {
+		t.Errorf("Expected 5 GiB verified, got %d bytes", report.Totals.Bytes)
+	}
+	if len(report.Results) != 2 {
+		t.Errorf("Expected 2 results, got %d", len(report.Results))
+	}
+	if report.FinishedAt.Before(report.StartedAt) {
+		t.Error("Expected finished_at after started_at")
+	}
+}
+
+func TestWriter_NDJSON(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewWriterTo(&buf, FormatNDJSON, "zip")
+
+	w.Add(map[string]string{"zip_file": "a.zip"}, false)
+	w.AddError(errors.New("no ZIP files found"))
+
+	if err := w.Close(); err != nil {
+		t.Fatalf("Failed to close report: %v", err)
+	}
+
+	var types []string
+	scanner := bufio.NewScanner(&buf)
+	for scanner.Scan() {
+		var event Event
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			t.Fatalf("Line is not valid JSON: %v", err)
+		}
+		types = append(types, event.Type)
+	}
+
+	expected := []string{"start", "result", "error", "summary"}
+	if len(types) != len(expected) {
+		t.Fatalf("Expected %d events, got %d (%v)", len(expected), len(types), types)
+	}
+	for i := range expected {
+		if types[i] != expected[i] {
+			t.Errorf("Event %d: expected %s, got %s", i, expected[i], types[i])
+		}
+	}
+}
+
+func TestNewWriterFor(t *testing.T) {
+	for _, format := range []string{"json", "yaml"} {
+		if NewWriterFor(format, "sfv") == nil {
+			t.Errorf("Expected a report writer for %s output", format)
+		}
+	}
+	if NewWriterFor("text", "sfv") != nil {
+		t.Error("Expected no report writer for text output")
+	}
+}
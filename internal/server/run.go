@@ -0,0 +1,180 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/autobrr/sfvbrr/internal/check"
+	"github.com/autobrr/sfvbrr/internal/checksum"
+	"github.com/autobrr/sfvbrr/internal/report"
+	"github.com/autobrr/sfvbrr/internal/validate"
+)
+
+// execute runs a job and returns its JSON report and verdict.
+// The report has the same format as the --json output of the matching command;
+// a cancelled job reports the results collected so far.
+func (s *Server) execute(ctx context.Context, req JobRequest) (json.RawMessage, bool) {
+	var buf bytes.Buffer
+	rep := report.NewWriterTo(&buf, report.FormatJSON, req.Type)
+	valid := true
+
+	add := func(result any, failed bool) {
+		rep.Add(result, failed)
+		if failed {
+			valid = false
+		}
+	}
+	addError := func(err error) {
+		rep.AddError(err)
+		valid = false
+	}
+
+	switch req.Type {
+	case JobValidate, JobCheck:
+		s.runReleases(ctx, req, add, addError)
+	case JobSFV:
+		s.runSFV(ctx, req, add, addError)
+	case JobZIP:
+		s.runZIP(ctx, req, add, addError)
+	default:
+		addError(fmt.Errorf("unknown job type %q", req.Type))
+	}
+	if ctx.Err() != nil {
+		addError(fmt.Errorf("job cancelled: %w", context.Cause(ctx)))
+	}
+
+	if err := rep.Close(); err != nil {
+		data, _ := json.Marshal(map[string]string{"error": err.Error()})
+		return data, false
+	}
+	return json.RawMessage(buf.Bytes()), valid
+}
+
+// runReleases runs a validate or check job on the release folders of the request
+func (s *Server) runReleases(ctx context.Context, req JobRequest, add func(any, bool), addError func(error)) {
+	releases := []string{req.Path}
+	if req.Recursive {
+		var err error
+		releases, err = validate.FindFoldersRecursive(req.Path, req.Category)
+		if err != nil {
+			addError(err)
+			return
+		}
+	}
+
+	checkOpts := check.DefaultOptions()
+	checkOpts.Workers = s.opts.Checksum.Workers
+	checkOpts.BufferSize = s.opts.Checksum.BufferSize
+	checkOpts.Quiet = true
+	checkOpts.AllowPath = s.allowPath
+
+	for _, release := range releases {
+		if ctx.Err() != nil {
+			return
+		}
+		category, err := validate.DetectCategory(release, req.Category)
+		if err != nil {
+			addError(fmt.Errorf("failed to detect category for %s: %w", release, err))
+			continue
+		}
+		if category == "" {
+			addError(fmt.Errorf("%s: unknown or unsupported release category", release))
+			continue
+		}
+
+		if req.Type == JobValidate {
+			result, err := validate.ValidateFolder(ctx, release, s.presetConfig, category)
+			if err != nil {
+				addError(fmt.Errorf("failed to validate folder: %w", err))
+				continue
+			}
+			add(validate.ConvertValidationResult(result), !result.Valid)
+			continue
+		}
+
+		result, err := check.CheckFolder(ctx, release, s.presetConfig, category, checkOpts)
+		if err != nil {
+			addError(fmt.Errorf("failed to check folder: %w", err))
+			continue
+		}
+		add(check.ConvertResult(result), !result.Valid)
+	}
+}
+
+// runSFV runs an sfv job on the SFV files of the request folder
+func (s *Server) runSFV(ctx context.Context, req JobRequest, add func(any, bool), addError func(error)) {
+	var sfvPaths []string
+	var err error
+	if req.Recursive {
+		sfvPaths, err = checksum.FindSFVFilesRecursive(req.Path)
+	} else {
+		sfvPaths, err = checksum.FindSFVFiles(req.Path)
+	}
+	if err != nil {
+		addError(fmt.Errorf("failed to find SFV files: %w", err))
+		return
+	}
+	if len(sfvPaths) == 0 {
+		addError(fmt.Errorf("no SFV or checksum files found in %s", req.Path))
+		return
+	}
+
+	opts := s.opts.Checksum
+	opts.Quiet = true
+	opts.CheckOrphans = req.Orphans
+	opts.AllowPath = s.allowPath
+
+	for _, sfvPath := range sfvPaths {
+		if ctx.Err() != nil {
+			return
+		}
+		if err := s.allowPath(sfvPath); err != nil {
+			addError(err)
+			continue
+		}
+		sfv, err := checksum.ParseManifestFile(sfvPath)
+		if err != nil {
+			addError(fmt.Errorf("failed to parse SFV file %s: %w", sfvPath, err))
+			continue
+		}
+		result, err := checksum.ValidateSFV(ctx, sfv, opts)
+		if err != nil {
+			addError(fmt.Errorf("failed to validate SFV %s: %w", sfvPath, err))
+			continue
+		}
+		add(checksum.ConvertValidationResult(result), result.Failed())
+	}
+}
+
+// runZIP runs a zip job on the ZIP files of the request folder
+func (s *Server) runZIP(ctx context.Context, req JobRequest, add func(any, bool), addError func(error)) {
+	var zipPaths []string
+	var err error
+	if req.Recursive {
+		zipPaths, err = checksum.FindZIPFilesRecursive(req.Path)
+	} else {
+		zipPaths, err = checksum.FindZIPFiles(req.Path)
+	}
+	if err != nil {
+		addError(fmt.Errorf("failed to find ZIP files: %w", err))
+		return
+	}
+	if len(zipPaths) == 0 {
+		addError(fmt.Errorf("no ZIP files found in %s", req.Path))
+		return
+	}
+
+	opts := s.opts.Checksum
+	opts.Quiet = true
+	opts.AllowPath = s.allowPath
+
+	results, err := checksum.ValidateZIPPaths(ctx, zipPaths, opts)
+	if err != nil {
+		addError(err)
+	}
+	for _, result := range results {
+		add(checksum.ConvertZIPValidationResult(result), result.Failed())
+	}
+}
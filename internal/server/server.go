@@ -0,0 +1,403 @@
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/autobrr/sfvbrr/internal/preset"
+)
+
+// Server runs validation jobs submitted over a small HTTP/JSON API:
+//
+//	POST /api/v1/jobs              submit a job
+//	GET  /api/v1/jobs              list jobs
+//	GET  /api/v1/jobs/{id}         job status
+//	GET  /api/v1/jobs/{id}/result  JSON report of a finished job
+//	GET  /healthz                  health check (no authentication)
+type Server struct {
+	opts         Options
+	roots        []string
+	presetConfig *preset.PresetConfig
+
+	mu    sync.Mutex
+	jobs  map[string]*job
+	queue chan *job
+}
+
+// New creates a server; the token and at least one root directory are required
+func New(opts Options) (*Server, error) {
+	if opts.Token == "" {
+		return nil, fmt.Errorf("an API token is required")
+	}
+	if len(opts.Roots) == 0 {
+		return nil, fmt.Errorf("at least one allowed root directory is required")
+	}
+	if opts.QueueSize <= 0 {
+		opts.QueueSize = DefaultOptions().QueueSize
+	}
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = 1
+	}
+
+	presetConfig, err := preset.LoadPresets(opts.PresetPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load presets: %w", err)
+	}
+
+	// Roots are compared with resolved request paths, so resolve them too
+	roots := make([]string, 0, len(opts.Roots))
+	for _, root := range opts.Roots {
+		absPath, err := filepath.Abs(root)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve path %s: %w", root, err)
+		}
+		resolved, err := filepath.EvalSymlinks(absPath)
+		if err != nil {
+			return nil, fmt.Errorf("%s does not exist: %w", root, err)
+		}
+		roots = append(roots, resolved)
+	}
+
+	return &Server{
+		opts:         opts,
+		roots:        roots,
+		presetConfig: presetConfig,
+		jobs:         make(map[string]*job),
+		queue:        make(chan *job, opts.QueueSize),
+	}, nil
+}
+
+// Run serves the API until the context is cancelled. Running jobs are finished
+// before it returns; queued jobs are dropped.
+func (s *Server) Run(ctx context.Context) error {
+	var wg sync.WaitGroup
+	workerCtx, cancelWorkers := context.WithCancel(ctx)
+	defer func() {
+		cancelWorkers()
+		wg.Wait()
+	}()
+
+	for i := 0; i < s.opts.Concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.worker(workerCtx)
+		}()
+	}
+
+	httpServer := &http.Server{
+		Addr:              s.opts.Addr,
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- httpServer.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errCh:
+		return fmt.Errorf("failed to serve: %w", err)
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := httpServer.Shutdown(shutdownCtx); err != nil {
+			return fmt.Errorf("failed to shut down: %w", err)
+		}
+		return nil
+	}
+}
+
+// worker runs queued jobs until the context is cancelled
+func (s *Server) worker(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case j := <-s.queue:
+			s.runJob(ctx, j)
+		}
+	}
+}
+
+// runJob runs a single job and stores its result. The job is cancelled when the
+// server shuts down or the job timeout expires.
+func (s *Server) runJob(ctx context.Context, j *job) {
+	if s.opts.JobTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.opts.JobTimeout)
+		defer cancel()
+	}
+
+	started := time.Now()
+	s.mu.Lock()
+	j.status.Status = StatusRunning
+	j.status.StartedAt = &started
+	s.mu.Unlock()
+
+	result, valid := s.execute(ctx, j.request)
+	status := StatusDone
+	if ctx.Err() != nil {
+		status = StatusCancelled
+	}
+
+	finished := time.Now()
+	s.mu.Lock()
+	j.status.Status = status
+	j.status.Valid = &valid
+	j.status.FinishedAt = &finished
+	j.result = result
+	s.mu.Unlock()
+}
+
+// Handler returns the HTTP handler of the API
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+	})
+	mux.Handle("POST /api/v1/jobs", s.authenticate(s.handleSubmit))
+	mux.Handle("GET /api/v1/jobs", s.authenticate(s.handleList))
+	mux.Handle("GET /api/v1/jobs/{id}", s.authenticate(s.handleStatus))
+	mux.Handle("GET /api/v1/jobs/{id}/result", s.authenticate(s.handleResult))
+	return mux
+}
+
+// authenticate rejects requests without the bearer token
+func (s *Server) authenticate(next http.HandlerFunc) http.Handler {
+	expected := []byte("Bearer " + s.opts.Token)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), expected) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			writeError(w, http.StatusUnauthorized, "invalid or missing API token")
+			return
+		}
+		next(w, r)
+	})
+}
+
+// handleSubmit queues a new job
+func (s *Server) handleSubmit(w http.ResponseWriter, r *http.Request) {
+	var req JobRequest
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid request: %v", err))
+		return
+	}
+
+	switch req.Type {
+	case JobValidate, JobSFV, JobZIP, JobCheck:
+	default:
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("unknown job type %q (expected one of validate, sfv, zip, check)", req.Type))
+		return
+	}
+	if req.Category != "" {
+		if _, exists := s.presetConfig.Rules[req.Category]; !exists {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid category '%s': category not found in preset configuration", req.Category))
+			return
+		}
+	}
+
+	path, code, err := s.resolvePath(req.Path)
+	if err != nil {
+		writeError(w, code, err.Error())
+		return
+	}
+	req.Path = path
+
+	j := &job{
+		request: req,
+		status: JobStatus{
+			ID:        newJobID(),
+			Type:      req.Type,
+			Path:      req.Path,
+			Recursive: req.Recursive,
+			Status:    StatusQueued,
+			CreatedAt: time.Now(),
+		},
+	}
+
+	s.mu.Lock()
+	s.pruneLocked(time.Now())
+	select {
+	case s.queue <- j:
+		s.jobs[j.status.ID] = j
+	default:
+		s.mu.Unlock()
+		writeError(w, http.StatusServiceUnavailable, "job queue is full")
+		return
+	}
+	status := j.status
+	s.mu.Unlock()
+
+	w.Header().Set("Location", "/api/v1/jobs/"+status.ID)
+	writeJSON(w, http.StatusAccepted, status)
+}
+
+// handleList lists all known jobs, oldest first
+func (s *Server) handleList(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	s.pruneLocked(time.Now())
+	statuses := make([]JobStatus, 0, len(s.jobs))
+	for _, j := range s.jobs {
+		statuses = append(statuses, j.status)
+	}
+	s.mu.Unlock()
+
+	sort.Slice(statuses, func(i, k int) bool {
+		return statuses[i].CreatedAt.Before(statuses[k].CreatedAt)
+	})
+	writeJSON(w, http.StatusOK, statuses)
+}
+
+// handleStatus returns the status of a job
+func (s *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	j, exists := s.jobs[r.PathValue("id")]
+	var status JobStatus
+	if exists {
+		status = j.status
+	}
+	s.mu.Unlock()
+
+	if !exists {
+		writeError(w, http.StatusNotFound, "job not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, status)
+}
+
+// handleResult returns the JSON report of a finished job
+func (s *Server) handleResult(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	j, exists := s.jobs[r.PathValue("id")]
+	var status string
+	var result json.RawMessage
+	if exists {
+		status = j.status.Status
+		result = j.result
+	}
+	s.mu.Unlock()
+
+	if !exists {
+		writeError(w, http.StatusNotFound, "job not found")
+		return
+	}
+	if status != StatusDone && status != StatusCancelled {
+		writeError(w, http.StatusConflict, fmt.Sprintf("job is %s", status))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(result)
+}
+
+// resolvePath resolves a requested path and checks that it is a directory below one
+// of the allowed roots. Symlinks are resolved first, so they can't point outside.
+func (s *Server) resolvePath(path string) (string, int, error) {
+	if path == "" || !filepath.IsAbs(path) {
+		return "", http.StatusBadRequest, fmt.Errorf("path must be absolute")
+	}
+
+	resolved, err := filepath.EvalSymlinks(filepath.Clean(path))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return "", http.StatusNotFound, fmt.Errorf("%s does not exist", path)
+		}
+		return "", http.StatusBadRequest, fmt.Errorf("failed to resolve path %s: %w", path, err)
+	}
+
+	if !s.allowed(resolved) {
+		return "", http.StatusForbidden, fmt.Errorf("%s is outside the allowed roots", path)
+	}
+
+	info, err := os.Stat(resolved)
+	if err != nil {
+		return "", http.StatusNotFound, fmt.Errorf("%s does not exist", path)
+	}
+	if !info.IsDir() {
+		return "", http.StatusBadRequest, fmt.Errorf("%s is not a directory", path)
+	}
+	return resolved, 0, nil
+}
+
+// allowPath checks that a file a job is about to read resolves to a path below one of
+// the allowed roots. Manifest entries such as "../secret.txt" and symlinks inside a
+// root that point outside are rejected, so their contents are never read.
+func (s *Server) allowPath(path string) error {
+	resolved, err := filepath.EvalSymlinks(path)
+	if errors.Is(err, os.ErrNotExist) {
+		// Missing files are reported by the validation, if their folder is allowed
+		var dir string
+		if dir, err = filepath.EvalSymlinks(filepath.Dir(path)); err == nil {
+			resolved = filepath.Join(dir, filepath.Base(path))
+		} else if errors.Is(err, os.ErrNotExist) {
+			resolved, err = filepath.Abs(path)
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("failed to resolve path %s: %w", path, err)
+	}
+
+	if !s.allowed(resolved) {
+		return fmt.Errorf("%s is outside the allowed roots", path)
+	}
+	return nil
+}
+
+// allowed reports whether a resolved path is one of the roots or below one
+func (s *Server) allowed(path string) bool {
+	for _, root := range s.roots {
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			continue
+		}
+		if rel == "." || (rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))) {
+			return true
+		}
+	}
+	return false
+}
+
+// pruneLocked removes finished jobs older than the retention; the caller must hold s.mu
+func (s *Server) pruneLocked(now time.Time) {
+	for id, j := range s.jobs {
+		if j.status.FinishedAt != nil && now.Sub(*j.status.FinishedAt) > s.opts.Retention {
+			delete(s.jobs, id)
+		}
+	}
+}
+
+// newJobID returns a random job ID
+func newJobID() string {
+	buf := make([]byte, 8)
+	rand.Read(buf)
+	return hex.EncodeToString(buf)
+}
+
+// writeJSON writes a JSON response
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeError writes a JSON error response
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
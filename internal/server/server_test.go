@@ -0,0 +1,276 @@
+package server
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/crc32"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/autobrr/sfvbrr/internal/report"
+)
+
+const testToken = "secret"
+
+const testPresets = `schema_version: 2
+rules:
+  app:
+    deny_unexpected: false
+    rules:
+      - pattern: "*.nfo"
+        min: 1
+`
+
+func newTestServer(t *testing.T) (*Server, *httptest.Server, string) {
+	t.Helper()
+	tmpDir := t.TempDir()
+
+	presetPath := filepath.Join(tmpDir, "presets.yaml")
+	if err := os.WriteFile(presetPath, []byte(testPresets), 0644); err != nil {
+		t.Fatalf("Failed to write presets: %v", err)
+	}
+	root := filepath.Join(tmpDir, "data")
+	release := filepath.Join(root, "App.v1.0-GRP")
+	if err := os.MkdirAll(release, 0755); err != nil {
+		t.Fatalf("Failed to create release: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(release, "app.nfo"), []byte("nfo"), 0644); err != nil {
+		t.Fatalf("Failed to write release file: %v", err)
+	}
+	// CRC-32 of "nfo" is 0x53A28FD0
+	if err := os.WriteFile(filepath.Join(release, "app.sfv"), []byte("app.nfo 53a28fd0\n"), 0644); err != nil {
+		t.Fatalf("Failed to write SFV file: %v", err)
+	}
+
+	opts := DefaultOptions()
+	opts.Token = testToken
+	opts.Roots = []string{root}
+	opts.PresetPath = presetPath
+	opts.QueueSize = 2
+
+	s, err := New(opts)
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	ts := httptest.NewServer(s.Handler())
+	t.Cleanup(ts.Close)
+	return s, ts, release
+}
+
+func request(t *testing.T, method string, url string, token string, body any) *http.Response {
+	t.Helper()
+	var reader *bytes.Reader
+	if body != nil {
+		data, _ := json.Marshal(body)
+		reader = bytes.NewReader(data)
+	} else {
+		reader = bytes.NewReader(nil)
+	}
+	req, err := http.NewRequest(method, url, reader)
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+	return resp
+}
+
+func TestServer_Jobs(t *testing.T) {
+	s, ts, release := newTestServer(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go s.worker(ctx)
+
+	for _, jobType := range []string{JobValidate, JobSFV, JobCheck} {
+		resp := request(t, http.MethodPost, ts.URL+"/api/v1/jobs", testToken, JobRequest{Type: jobType, Path: release})
+		if resp.StatusCode != http.StatusAccepted {
+			t.Fatalf("%s: expected 202, got %d", jobType, resp.StatusCode)
+		}
+		var status JobStatus
+		if err := json.NewDecoder(resp.Body).Decode(&status); err != nil {
+			t.Fatalf("%s: invalid status: %v", jobType, err)
+		}
+
+		// Poll until the job is done
+		deadline := time.Now().Add(5 * time.Second)
+		for status.Status != StatusDone && time.Now().Before(deadline) {
+			time.Sleep(10 * time.Millisecond)
+			resp := request(t, http.MethodGet, ts.URL+"/api/v1/jobs/"+status.ID, testToken, nil)
+			json.NewDecoder(resp.Body).Decode(&status)
+		}
+		if status.Status != StatusDone {
+			t.Fatalf("%s: job did not finish, status %s", jobType, status.Status)
+		}
+		if status.Valid == nil || !*status.Valid {
+			t.Errorf("%s: expected a valid verdict", jobType)
+		}
+
+		resp = request(t, http.MethodGet, ts.URL+"/api/v1/jobs/"+status.ID+"/result", testToken, nil)
+		var rep report.Report
+		if err := json.NewDecoder(resp.Body).Decode(&rep); err != nil {
+			t.Fatalf("%s: invalid report: %v", jobType, err)
+		}
+		if rep.Command != jobType || rep.Totals.Items != 1 || rep.Totals.Passed != 1 {
+			t.Errorf("%s: unexpected report %+v", jobType, rep)
+		}
+	}
+}
+
+func TestServer_Rejects(t *testing.T) {
+	_, ts, release := newTestServer(t)
+	outside := t.TempDir()
+
+	// Escape the root through a symlink
+	link := filepath.Join(filepath.Dir(release), "escape")
+	if err := os.Symlink(outside, link); err != nil {
+		t.Fatalf("Failed to create symlink: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		token string
+		body  any
+		want  int
+	}{
+		{"missing token", "", JobRequest{Type: JobSFV, Path: release}, http.StatusUnauthorized},
+		{"wrong token", "wrong", JobRequest{Type: JobSFV, Path: release}, http.StatusUnauthorized},
+		{"unknown type", testToken, JobRequest{Type: "rm", Path: release}, http.StatusBadRequest},
+		{"unknown field", testToken, map[string]string{"type": JobSFV, "path": release, "cmd": "x"}, http.StatusBadRequest},
+		{"unknown category", testToken, JobRequest{Type: JobValidate, Path: release, Category: "nope"}, http.StatusBadRequest},
+		{"relative path", testToken, JobRequest{Type: JobSFV, Path: "data"}, http.StatusBadRequest},
+		{"outside roots", testToken, JobRequest{Type: JobSFV, Path: outside}, http.StatusForbidden},
+		{"parent traversal", testToken, JobRequest{Type: JobSFV, Path: release + "/../../"}, http.StatusForbidden},
+		{"symlink escape", testToken, JobRequest{Type: JobSFV, Path: link}, http.StatusForbidden},
+		{"missing path", testToken, JobRequest{Type: JobSFV, Path: release + "-missing"}, http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := request(t, http.MethodPost, ts.URL+"/api/v1/jobs", tt.token, tt.body)
+			if resp.StatusCode != tt.want {
+				t.Errorf("Expected %d, got %d", tt.want, resp.StatusCode)
+			}
+		})
+	}
+
+	if resp := request(t, http.MethodGet, ts.URL+"/healthz", "", nil); resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected health check without token, got %d", resp.StatusCode)
+	}
+	if resp := request(t, http.MethodGet, ts.URL+"/api/v1/jobs/unknown", testToken, nil); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected 404 for an unknown job, got %d", resp.StatusCode)
+	}
+}
+
+func TestServer_QueueFull(t *testing.T) {
+	// No worker runs, so submitted jobs stay queued
+	_, ts, release := newTestServer(t)
+
+	var codes []int
+	for i := 0; i < 3; i++ {
+		resp := request(t, http.MethodPost, ts.URL+"/api/v1/jobs", testToken, JobRequest{Type: JobSFV, Path: release})
+		codes = append(codes, resp.StatusCode)
+	}
+	if codes[0] != http.StatusAccepted || codes[1] != http.StatusAccepted || codes[2] != http.StatusServiceUnavailable {
+		t.Errorf("Expected the third job to be rejected, got %v", codes)
+	}
+
+	// Results of queued jobs are not available yet
+	resp := request(t, http.MethodGet, ts.URL+"/api/v1/jobs", testToken, nil)
+	var statuses []JobStatus
+	json.NewDecoder(resp.Body).Decode(&statuses)
+	if len(statuses) != 2 {
+		t.Fatalf("Expected 2 jobs, got %d", len(statuses))
+	}
+	resp = request(t, http.MethodGet, ts.URL+"/api/v1/jobs/"+statuses[0].ID+"/result", testToken, nil)
+	if resp.StatusCode != http.StatusConflict {
+		t.Errorf("Expected 409 for a queued job, got %d", resp.StatusCode)
+	}
+	if !strings.Contains(resp.Header.Get("Content-Type"), "application/json") {
+		t.Errorf("Expected a JSON error, got %s", resp.Header.Get("Content-Type"))
+	}
+}
+
+func TestServer_FilesOutsideRoots(t *testing.T) {
+	s, _, release := newTestServer(t)
+	outside := filepath.Dir(filepath.Dir(release))
+
+	secret := []byte("secret")
+	if err := os.WriteFile(filepath.Join(outside, "secret.txt"), secret, 0644); err != nil {
+		t.Fatalf("Failed to write secret: %v", err)
+	}
+	secretCRC := fmt.Sprintf("%08X", crc32.ChecksumIEEE(secret))
+
+	// One release escapes through a parent reference, the other through a symlink
+	traversal := filepath.Join(filepath.Dir(release), "Traversal.v1.0-GRP")
+	symlink := filepath.Join(filepath.Dir(release), "Symlink.v1.0-GRP")
+	for _, dir := range []string{traversal, symlink} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatalf("Failed to create release: %v", err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "app.nfo"), []byte("nfo"), 0644); err != nil {
+			t.Fatalf("Failed to write release file: %v", err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(traversal, "app.sfv"), []byte("../../secret.txt 00000000\n"), 0644); err != nil {
+		t.Fatalf("Failed to write SFV file: %v", err)
+	}
+	if err := os.Symlink(filepath.Join(outside, "secret.txt"), filepath.Join(symlink, "link.txt")); err != nil {
+		t.Fatalf("Failed to create symlink: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(symlink, "app.sfv"), []byte("link.txt 00000000\n"), 0644); err != nil {
+		t.Fatalf("Failed to write SFV file: %v", err)
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	w, _ := zw.Create("secret.txt")
+	w.Write(secret)
+	zw.Close()
+	if err := os.WriteFile(filepath.Join(outside, "secret.zip"), buf.Bytes(), 0644); err != nil {
+		t.Fatalf("Failed to write ZIP file: %v", err)
+	}
+	if err := os.Symlink(filepath.Join(outside, "secret.zip"), filepath.Join(symlink, "link.zip")); err != nil {
+		t.Fatalf("Failed to create symlink: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		req  JobRequest
+	}{
+		{"sfv parent traversal", JobRequest{Type: JobSFV, Path: traversal}},
+		{"sfv symlink", JobRequest{Type: JobSFV, Path: symlink}},
+		{"zip symlink", JobRequest{Type: JobZIP, Path: symlink}},
+		{"check parent traversal", JobRequest{Type: JobCheck, Path: traversal}},
+		{"check symlink", JobRequest{Type: JobCheck, Path: symlink}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, valid := s.execute(context.Background(), tt.req)
+			if valid {
+				t.Errorf("Expected an invalid verdict")
+			}
+			if !strings.Contains(string(result), "is outside the allowed roots") {
+				t.Errorf("Expected the file to be reported outside the allowed roots, got %s", result)
+			}
+			if strings.Contains(strings.ToUpper(string(result)), secretCRC) {
+				t.Errorf("The checksum of a file outside the roots was reported: %s", result)
+			}
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package server
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/autobrr/sfvbrr/internal/checksum"
+)
+
+// Job types accepted by the server
+const (
+	JobValidate = "validate" // Validate release folders against the category rules
+	JobSFV      = "sfv"      // Validate the SFV files of a folder
+	JobZIP      = "zip"      // Test the ZIP files of a folder
+	JobCheck    = "check"    // Run all checks configured for the release category
+)
+
+// Job statuses
+const (
+	StatusQueued    = "queued"
+	StatusRunning   = "running"
+	StatusDone      = "done"
+	StatusCancelled = "cancelled" // Stopped by the job timeout or a server shutdown; the result is partial
+)
+
+// JobRequest is the body of a job submission
+type JobRequest struct {
+	Type      string `json:"type"`
+	Path      string `json:"path"`                // Absolute path below one of the allowed roots
+	Recursive bool   `json:"recursive,omitempty"` // Search subdirectories for releases, SFV or ZIP files
+	Category  string `json:"category,omitempty"`  // Override category detection (validate and check jobs)
+	Orphans   bool   `json:"orphans,omitempty"`   // Report files not listed in the SFV (sfv jobs)
+}
+
+// JobStatus is the state of a job as returned by the API
+type JobStatus struct {
+	ID         string     `json:"id"`
+	Type       string     `json:"type"`
+	Path       string     `json:"path"`
+	Recursive  bool       `json:"recursive,omitempty"`
+	Status     string     `json:"status"`
+	Valid      *bool      `json:"valid,omitempty"` // Verdict once the job is done or cancelled
+	CreatedAt  time.Time  `json:"created_at"`
+	StartedAt  *time.Time `json:"started_at,omitempty"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+}
+
+// job is a submitted job and its result
+type job struct {
+	status  JobStatus
+	request JobRequest
+	result  json.RawMessage // JSON report, set once the job is done
+}
+
+// Options contains configuration options for the API server
+type Options struct {
+	Addr        string           // Listen address, e.g. ":8080"
+	Token       string           // Bearer token required for every API request
+	Roots       []string         // Directories the jobs may validate, including their subdirectories
+	PresetPath  string           // Path to preset YAML file (empty = auto-detect)
+	QueueSize   int              // Maximum number of queued jobs; further submissions are rejected
+	Concurrency int              // Number of jobs run at the same time
+	Retention   time.Duration    // How long finished jobs and their results are kept
+	JobTimeout  time.Duration    // Cancel jobs running longer than this (0 = no limit)
+	Checksum    checksum.Options // Worker settings of the SFV and ZIP validation
+}
+
+// DefaultOptions returns default options for the API server
+func DefaultOptions() Options {
+	checksumOpts := checksum.DefaultOptions()
+	checksumOpts.Quiet = true
+
+	return Options{
+		Addr:        ":8080",
+		Token:       "",
+		Roots:       nil,
+		PresetPath:  "",
+		QueueSize:   64,
+		Concurrency: 1,
+		Retention:   time.Hour,
+		Checksum:    checksumOpts,
+	}
+}
@@ -1,11 +1,15 @@
 package validate
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 
+	"github.com/autobrr/sfvbrr/internal/action"
 	"github.com/autobrr/sfvbrr/internal/preset"
+	"github.com/autobrr/sfvbrr/internal/report"
 )
 
 // FindFoldersRecursive finds all folders recursively in the given directory
@@ -40,8 +44,8 @@ func FindFoldersRecursive(dir string, overwriteCategory string) ([]string, error
 	return folders, nil
 }
 
-// validateSingleFolder validates a single folder and displays results
-func validateSingleFolder(folderPath string, presetConfig *preset.PresetConfig, opts Options) (bool, error) {
+// validateSingleFolder validates a single folder and displays or reports the results
+func validateSingleFolder(ctx context.Context, folderPath string, presetConfig *preset.PresetConfig, opts Options, rep *report.Writer) (bool, error) {
 	// Detect category (or use overwrite if provided)
 	category, err := DetectCategory(folderPath, opts.OverwriteCategory)
 	if err != nil {
@@ -53,22 +57,107 @@ func validateSingleFolder(folderPath string, presetConfig *preset.PresetConfig,
 		if !opts.Quiet {
 			fmt.Fprintf(os.Stderr, "Warning: %s - unknown or unsupported release category\n", folderPath)
 		}
+		if rep != nil {
+			rep.AddError(fmt.Errorf("%s: unknown or unsupported release category", folderPath))
+		}
 		return false, nil
 	}
 
 	// Validate folder
-	result, err := ValidateFolder(folderPath, presetConfig, category)
+	result, err := ValidateFolder(ctx, folderPath, presetConfig, category)
 	if err != nil {
 		return false, fmt.Errorf("failed to validate folder: %w", err)
 	}
 
-	// Display results and return validation status
-	failed := DisplayResult(result, opts)
-	return !failed, nil
+	// Run the post-validation actions before reporting, so their outcomes are included.
+	// Cancelled validations have no verdict to act on.
+	if !opts.SkipActions && len(presetConfig.Actions) > 0 && !result.Cancelled {
+		result.Actions = action.Run(presetConfig.Actions, action.Verdict{
+			Folder:   result.FolderPath,
+			Category: result.Category,
+			Valid:    result.Valid,
+			Errors:   ErrorTypes(result),
+			Result:   ConvertValidationResult(result),
+		}, opts.DryRun)
+	}
+
+	// Machine-readable output is collected into a single report
+	if rep != nil {
+		rep.Add(ConvertValidationResult(result), !result.Valid)
+	} else {
+		DisplayResult(result, opts)
+	}
+
+	if failed := action.Failed(result.Actions); len(failed) > 0 {
+		return result.Valid, fmt.Errorf("%s: %s action failed: %s", folderPath, failed[0].Type, failed[0].Error)
+	}
+	return result.Valid, nil
 }
 
-// ValidateFolders validates multiple folders
-func ValidateFolders(folders []string, opts Options) error {
+// ErrorTypes classifies the problems of a failed validation by error type
+func ErrorTypes(result *ValidationResult) []string {
+	var types []string
+	for _, ruleResult := range result.RuleResults {
+		if ruleResult.Valid {
+			continue
+		}
+		switch {
+		case ruleResult.Rule.Verify == preset.VerifyRAR && len(ruleResult.Issues) > 0:
+			types = appendErrorType(types, preset.ErrorArchive)
+		case ruleResult.DiskSet != nil && len(ruleResult.Issues) > 0:
+			if len(ruleResult.DiskSet.MissingDisks) > 0 {
+				types = appendErrorType(types, preset.ErrorMissing)
+			}
+			if len(ruleResult.DiskSet.MissingDisks) < len(ruleResult.Issues) {
+				types = appendErrorType(types, preset.ErrorArchive)
+			}
+		case ruleResult.Playlist != nil && len(ruleResult.Issues) > 0:
+			if len(ruleResult.Playlist.Missing) > 0 {
+				types = appendErrorType(types, preset.ErrorMissing)
+			}
+			if len(ruleResult.Playlist.Missing) < len(ruleResult.Issues) {
+				types = appendErrorType(types, preset.ErrorPlaylist)
+			}
+		case (ruleResult.Rule.Verify == preset.VerifyContainer || ruleResult.Rule.Verify == preset.VerifyAudio) && len(ruleResult.Issues) > 0:
+			types = appendErrorType(types, preset.ErrorMedia)
+		case ruleResult.Rule.Naming != nil:
+			types = appendErrorType(types, preset.ErrorNaming)
+		case ruleResult.Rule.Content != nil && len(ruleResult.Issues) > 0:
+			types = appendErrorType(types, preset.ErrorContent)
+		default:
+			types = appendErrorType(types, preset.ErrorRules)
+		}
+	}
+	if len(result.UnexpectedFiles) > 0 {
+		types = appendErrorType(types, preset.ErrorUnexpected)
+	}
+	for _, subfolder := range result.Subfolders {
+		if subfolder.Valid {
+			continue
+		}
+		subTypes := ErrorTypes(subfolder)
+		if len(subTypes) == 0 {
+			// A subfolder of an unknown category has no rules to blame
+			subTypes = []string{preset.ErrorRules}
+		}
+		for _, errorType := range subTypes {
+			types = appendErrorType(types, errorType)
+		}
+	}
+	return types
+}
+
+// appendErrorType appends an error type unless it is already listed
+func appendErrorType(types []string, errorType string) []string {
+	if slices.Contains(types, errorType) {
+		return types
+	}
+	return append(types, errorType)
+}
+
+// ValidateFolders validates multiple folders. Folders left when the context is done
+// are skipped and a cancellation error is returned.
+func ValidateFolders(ctx context.Context, folders []string, opts Options) error {
 	// Load preset configuration
 	presetConfig, err := preset.LoadPresets(opts.PresetPath)
 	if err != nil {
@@ -83,12 +172,17 @@ func ValidateFolders(folders []string, opts Options) error {
 	}
 
 	var hasErrors bool
+	rep := report.NewWriterFor(string(opts.OutputFormat), "validate")
 
 	for _, folder := range folders {
+		if ctx.Err() != nil {
+			break
+		}
+
 		// Resolve absolute path
 		absPath, err := filepath.Abs(folder)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error: failed to resolve path %s: %v\n", folder, err)
+			report.Error(rep, fmt.Errorf("failed to resolve path %s: %w", folder, err))
 			hasErrors = true
 			continue
 		}
@@ -96,13 +190,13 @@ func ValidateFolders(folders []string, opts Options) error {
 		// Check if directory exists
 		info, err := os.Stat(absPath)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error: %s does not exist: %v\n", folder, err)
+			report.Error(rep, fmt.Errorf("%s does not exist: %w", folder, err))
 			hasErrors = true
 			continue
 		}
 
 		if !info.IsDir() {
-			fmt.Fprintf(os.Stderr, "Error: %s is not a directory\n", folder)
+			report.Error(rep, fmt.Errorf("%s is not a directory", folder))
 			hasErrors = true
 			continue
 		}
@@ -111,7 +205,7 @@ func ValidateFolders(folders []string, opts Options) error {
 			// Find all folders recursively
 			subFolders, err := FindFoldersRecursive(absPath, opts.OverwriteCategory)
 			if err != nil {
-				fmt.Fprintf(os.Stderr, "Error: failed to find folders recursively in %s: %v\n", folder, err)
+				report.Error(rep, fmt.Errorf("failed to find folders recursively in %s: %w", folder, err))
 				hasErrors = true
 				continue
 			}
@@ -126,9 +220,12 @@ func ValidateFolders(folders []string, opts Options) error {
 
 			// Validate each folder found
 			for _, subFolder := range subFolders {
-				valid, err := validateSingleFolder(subFolder, presetConfig, opts)
+				if ctx.Err() != nil {
+					break
+				}
+				valid, err := validateSingleFolder(ctx, subFolder, presetConfig, opts, rep)
 				if err != nil {
-					fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+					report.Error(rep, err)
 					hasErrors = true
 				} else if !valid {
 					hasErrors = true
@@ -136,9 +233,9 @@ func ValidateFolders(folders []string, opts Options) error {
 			}
 		} else {
 			// Validate single folder
-			valid, err := validateSingleFolder(absPath, presetConfig, opts)
+			valid, err := validateSingleFolder(ctx, absPath, presetConfig, opts, rep)
 			if err != nil {
-				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				report.Error(rep, err)
 				hasErrors = true
 			} else if !valid {
 				hasErrors = true
@@ -146,6 +243,16 @@ func ValidateFolders(folders []string, opts Options) error {
 		}
 	}
 
+	if rep != nil {
+		if err := rep.Close(); err != nil {
+			return err
+		}
+	}
+
+	if ctx.Err() != nil {
+		return fmt.Errorf("validation cancelled: %w", context.Cause(ctx))
+	}
+
 	if hasErrors {
 		return fmt.Errorf("one or more folders had errors")
 	}
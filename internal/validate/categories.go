@@ -15,15 +15,8 @@ func DetectCategory(folderPath string, overwriteCategory string) (string, error)
 		return overwriteCategory, nil
 	}
 
-	// Extract folder name from path
-	folderName := filepath.Base(folderPath)
-
-	// Remove trailing slash if present
-	folderName = strings.TrimSuffix(folderName, "/")
-	folderName = strings.TrimSuffix(folderName, "\\")
-
 	// Parse using rls library
-	release := rls.ParseString(folderName)
+	release := ParseRelease(folderPath)
 
 	// Get category type as string
 	category := release.Type.String()
@@ -35,3 +28,15 @@ func DetectCategory(folderPath string, overwriteCategory string) (string, error)
 
 	return category, nil
 }
+
+// ParseRelease parses the release name of a folder path into its attributes
+func ParseRelease(folderPath string) rls.Release {
+	// Extract folder name from path
+	folderName := filepath.Base(folderPath)
+
+	// Remove trailing slash if present
+	folderName = strings.TrimSuffix(folderName, "/")
+	folderName = strings.TrimSuffix(folderName, "\\")
+
+	return rls.ParseString(folderName)
+}
@@ -5,6 +5,7 @@ import (
 	"os"
 	"path/filepath"
 
+	"github.com/autobrr/sfvbrr/internal/action"
 	"github.com/fatih/color"
 )
 
@@ -16,21 +17,14 @@ var (
 	errorColor = color.New(color.FgRed).SprintFunc()
 )
 
-// DisplayResult displays the validation results to the user
+// DisplayResult displays the validation results to the user in text form
 // Returns true if validation failed (has invalid rules)
 func DisplayResult(result *ValidationResult, opts Options) bool {
-	// Handle JSON/YAML output
-	if opts.OutputFormat != OutputFormatText {
-		if err := OutputValidationResult(result, opts.OutputFormat); err != nil {
-			fmt.Fprintf(os.Stderr, "Error: failed to output result: %v\n", err)
-			return true
-		}
-		return !result.Valid
-	}
-
 	if opts.Quiet {
 		// In quiet mode, only show errors
-		if !result.Valid {
+		if result.Cancelled {
+			fmt.Fprintf(os.Stderr, "%s: validation cancelled\n", result.FolderPath)
+		} else if !result.Valid {
 			fmt.Fprintf(os.Stderr, "%s: validation failed\n", result.FolderPath)
 		}
 		return !result.Valid
@@ -45,6 +39,11 @@ func DisplayResult(result *ValidationResult, opts Options) bool {
 	} else {
 		fmt.Fprintf(os.Stdout, "  %-13s %s\n", label("Category:"), yellow("unknown"))
 	}
+	if opts.Verbose {
+		for _, set := range result.RuleSets {
+			fmt.Fprintf(os.Stdout, "  %-13s %s\n", label("Rule set:"), set)
+		}
+	}
 	fmt.Fprintln(os.Stdout)
 
 	// Show rule results
@@ -66,6 +65,27 @@ func DisplayResult(result *ValidationResult, opts Options) bool {
 						fmt.Fprintf(os.Stdout, " - %s", ruleResult.Description)
 					}
 					fmt.Fprintln(os.Stdout)
+					for _, container := range ruleResult.Containers {
+						if container.Info != nil {
+							fmt.Fprintf(os.Stdout, "      %s: %s\n", container.Path, container.Info)
+						}
+					}
+					for _, audio := range ruleResult.Audio {
+						if audio.Info != nil {
+							fmt.Fprintf(os.Stdout, "      %s: %s\n", audio.Path, audio.Info)
+						}
+					}
+					for _, content := range ruleResult.Contents {
+						if content.Info != nil {
+							fmt.Fprintf(os.Stdout, "      %s: %s\n", content.Path, content.Info)
+						}
+					}
+					if ruleResult.DiskSet != nil {
+						fmt.Fprintf(os.Stdout, "      %s\n", ruleResult.DiskSet)
+					}
+					if ruleResult.Playlist != nil {
+						fmt.Fprintf(os.Stdout, "      %s\n", ruleResult.Playlist)
+					}
 				}
 			} else {
 				invalidCount++
@@ -79,6 +99,9 @@ func DisplayResult(result *ValidationResult, opts Options) bool {
 					fmt.Fprintf(os.Stdout, " - %s", ruleResult.Description)
 				}
 				fmt.Fprintln(os.Stdout)
+				for _, issue := range ruleResult.Issues {
+					fmt.Fprintf(os.Stdout, "      %s\n", errorColor(issue))
+				}
 			}
 		}
 
@@ -97,6 +120,13 @@ func DisplayResult(result *ValidationResult, opts Options) bool {
 		fmt.Fprintln(os.Stdout)
 	}
 
+	// Show the subfolders validated as releases of their own
+	if len(result.Subfolders) > 0 {
+		fmt.Fprintf(os.Stdout, "%s\n", magenta("Subfolders:"))
+		displaySubfolders(result.Subfolders, "  ", opts.Verbose)
+		fmt.Fprintln(os.Stdout)
+	}
+
 	// Show unexpected files if any
 	if len(result.UnexpectedFiles) > 0 {
 		fmt.Fprintf(os.Stdout, "%s\n", errorColor("Unexpected Files/Directories:"))
@@ -115,10 +145,54 @@ func DisplayResult(result *ValidationResult, opts Options) bool {
 		fmt.Fprintln(os.Stdout)
 	}
 
+	action.DisplayOutcomes(result.Actions)
+
 	// Return true if validation failed
 	return !result.Valid
 }
 
+// displaySubfolders displays the results of subfolders as a tree, with the failed rules,
+// unexpected files and errors of each invalid subfolder below it
+func displaySubfolders(results []*ValidationResult, indent string, verbose bool) {
+	for _, result := range results {
+		category := result.Category
+		if category == "" {
+			category = yellow("unknown")
+		}
+		name := filepath.Base(result.FolderPath)
+		if result.Valid {
+			fmt.Fprintf(os.Stdout, "%s%s %s (%s)\n", indent, success("✓"), name, category)
+		} else {
+			fmt.Fprintf(os.Stdout, "%s%s %s (%s)\n", indent, errorColor("✗"), name, category)
+		}
+
+		for _, ruleResult := range result.RuleResults {
+			if ruleResult.Valid {
+				if verbose {
+					fmt.Fprintf(os.Stdout, "%s    %s %s\n", indent, success("✓"), ruleResult.Rule.Pattern)
+				}
+				continue
+			}
+			fmt.Fprintf(os.Stdout, "%s    %s %s", indent, errorColor("✗"), ruleResult.Rule.Pattern)
+			if ruleResult.Error != nil {
+				fmt.Fprintf(os.Stdout, " - %s", errorColor(ruleResult.Error.Error()))
+			}
+			fmt.Fprintln(os.Stdout)
+		}
+		for _, file := range result.UnexpectedFiles {
+			fmt.Fprintf(os.Stdout, "%s    %s unexpected: %s\n", indent, errorColor("✗"), file)
+		}
+		if len(result.RuleResults) == 0 {
+			// Without rules, the errors are all there is to tell why the subfolder failed
+			for _, err := range result.Errors {
+				fmt.Fprintf(os.Stdout, "%s    %s\n", indent, errorColor(err.Error()))
+			}
+		}
+
+		displaySubfolders(result.Subfolders, indent+"    ", verbose)
+	}
+}
+
 // FormatFolderPath formats a folder path for display (relative to current directory if possible)
 func FormatFolderPath(path string) string {
 	wd, err := os.Getwd()
@@ -1,39 +1,118 @@
 package validate
 
 import (
-	"encoding/json"
-	"fmt"
-	"os"
-
-	"gopkg.in/yaml.v3"
+	"github.com/autobrr/sfvbrr/internal/action"
+	"github.com/autobrr/sfvbrr/internal/media"
+	"github.com/autobrr/sfvbrr/internal/music"
+	"github.com/autobrr/sfvbrr/internal/nfo"
+	"github.com/autobrr/sfvbrr/internal/preset"
+	"github.com/autobrr/sfvbrr/internal/zipset"
 )
 
 // OutputResult represents the JSON/YAML output structure for validation
 type OutputResult struct {
-	FolderPath      string            `json:"folder_path" yaml:"folder_path"`
-	Category        string            `json:"category" yaml:"category"`
-	Valid           bool              `json:"valid" yaml:"valid"`
-	RuleResults     []RuleResultOutput `json:"rule_results,omitempty" yaml:"rule_results,omitempty"`
-	UnexpectedFiles []string          `json:"unexpected_files,omitempty" yaml:"unexpected_files,omitempty"`
-	Errors          []string          `json:"errors,omitempty" yaml:"errors,omitempty"`
+	FolderPath      string                  `json:"folder_path" yaml:"folder_path"`
+	Category        string                  `json:"category" yaml:"category"`
+	RuleSets        []preset.MatchedRuleSet `json:"rule_sets,omitempty" yaml:"rule_sets,omitempty"`
+	Valid           bool                    `json:"valid" yaml:"valid"`
+	Cancelled       bool                    `json:"cancelled,omitempty" yaml:"cancelled,omitempty"`
+	RuleResults     []RuleResultOutput      `json:"rule_results,omitempty" yaml:"rule_results,omitempty"`
+	UnexpectedFiles []string                `json:"unexpected_files,omitempty" yaml:"unexpected_files,omitempty"`
+	Subfolders      []*OutputResult         `json:"subfolders,omitempty" yaml:"subfolders,omitempty"`
+	Errors          []string                `json:"errors,omitempty" yaml:"errors,omitempty"`
+	Actions         []action.Outcome        `json:"actions,omitempty" yaml:"actions,omitempty"`
 }
 
 type RuleResultOutput struct {
-	Pattern     string `json:"pattern" yaml:"pattern"`
-	Type        string `json:"type" yaml:"type"`
-	Matched     int    `json:"matched" yaml:"matched"`
-	Valid       bool   `json:"valid" yaml:"valid"`
-	Description string `json:"description,omitempty" yaml:"description,omitempty"`
-	Error       string `json:"error,omitempty" yaml:"error,omitempty"`
+	Pattern     string            `json:"pattern" yaml:"pattern"`
+	Type        string            `json:"type" yaml:"type"`
+	Matched     int               `json:"matched" yaml:"matched"`
+	Valid       bool              `json:"valid" yaml:"valid"`
+	Description string            `json:"description,omitempty" yaml:"description,omitempty"`
+	Error       string            `json:"error,omitempty" yaml:"error,omitempty"`
+	Issues      []string          `json:"issues,omitempty" yaml:"issues,omitempty"`
+	Containers  []ContainerOutput `json:"containers,omitempty" yaml:"containers,omitempty"`
+	Audio       []AudioOutput     `json:"audio,omitempty" yaml:"audio,omitempty"`
+	Contents    []ContentOutput   `json:"contents,omitempty" yaml:"contents,omitempty"`
+	DiskSet     *DiskSetOutput    `json:"disk_set,omitempty" yaml:"disk_set,omitempty"`
+	Playlist    *PlaylistOutput   `json:"playlist,omitempty" yaml:"playlist,omitempty"`
+}
+
+// PlaylistOutput represents the track list check of a music release in the output
+type PlaylistOutput struct {
+	Playlists  []string `json:"playlists" yaml:"playlists"`
+	Tracks     []string `json:"tracks" yaml:"tracks"`
+	Discs      int      `json:"discs" yaml:"discs"`
+	Missing    []string `json:"missing,omitempty" yaml:"missing,omitempty"`
+	Unlisted   []string `json:"unlisted,omitempty" yaml:"unlisted,omitempty"`
+	Unverified []string `json:"unverified,omitempty" yaml:"unverified,omitempty"`
+}
+
+// DiskSetOutput represents the disk set check of 0-day ZIP files in the output
+type DiskSetOutput struct {
+	Total        int          `json:"total" yaml:"total"`
+	Disks        []DiskOutput `json:"disks" yaml:"disks"`
+	MissingDisks []int        `json:"missing_disks,omitempty" yaml:"missing_disks,omitempty"`
+}
+
+// DiskOutput represents a single ZIP file of a disk set in the output
+type DiskOutput struct {
+	Name       string   `json:"name" yaml:"name"`
+	Number     int      `json:"number" yaml:"number"`
+	Total      int      `json:"total" yaml:"total"`
+	RARVolumes []string `json:"rar_volumes,omitempty" yaml:"rar_volumes,omitempty"`
+	Error      string   `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// ContentOutput represents the content check of an NFO or DIZ file in the output
+type ContentOutput struct {
+	Path    string     `json:"path" yaml:"path"`
+	Valid   bool       `json:"valid" yaml:"valid"`
+	Release string     `json:"release,omitempty" yaml:"release,omitempty"`
+	Group   string     `json:"group,omitempty" yaml:"group,omitempty"`
+	Links   []nfo.Link `json:"links,omitempty" yaml:"links,omitempty"`
+	Disk    int        `json:"disk,omitempty" yaml:"disk,omitempty"`
+	Disks   int        `json:"disks,omitempty" yaml:"disks,omitempty"`
+	Issues  []string   `json:"issues,omitempty" yaml:"issues,omitempty"`
+}
+
+// ContainerOutput represents the container check of a video file in the output
+type ContainerOutput struct {
+	Path            string        `json:"path" yaml:"path"`
+	Valid           bool          `json:"valid" yaml:"valid"`
+	Format          string        `json:"format,omitempty" yaml:"format,omitempty"`
+	DurationSeconds float64       `json:"duration_seconds,omitempty" yaml:"duration_seconds,omitempty"`
+	Tracks          []media.Track `json:"tracks,omitempty" yaml:"tracks,omitempty"`
+	Error           string        `json:"error,omitempty" yaml:"error,omitempty"`
 }
 
-// convertValidationResult converts ValidationResult to OutputResult
-func convertValidationResult(result *ValidationResult) *OutputResult {
+// AudioOutput represents the header check of an MP3 or FLAC file in the output
+type AudioOutput struct {
+	Path            string  `json:"path" yaml:"path"`
+	Valid           bool    `json:"valid" yaml:"valid"`
+	Format          string  `json:"format,omitempty" yaml:"format,omitempty"`
+	DurationSeconds float64 `json:"duration_seconds,omitempty" yaml:"duration_seconds,omitempty"`
+	SampleRate      int     `json:"sample_rate,omitempty" yaml:"sample_rate,omitempty"`
+	Bitrate         int     `json:"bitrate,omitempty" yaml:"bitrate,omitempty"` // kbps
+	Channels        int     `json:"channels,omitempty" yaml:"channels,omitempty"`
+	BitsPerSample   int     `json:"bits_per_sample,omitempty" yaml:"bits_per_sample,omitempty"`
+	VBR             bool    `json:"vbr,omitempty" yaml:"vbr,omitempty"`
+	ID3v2           string  `json:"id3v2,omitempty" yaml:"id3v2,omitempty"`
+	MD5             string  `json:"md5,omitempty" yaml:"md5,omitempty"`
+	MD5Verified     bool    `json:"md5_verified,omitempty" yaml:"md5_verified,omitempty"`
+	Error           string  `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// ConvertValidationResult converts ValidationResult to OutputResult
+func ConvertValidationResult(result *ValidationResult) *OutputResult {
 	output := &OutputResult{
 		FolderPath:      result.FolderPath,
 		Category:        result.Category,
+		RuleSets:        result.RuleSets,
 		Valid:           result.Valid,
+		Cancelled:       result.Cancelled,
 		UnexpectedFiles: result.UnexpectedFiles,
+		Actions:         result.Actions,
 	}
 
 	if len(result.RuleResults) > 0 {
@@ -45,13 +124,33 @@ func convertValidationResult(result *ValidationResult) *OutputResult {
 				Matched:     res.Matched,
 				Valid:       res.Valid,
 				Description: res.Description,
+				Issues:      res.Issues,
 			}
 			if res.Error != nil {
 				output.RuleResults[i].Error = res.Error.Error()
 			}
+			for _, container := range res.Containers {
+				output.RuleResults[i].Containers = append(output.RuleResults[i].Containers, convertContainerResult(container))
+			}
+			for _, audio := range res.Audio {
+				output.RuleResults[i].Audio = append(output.RuleResults[i].Audio, convertAudioResult(audio))
+			}
+			for _, content := range res.Contents {
+				output.RuleResults[i].Contents = append(output.RuleResults[i].Contents, convertContentResult(content))
+			}
+			if res.DiskSet != nil {
+				output.RuleResults[i].DiskSet = convertDiskSet(res.DiskSet)
+			}
+			if res.Playlist != nil {
+				output.RuleResults[i].Playlist = convertPlaylist(res.Playlist)
+			}
 		}
 	}
 
+	for _, subfolder := range result.Subfolders {
+		output.Subfolders = append(output.Subfolders, ConvertValidationResult(subfolder))
+	}
+
 	if len(result.Errors) > 0 {
 		output.Errors = make([]string, len(result.Errors))
 		for i, err := range result.Errors {
@@ -62,24 +161,90 @@ func convertValidationResult(result *ValidationResult) *OutputResult {
 	return output
 }
 
-// OutputValidationResult outputs the validation result in the specified format
-func OutputValidationResult(result *ValidationResult, format OutputFormat) error {
-	if format == OutputFormatText {
-		return nil // Use regular display
+// convertContainerResult converts the container check of a video file
+func convertContainerResult(container ContainerResult) ContainerOutput {
+	output := ContainerOutput{Path: container.Path, Valid: container.Error == nil}
+	if container.Info != nil {
+		output.Format = container.Info.Format
+		output.DurationSeconds = container.Info.Duration.Seconds()
+		output.Tracks = container.Info.Tracks
+	}
+	if container.Error != nil {
+		output.Error = container.Error.Error()
+	}
+	return output
+}
+
+// convertAudioResult converts the header check of an MP3 or FLAC file
+func convertAudioResult(audio AudioResult) AudioOutput {
+	output := AudioOutput{Path: audio.Path, Valid: audio.Error == nil}
+	if audio.Info != nil {
+		output.Format = audio.Info.Format
+		output.DurationSeconds = audio.Info.Duration.Seconds()
+		output.SampleRate = audio.Info.SampleRate
+		output.Bitrate = audio.Info.Bitrate
+		output.Channels = audio.Info.Channels
+		output.BitsPerSample = audio.Info.BitsPerSample
+		output.VBR = audio.Info.VBR
+		output.ID3v2 = audio.Info.ID3v2
+		output.MD5 = audio.Info.MD5
+		output.MD5Verified = audio.Info.MD5Verified
+	}
+	if audio.Error != nil {
+		output.Error = audio.Error.Error()
+	}
+	return output
+}
+
+// convertContentResult converts the content check of an NFO or DIZ file
+func convertContentResult(content ContentResult) ContentOutput {
+	output := ContentOutput{Path: content.Path, Valid: len(content.Issues) == 0, Issues: content.Issues}
+	if content.Info != nil {
+		output.Release = content.Info.Release
+		output.Group = content.Info.Group
+		output.Links = content.Info.Links
+		output.Disk = content.Info.Disk
+		output.Disks = content.Info.Disks
 	}
+	return output
+}
 
-	output := convertValidationResult(result)
-
-	switch format {
-	case OutputFormatJSON:
-		encoder := json.NewEncoder(os.Stdout)
-		encoder.SetIndent("", "  ")
-		return encoder.Encode(output)
-	case OutputFormatYAML:
-		encoder := yaml.NewEncoder(os.Stdout)
-		defer encoder.Close()
-		return encoder.Encode(output)
-	default:
-		return fmt.Errorf("unknown output format: %s", format)
+// convertDiskSet converts the disk set check of 0-day ZIP files
+func convertDiskSet(result *zipset.Result) *DiskSetOutput {
+	output := &DiskSetOutput{
+		Total:        result.Total,
+		Disks:        make([]DiskOutput, len(result.Disks)),
+		MissingDisks: result.MissingDisks,
 	}
+	for i, disk := range result.Disks {
+		output.Disks[i] = DiskOutput{
+			Name:       disk.Name,
+			Number:     disk.Number,
+			Total:      disk.Total,
+			RARVolumes: disk.RARVolumes,
+		}
+		if disk.Error != nil {
+			output.Disks[i].Error = disk.Error.Error()
+		}
+	}
+	return output
+}
+
+// convertPlaylist converts the track list check of a music release
+func convertPlaylist(result *music.Result) *PlaylistOutput {
+	output := &PlaylistOutput{
+		Playlists:  make([]string, len(result.Playlists)),
+		Tracks:     make([]string, len(result.Tracks)),
+		Discs:      result.Discs,
+		Missing:    result.Missing,
+		Unlisted:   result.Unlisted,
+		Unverified: result.Unverified,
+	}
+	for i, playlist := range result.Playlists {
+		output.Playlists[i] = playlist.Name
+	}
+	for i, track := range result.Tracks {
+		output.Tracks[i] = track.Name
+	}
+	return output
 }
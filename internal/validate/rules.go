@@ -1,17 +1,27 @@
 package validate
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"path"
 	"path/filepath"
-	"regexp"
+	"slices"
 	"strings"
 
+	"github.com/autobrr/sfvbrr/internal/media"
+	"github.com/autobrr/sfvbrr/internal/music"
+	"github.com/autobrr/sfvbrr/internal/nfo"
+	"github.com/autobrr/sfvbrr/internal/pathmatch"
 	"github.com/autobrr/sfvbrr/internal/preset"
+	"github.com/autobrr/sfvbrr/internal/rar"
+	"github.com/autobrr/sfvbrr/internal/zipset"
 )
 
-// ValidateFolder validates a folder against rules for its category
-func ValidateFolder(folderPath string, presetConfig *preset.PresetConfig, category string) (*ValidationResult, error) {
+// ValidateFolder validates a folder against rules for its category. When the context is
+// done, the remaining rules and subfolders are not validated and the result is marked
+// as cancelled.
+func ValidateFolder(ctx context.Context, folderPath string, presetConfig *preset.PresetConfig, category string) (*ValidationResult, error) {
 	result := &ValidationResult{
 		FolderPath:  folderPath,
 		Category:    category,
@@ -27,30 +37,66 @@ func ValidateFolder(folderPath string, presetConfig *preset.PresetConfig, catego
 		return result, nil
 	}
 
-	// Get rules for this category
-	rules, err := presetConfig.GetRulesForCategory(category)
+	// Get the rules of this category for the release, with the matching rule sets applied
+	selection, err := presetConfig.SelectRules(category, ParseRelease(folderPath))
 	if err != nil {
 		result.Valid = false
 		result.Errors = append(result.Errors, err)
 		return result, nil
 	}
+	rules := selection.Rules
+	result.RuleSets = selection.RuleSets
+
+	// Check the release name against the naming policy of the category
+	if selection.Naming != nil {
+		namingResult := validateNaming(folderPath, selection.Naming)
+		result.RuleResults = append(result.RuleResults, namingResult)
+		if !namingResult.Valid {
+			result.Valid = false
+			result.Errors = append(result.Errors, namingResult.Error)
+		}
+	}
 
 	// Validate each rule
 	for _, rule := range rules {
-		ruleResult := validateRule(folderPath, rule)
+		if ctx.Err() != nil {
+			break
+		}
+		ruleResult := validateRule(ctx, folderPath, rule)
 		result.RuleResults = append(result.RuleResults, ruleResult)
 
 		if !ruleResult.Valid {
 			result.Valid = false
-			if ruleResult.Error != nil {
+			// The cancellation is reported once for the whole folder
+			if ruleResult.Error != nil && ctx.Err() == nil {
 				result.Errors = append(result.Errors, ruleResult.Error)
 			}
 		}
 	}
 
+	// Validate the subfolders that are releases of their own
+	if len(selection.Subfolders) > 0 && ctx.Err() == nil {
+		subfolders, err := validateSubfolders(ctx, folderPath, presetConfig, selection.Subfolders)
+		if err != nil {
+			result.Valid = false
+			result.Errors = append(result.Errors, fmt.Errorf("failed to validate subfolders: %w", err))
+		}
+		result.Subfolders = subfolders
+		failed := 0
+		for _, subfolder := range subfolders {
+			if !subfolder.Valid {
+				failed++
+			}
+		}
+		if failed > 0 {
+			result.Valid = false
+			result.Errors = append(result.Errors, fmt.Errorf("%d subfolder(s) failed validation", failed))
+		}
+	}
+
 	// Check for unexpected files/directories if deny_unexpected is enabled
-	if presetConfig.GetDenyUnexpected(category) {
-		unexpected, err := findUnexpectedFiles(folderPath, rules)
+	if selection.DenyUnexpected {
+		unexpected, err := findUnexpectedFiles(folderPath, rules, selection.Subfolders)
 		if err != nil {
 			result.Valid = false
 			result.Errors = append(result.Errors, fmt.Errorf("failed to check for unexpected files: %w", err))
@@ -61,11 +107,18 @@ func ValidateFolder(folderPath string, presetConfig *preset.PresetConfig, catego
 		}
 	}
 
+	// Rules and subfolders left when the context is done are not validated
+	if ctx.Err() != nil {
+		result.Cancelled = true
+		result.Valid = false
+		result.Errors = append(result.Errors, fmt.Errorf("validation cancelled: %w", context.Cause(ctx)))
+	}
 	return result, nil
 }
 
-// validateRule validates a single rule against a folder
-func validateRule(folderPath string, rule preset.Rule) RuleResult {
+// validateRule validates a single rule against a folder. Verifications stopped by a done
+// context fail the rule with the cancellation error.
+func validateRule(ctx context.Context, folderPath string, rule preset.Rule) RuleResult {
 	result := RuleResult{
 		Rule: Rule{
 			Pattern:     rule.Pattern,
@@ -74,6 +127,9 @@ func validateRule(folderPath string, rule preset.Rule) RuleResult {
 			Max:         rule.Max,
 			Description: rule.Description,
 			Regex:       rule.Regex,
+			Verify:      rule.Verify,
+			Content:     rule.Content,
+			Audio:       rule.Audio,
 		},
 		Description: rule.Description,
 	}
@@ -81,16 +137,120 @@ func validateRule(folderPath string, rule preset.Rule) RuleResult {
 	// Determine if we're matching files or directories
 	isDirRule := rule.Type == "dir"
 
-	// Count matches
-	matched, err := countMatches(folderPath, rule.Pattern, isDirRule, rule.Regex)
+	// Find matches
+	matches, err := findMatches(folderPath, rule.Pattern, isDirRule, rule.Regex)
 	if err != nil {
 		result.Valid = false
 		result.Error = err
 		return result
 	}
 
+	matched := len(matches)
 	result.Matched = matched
 
+	cancelled := func() bool {
+		if ctx.Err() == nil {
+			return false
+		}
+		result.Valid = false
+		result.Issues = nil
+		result.Error = fmt.Errorf("validation cancelled: %w", context.Cause(ctx))
+		return true
+	}
+
+	// RAR rules additionally verify every volume set with a matching volume
+	if rule.Verify == preset.VerifyRAR {
+		issues, err := verifyRARSets(ctx, folderPath, matches)
+		if cancelled() {
+			return result
+		}
+		if err != nil {
+			result.Valid = false
+			result.Error = err
+			return result
+		}
+		if len(issues) > 0 {
+			result.Valid = false
+			result.Issues = issues
+			result.Error = fmt.Errorf("%d RAR volume set problem(s) found", len(issues))
+			return result
+		}
+	}
+
+	// Container rules check the structure of every matching video file
+	if rule.Verify == preset.VerifyContainer {
+		result.Containers = verifyContainers(ctx, folderPath, matches)
+		if cancelled() {
+			return result
+		}
+		for _, container := range result.Containers {
+			if container.Error != nil {
+				result.Issues = append(result.Issues, fmt.Sprintf("%s: %v", container.Path, container.Error))
+			}
+		}
+		if len(result.Issues) > 0 {
+			result.Valid = false
+			result.Error = fmt.Errorf("%d broken video container(s) found", len(result.Issues))
+			return result
+		}
+	}
+
+	// Audio rules check the headers of every matching MP3 or FLAC file
+	if rule.Verify == preset.VerifyAudio {
+		result.Audio, result.Issues = verifyAudio(ctx, folderPath, rule.Audio, matches)
+		if cancelled() {
+			return result
+		}
+		if len(result.Issues) > 0 {
+			result.Valid = false
+			result.Error = fmt.Errorf("%d audio file problem(s) found", len(result.Issues))
+			return result
+		}
+	}
+
+	// Disk rules cross-check the ZIP files of 0-day releases
+	if rule.Verify == preset.VerifyDisks && matched > 0 {
+		result.DiskSet = zipset.Validate(folderPath, matches)
+		for _, err := range result.DiskSet.Errors {
+			result.Issues = append(result.Issues, err.Error())
+		}
+		if len(result.Issues) > 0 {
+			result.Valid = false
+			result.Error = fmt.Errorf("%d disk set problem(s) found", len(result.Issues))
+			return result
+		}
+	}
+
+	// Playlist rules cross-check the playlists of music releases with the audio and SFV files
+	if rule.Verify == preset.VerifyPlaylist && matched > 0 {
+		result.Playlist = music.Validate(folderPath, matches)
+		for _, err := range result.Playlist.Errors {
+			result.Issues = append(result.Issues, err.Error())
+		}
+		if len(result.Issues) > 0 {
+			result.Valid = false
+			result.Error = fmt.Errorf("%d playlist/track list problem(s) found", len(result.Issues))
+			return result
+		}
+	}
+
+	// Content rules check the text of every matching NFO or DIZ file
+	if rule.Content != nil {
+		result.Contents = verifyContents(folderPath, rule.Content, matches)
+		problems := 0
+		for _, content := range result.Contents {
+			for _, issue := range content.Issues {
+				result.Issues = append(result.Issues, fmt.Sprintf("%s: %s", content.Path, issue))
+				problems++
+			}
+		}
+		if problems > 0 {
+			result.Valid = false
+			result.Error = fmt.Errorf("%d NFO/DIZ content problem(s) found", problems)
+			return result
+		}
+	}
+
 	// Check min constraint
 	if rule.Min > 0 && matched < rule.Min {
 		result.Valid = false
@@ -109,248 +269,319 @@ func validateRule(folderPath string, rule preset.Rule) RuleResult {
 	return result
 }
 
-// countMatches counts how many files or directories match the pattern
-func countMatches(folderPath string, pattern string, isDir bool, useRegex bool) (int, error) {
-	// Read directory entries
-	entries, err := os.ReadDir(folderPath)
-	if err != nil {
-		return 0, fmt.Errorf("failed to read directory: %w", err)
+// validateNaming checks the release name of a folder against a naming policy. Every
+// violation is reported as an issue of a rule whose pattern is the release name.
+func validateNaming(folderPath string, naming *preset.Naming) RuleResult {
+	name := filepath.Base(folderPath)
+	result := RuleResult{
+		Rule:        Rule{Pattern: name, Type: "name", Description: "release name", Naming: naming},
+		Description: "release name",
+		Valid:       true,
+		Issues:      naming.Check(name),
 	}
+	if len(result.Issues) > 0 {
+		result.Valid = false
+		result.Error = fmt.Errorf("%d naming policy violation(s) found", len(result.Issues))
+	}
+	return result
+}
 
-	count := 0
-
-	// Handle special patterns like "Sample/*.{mkv,mp4}"
-	if strings.Contains(pattern, "/") && strings.Contains(pattern, "{") {
-		// This is a nested pattern like "Sample/*.{mkv,mp4}"
-		parts := strings.SplitN(pattern, "/", 2)
-		dirPattern := parts[0]
-		filePattern := parts[1]
+// validateSubfolders validates the subfolders matching a subfolder pattern as releases of
+// their own, with the rules of the configured or the detected category
+func validateSubfolders(ctx context.Context, folderPath string, presetConfig *preset.PresetConfig, subfolders []preset.Subfolder) ([]*ValidationResult, error) {
+	var results []*ValidationResult
+	validated := make(map[string]bool)
+	for _, subfolder := range subfolders {
+		matches, err := findMatches(folderPath, subfolder.Pattern, true, subfolder.Regex)
+		if err != nil {
+			return results, err
+		}
 
-		// First find matching directories
-		for _, entry := range entries {
-			if !entry.IsDir() {
+		for _, match := range matches {
+			if ctx.Err() != nil {
+				return results, nil
+			}
+			if validated[match] {
 				continue
 			}
+			validated[match] = true
 
-			matched, err := matchPattern(entry.Name(), dirPattern, useRegex)
+			subPath := filepath.Join(folderPath, match)
+			category, err := DetectCategory(subPath, subfolder.Category)
 			if err != nil {
-				continue
+				return results, fmt.Errorf("failed to detect category for %s: %w", match, err)
 			}
+			result, err := ValidateFolder(ctx, subPath, presetConfig, category)
+			if err != nil {
+				return results, err
+			}
+			results = append(results, result)
+		}
+	}
+	return results, nil
+}
 
-			if matched {
-				// Check files inside this directory
-				subDirPath := filepath.Join(folderPath, entry.Name())
-				subEntries, err := os.ReadDir(subDirPath)
-				if err != nil {
-					continue
-				}
-
-				for _, subEntry := range subEntries {
-					if subEntry.IsDir() {
-						continue
-					}
-
-					matched, err := matchPattern(subEntry.Name(), filePattern, useRegex)
-					if err != nil {
-						continue
-					}
+// verifyRARSets validates the RAR volume sets that have at least one volume among the
+// matches, until the context is done
+func verifyRARSets(ctx context.Context, folderPath string, matches []string) ([]string, error) {
+	matched := make(map[string]bool, len(matches))
+	var dirs []string
+	for _, match := range matches {
+		matched[match] = true
+		if dir := filepath.Dir(match); !slices.Contains(dirs, dir) {
+			dirs = append(dirs, dir)
+		}
+	}
 
-					if matched {
-						count++
-					}
-				}
-			}
+	var issues []string
+	for _, dir := range dirs {
+		sets, err := rar.FindVolumeSets(filepath.Join(folderPath, dir))
+		if err != nil {
+			return nil, err
 		}
-	} else {
-		// Regular pattern matching
-		for _, entry := range entries {
-			// Filter by type
-			if isDir && !entry.IsDir() {
-				continue
-			}
-			if !isDir && entry.IsDir() {
-				continue
-			}
 
-			matched, err := matchPattern(entry.Name(), pattern, useRegex)
-			if err != nil {
+		for _, set := range sets {
+			if ctx.Err() != nil {
+				return issues, nil
+			}
+			if !slices.ContainsFunc(set.Volumes, func(volume rar.Volume) bool {
+				return matched[filepath.Join(dir, volume.Name)]
+			}) {
 				continue
 			}
 
-			if matched {
-				count++
+			setResult := rar.ValidateSet(set)
+			for _, setErr := range setResult.Errors {
+				issues = append(issues, fmt.Sprintf("%s: %v", filepath.Join(dir, set.Name), setErr))
 			}
 		}
 	}
 
-	return count, nil
+	return issues, nil
+}
+
+// verifyContainers checks the container structure of the matched files, until the
+// context is done
+func verifyContainers(ctx context.Context, folderPath string, matches []string) []ContainerResult {
+	results := make([]ContainerResult, 0, len(matches))
+	for _, match := range matches {
+		if ctx.Err() != nil {
+			break
+		}
+		info, err := media.Probe(filepath.Join(folderPath, match))
+		results = append(results, ContainerResult{Path: match, Info: info, Error: err})
+	}
+	return results
 }
 
-// matchPattern matches a filename against a pattern
-// Supports glob patterns like *.nfo, *.r???, brace expansion like {mkv,mp4}, and regex patterns
-func matchPattern(filename string, pattern string, useRegex bool) (bool, error) {
-	// If regex is enabled, use regex matching
-	if useRegex {
-		matched, err := regexp.MatchString(pattern, filename)
+// verifyAudio checks the headers of the matched audio files and the audio assertions
+// across them, until the context is done
+func verifyAudio(ctx context.Context, folderPath string, audio *preset.AudioRule, matches []string) ([]AudioResult, []string) {
+	if audio == nil {
+		audio = &preset.AudioRule{}
+	}
+
+	results := make([]AudioResult, 0, len(matches))
+	var issues []string
+	bitrates := make(map[string]int)
+	sampleRates := make(map[string]int)
+	for _, match := range matches {
+		if ctx.Err() != nil {
+			return results, issues
+		}
+		info, err := media.ProbeAudio(ctx, filepath.Join(folderPath, match), audio.VerifyMD5)
+		if ctx.Err() != nil {
+			return results, issues
+		}
+		results = append(results, AudioResult{Path: match, Info: info, Error: err})
 		if err != nil {
-			return false, fmt.Errorf("invalid regex pattern: %w", err)
-		}
-		return matched, nil
-	}
-
-	// Handle brace expansion like {mkv,mp4} or *.{mkv,mp4}
-	if strings.Contains(pattern, "{") && strings.Contains(pattern, "}") {
-		// Extract the base pattern and brace content
-		startIdx := strings.Index(pattern, "{")
-		endIdx := strings.Index(pattern, "}")
-		if startIdx >= 0 && endIdx > startIdx {
-			prefix := pattern[:startIdx]
-			suffix := pattern[endIdx+1:]
-			braceContent := pattern[startIdx+1 : endIdx]
-
-			// Split brace content by comma
-			options := strings.Split(braceContent, ",")
-			for _, option := range options {
-				option = strings.TrimSpace(option)
-				// Reconstruct pattern with expanded option
-				testPattern := prefix + option + suffix
-				matched, err := filepath.Match(testPattern, filename)
-				if err != nil {
-					continue
-				}
-				if matched {
-					return true, nil
-				}
+			issues = append(issues, fmt.Sprintf("%s: %v", match, err))
+			continue
+		}
+
+		if audio.MinBitrate > 0 && info.Bitrate < audio.MinBitrate {
+			issues = append(issues, fmt.Sprintf("%s: bitrate %d kbps is below %d kbps", match, info.Bitrate, audio.MinBitrate))
+		}
+		if info.Format == media.FormatMP3 {
+			bitrate := fmt.Sprintf("%d kbps", info.Bitrate)
+			if info.VBR {
+				bitrate = "VBR"
 			}
-			return false, nil
+			bitrates[bitrate]++
 		}
+		sampleRates[fmt.Sprintf("%d Hz", info.SampleRate)]++
 	}
 
-	// Use filepath.Match for glob patterns
-	matched, err := filepath.Match(pattern, filename)
-	if err != nil {
-		return false, err
+	if audio.SameBitrate && len(bitrates) > 1 {
+		issues = append(issues, "mixed bitrates: "+countSummary(bitrates))
 	}
-
-	return matched, nil
+	if audio.SameSampleRate && len(sampleRates) > 1 {
+		issues = append(issues, "mixed sample rates: "+countSummary(sampleRates))
+	}
+	return results, issues
 }
 
-// findUnexpectedFiles finds all files and directories that don't match any rule pattern
-func findUnexpectedFiles(folderPath string, rules []preset.Rule) ([]string, error) {
-	// Read all directory entries (including hidden files)
-	entries, err := os.ReadDir(folderPath)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read directory: %w", err)
+// countSummary lists the values of a count map from the most to the least common, e.g.
+// "320 kbps (10 files), 192 kbps (2 files)"
+func countSummary(counts map[string]int) string {
+	values := make([]string, 0, len(counts))
+	for value := range counts {
+		values = append(values, value)
 	}
+	slices.SortFunc(values, func(a, b string) int {
+		if counts[a] != counts[b] {
+			return counts[b] - counts[a]
+		}
+		return strings.Compare(a, b)
+	})
 
-	// Track allowed entries
-	allowedRootEntries := make(map[string]bool)            // Root level files/dirs
-	allowedDirsForNested := make(map[string]bool)          // Dirs that have nested patterns
-	allowedNestedFiles := make(map[string]map[string]bool) // dir -> set of allowed files
+	parts := make([]string, len(values))
+	for i, value := range values {
+		if counts[value] == 1 {
+			parts[i] = value + " (1 file)"
+		} else {
+			parts[i] = fmt.Sprintf("%s (%d files)", value, counts[value])
+		}
+	}
+	return strings.Join(parts, ", ")
+}
 
-	// First pass: identify which entries match rules
-	for _, rule := range rules {
-		isDirRule := rule.Type == "dir"
-
-		// Handle nested patterns like "Sample/*.{mkv,mp4}"
-		if strings.Contains(rule.Pattern, "/") {
-			parts := strings.SplitN(rule.Pattern, "/", 2)
-			dirPattern := parts[0]
-			filePattern := parts[1]
-
-			// Find matching directories
-			for _, entry := range entries {
-				if !entry.IsDir() {
-					continue
-				}
+// verifyContents parses the matched NFO or DIZ files and checks the content assertions
+func verifyContents(folderPath string, content *preset.ContentRule, matches []string) []ContentResult {
+	folderName := filepath.Base(folderPath)
+	results := make([]ContentResult, 0, len(matches))
+	for _, match := range matches {
+		result := ContentResult{Path: match}
+		info, err := nfo.ParseFile(filepath.Join(folderPath, match))
+		if err != nil {
+			result.Issues = append(result.Issues, err.Error())
+			results = append(results, result)
+			continue
+		}
+		result.Info = info
 
-				matched, err := matchPattern(entry.Name(), dirPattern, rule.Regex)
-				if err != nil || !matched {
-					continue
-				}
+		if content.ReleaseMatchesFolder && !info.MentionsRelease(folderName) {
+			if info.Release == "" {
+				result.Issues = append(result.Issues, "no release name found")
+			} else {
+				result.Issues = append(result.Issues, fmt.Sprintf("release name %q doesn't match the folder", info.Release))
+			}
+		}
+		if content.GroupMatchesFolder {
+			if group := nfo.GroupOf(folderName); !strings.EqualFold(info.Group, group) {
+				result.Issues = append(result.Issues, fmt.Sprintf("group %q doesn't match the folder group %q", info.Group, group))
+			}
+		}
+		for _, site := range content.RequireLinks {
+			if !info.HasLink(site) {
+				result.Issues = append(result.Issues, fmt.Sprintf("no %s link found", site))
+			}
+		}
+		if content.DisksMatch != "" {
+			files, err := findMatches(folderPath, content.DisksMatch, false, false)
+			switch {
+			case err != nil:
+				result.Issues = append(result.Issues, err.Error())
+			case info.Disks == 0:
+				result.Issues = append(result.Issues, "no disk count found")
+			case info.Disks != len(files):
+				result.Issues = append(result.Issues, fmt.Sprintf("disk count is %d, but %d file(s) match %s", info.Disks, len(files), content.DisksMatch))
+			}
+		}
+		results = append(results, result)
+	}
+	return results
+}
 
-				// This directory is allowed for nested patterns
-				allowedDirsForNested[entry.Name()] = true
-				if allowedNestedFiles[entry.Name()] == nil {
-					allowedNestedFiles[entry.Name()] = make(map[string]bool)
-				}
+// findMatches returns the files or directories that match the pattern, relative to the folder
+func findMatches(folderPath string, pattern string, isDir bool, useRegex bool) ([]string, error) {
+	p, err := pathmatch.Compile(pattern, useRegex)
+	if err != nil {
+		return nil, err
+	}
 
-				// Check files inside this directory
-				subDirPath := filepath.Join(folderPath, entry.Name())
-				subEntries, err := os.ReadDir(subDirPath)
-				if err != nil {
-					continue
-				}
+	var matches []string
+	err = walkFolder(folderPath, func(name string, entry os.DirEntry) bool {
+		if entry.IsDir() == isDir && p.Match(name) {
+			matches = append(matches, filepath.FromSlash(name))
+		}
+		return entry.IsDir() && p.MatchBelow(name)
+	})
+	if err != nil {
+		return nil, err
+	}
 
-				for _, subEntry := range subEntries {
-					if subEntry.IsDir() {
-						continue // Subdirectories are not allowed
-					}
+	return matches, nil
+}
 
-					matched, err := matchPattern(subEntry.Name(), filePattern, rule.Regex)
-					if err == nil && matched {
-						allowedNestedFiles[entry.Name()][subEntry.Name()] = true
-					}
+// walkFolder calls visit for the entries of the folder with their slash-separated path
+// relative to the folder, and descends into the directories for which visit returns true
+func walkFolder(folderPath string, visit func(name string, entry os.DirEntry) bool) error {
+	var walk func(dir string) error
+	walk = func(dir string) error {
+		entries, err := os.ReadDir(filepath.Join(folderPath, filepath.FromSlash(dir)))
+		if err != nil {
+			return fmt.Errorf("failed to read directory: %w", err)
+		}
+		for _, entry := range entries {
+			name := path.Join(dir, entry.Name())
+			if visit(name, entry) {
+				if err := walk(name); err != nil {
+					return err
 				}
 			}
-		} else {
-			// Regular pattern matching
-			for _, entry := range entries {
-				// Filter by type
-				if isDirRule && !entry.IsDir() {
-					continue
-				}
-				if !isDirRule && entry.IsDir() {
-					continue
-				}
+		}
+		return nil
+	}
+	return walk("")
+}
 
-				matched, err := matchPattern(entry.Name(), rule.Pattern, rule.Regex)
-				if err == nil && matched {
-					allowedRootEntries[entry.Name()] = true
-				}
-			}
+// findUnexpectedFiles finds all files and directories that don't match any rule pattern.
+// Directories matched by a rule are only searched if a rule can match something inside
+// them, e.g. the contents of "Sample" are checked when there is a "Sample/*.mkv" rule,
+// but not for a "Sample" directory rule alone. Subfolders validated as releases of their
+// own are never searched.
+func findUnexpectedFiles(folderPath string, rules []preset.Rule, subfolders []preset.Subfolder) ([]string, error) {
+	type compiledRule struct {
+		pattern *pathmatch.Pattern
+		isDir   bool
+	}
+
+	compiled := make([]compiledRule, 0, len(rules))
+	for _, rule := range rules {
+		// Invalid patterns are reported by the rule itself
+		if p, err := pathmatch.Compile(rule.Pattern, rule.Regex); err == nil {
+			compiled = append(compiled, compiledRule{pattern: p, isDir: rule.Type == "dir"})
+		}
+	}
+	var releases []*pathmatch.Pattern
+	for _, subfolder := range subfolders {
+		if p, err := pathmatch.Compile(subfolder.Pattern, subfolder.Regex); err == nil {
+			releases = append(releases, p)
 		}
 	}
 
-	// Second pass: find unexpected entries
 	unexpected := make([]string, 0)
-
-	for _, entry := range entries {
-		entryName := entry.Name()
-
-		if entry.IsDir() {
-			// Check if directory is allowed (either explicitly or via nested patterns)
-			if allowedRootEntries[entryName] || allowedDirsForNested[entryName] {
-				// Only check directory contents if it has nested patterns
-				// If it's just a simple directory rule, we don't validate its contents
-				if allowedDirsForNested[entryName] {
-					// Directory is allowed for nested patterns - check its contents
-					subDirPath := filepath.Join(folderPath, entryName)
-					subEntries, err := os.ReadDir(subDirPath)
-					if err == nil {
-						allowedFiles := allowedNestedFiles[entryName]
-						for _, subEntry := range subEntries {
-							if subEntry.IsDir() {
-								// Subdirectories are not allowed
-								unexpected = append(unexpected, filepath.Join(entryName, subEntry.Name()))
-							} else if allowedFiles == nil || !allowedFiles[subEntry.Name()] {
-								// File doesn't match any nested pattern
-								unexpected = append(unexpected, filepath.Join(entryName, subEntry.Name()))
-							}
-						}
-					}
-				}
-				// If directory is only in allowedRootEntries (simple dir rule), don't check contents
-			} else {
-				// Directory is not allowed at all
-				unexpected = append(unexpected, entryName)
+	err := walkFolder(folderPath, func(name string, entry os.DirEntry) bool {
+		if entry.IsDir() && slices.ContainsFunc(releases, func(p *pathmatch.Pattern) bool { return p.Match(name) }) {
+			return false
+		}
+		matched, below := false, false
+		for _, rule := range compiled {
+			if rule.isDir == entry.IsDir() && rule.pattern.Match(name) {
+				matched = true
 			}
-		} else {
-			// File is not allowed
-			if !allowedRootEntries[entryName] {
-				unexpected = append(unexpected, entryName)
+			if entry.IsDir() && rule.pattern.MatchBelow(name) {
+				below = true
 			}
 		}
+		if !matched && !below {
+			unexpected = append(unexpected, filepath.FromSlash(name))
+		}
+		return below
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	return unexpected, nil
@@ -0,0 +1,112 @@
+package validate
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/autobrr/sfvbrr/internal/preset"
+)
+
+func TestValidateFolder_Cancelled(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "Movie.2025.1080p.BluRay.x264-GRP")
+	if err := os.MkdirAll(filepath.Join(dir, "CD1"), 0755); err != nil {
+		t.Fatalf("Failed to create release: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "movie.mkv"), []byte("not a container"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	presetConfig := &preset.PresetConfig{
+		Rules: map[string]*preset.CategoryRules{
+			"movie": {
+				Rules:      []preset.Rule{{Pattern: "*.mkv", Verify: preset.VerifyContainer}},
+				Subfolders: []preset.Subfolder{{Pattern: "CD[0-9]", Category: "movie"}},
+			},
+		},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	result, err := ValidateFolder(ctx, dir, presetConfig, "movie")
+	if err != nil {
+		t.Fatalf("ValidateFolder failed: %v", err)
+	}
+	if !result.Cancelled || result.Valid {
+		t.Errorf("Expected a cancelled, failed result, got %+v", result)
+	}
+	// Neither the container nor the subfolder are checked
+	if len(result.RuleResults) != 0 || len(result.Subfolders) != 0 {
+		t.Errorf("Expected no rule or subfolder results, got %+v", result)
+	}
+	if len(result.Errors) != 1 || !errors.Is(result.Errors[0], context.Canceled) {
+		t.Errorf("Expected a single cancellation error, got %v", result.Errors)
+	}
+	if !ConvertValidationResult(result).Cancelled {
+		t.Error("Expected the output to be marked as cancelled")
+	}
+
+	// A rule whose verification is cancelled fails without reporting the files it skipped
+	ruleResult := validateRule(ctx, dir, presetConfig.Rules["movie"].Rules[0])
+	if ruleResult.Valid || len(ruleResult.Containers) != 0 || !errors.Is(ruleResult.Error, context.Canceled) {
+		t.Errorf("Expected a cancelled rule, got %+v", ruleResult)
+	}
+}
+
+// createEpisode creates an episode folder with the files the default episode rules require
+func createEpisode(t *testing.T, dir string, rar bool) {
+	t.Helper()
+	files := []string{"episode.nfo", "episode.sfv", "episode.r00", "Sample/episode-sample.mkv"}
+	if rar {
+		files = append(files, "episode.rar")
+	}
+	for _, file := range files {
+		path := filepath.Join(dir, file)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create %s: %v", filepath.Dir(path), err)
+		}
+		if err := os.WriteFile(path, nil, 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", path, err)
+		}
+	}
+}
+
+func TestValidateFolder_SeasonPack(t *testing.T) {
+	presetPath := filepath.Join(t.TempDir(), "presets.yaml")
+	if err := os.WriteFile(presetPath, []byte("include: [default]\n"), 0644); err != nil {
+		t.Fatalf("Failed to write presets: %v", err)
+	}
+	presetConfig, err := preset.LoadPresets(presetPath)
+	if err != nil {
+		t.Fatalf("Failed to load presets: %v", err)
+	}
+
+	dir := filepath.Join(t.TempDir(), "Show.S01.720p.HDTV.x264-GRP")
+	createEpisode(t, filepath.Join(dir, "Show.S01E01.720p.HDTV.x264-GRP"), true)
+	createEpisode(t, filepath.Join(dir, "Show.S01E02.720p.HDTV.x264-GRP"), false)
+
+	category, err := DetectCategory(dir, "")
+	if err != nil || category != "series" {
+		t.Fatalf("Expected a series, got %q (%v)", category, err)
+	}
+	result, err := ValidateFolder(context.Background(), dir, presetConfig, category)
+	if err != nil {
+		t.Fatalf("ValidateFolder failed: %v", err)
+	}
+	if result.Valid || len(result.Subfolders) != 2 {
+		t.Fatalf("Expected the season pack to fail with 2 episodes, got %+v", result)
+	}
+	first, second := result.Subfolders[0], result.Subfolders[1]
+	if first.Category != "episode" || !first.Valid {
+		t.Errorf("Expected the first episode to pass, got %+v", first)
+	}
+	if second.Category != "episode" || second.Valid || len(second.RuleResults) == 0 || second.RuleResults[0].Rule.Pattern != "*.rar" || second.RuleResults[0].Valid {
+		t.Errorf("Expected the second episode to fail its .rar rule, got %+v", second)
+	}
+	if types := ErrorTypes(result); len(types) != 1 || types[0] != preset.ErrorRules {
+		t.Errorf("Expected error types [rules], got %v", types)
+	}
+}
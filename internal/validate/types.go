@@ -1,12 +1,22 @@
 package validate
 
+import (
+	"github.com/autobrr/sfvbrr/internal/action"
+	"github.com/autobrr/sfvbrr/internal/media"
+	"github.com/autobrr/sfvbrr/internal/music"
+	"github.com/autobrr/sfvbrr/internal/nfo"
+	"github.com/autobrr/sfvbrr/internal/preset"
+	"github.com/autobrr/sfvbrr/internal/zipset"
+)
+
 // OutputFormat represents the output format type
 type OutputFormat string
 
 const (
-	OutputFormatText OutputFormat = "text"
-	OutputFormatJSON OutputFormat = "json"
-	OutputFormatYAML OutputFormat = "yaml"
+	OutputFormatText   OutputFormat = "text"
+	OutputFormatJSON   OutputFormat = "json"
+	OutputFormatYAML   OutputFormat = "yaml"
+	OutputFormatNDJSON OutputFormat = "ndjson"
 )
 
 // RuleResult represents the result of validating a single rule
@@ -16,16 +26,47 @@ type RuleResult struct {
 	Valid       bool
 	Error       error
 	Description string
+	Issues      []string          // Individual problems found by rule verifications (e.g. RAR volume sets)
+	Containers  []ContainerResult // Container checks of the matching files (verify: container rules)
+	Audio       []AudioResult     // Header checks of the matching audio files (verify: audio rules)
+	Contents    []ContentResult   // Content checks of the matching NFO or DIZ files (content rules)
+	DiskSet     *zipset.Result    // Disk set check of the matching ZIP files (verify: disks rules)
+	Playlist    *music.Result     // Track list check of the matching playlists (verify: playlist rules)
+}
+
+// ContainerResult is the result of checking the container structure of a video file
+type ContainerResult struct {
+	Path  string      // Path of the file, relative to the release folder
+	Info  *media.Info // Format, duration and tracks (nil if the check failed)
+	Error error
+}
+
+// AudioResult is the result of checking the headers of an MP3 or FLAC file
+type AudioResult struct {
+	Path  string           // Path of the file, relative to the release folder
+	Info  *media.AudioInfo // Format, duration, sample rate and bitrate (nil if the check failed)
+	Error error
+}
+
+// ContentResult is the result of checking the content of an NFO or DIZ file
+type ContentResult struct {
+	Path   string    // Path of the file, relative to the release folder
+	Info   *nfo.Info // Parsed fields (nil if the file couldn't be read)
+	Issues []string  // Failed content assertions
 }
 
 // ValidationResult represents the overall result of folder validation
 type ValidationResult struct {
 	FolderPath      string
 	Category        string
+	RuleSets        []preset.MatchedRuleSet // Rule sets of the category whose conditions the release matched
 	Valid           bool
 	RuleResults     []RuleResult
 	Errors          []error
-	UnexpectedFiles []string // Files/directories that don't match any rule pattern
+	UnexpectedFiles []string            // Files/directories that don't match any rule pattern
+	Subfolders      []*ValidationResult // Subfolders validated as releases of their own
+	Cancelled       bool                // Validation was cancelled before every rule was checked
+	Actions         []action.Outcome    // Outcomes of the post-validation actions
 }
 
 // Options contains configuration options for validation
@@ -35,7 +76,9 @@ type Options struct {
 	Quiet             bool         // Quiet mode (minimal output)
 	Recursive         bool         // Recursive mode - search subdirectories
 	OverwriteCategory string       // Override category detection (empty = use auto-detection)
-	OutputFormat      OutputFormat // Output format: text, json, or yaml
+	OutputFormat      OutputFormat // Output format: text, json, yaml, or ndjson
+	SkipActions       bool         // Don't run the post-validation actions of the preset configuration
+	DryRun            bool         // Report the post-validation actions without running them
 }
 
 // DefaultOptions returns default options for validation
@@ -47,15 +90,21 @@ func DefaultOptions() Options {
 		Recursive:         false,
 		OverwriteCategory: "",
 		OutputFormat:      OutputFormatText,
+		SkipActions:       false,
+		DryRun:            false,
 	}
 }
 
 // Rule represents a validation rule (imported from preset package)
 type Rule struct {
 	Pattern     string
-	Type        string // "file" (default) or "dir"
+	Type        string // "file" (default), "dir" or "name" (naming policy of the release name)
 	Min         int
 	Max         int
 	Description string
-	Regex       bool // If true, pattern is treated as regex instead of glob
+	Regex       bool                // If true, pattern is treated as regex instead of glob
+	Verify      string              // "audio" (MP3/FLAC headers), "container" (video file structure), "disks" (0-day ZIP disk set), "playlist" (music track list) or "rar" (RAR volume sets)
+	Content     *preset.ContentRule // Assertions on the text of every matching NFO or DIZ file
+	Audio       *preset.AudioRule   // Assertions on the audio files of verify: audio rules
+	Naming      *preset.Naming      // Naming policy checked against the release name (name rules)
 }
@@ -0,0 +1,108 @@
+package watch
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/autobrr/sfvbrr/internal/check"
+	"github.com/fatih/color"
+)
+
+var (
+	yellow     = color.New(color.FgYellow).SprintFunc()
+	success    = color.New(color.FgGreen).SprintFunc()
+	label      = color.New(color.FgCyan).SprintFunc()
+	errorColor = color.New(color.FgRed).SprintFunc()
+)
+
+// eventWriter writes watcher events as text lines or NDJSON
+type eventWriter struct {
+	mu      sync.Mutex
+	out     io.Writer
+	format  OutputFormat
+	verbose bool
+}
+
+func newEventWriter(opts Options) *eventWriter {
+	out := opts.Output
+	if out == nil {
+		out = os.Stdout
+	}
+	return &eventWriter{
+		out:     out,
+		format:  opts.OutputFormat,
+		verbose: opts.Check.Verbose,
+	}
+}
+
+// verdict emits the verdict of a checked release
+func (e *eventWriter) verdict(result *check.Result) {
+	valid := result.Valid
+	e.emit(Event{
+		Type:     EventVerdict,
+		Folder:   result.FolderPath,
+		Category: result.Category,
+		Valid:    &valid,
+		Result:   check.ConvertResult(result),
+	})
+
+	// Show the full results of failed releases in verbose text mode
+	if e.format != OutputFormatNDJSON && e.verbose && !valid {
+		e.mu.Lock()
+		check.DisplayResult(result, check.Options{})
+		e.mu.Unlock()
+	}
+}
+
+// emit writes a single event
+func (e *eventWriter) emit(event Event) {
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.format == OutputFormatNDJSON {
+		data, err := json.Marshal(event)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: failed to encode event: %v\n", err)
+			return
+		}
+		e.out.Write(append(data, '\n'))
+		return
+	}
+
+	timestamp := event.Time.Format("2006-01-02 15:04:05")
+	switch event.Type {
+	case EventVerdict:
+		verdict := success("PASS")
+		if event.Valid != nil && !*event.Valid {
+			verdict = errorColor("FAIL")
+		}
+		fmt.Fprintf(e.out, "%s %s %s (%s)\n", timestamp, verdict, event.Folder, event.Category)
+		if event.Result != nil {
+			for _, outcome := range event.Result.Actions {
+				if outcome.Error != "" {
+					fmt.Fprintf(e.out, "%s %s %s: %s\n", timestamp, errorColor("ACTION"), outcome.Description, outcome.Error)
+				} else {
+					fmt.Fprintf(e.out, "%s %s %s\n", timestamp, label("ACTION"), outcome.Description)
+				}
+			}
+		}
+	case EventError:
+		fmt.Fprintf(e.out, "%s %s %s: %s\n", timestamp, errorColor("ERROR"), event.Folder, event.Error)
+	case EventPending:
+		fmt.Fprintf(e.out, "%s %s %s\n", timestamp, yellow("WAIT"), event.Folder)
+	case EventSkipped:
+		if e.verbose {
+			fmt.Fprintf(e.out, "%s %s %s (unchanged since last check)\n", timestamp, label("SKIP"), event.Folder)
+		}
+	case EventWatching:
+		fmt.Fprintf(e.out, "%s %s %s\n", timestamp, label("WATCH"), event.Folder)
+	}
+}
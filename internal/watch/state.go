@@ -0,0 +1,133 @@
+package watch
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Release is the persisted state of a checked release
+type Release struct {
+	Fingerprint string    `json:"fingerprint"` // Fingerprint of the release contents when it was checked
+	Valid       bool      `json:"valid"`
+	CheckedAt   time.Time `json:"checked_at"`
+}
+
+// State records the checked releases, so they are not checked again after a restart
+type State struct {
+	path     string
+	mu       sync.Mutex
+	Releases map[string]Release `json:"releases"`
+}
+
+// DefaultStatePath returns the default state file path (cross-platform)
+func DefaultStatePath() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(homeDir, ".config", "sfvbrr", "watch-state.json"), nil
+}
+
+// LoadState loads the state file at the given path. A missing file yields an empty state.
+func LoadState(path string) (*State, error) {
+	state := &State{path: path, Releases: make(map[string]Release)}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return state, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read state file: %w", err)
+	}
+
+	if err := json.Unmarshal(data, state); err != nil {
+		return nil, fmt.Errorf("failed to parse state file %s: %w", path, err)
+	}
+	if state.Releases == nil {
+		state.Releases = make(map[string]Release)
+	}
+	return state, nil
+}
+
+// Checked reports whether the release was checked with the given fingerprint
+func (s *State) Checked(folder string, fingerprint string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	release, exists := s.Releases[folder]
+	return exists && release.Fingerprint == fingerprint
+}
+
+// Record stores the verdict of a release and saves the state file
+func (s *State) Record(folder string, release Release) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Releases[folder] = release
+	return s.save()
+}
+
+// Forget removes a release from the state and saves the state file
+func (s *State) Forget(folder string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, exists := s.Releases[folder]; !exists {
+		return nil
+	}
+	delete(s.Releases, folder)
+	return s.save()
+}
+
+// save writes the state file atomically; the caller must hold s.mu
+func (s *State) save() error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode state: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return fmt.Errorf("failed to create state directory: %w", err)
+	}
+
+	tmpPath := s.path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write state file: %w", err)
+	}
+	if err := os.Rename(tmpPath, s.path); err != nil {
+		return fmt.Errorf("failed to write state file: %w", err)
+	}
+	return nil
+}
+
+// Fingerprint summarizes the contents of a release folder (file count, total size
+// and latest modification time), so changes made while the watcher was stopped are noticed
+func Fingerprint(folder string) (string, error) {
+	var files, size, latest int64
+
+	err := filepath.WalkDir(folder, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		files++
+		size += info.Size()
+		if mtime := info.ModTime().UnixNano(); mtime > latest {
+			latest = mtime
+		}
+		return nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to read release folder: %w", err)
+	}
+
+	return fmt.Sprintf("%d:%d:%d", files, size, latest), nil
+}
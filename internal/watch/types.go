@@ -0,0 +1,56 @@
+package watch
+
+import (
+	"io"
+	"time"
+
+	"github.com/autobrr/sfvbrr/internal/check"
+)
+
+// OutputFormat represents the output format type
+type OutputFormat string
+
+const (
+	OutputFormatText   OutputFormat = "text"
+	OutputFormatNDJSON OutputFormat = "ndjson"
+)
+
+// Event types emitted by the watcher
+const (
+	EventWatching = "watching" // A directory is being watched
+	EventPending  = "pending"  // A release was detected and waits for its quiet period
+	EventSkipped  = "skipped"  // A release is unchanged since it was last checked
+	EventVerdict  = "verdict"  // A release was checked
+	EventError    = "error"    // A release or directory could not be processed
+)
+
+// Event is a single structured event of the watcher
+type Event struct {
+	Time     time.Time           `json:"time"`
+	Type     string              `json:"type"`
+	Folder   string              `json:"folder,omitempty"`
+	Category string              `json:"category,omitempty"`
+	Valid    *bool               `json:"valid,omitempty"`
+	Result   *check.OutputResult `json:"result,omitempty"`
+	Error    string              `json:"error,omitempty"`
+}
+
+// Options contains configuration options for watch mode
+type Options struct {
+	Check        check.Options // Options of the checks run on each release
+	QuietPeriod  time.Duration // Time without writes before a release is checked
+	CheckTimeout time.Duration // Cancel checks of a release running longer than this (0 = no limit)
+	StatePath    string        // Path to the state file of checked releases (empty = default)
+	OutputFormat OutputFormat  // Output format of events: text or ndjson
+	Output       io.Writer     // Destination of events (nil = stdout)
+}
+
+// DefaultOptions returns default options for watch mode
+func DefaultOptions() Options {
+	return Options{
+		Check:        check.DefaultOptions(),
+		QuietPeriod:  30 * time.Second,
+		StatePath:    "",
+		OutputFormat: OutputFormatText,
+	}
+}
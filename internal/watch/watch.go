@@ -0,0 +1,362 @@
+package watch
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/autobrr/sfvbrr/internal/check"
+	"github.com/autobrr/sfvbrr/internal/preset"
+	"github.com/autobrr/sfvbrr/internal/validate"
+	"github.com/fsnotify/fsnotify"
+)
+
+// Watcher checks release folders as they land in one or more incoming directories.
+// Every direct subdirectory of a watched directory is a release; it is checked once
+// no file in it has been written for the quiet period.
+type Watcher struct {
+	roots        []string
+	opts         Options
+	presetConfig *preset.PresetConfig
+	state        *State
+	fsw          *fsnotify.Watcher
+	out          *eventWriter
+
+	mu      sync.Mutex
+	pending map[string]time.Time // Release folders waiting for their quiet period, by time of the last write
+	queued  map[string]bool      // Release folders waiting to be checked
+	queue   chan string
+}
+
+// New creates a watcher for the given directories
+func New(dirs []string, opts Options) (*Watcher, error) {
+	// Load preset configuration
+	presetConfig, err := preset.LoadPresets(opts.Check.PresetPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load presets: %w", err)
+	}
+
+	// Validate overwrite category if provided
+	if category := opts.Check.OverwriteCategory; category != "" {
+		if _, exists := presetConfig.Rules[category]; !exists {
+			return nil, fmt.Errorf("invalid category '%s': category not found in preset configuration", category)
+		}
+	}
+
+	statePath := opts.StatePath
+	if statePath == "" {
+		if statePath, err = DefaultStatePath(); err != nil {
+			return nil, err
+		}
+	}
+	state, err := LoadState(statePath)
+	if err != nil {
+		return nil, err
+	}
+
+	roots := make([]string, 0, len(dirs))
+	for _, dir := range dirs {
+		absPath, err := filepath.Abs(dir)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve path %s: %w", dir, err)
+		}
+		info, err := os.Stat(absPath)
+		if err != nil {
+			return nil, fmt.Errorf("%s does not exist: %w", dir, err)
+		}
+		if !info.IsDir() {
+			return nil, fmt.Errorf("%s is not a directory", dir)
+		}
+		roots = append(roots, absPath)
+	}
+
+	fsw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create file watcher: %w", err)
+	}
+
+	// Releases are checked silently, verdicts are reported as events
+	opts.Check.Quiet = true
+	opts.Check.Recursive = false
+
+	return &Watcher{
+		roots:        roots,
+		opts:         opts,
+		presetConfig: presetConfig,
+		state:        state,
+		fsw:          fsw,
+		out:          newEventWriter(opts),
+		pending:      make(map[string]time.Time),
+		queued:       make(map[string]bool),
+		queue:        make(chan string, 1024),
+	}, nil
+}
+
+// Run watches the directories until the context is cancelled
+func (w *Watcher) Run(ctx context.Context) error {
+	defer w.fsw.Close()
+
+	for _, root := range w.roots {
+		if err := w.fsw.Add(root); err != nil {
+			return fmt.Errorf("failed to watch %s: %w", root, err)
+		}
+		w.out.emit(Event{Type: EventWatching, Folder: root})
+	}
+
+	// Pick up releases that landed while the watcher was not running
+	for _, root := range w.roots {
+		entries, err := os.ReadDir(root)
+		if err != nil {
+			w.out.emit(Event{Type: EventError, Folder: root, Error: err.Error()})
+			continue
+		}
+		for _, entry := range entries {
+			if entry.IsDir() {
+				w.addExisting(filepath.Join(root, entry.Name()))
+			}
+		}
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for folder := range w.queue {
+			w.mu.Lock()
+			delete(w.queued, folder)
+			w.mu.Unlock()
+			// Releases still queued on shutdown are picked up again on the next start
+			if ctx.Err() == nil {
+				w.checkRelease(ctx, folder)
+			}
+		}
+	}()
+	defer func() {
+		close(w.queue)
+		wg.Wait()
+	}()
+
+	ticker := time.NewTicker(tickInterval(w.opts.QuietPeriod))
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-w.fsw.Events:
+			if !ok {
+				return nil
+			}
+			w.handleEvent(event)
+		case err, ok := <-w.fsw.Errors:
+			if !ok {
+				return nil
+			}
+			w.out.emit(Event{Type: EventError, Error: err.Error()})
+		case now := <-ticker.C:
+			w.dispatch(now)
+		}
+	}
+}
+
+// tickInterval returns how often pending releases are checked for the end of their quiet period
+func tickInterval(quiet time.Duration) time.Duration {
+	interval := quiet / 4
+	if interval < 50*time.Millisecond {
+		interval = 50 * time.Millisecond
+	}
+	if interval > time.Second {
+		interval = time.Second
+	}
+	return interval
+}
+
+// addExisting watches a release found at startup and schedules it unless it is
+// unchanged since it was last checked
+func (w *Watcher) addExisting(folder string) {
+	w.watchTree(folder)
+
+	fingerprint, err := Fingerprint(folder)
+	if err == nil && w.state.Checked(folder, fingerprint) {
+		w.out.emit(Event{Type: EventSkipped, Folder: folder})
+		return
+	}
+	w.touch(folder)
+}
+
+// watchTree adds a watch for a directory and all of its subdirectories
+func (w *Watcher) watchTree(dir string) {
+	filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.IsDir() {
+			if err := w.fsw.Add(path); err != nil {
+				w.out.emit(Event{Type: EventError, Folder: path, Error: fmt.Sprintf("failed to watch: %v", err)})
+			}
+		}
+		return nil
+	})
+}
+
+// releaseOf returns the release folder a path belongs to, or "" for paths outside any release
+func (w *Watcher) releaseOf(path string) string {
+	for _, root := range w.roots {
+		rel, err := filepath.Rel(root, path)
+		if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+			continue
+		}
+		first := strings.SplitN(filepath.ToSlash(rel), "/", 2)[0]
+		return filepath.Join(root, first)
+	}
+	return ""
+}
+
+// handleEvent restarts the quiet period of the release a file system event belongs to
+func (w *Watcher) handleEvent(event fsnotify.Event) {
+	release := w.releaseOf(event.Name)
+	if release == "" {
+		return
+	}
+
+	// A release folder that was removed or moved away is forgotten
+	if event.Name == release && event.Op&(fsnotify.Remove|fsnotify.Rename) != 0 {
+		w.mu.Lock()
+		delete(w.pending, release)
+		w.mu.Unlock()
+		if err := w.state.Forget(release); err != nil {
+			w.out.emit(Event{Type: EventError, Folder: release, Error: err.Error()})
+		}
+		return
+	}
+
+	info, err := os.Lstat(release)
+	if err != nil || !info.IsDir() {
+		// Files and symlinks directly in the watched directory are not releases
+		return
+	}
+
+	if event.Op&fsnotify.Create != 0 {
+		if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+			w.watchTree(event.Name)
+		}
+	}
+
+	w.touch(release)
+}
+
+// touch marks a release as written to now
+func (w *Watcher) touch(release string) {
+	w.mu.Lock()
+	_, exists := w.pending[release]
+	w.pending[release] = time.Now()
+	w.mu.Unlock()
+
+	if !exists {
+		w.out.emit(Event{Type: EventPending, Folder: release})
+	}
+}
+
+// dispatch queues the releases whose quiet period has ended
+func (w *Watcher) dispatch(now time.Time) {
+	var due []string
+
+	w.mu.Lock()
+	for release, last := range w.pending {
+		if now.Sub(last) < w.opts.QuietPeriod || w.queued[release] {
+			continue
+		}
+		delete(w.pending, release)
+		w.queued[release] = true
+		due = append(due, release)
+	}
+	w.mu.Unlock()
+
+	sort.Strings(due)
+	for _, release := range due {
+		w.queue <- release
+	}
+}
+
+// checkRelease runs the configured checks on a release, records the verdict and emits it.
+// A check cancelled by a shutdown or the check timeout is not recorded, so the release is
+// checked again on the next start.
+func (w *Watcher) checkRelease(ctx context.Context, folder string) {
+	if _, err := os.Stat(folder); err != nil {
+		// Removed while waiting
+		return
+	}
+
+	fingerprint, err := Fingerprint(folder)
+	if err != nil {
+		w.out.emit(Event{Type: EventError, Folder: folder, Error: err.Error()})
+		return
+	}
+	if w.state.Checked(folder, fingerprint) {
+		// Only touched by our own actions (e.g. a marker file) since the last check
+		return
+	}
+
+	category, err := validate.DetectCategory(folder, w.opts.Check.OverwriteCategory)
+	if err != nil {
+		w.out.emit(Event{Type: EventError, Folder: folder, Error: err.Error()})
+		return
+	}
+	if category == "" {
+		w.record(folder, fingerprint, false)
+		w.out.emit(Event{Type: EventError, Folder: folder, Error: "unknown or unsupported release category"})
+		return
+	}
+
+	if w.opts.CheckTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, w.opts.CheckTimeout)
+		defer cancel()
+	}
+
+	result, err := check.CheckFolder(ctx, folder, w.presetConfig, category, w.opts.Check)
+	if err != nil {
+		w.out.emit(Event{Type: EventError, Folder: folder, Category: category, Error: err.Error()})
+		return
+	}
+	if result.Cancelled {
+		w.out.emit(Event{Type: EventError, Folder: folder, Category: category, Error: fmt.Sprintf("check cancelled: %v", context.Cause(ctx))})
+		return
+	}
+
+	if !w.opts.Check.SkipActions {
+		check.RunActions(result, w.presetConfig, w.opts.Check.DryRun)
+	}
+
+	if _, err := os.Stat(folder); err != nil {
+		// Moved away by an action
+		if err := w.state.Forget(folder); err != nil {
+			w.out.emit(Event{Type: EventError, Folder: folder, Error: err.Error()})
+		}
+	} else {
+		// Files created by actions are part of the checked release
+		if updated, err := Fingerprint(folder); err == nil {
+			fingerprint = updated
+		}
+		w.record(folder, fingerprint, result.Valid)
+	}
+	w.out.verdict(result)
+}
+
+// record persists the verdict of a release
+func (w *Watcher) record(folder string, fingerprint string, valid bool) {
+	err := w.state.Record(folder, Release{
+		Fingerprint: fingerprint,
+		Valid:       valid,
+		CheckedAt:   time.Now(),
+	})
+	if err != nil {
+		w.out.emit(Event{Type: EventError, Folder: folder, Error: err.Error()})
+	}
+}
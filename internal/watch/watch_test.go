@@ -0,0 +1,193 @@
+package watch
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+const testPresets = `schema_version: 2
+rules:
+  app:
+    deny_unexpected: true
+    checks: [rules]
+    rules:
+      - pattern: "*.nfo"
+        min: 1
+        max: 1
+`
+
+// syncBuffer is a bytes.Buffer safe for concurrent use
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) events(t *testing.T) []Event {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var events []Event
+	scanner := bufio.NewScanner(bytes.NewReader(b.buf.Bytes()))
+	scanner.Buffer(make([]byte, 1024*1024), 1024*1024)
+	for scanner.Scan() {
+		var event Event
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			t.Fatalf("Event is not valid JSON: %v", err)
+		}
+		events = append(events, event)
+	}
+	return events
+}
+
+// waitForEvent waits until an event of the given type was emitted for the folder
+func (b *syncBuffer) waitForEvent(t *testing.T, eventType string, folder string, timeout time.Duration) *Event {
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		for _, event := range b.events(t) {
+			if event.Type == eventType && event.Folder == folder {
+				return &event
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	return nil
+}
+
+func startWatcher(t *testing.T, root string, opts Options) (*syncBuffer, context.CancelFunc, chan error) {
+	t.Helper()
+
+	out := &syncBuffer{}
+	opts.Output = out
+
+	watcher, err := New([]string{root}, opts)
+	if err != nil {
+		t.Fatalf("Failed to create watcher: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- watcher.Run(ctx)
+	}()
+
+	if out.waitForEvent(t, EventWatching, root, 2*time.Second) == nil {
+		cancel()
+		t.Fatal("Watcher did not start")
+	}
+	return out, cancel, done
+}
+
+func TestWatcher(t *testing.T) {
+	tmpDir := t.TempDir()
+	root := filepath.Join(tmpDir, "incoming")
+	if err := os.Mkdir(root, 0755); err != nil {
+		t.Fatalf("Failed to create incoming folder: %v", err)
+	}
+	presetPath := filepath.Join(tmpDir, "presets.yaml")
+	if err := os.WriteFile(presetPath, []byte(testPresets), 0644); err != nil {
+		t.Fatalf("Failed to write presets: %v", err)
+	}
+
+	opts := DefaultOptions()
+	opts.Check.PresetPath = presetPath
+	opts.Check.OverwriteCategory = "app"
+	opts.QuietPeriod = 200 * time.Millisecond
+	opts.StatePath = filepath.Join(tmpDir, "state.json")
+	opts.OutputFormat = OutputFormatNDJSON
+
+	out, cancel, done := startWatcher(t, root, opts)
+
+	// A release lands in the incoming folder
+	release := filepath.Join(root, "App.v1.0-GRP")
+	if err := os.Mkdir(release, 0755); err != nil {
+		t.Fatalf("Failed to create release: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(release, "app.nfo"), []byte("nfo"), 0644); err != nil {
+		t.Fatalf("Failed to write release file: %v", err)
+	}
+
+	verdict := out.waitForEvent(t, EventVerdict, release, 5*time.Second)
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("Watcher failed: %v", err)
+	}
+	if verdict == nil {
+		t.Fatal("Expected a verdict for the new release")
+	}
+	if verdict.Valid == nil || !*verdict.Valid || verdict.Result == nil {
+		t.Errorf("Expected a passing verdict with results, got %+v", verdict)
+	}
+
+	// After a restart the unchanged release is not checked again
+	out, cancel, done = startWatcher(t, root, opts)
+	skipped := out.waitForEvent(t, EventSkipped, release, 2*time.Second)
+	time.Sleep(3 * opts.QuietPeriod)
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("Watcher failed: %v", err)
+	}
+	if skipped == nil {
+		t.Error("Expected the checked release to be skipped after a restart")
+	}
+	for _, event := range out.events(t) {
+		if event.Type == EventVerdict {
+			t.Errorf("Expected no verdict after a restart, got one for %s", event.Folder)
+		}
+	}
+}
+
+func TestState_RoundTrip(t *testing.T) {
+	tmpDir := t.TempDir()
+	statePath := filepath.Join(tmpDir, "state.json")
+
+	release := filepath.Join(tmpDir, "Release-GRP")
+	if err := os.Mkdir(release, 0755); err != nil {
+		t.Fatalf("Failed to create release: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(release, "a.rar"), []byte("data"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	fingerprint, err := Fingerprint(release)
+	if err != nil {
+		t.Fatalf("Failed to fingerprint release: %v", err)
+	}
+
+	state, err := LoadState(statePath)
+	if err != nil {
+		t.Fatalf("Failed to load state: %v", err)
+	}
+	if err := state.Record(release, Release{Fingerprint: fingerprint, Valid: true}); err != nil {
+		t.Fatalf("Failed to record release: %v", err)
+	}
+
+	state, err = LoadState(statePath)
+	if err != nil {
+		t.Fatalf("Failed to reload state: %v", err)
+	}
+	if !state.Checked(release, fingerprint) {
+		t.Error("Expected release to be recorded as checked")
+	}
+
+	// Adding a file changes the fingerprint
+	if err := os.WriteFile(filepath.Join(release, "a.r00"), []byte("more"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	changed, _ := Fingerprint(release)
+	if state.Checked(release, changed) {
+		t.Error("Expected a changed release not to be recorded as checked")
+	}
+}
@@ -0,0 +1,257 @@
+package zipset
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/autobrr/sfvbrr/internal/nfo"
+	"github.com/autobrr/sfvbrr/internal/rar"
+)
+
+const (
+	// dizName is the name of the description file inside every disk
+	dizName = "file_id.diz"
+	// maxDIZSize limits how much of a file_id.diz is read
+	maxDIZSize = 64 * 1024
+	// maxInflatedVolume limits the size of compressed RAR volumes that are inflated in memory
+	// to read their headers; stored volumes are read in place
+	maxInflatedVolume = 256 * 1024 * 1024
+)
+
+// diskNumberRegex matches the disk number at the end of a ZIP file name, e.g. "grp01"
+var diskNumberRegex = regexp.MustCompile(`(\d+)$`)
+
+// Disk is a single ZIP file of a 0-day release
+type Disk struct {
+	Name       string    // File name of the ZIP file, relative to the release folder
+	Number     int       // Disk number from the file_id.diz, or from the file name for "[xx/15]" (0 = unknown)
+	Total      int       // Number of disks stated by the file_id.diz (0 = unknown)
+	DIZ        *nfo.Info // Parsed file_id.diz (nil if the ZIP has none)
+	RARVolumes []string  // Names of the RAR volumes inside the ZIP file
+	Error      error     // The ZIP file could not be read
+}
+
+// Result is the result of cross-checking the disks of a release
+type Result struct {
+	Dir          string
+	Disks        []Disk           // Disks ordered by disk number
+	Total        int              // Number of disks the release should have
+	MissingDisks []int            // Disk numbers that are not present
+	RAR          []*rar.SetResult // Validation of the RAR volume sets inside the ZIP files
+	Valid        bool
+	Errors       []error
+}
+
+// String summarizes the result, e.g. "15/15 disks, RAR set grp.rar"
+func (r *Result) String() string {
+	s := fmt.Sprintf("%d/%d disks", len(r.Disks), r.Total)
+	for _, set := range r.RAR {
+		s += ", RAR set " + set.Set.Name
+	}
+	return s
+}
+
+// Validate cross-checks the ZIP files of a 0-day release. Every ZIP file should hold a
+// file_id.diz with a "[01/15]" disk count and one RAR volume; the disk numbers must be
+// continuous and complete and the RAR volumes must form a consistent set. Names are
+// relative to dir. Each problem, e.g. every missing disk, is reported as its own error.
+func Validate(dir string, names []string) *Result {
+	result := &Result{Dir: dir, Valid: true}
+	fail := func(format string, args ...any) {
+		result.Valid = false
+		result.Errors = append(result.Errors, fmt.Errorf(format, args...))
+	}
+
+	volumes := make(map[string]rar.Volume)
+	var volumeNames []string
+	for _, name := range names {
+		disk, diskVolumes := readDisk(dir, name)
+		result.Disks = append(result.Disks, disk)
+		for _, volume := range diskVolumes {
+			volumes[volume.Name] = volume
+			volumeNames = append(volumeNames, volume.Name)
+		}
+	}
+	sort.SliceStable(result.Disks, func(i, j int) bool {
+		return result.Disks[i].Number < result.Disks[j].Number
+	})
+
+	// The disk count of the first file_id.diz is the expected total
+	for _, disk := range result.Disks {
+		if disk.Total > 0 {
+			result.Total = disk.Total
+			break
+		}
+	}
+	if result.Total == 0 {
+		result.Total = len(result.Disks)
+	}
+
+	present := make(map[int]string)
+	for _, disk := range result.Disks {
+		switch {
+		case disk.Error != nil:
+			fail("%s: %v", disk.Name, disk.Error)
+		case disk.DIZ == nil:
+			fail("%s: no %s", disk.Name, dizName)
+		case disk.Total == 0:
+			fail("%s: %s has no disk count", disk.Name, dizName)
+		case disk.Total != result.Total:
+			fail("%s: %s says %d disks, expected %d", disk.Name, dizName, disk.Total, result.Total)
+		}
+
+		switch {
+		case disk.Number == 0:
+			if disk.Error == nil {
+				fail("%s: unknown disk number", disk.Name)
+			}
+		case disk.Number > result.Total:
+			fail("%s: disk %02d is beyond the %d disks of the release", disk.Name, disk.Number, result.Total)
+		default:
+			if other, exists := present[disk.Number]; exists {
+				fail("duplicate disk %02d: %s and %s", disk.Number, other, disk.Name)
+			} else {
+				present[disk.Number] = disk.Name
+			}
+		}
+
+		if len(disk.RARVolumes) > 1 {
+			fail("%s: holds %d RAR volumes, expected one", disk.Name, len(disk.RARVolumes))
+		} else if len(disk.RARVolumes) == 1 && disk.Number > 0 {
+			if parsed, ok := rar.ParseVolumeName(disk.RARVolumes[0]); ok && parsed.Index+1 != disk.Number {
+				fail("%s: holds RAR volume %d, but is disk %02d", disk.Name, parsed.Index+1, disk.Number)
+			}
+		}
+	}
+
+	for number := 1; number <= result.Total; number++ {
+		if _, exists := present[number]; !exists {
+			result.MissingDisks = append(result.MissingDisks, number)
+			fail("missing disk %02d/%02d", number, result.Total)
+		}
+	}
+
+	// The RAR volumes of all disks must form a single consistent set
+	sets := rar.GroupVolumes(dir, volumeNames)
+	if len(sets) > 1 {
+		fail("the ZIP files hold %d different RAR sets", len(sets))
+	}
+	for _, set := range sets {
+		for i := range set.Volumes {
+			volume := volumes[set.Volumes[i].Name]
+			set.Volumes[i].Path = volume.Path
+			set.Volumes[i].Header = volume.Header
+			set.Volumes[i].Error = volume.Error
+		}
+		setResult := rar.ValidateSet(set)
+		result.RAR = append(result.RAR, setResult)
+		for _, err := range setResult.Errors {
+			fail("RAR set %s: %v", set.Name, err)
+		}
+	}
+
+	return result
+}
+
+// readDisk reads the file_id.diz and the headers of the RAR volumes inside a ZIP file
+func readDisk(dir, name string) (Disk, []rar.Volume) {
+	disk := Disk{Name: name}
+	zipPath := filepath.Join(dir, name)
+
+	// DIZ files of many groups say "[xx/15]", the number is then taken from the file name
+	stem := strings.TrimSuffix(filepath.Base(name), filepath.Ext(name))
+	if m := diskNumberRegex.FindStringSubmatch(stem); m != nil {
+		disk.Number, _ = strconv.Atoi(m[1])
+	}
+
+	f, err := os.Open(zipPath)
+	if err != nil {
+		disk.Error = fmt.Errorf("failed to open ZIP file: %w", err)
+		return disk, nil
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		disk.Error = fmt.Errorf("failed to open ZIP file: %w", err)
+		return disk, nil
+	}
+	r, err := zip.NewReader(f, info.Size())
+	if err != nil {
+		disk.Error = fmt.Errorf("failed to open ZIP file: %w", err)
+		return disk, nil
+	}
+
+	var volumes []rar.Volume
+	for _, file := range r.File {
+		if file.FileInfo().IsDir() {
+			continue
+		}
+		base := path.Base(file.Name)
+
+		if strings.EqualFold(base, dizName) {
+			data, err := readEntry(file, maxDIZSize)
+			if err != nil {
+				disk.Error = fmt.Errorf("failed to read %s: %w", dizName, err)
+				return disk, nil
+			}
+			disk.DIZ = nfo.Parse(data)
+			if disk.DIZ.Disk > 0 {
+				disk.Number = disk.DIZ.Disk
+			}
+			disk.Total = disk.DIZ.Disks
+			continue
+		}
+
+		parsed, ok := rar.ParseVolumeName(base)
+		if !ok {
+			continue
+		}
+		volume := rar.Volume{Name: base, Path: filepath.Join(zipPath, file.Name), Index: parsed.Index}
+		volume.Header, volume.Error = readVolumeHeader(f, file)
+		volumes = append(volumes, volume)
+		disk.RARVolumes = append(disk.RARVolumes, base)
+	}
+
+	return disk, volumes
+}
+
+// readVolumeHeader reads the headers of a RAR volume stored in a ZIP file. Stored
+// volumes are read in place; compressed ones are inflated in memory.
+func readVolumeHeader(f *os.File, file *zip.File) (*rar.Header, error) {
+	if file.Method == zip.Store {
+		offset, err := file.DataOffset()
+		if err != nil {
+			return nil, fmt.Errorf("failed to locate RAR volume: %w", err)
+		}
+		return rar.ReadHeader(io.NewSectionReader(f, offset, int64(file.CompressedSize64)), int64(file.CompressedSize64))
+	}
+
+	if file.UncompressedSize64 > maxInflatedVolume {
+		return nil, fmt.Errorf("compressed RAR volume of %d bytes is too large to inspect", file.UncompressedSize64)
+	}
+	data, err := readEntry(file, maxInflatedVolume)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read RAR volume: %w", err)
+	}
+	return rar.ReadHeader(bytes.NewReader(data), int64(len(data)))
+}
+
+// readEntry reads up to limit bytes of a ZIP entry
+func readEntry(file *zip.File, limit int64) ([]byte, error) {
+	rc, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	return io.ReadAll(io.LimitReader(rc, limit))
+}
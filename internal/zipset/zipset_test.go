@@ -0,0 +1,181 @@
+package zipset
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// buildRAR4Volume creates a minimal old-style RAR4 volume holding one split file
+func buildRAR4Volume(index, total int) []byte {
+	var buf bytes.Buffer
+	buf.Write([]byte{0x52, 0x61, 0x72, 0x21, 0x1a, 0x07, 0x00})
+
+	block := func(headType byte, flags uint16, body []byte) {
+		data := make([]byte, 7+len(body))
+		data[2] = headType
+		binary.LittleEndian.PutUint16(data[3:5], flags)
+		binary.LittleEndian.PutUint16(data[5:7], uint16(len(data)))
+		copy(data[7:], body)
+		binary.LittleEndian.PutUint16(data[0:2], uint16(crc32.ChecksumIEEE(data[2:])))
+		buf.Write(data)
+	}
+
+	block(0x73, 0x0001, make([]byte, 6)) // Main header, volume flag
+
+	payload := []byte("payload")
+	name := []byte("setup.exe")
+	fileBody := make([]byte, 25+len(name))
+	binary.LittleEndian.PutUint32(fileBody[0:4], uint32(len(payload)))
+	binary.LittleEndian.PutUint16(fileBody[19:21], uint16(len(name)))
+	copy(fileBody[25:], name)
+	fileFlags := uint16(0x8000)
+	if index > 0 {
+		fileFlags |= 0x0001
+	}
+	if index < total-1 {
+		fileFlags |= 0x0002
+	}
+	block(0x74, fileFlags, fileBody)
+	buf.Write(payload)
+
+	endFlags := uint16(0x0008)
+	if index < total-1 {
+		endFlags |= 0x0001
+	}
+	endBody := make([]byte, 2)
+	binary.LittleEndian.PutUint16(endBody, uint16(index))
+	block(0x7b, endFlags, endBody)
+
+	return buf.Bytes()
+}
+
+// volumeName returns the old-style name of a RAR volume
+func volumeName(index int) string {
+	if index == 0 {
+		return "tool.rar"
+	}
+	return fmt.Sprintf("tool.r%02d", index-1)
+}
+
+// writeDisk writes a ZIP file holding a file_id.diz and the given RAR volume
+func writeDisk(t *testing.T, dir, name, diz string, volume int, total int, method uint16) {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	add := func(name string, data []byte, method uint16) {
+		w, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: method})
+		if err != nil {
+			t.Fatalf("Failed to create ZIP entry: %v", err)
+		}
+		if _, err := w.Write(data); err != nil {
+			t.Fatalf("Failed to write ZIP entry: %v", err)
+		}
+	}
+	if diz != "" {
+		add("FILE_ID.DIZ", []byte(diz), zip.Deflate)
+	}
+	if volume >= 0 {
+		add(volumeName(volume), buildRAR4Volume(volume, total), method)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("Failed to close ZIP: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, name), buf.Bytes(), 0644); err != nil {
+		t.Fatalf("Failed to write ZIP: %v", err)
+	}
+}
+
+func TestValidate_CompleteSet(t *testing.T) {
+	dir := t.TempDir()
+	var names []string
+	for i := 0; i < 3; i++ {
+		name := fmt.Sprintf("tool%02d.zip", i+1)
+		method := zip.Store
+		if i == 1 {
+			method = zip.Deflate // Compressed volumes are inflated to read their headers
+		}
+		writeDisk(t, dir, name, "\xc9\xcd Tool v1.0 [xx/03] \xcd\xbb", i, 3, method)
+		names = append(names, name)
+	}
+
+	result := Validate(dir, names)
+	if !result.Valid {
+		t.Fatalf("Expected a valid disk set, got %v", result.Errors)
+	}
+	if result.Total != 3 || len(result.Disks) != 3 || result.Disks[2].Number != 3 {
+		t.Errorf("Expected 3 numbered disks, got %+v", result.Disks)
+	}
+	if len(result.RAR) != 1 || !result.RAR[0].Valid {
+		t.Errorf("Expected one valid RAR set, got %+v", result.RAR)
+	}
+	if result.String() != "3/3 disks, RAR set tool.rar" {
+		t.Errorf("Unexpected summary %q", result.String())
+	}
+}
+
+func TestValidate_Gaps(t *testing.T) {
+	dir := t.TempDir()
+	// Disks 2 and 4 of 5 are missing, disk 5 states a different disk count
+	writeDisk(t, dir, "tool01.zip", "Tool [01/05]", 0, 5, zip.Store)
+	writeDisk(t, dir, "tool03.zip", "Tool [03/05]", 2, 5, zip.Store)
+	writeDisk(t, dir, "tool05.zip", "Tool [05/06]", 4, 5, zip.Store)
+
+	result := Validate(dir, []string{"tool01.zip", "tool03.zip", "tool05.zip"})
+	if result.Valid {
+		t.Fatal("Expected an invalid disk set")
+	}
+	if len(result.MissingDisks) != 2 || result.MissingDisks[0] != 2 || result.MissingDisks[1] != 4 {
+		t.Errorf("Expected disks 2 and 4 to be missing, got %v", result.MissingDisks)
+	}
+
+	var messages []string
+	for _, err := range result.Errors {
+		messages = append(messages, err.Error())
+	}
+	joined := strings.Join(messages, "\n")
+	for _, expected := range []string{
+		"missing disk 02/05",
+		"missing disk 04/05",
+		"tool05.zip: file_id.diz says 6 disks, expected 5",
+		"RAR set tool.rar: missing volume tool.r00",
+		"RAR set tool.rar: missing volume tool.r02",
+	} {
+		if !strings.Contains(joined, expected) {
+			t.Errorf("Expected error %q, got:\n%s", expected, joined)
+		}
+	}
+}
+
+func TestValidate_BrokenDisks(t *testing.T) {
+	dir := t.TempDir()
+	writeDisk(t, dir, "tool01.zip", "", 0, 2, zip.Store)
+	writeDisk(t, dir, "tool02.zip", "Tool [02/02]", 0, 2, zip.Store) // Holds the first volume again
+	if err := os.WriteFile(filepath.Join(dir, "tool03.zip"), []byte("not a zip"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	result := Validate(dir, []string{"tool01.zip", "tool02.zip", "tool03.zip"})
+
+	var messages []string
+	for _, err := range result.Errors {
+		messages = append(messages, err.Error())
+	}
+	joined := strings.Join(messages, "\n")
+	for _, expected := range []string{
+		"tool01.zip: no file_id.diz",
+		"tool02.zip: holds RAR volume 1, but is disk 02",
+		"tool03.zip: failed to open ZIP file",
+		"tool03.zip: disk 03 is beyond the 2 disks of the release",
+	} {
+		if !strings.Contains(joined, expected) {
+			t.Errorf("Expected error %q, got:\n%s", expected, joined)
+		}
+	}
+}
@@ -0,0 +1,285 @@
+package sfvbrr
+
+import (
+	"errors"
+	"slices"
+
+	"github.com/autobrr/sfvbrr/internal/check"
+	"github.com/autobrr/sfvbrr/internal/checksum"
+	"github.com/autobrr/sfvbrr/internal/music"
+	"github.com/autobrr/sfvbrr/internal/rar"
+	"github.com/autobrr/sfvbrr/internal/validate"
+	"github.com/autobrr/sfvbrr/internal/zipset"
+)
+
+// convertSFVResult converts an internal SFV validation result
+func convertSFVResult(result *checksum.ValidationResult) *SFVResult {
+	output := &SFVResult{
+		Path:      result.SFVFile.Path,
+		Algorithm: string(result.SFVFile.Hasher().Algorithm),
+		Files:     make([]FileResult, len(result.Results)),
+		Total:     result.TotalFiles,
+		Valid:     result.ValidFiles,
+		Invalid:   result.InvalidFiles,
+		Missing:   result.MissingFiles,
+		Orphans:   result.Orphans,
+		Bytes:     result.TotalBytes,
+		Cancelled: result.Cancelled,
+	}
+
+	for i, res := range result.Results {
+		output.Files[i] = FileResult{
+			Name:      res.Entry.Filename,
+			Path:      res.Entry.Path,
+			Expected:  res.Entry.Checksum,
+			Computed:  res.Computed,
+			Bytes:     res.Bytes,
+			Valid:     res.Valid,
+			Missing:   errors.Is(res.Error, checksum.ErrFileNotFound),
+			Cancelled: checksum.IsCancelled(res.Error),
+			Err:       res.Error,
+		}
+	}
+
+	return output
+}
+
+// convertZIPResult converts an internal ZIP validation result
+func convertZIPResult(result *checksum.ZIPValidationResult) *ZIPResult {
+	output := &ZIPResult{
+		Path:      result.ZIPFile.Path,
+		Entries:   make([]FileResult, len(result.Results)),
+		Total:     result.TotalEntries,
+		Valid:     result.ValidEntries,
+		Invalid:   result.InvalidEntries,
+		Bytes:     result.TotalBytes,
+		Cancelled: result.Cancelled,
+	}
+
+	// A ZIP file that could not be parsed has no entry results, only its error
+	if len(result.Results) == 0 && len(result.Errors) > 0 {
+		output.Err = result.Errors[0]
+	}
+
+	for i, res := range result.Results {
+		output.Entries[i] = FileResult{
+			Name:      res.Entry.Name,
+			Path:      res.Entry.Path,
+			Bytes:     res.Bytes,
+			Valid:     res.Valid,
+			Cancelled: checksum.IsCancelled(res.Error),
+			Err:       res.Error,
+		}
+	}
+
+	return output
+}
+
+// convertRARResult converts an internal RAR volume set result
+func convertRARResult(result *rar.SetResult) *RARResult {
+	output := &RARResult{
+		Name:           result.Set.Name,
+		Volumes:        make([]string, len(result.Set.Volumes)),
+		MissingVolumes: result.MissingVolumes,
+		Valid:          result.Valid,
+		Errors:         result.Errors,
+	}
+	for i, volume := range result.Set.Volumes {
+		output.Volumes[i] = volume.Path
+	}
+	return output
+}
+
+// convertRuleResults converts the internal results of the category rules
+func convertRuleResults(result *validate.ValidationResult) []RuleResult {
+	output := make([]RuleResult, len(result.RuleResults))
+	for i, res := range result.RuleResults {
+		ruleType := res.Rule.Type
+		if ruleType == "" {
+			ruleType = "file"
+		}
+		output[i] = RuleResult{
+			Pattern:     res.Rule.Pattern,
+			Type:        ruleType,
+			Description: res.Description,
+			Matched:     res.Matched,
+			Valid:       res.Valid,
+			Err:         res.Error,
+			Issues:      res.Issues,
+		}
+		for _, container := range res.Containers {
+			output[i].Containers = append(output[i].Containers, convertContainerResult(container))
+		}
+		for _, audio := range res.Audio {
+			output[i].Audio = append(output[i].Audio, convertAudioResult(audio))
+		}
+		for _, content := range res.Contents {
+			output[i].Contents = append(output[i].Contents, convertContentResult(content))
+		}
+		if res.DiskSet != nil {
+			output[i].DiskSet = convertDiskSet(res.DiskSet)
+		}
+		if res.Playlist != nil {
+			output[i].Playlist = convertPlaylist(res.Playlist)
+		}
+	}
+	return output
+}
+
+// convertPlaylist converts the internal result of a music track list check
+func convertPlaylist(result *music.Result) *PlaylistResult {
+	output := &PlaylistResult{
+		Discs:      result.Discs,
+		Missing:    result.Missing,
+		Unlisted:   result.Unlisted,
+		Unverified: result.Unverified,
+	}
+	for _, playlist := range result.Playlists {
+		output.Playlists = append(output.Playlists, playlist.Name)
+	}
+	for _, track := range result.Tracks {
+		output.Tracks = append(output.Tracks, AudioTrack{Name: track.Name, Disc: track.Disc, Number: track.Number})
+	}
+	return output
+}
+
+// convertDiskSet converts the internal result of a 0-day disk set check
+func convertDiskSet(result *zipset.Result) *DiskSetResult {
+	output := &DiskSetResult{Total: result.Total, MissingDisks: result.MissingDisks}
+	for _, disk := range result.Disks {
+		output.Disks = append(output.Disks, DiskResult{
+			Name:       disk.Name,
+			Number:     disk.Number,
+			Total:      disk.Total,
+			RARVolumes: disk.RARVolumes,
+			Err:        disk.Error,
+		})
+	}
+	return output
+}
+
+// convertContentResult converts the internal result of an NFO or DIZ content check
+func convertContentResult(content validate.ContentResult) ContentResult {
+	output := ContentResult{Path: content.Path, Issues: content.Issues}
+	if content.Info != nil {
+		output.Release = content.Info.Release
+		output.Group = content.Info.Group
+		output.Disk = content.Info.Disk
+		output.Disks = content.Info.Disks
+		for _, link := range content.Info.Links {
+			output.Links = append(output.Links, Link{Site: link.Site, URL: link.URL, ID: link.ID})
+		}
+	}
+	return output
+}
+
+// convertContainerResult converts the internal result of a container check
+func convertContainerResult(container validate.ContainerResult) ContainerResult {
+	output := ContainerResult{Path: container.Path, Err: container.Error}
+	if container.Info != nil {
+		output.Format = container.Info.Format
+		output.Duration = container.Info.Duration
+		for _, track := range container.Info.Tracks {
+			output.Tracks = append(output.Tracks, Track{Number: track.Number, Type: track.Type, Codec: track.Codec})
+		}
+	}
+	return output
+}
+
+// convertAudioResult converts the internal result of an audio header check
+func convertAudioResult(audio validate.AudioResult) AudioResult {
+	output := AudioResult{Path: audio.Path, Err: audio.Error}
+	if audio.Info != nil {
+		output.Format = audio.Info.Format
+		output.Duration = audio.Info.Duration
+		output.SampleRate = audio.Info.SampleRate
+		output.Channels = audio.Info.Channels
+		output.BitsPerSample = audio.Info.BitsPerSample
+		output.Bitrate = audio.Info.Bitrate
+		output.VBR = audio.Info.VBR
+		output.ID3v2 = audio.Info.ID3v2
+		output.MD5 = audio.Info.MD5
+		output.MD5Verified = audio.Info.MD5Verified
+	}
+	return output
+}
+
+// convertRuleSets converts the rule sets applied to a release
+func convertRuleSets(result *validate.ValidationResult) []RuleSet {
+	var output []RuleSet
+	for _, set := range result.RuleSets {
+		when := make(map[string][]string, len(set.When))
+		for attribute, values := range set.When {
+			when[attribute] = slices.Clone(values)
+		}
+		output = append(output, RuleSet{ID: set.ID, When: when})
+	}
+	return output
+}
+
+// otherErrors returns the errors of a rule validation that are not reported with a rule
+func otherErrors(result *validate.ValidationResult) []error {
+	ruleErrors := make(map[error]bool)
+	for _, res := range result.RuleResults {
+		if res.Error != nil {
+			ruleErrors[res.Error] = true
+		}
+	}
+	var output []error
+	for _, err := range result.Errors {
+		if !ruleErrors[err] {
+			output = append(output, err)
+		}
+	}
+	return output
+}
+
+// convertSubfolders converts the results of the subfolders validated as releases of their own
+func convertSubfolders(result *validate.ValidationResult) []*SubfolderResult {
+	var output []*SubfolderResult
+	for _, subfolder := range result.Subfolders {
+		output = append(output, &SubfolderResult{
+			Path:            subfolder.FolderPath,
+			Category:        subfolder.Category,
+			Valid:           subfolder.Valid,
+			RuleSets:        convertRuleSets(subfolder),
+			Rules:           convertRuleResults(subfolder),
+			UnexpectedFiles: subfolder.UnexpectedFiles,
+			Subfolders:      convertSubfolders(subfolder),
+			Errors:          otherErrors(subfolder),
+		})
+	}
+	return output
+}
+
+// convertReleaseResult converts an internal release check result
+func convertReleaseResult(result *check.Result) *ReleaseResult {
+	output := &ReleaseResult{
+		Path:      result.FolderPath,
+		Category:  result.Category,
+		Checks:    result.Checks,
+		Skipped:   result.Skipped,
+		Valid:     result.Valid,
+		Cancelled: result.Cancelled,
+		Errors:    slices.Clone(result.Errors),
+	}
+
+	if result.Rules != nil {
+		output.Rules = convertRuleResults(result.Rules)
+		output.RuleSets = convertRuleSets(result.Rules)
+		output.UnexpectedFiles = result.Rules.UnexpectedFiles
+		output.Subfolders = convertSubfolders(result.Rules)
+		output.Errors = append(output.Errors, otherErrors(result.Rules)...)
+	}
+	for _, res := range result.SFV {
+		output.SFV = append(output.SFV, convertSFVResult(res))
+	}
+	for _, res := range result.ZIP {
+		output.ZIP = append(output.ZIP, convertZIPResult(res))
+	}
+	for _, res := range result.RAR {
+		output.RAR = append(output.RAR, convertRARResult(res))
+	}
+
+	return output
+}
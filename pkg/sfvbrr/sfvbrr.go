@@ -0,0 +1,134 @@
+// Package sfvbrr validates scene releases: SFV checksums, ZIP integrity and the
+// contents of release folders against category rules.
+//
+// The functions never print anything; results are returned as typed values and
+// progress is reported through Options.Progress. Validations stop soon after the
+// context is done and return the partial result, marked as cancelled, together
+// with the context error.
+package sfvbrr
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/autobrr/sfvbrr/internal/check"
+	"github.com/autobrr/sfvbrr/internal/checksum"
+	"github.com/autobrr/sfvbrr/internal/preset"
+	"github.com/autobrr/sfvbrr/internal/validate"
+)
+
+// LoadPresets loads a preset configuration file. An empty path loads the default
+// configuration (~/.config/sfvbrr/presets.yaml), creating it on first use.
+func LoadPresets(path string) (*Presets, error) {
+	config, err := preset.LoadPresets(path)
+	if err != nil {
+		return nil, err
+	}
+	return &Presets{config: config}, nil
+}
+
+// DetectCategory detects the release category from the name of a release folder.
+// It returns an empty string for unknown or unsupported releases.
+func DetectCategory(dir string) (string, error) {
+	return validate.DetectCategory(dir, "")
+}
+
+// ValidateSFV validates the files listed in an SFV file or in an MD5, SHA-1, SHA-256
+// or BLAKE3 manifest; the format is detected from the file name and content
+func ValidateSFV(ctx context.Context, path string, opts Options) (*SFVResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	sfv, err := checksum.ParseManifestFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse SFV file: %w", err)
+	}
+
+	checksumOpts := opts.checksumOptions(CheckSFV)
+	checksumOpts.CheckOrphans = opts.CheckOrphans
+	result, err := checksum.ValidateSFV(ctx, sfv, checksumOpts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to validate SFV: %w", err)
+	}
+	return convertSFVResult(result), cancelled(ctx, result.Cancelled)
+}
+
+// ValidateZIP tests the integrity of every entry of a ZIP file. A ZIP file that
+// can't be read is reported in ZIPResult.Err rather than as an error.
+func ValidateZIP(ctx context.Context, path string, opts Options) (*ZIPResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	results, err := checksum.ValidateZIPPaths(ctx, []string{path}, opts.checksumOptions(CheckZIP))
+	if err != nil {
+		return nil, err
+	}
+	return convertZIPResult(results[0]), cancelled(ctx, results[0].Cancelled)
+}
+
+// ValidateRelease runs the checks configured for the category of a release folder:
+// the category rules and, depending on the presets, the SFV, ZIP and RAR checks.
+func ValidateRelease(ctx context.Context, dir string, opts Options) (*ReleaseResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	presets := opts.Presets
+	if presets == nil {
+		var err error
+		if presets, err = LoadPresets(""); err != nil {
+			return nil, err
+		}
+	}
+
+	category, err := validate.DetectCategory(dir, opts.Category)
+	if err != nil {
+		return nil, fmt.Errorf("failed to detect category: %w", err)
+	}
+	if category == "" {
+		return nil, fmt.Errorf("unknown or unsupported release category")
+	}
+	if _, exists := presets.config.Rules[category]; !exists {
+		return nil, fmt.Errorf("invalid category '%s': category not found in preset configuration", category)
+	}
+
+	checkOpts := check.DefaultOptions()
+	checkOpts.Workers = opts.Workers
+	checkOpts.BufferSize = opts.BufferSize
+	checkOpts.Quiet = true
+	if opts.Progress != nil {
+		checkOpts.Progress = func(check string, completed, total int) {
+			opts.Progress(Progress{Check: check, Completed: completed, Total: total})
+		}
+	}
+
+	result, err := check.CheckFolder(ctx, dir, presets.config, category, checkOpts)
+	if err != nil {
+		return nil, err
+	}
+	return convertReleaseResult(result), cancelled(ctx, result.Cancelled)
+}
+
+// cancelled returns the context error of a result cut short by the context
+func cancelled(ctx context.Context, isCancelled bool) error {
+	if !isCancelled {
+		return nil
+	}
+	return ctx.Err()
+}
+
+// checksumOptions returns the options of an SFV or ZIP validation
+func (o Options) checksumOptions(check string) checksum.Options {
+	opts := checksum.DefaultOptions()
+	opts.Workers = o.Workers
+	opts.BufferSize = o.BufferSize
+	opts.Quiet = true
+	if o.Progress != nil {
+		opts.Progress = func(completed, total int) {
+			o.Progress(Progress{Check: check, Completed: completed, Total: total})
+		}
+	}
+	return opts
+}
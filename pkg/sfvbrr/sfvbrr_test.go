@@ -0,0 +1,296 @@
+package sfvbrr
+
+import (
+	"archive/zip"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+const testPresets = `schema_version: 2
+rules:
+  app:
+    deny_unexpected: false
+    checks: [rules, sfv, zip]
+    rules:
+      - pattern: "*.nfo"
+        min: 1
+`
+
+func writeFile(t *testing.T, path string, data []byte) {
+	t.Helper()
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatalf("Failed to write %s: %v", path, err)
+	}
+}
+
+// createRelease creates a release folder with an NFO, an SFV listing it and a ZIP file
+func createRelease(t *testing.T) string {
+	t.Helper()
+	dir := filepath.Join(t.TempDir(), "App.v1.0-GRP")
+	if err := os.Mkdir(dir, 0755); err != nil {
+		t.Fatalf("Failed to create release: %v", err)
+	}
+
+	writeFile(t, filepath.Join(dir, "app.nfo"), []byte("nfo"))
+	// CRC-32 of "nfo" is 0x53A28FD0, "missing.r00" does not exist
+	writeFile(t, filepath.Join(dir, "app.sfv"), []byte("app.nfo 53a28fd0\nmissing.r00 00000000\n"))
+
+	f, err := os.Create(filepath.Join(dir, "app.zip"))
+	if err != nil {
+		t.Fatalf("Failed to create ZIP file: %v", err)
+	}
+	zw := zip.NewWriter(f)
+	w, _ := zw.Create("setup.exe")
+	w.Write([]byte("binary"))
+	zw.Close()
+	f.Close()
+
+	return dir
+}
+
+func TestValidateSFV(t *testing.T) {
+	dir := createRelease(t)
+
+	var calls int
+	opts := Options{Progress: func(p Progress) {
+		calls++
+		if p.Check != CheckSFV || p.Total != 2 {
+			t.Errorf("Unexpected progress %+v", p)
+		}
+	}}
+
+	result, err := ValidateSFV(context.Background(), filepath.Join(dir, "app.sfv"), opts)
+	if err != nil {
+		t.Fatalf("ValidateSFV failed: %v", err)
+	}
+	if result.Total != 2 || result.Valid != 1 || result.Missing != 1 || result.OK() {
+		t.Errorf("Unexpected result %+v", result)
+	}
+	if !result.Files[0].Valid || result.Files[0].Computed != "53A28FD0" {
+		t.Errorf("Expected app.nfo to be valid, got %+v", result.Files[0])
+	}
+	if !result.Files[1].Missing {
+		t.Errorf("Expected missing.r00 to be missing, got %+v", result.Files[1])
+	}
+	if calls != 2 {
+		t.Errorf("Expected 2 progress calls, got %d", calls)
+	}
+}
+
+func TestValidateZIP(t *testing.T) {
+	dir := createRelease(t)
+
+	result, err := ValidateZIP(context.Background(), filepath.Join(dir, "app.zip"), Options{})
+	if err != nil {
+		t.Fatalf("ValidateZIP failed: %v", err)
+	}
+	if !result.OK() || result.Total != 1 || result.Entries[0].Name != "setup.exe" {
+		t.Errorf("Unexpected result %+v", result)
+	}
+
+	// An unreadable ZIP file is an invalid result, not an error
+	writeFile(t, filepath.Join(dir, "broken.zip"), []byte("not a zip"))
+	result, err = ValidateZIP(context.Background(), filepath.Join(dir, "broken.zip"), Options{})
+	if err != nil {
+		t.Fatalf("ValidateZIP failed: %v", err)
+	}
+	if result.OK() || result.Err == nil {
+		t.Errorf("Expected a broken ZIP result, got %+v", result)
+	}
+}
+
+func TestValidateRelease(t *testing.T) {
+	dir := createRelease(t)
+	presetPath := filepath.Join(t.TempDir(), "presets.yaml")
+	writeFile(t, presetPath, []byte(testPresets))
+
+	presets, err := LoadPresets(presetPath)
+	if err != nil {
+		t.Fatalf("LoadPresets failed: %v", err)
+	}
+
+	result, err := ValidateRelease(context.Background(), dir, Options{Presets: presets, Category: "app"})
+	if err != nil {
+		t.Fatalf("ValidateRelease failed: %v", err)
+	}
+	if result.Valid {
+		t.Error("Expected the release with a missing file to fail")
+	}
+	if len(result.Rules) != 1 || !result.Rules[0].Valid {
+		t.Errorf("Expected the NFO rule to pass, got %+v", result.Rules)
+	}
+	if len(result.SFV) != 1 || result.SFV[0].Missing != 1 || len(result.ZIP) != 1 || !result.ZIP[0].OK() {
+		t.Errorf("Unexpected SFV or ZIP results: %+v %+v", result.SFV, result.ZIP)
+	}
+
+	if _, err := ValidateRelease(context.Background(), dir, Options{Presets: presets, Category: "movie"}); err == nil {
+		t.Error("Expected an error for a category missing from the presets")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := ValidateRelease(ctx, dir, Options{Presets: presets}); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected a cancelled context to be reported, got %v", err)
+	}
+}
+
+func TestValidateRelease_Subfolders(t *testing.T) {
+	const presets = `rules:
+  movie:
+    deny_unexpected: true
+    checks: [rules]
+    rules:
+      - {pattern: "*.nfo", min: 1, max: 1}
+    subfolders:
+      - {pattern: "CD[0-9]", category: disc}
+  disc:
+    deny_unexpected: true
+    rules:
+      - {pattern: "*.sfv", min: 1, max: 1}
+`
+	presetPath := filepath.Join(t.TempDir(), "presets.yaml")
+	writeFile(t, presetPath, []byte(presets))
+	config, err := LoadPresets(presetPath)
+	if err != nil {
+		t.Fatalf("LoadPresets failed: %v", err)
+	}
+
+	dir := filepath.Join(t.TempDir(), "Movie.2025.DVDRip.x264-GRP")
+	for _, disc := range []string{"CD1", "CD2"} {
+		if err := os.MkdirAll(filepath.Join(dir, disc), 0755); err != nil {
+			t.Fatalf("Failed to create release: %v", err)
+		}
+	}
+	writeFile(t, filepath.Join(dir, "movie.nfo"), []byte("nfo"))
+	writeFile(t, filepath.Join(dir, "CD1", "cd1.sfv"), []byte("; empty\n"))
+	writeFile(t, filepath.Join(dir, "CD2", "unexpected.txt"), []byte("txt"))
+
+	result, err := ValidateRelease(context.Background(), dir, Options{Presets: config, Category: "movie"})
+	if err != nil {
+		t.Fatalf("ValidateRelease failed: %v", err)
+	}
+	if result.Valid || len(result.UnexpectedFiles) != 0 {
+		t.Errorf("Expected only the CD2 subfolder to fail the release, got %+v", result)
+	}
+	if len(result.Subfolders) != 2 {
+		t.Fatalf("Expected 2 subfolders, got %+v", result.Subfolders)
+	}
+	cd1, cd2 := result.Subfolders[0], result.Subfolders[1]
+	if filepath.Base(cd1.Path) != "CD1" || cd1.Category != "disc" || !cd1.Valid {
+		t.Errorf("Expected CD1 to be a valid disc, got %+v", cd1)
+	}
+	if filepath.Base(cd2.Path) != "CD2" || cd2.Valid || len(cd2.UnexpectedFiles) != 1 {
+		t.Errorf("Expected CD2 to fail with an unexpected file, got %+v", cd2)
+	}
+}
+
+// mp3Frames returns frames of MPEG-1 layer III audio at 44.1 kHz, with the bitrate index
+// in the high nibble of the third header byte
+func mp3Frames(bitrateIndex byte, length, frames int) []byte {
+	var data []byte
+	for range frames {
+		frame := make([]byte, length)
+		copy(frame, []byte{0xff, 0xfb, bitrateIndex<<4 | 0x00, 0x00})
+		data = append(data, frame...)
+	}
+	return data
+}
+
+func TestValidateRelease_Audio(t *testing.T) {
+	const presets = `rules:
+  music:
+    deny_unexpected: false
+    checks: [rules]
+    rules:
+      - pattern: "*.mp3"
+        verify: audio
+        audio: {same_bitrate: true}
+`
+	presetPath := filepath.Join(t.TempDir(), "presets.yaml")
+	writeFile(t, presetPath, []byte(presets))
+	config, err := LoadPresets(presetPath)
+	if err != nil {
+		t.Fatalf("LoadPresets failed: %v", err)
+	}
+
+	dir := filepath.Join(t.TempDir(), "Artist-Album-WEB-2025-GRP")
+	if err := os.Mkdir(dir, 0755); err != nil {
+		t.Fatalf("Failed to create release: %v", err)
+	}
+	// 128 kbps frames are 417 bytes, 320 kbps frames 1044 bytes
+	writeFile(t, filepath.Join(dir, "01-artist-one.mp3"), mp3Frames(9, 417, 20))
+	writeFile(t, filepath.Join(dir, "02-artist-two.mp3"), mp3Frames(9, 417, 20))
+	writeFile(t, filepath.Join(dir, "03-artist-three.mp3"), mp3Frames(14, 1044, 20))
+	writeFile(t, filepath.Join(dir, "04-artist-four.mp3"), []byte("not an mp3 file"))
+
+	result, err := ValidateRelease(context.Background(), dir, Options{Presets: config, Category: "music"})
+	if err != nil {
+		t.Fatalf("ValidateRelease failed: %v", err)
+	}
+	if result.Valid || len(result.Rules) != 1 {
+		t.Fatalf("Expected the audio rule to fail, got %+v", result)
+	}
+	rule := result.Rules[0]
+	if len(rule.Audio) != 4 || rule.Audio[0].Bitrate != 128 || rule.Audio[0].SampleRate != 44100 || rule.Audio[3].Err == nil {
+		t.Errorf("Unexpected audio results %+v", rule.Audio)
+	}
+	expected := []string{
+		"04-artist-four.mp3: file structure is corrupt: no MPEG audio frame sync found",
+		"mixed bitrates: 128 kbps (2 files), 320 kbps (1 file)",
+	}
+	if len(rule.Issues) != len(expected) {
+		t.Fatalf("Expected issues %q, got %q", expected, rule.Issues)
+	}
+	for i := range expected {
+		if rule.Issues[i] != expected[i] {
+			t.Errorf("Expected issue %q, got %q", expected[i], rule.Issues[i])
+		}
+	}
+}
+
+func TestValidateRelease_Naming(t *testing.T) {
+	const presets = `rules:
+  movie:
+    deny_unexpected: false
+    checks: [rules]
+    naming:
+      no_spaces: true
+      require_group: true
+      require: [resolution]
+      deny_dupes: true
+    rules:
+      - {pattern: "*.nfo", min: 1}
+`
+	presetPath := filepath.Join(t.TempDir(), "presets.yaml")
+	writeFile(t, presetPath, []byte(presets))
+	config, err := LoadPresets(presetPath)
+	if err != nil {
+		t.Fatalf("LoadPresets failed: %v", err)
+	}
+
+	dir := filepath.Join(t.TempDir(), "Movie.2025.BluRay.x264-GRP_1")
+	if err := os.Mkdir(dir, 0755); err != nil {
+		t.Fatalf("Failed to create release: %v", err)
+	}
+	writeFile(t, filepath.Join(dir, "movie.nfo"), []byte("nfo"))
+
+	result, err := ValidateRelease(context.Background(), dir, Options{Presets: config, Category: "movie"})
+	if err != nil {
+		t.Fatalf("ValidateRelease failed: %v", err)
+	}
+	if result.Valid || len(result.Rules) != 2 {
+		t.Fatalf("Expected the naming policy to fail the release, got %+v", result)
+	}
+	naming := result.Rules[0]
+	expected := []string{`dupe suffix "_1"`, "has no resolution tag"}
+	if naming.Type != "name" || naming.Valid || !slices.Equal(naming.Issues, expected) {
+		t.Errorf("Expected a failed name rule with issues %q, got %+v", expected, naming)
+	}
+	if !result.Rules[1].Valid {
+		t.Errorf("Expected the NFO rule to pass, got %+v", result.Rules[1])
+	}
+}
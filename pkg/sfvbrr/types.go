@@ -0,0 +1,246 @@
+package sfvbrr
+
+import (
+	"time"
+
+	"github.com/autobrr/sfvbrr/internal/preset"
+)
+
+// Checks that can be run on a release folder
+const (
+	CheckRules = preset.CheckRules // Validate the folder contents against the category rules
+	CheckSFV   = preset.CheckSFV   // Validate the CRC-32 checksums of every SFV file
+	CheckZIP   = preset.CheckZIP   // Test the integrity of every ZIP file
+	CheckRAR   = preset.CheckRAR   // Validate every RAR volume set
+)
+
+// Progress describes how far a validation has come
+type Progress struct {
+	Check     string // Check being run: CheckSFV or CheckZIP
+	Completed int    // Number of validated files or ZIP entries
+	Total     int    // Total number of files or ZIP entries of the current SFV or ZIP validation
+}
+
+// ProgressFunc is called after every validated file or ZIP entry. It is called from a
+// single goroutine, but must return quickly as it holds up the validation.
+type ProgressFunc func(Progress)
+
+// Options contains configuration options for validation. The zero value is ready to use.
+type Options struct {
+	Workers      int          // Number of parallel workers (0 = auto)
+	BufferSize   int          // Buffer size for file reading (0 = auto)
+	CheckOrphans bool         // Report files on disk that are not listed in an SFV
+	Progress     ProgressFunc // Progress hook (nil = none)
+
+	Presets  *Presets // Preset configuration for release validation (nil = default presets)
+	Category string   // Category of a release (empty = detect from the folder name)
+}
+
+// Presets is a loaded preset configuration with the rules of every release category
+type Presets struct {
+	config *preset.PresetConfig
+}
+
+// Categories returns the names of the configured release categories
+func (p *Presets) Categories() []string {
+	categories := make([]string, 0, len(p.config.Rules))
+	for category := range p.config.Rules {
+		categories = append(categories, category)
+	}
+	return categories
+}
+
+// FileResult is the result of validating a single file of an SFV or entry of a ZIP
+type FileResult struct {
+	Name      string // File name as listed in the SFV, or entry name inside the ZIP
+	Path      string // Path of the file, or of the ZIP file containing the entry
+	Expected  string // Expected checksum (SFV only)
+	Computed  string // Computed checksum (SFV only)
+	Bytes     int64  // Number of bytes read to verify the file or entry
+	Valid     bool
+	Missing   bool  // The file listed in the SFV does not exist
+	Cancelled bool  // The file was not verified because the context was done
+	Err       error // Reason the file is invalid
+}
+
+// SFVResult is the result of validating an SFV file
+type SFVResult struct {
+	Path      string       // Path of the SFV file or checksum manifest
+	Algorithm string       // Checksum algorithm: crc32, md5, sha1, sha256 or blake3
+	Files     []FileResult // Results in SFV order
+	Total     int
+	Valid     int
+	Invalid   int      // Files with a checksum mismatch or read error
+	Missing   int      // Files listed in the SFV that don't exist
+	Orphans   []string // Files on disk not listed in the SFV, if Options.CheckOrphans is set
+	Bytes     int64    // Number of bytes read and verified
+	Cancelled bool     // The validation was cancelled before every file was verified
+}
+
+// OK reports whether every file is present and valid and no orphans were found
+func (r *SFVResult) OK() bool {
+	return r.Invalid == 0 && r.Missing == 0 && len(r.Orphans) == 0 && !r.Cancelled
+}
+
+// ZIPResult is the result of testing a ZIP file
+type ZIPResult struct {
+	Path      string       // Path of the ZIP file
+	Entries   []FileResult // Results in central directory order
+	Total     int
+	Valid     int
+	Invalid   int
+	Bytes     int64 // Number of uncompressed bytes read and tested
+	Cancelled bool  // The test was cancelled before every entry was read
+	Err       error // The ZIP file could not be opened or parsed
+}
+
+// OK reports whether the ZIP file could be read and every entry is valid
+func (r *ZIPResult) OK() bool {
+	return r.Err == nil && r.Invalid == 0 && !r.Cancelled
+}
+
+// RuleResult is the result of a single category rule
+type RuleResult struct {
+	Pattern     string
+	Type        string // "file", "dir" or "name" (naming policy of the release name)
+	Description string
+	Matched     int // Number of matching files or directories
+	Valid       bool
+	Err         error
+	Issues      []string          // Individual problems found by rule verifications (e.g. RAR volume sets)
+	Containers  []ContainerResult // Container checks of the matching files of verify: container rules
+	Audio       []AudioResult     // Header checks of the matching audio files of verify: audio rules
+	Contents    []ContentResult   // Content checks of the matching NFO or DIZ files of content rules
+	DiskSet     *DiskSetResult    // Disk set check of the matching ZIP files of verify: disks rules
+	Playlist    *PlaylistResult   // Track list check of the matching playlists of verify: playlist rules
+}
+
+// PlaylistResult is the result of cross-checking the playlists, SFV files and audio files
+// of a music release
+type PlaylistResult struct {
+	Playlists  []string     // Paths of the playlists, relative to the release folder
+	Tracks     []AudioTrack // Audio files in track order
+	Discs      int          // Number of discs the tracks are numbered on
+	Missing    []string     // Files listed in a playlist that are not on disk
+	Unlisted   []string     // Audio files not listed in any playlist
+	Unverified []string     // Audio files not listed in any SFV file
+}
+
+// AudioTrack describes a single audio file of a music release
+type AudioTrack struct {
+	Name   string // File name of the audio file
+	Disc   int    // Disc number from a "105-" prefix (1 for "05-", 0 = unnumbered)
+	Number int    // Track number on the disc
+}
+
+// DiskSetResult is the result of cross-checking the ZIP files of a 0-day release
+type DiskSetResult struct {
+	Total        int          // Number of disks stated by the file_id.diz files
+	Disks        []DiskResult // ZIP files ordered by disk number
+	MissingDisks []int        // Disk numbers that are not present
+}
+
+// DiskResult describes a single ZIP file of a 0-day release
+type DiskResult struct {
+	Name       string   // File name of the ZIP file
+	Number     int      // Disk number (0 = unknown)
+	Total      int      // Number of disks stated by its file_id.diz (0 = unknown)
+	RARVolumes []string // Names of the RAR volumes inside the ZIP file
+	Err        error    // The ZIP file could not be read
+}
+
+// ContentResult is the result of checking the content of an NFO or file_id.diz file
+type ContentResult struct {
+	Path    string   // Path of the file, relative to the release folder
+	Release string   // Release name found in the text
+	Group   string   // Release group
+	Links   []Link   // IMDb, TVDB and Discogs links
+	Disk    int      // Disk number of a "[01/15]" marker (0 for "[xx/15]")
+	Disks   int      // Total number of disks of a "[01/15]" marker
+	Issues  []string // Failed content assertions
+}
+
+// Link is a link to a database entry found in an NFO or DIZ file
+type Link struct {
+	Site string // "imdb", "tvdb" or "discogs"
+	URL  string
+	ID   string
+}
+
+// ContainerResult is the result of checking the Matroska or MP4 container of a video file
+type ContainerResult struct {
+	Path     string // Path of the file, relative to the release folder
+	Format   string // "matroska", "webm" or "mp4"
+	Duration time.Duration
+	Tracks   []Track
+	Err      error // The container is broken or truncated
+}
+
+// AudioResult is the result of checking the headers of an MP3 or FLAC file
+type AudioResult struct {
+	Path          string // Path of the file, relative to the release folder
+	Format        string // "mp3" or "flac"
+	Duration      time.Duration
+	SampleRate    int    // Hz
+	Channels      int    // Number of channels
+	BitsPerSample int    // Bits per sample of FLAC files
+	Bitrate       int    // Average bitrate in kbps
+	VBR           bool   // MP3 file with a Xing or VBRI header
+	ID3v2         string // Version of the ID3v2 tag, e.g. "2.4" (empty = none)
+	MD5           string // MD5 of the decoded audio from the FLAC STREAMINFO
+	MD5Verified   bool   // The FLAC audio was decoded and matched the MD5
+	Err           error  // The headers are broken or the file is truncated
+}
+
+// Track describes a single track of a video file
+type Track struct {
+	Number int
+	Type   string // "video", "audio", "subtitle" or "other"
+	Codec  string
+}
+
+// RARResult is the result of validating a RAR volume set
+type RARResult struct {
+	Name           string   // File name of the first volume
+	Volumes        []string // Paths of the volumes found on disk
+	MissingVolumes []string // File names of expected volumes that are not on disk
+	Valid          bool
+	Errors         []error
+}
+
+// RuleSet is a rule set of a category that was applied to a release
+type RuleSet struct {
+	ID   string
+	When map[string][]string // Conditions on the release name attributes, e.g. "source": {"BluRay"}
+}
+
+// SubfolderResult is the result of validating a subfolder of a release, such as a disc of
+// a multi-disc release or an episode of a season pack, against the rules of its category
+type SubfolderResult struct {
+	Path            string
+	Category        string
+	Valid           bool
+	RuleSets        []RuleSet
+	Rules           []RuleResult
+	UnexpectedFiles []string
+	Subfolders      []*SubfolderResult
+	Errors          []error
+}
+
+// ReleaseResult is the combined result of all checks run on a release folder
+type ReleaseResult struct {
+	Path            string
+	Category        string
+	Checks          []string  // Checks run on the release, in order
+	Skipped         []string  // Checks that found nothing to verify (e.g. no SFV files)
+	Valid           bool      // Verdict for the whole release
+	Cancelled       bool      // The checks were cancelled before they were complete
+	RuleSets        []RuleSet // Rule sets of the category whose conditions the release name matched
+	Rules           []RuleResult
+	UnexpectedFiles []string           // Files not matching any rule of a deny_unexpected category
+	Subfolders      []*SubfolderResult // Subfolders validated as releases of their own
+	SFV             []*SFVResult
+	ZIP             []*ZIPResult
+	RAR             []*RARResult
+	Errors          []error // Problems not reported with a rule or check result
+}